@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
@@ -16,6 +17,7 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 
+	"github.com/yourusername/skillsync/internal/domain"
 	"github.com/yourusername/skillsync/internal/handler"
 	"github.com/yourusername/skillsync/internal/middleware"
 	"github.com/yourusername/skillsync/internal/service"
@@ -64,28 +66,129 @@ func main() {
 		log.Fatal().Err(err).Msg("failed to run migrations")
 	}
 
+	// ---- websocket hub ----
+	wsConfig, err := ws.ConfigFromEnv()
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid websocket configuration")
+	}
+	log.Info().
+		Dur("write_wait", wsConfig.WriteWait).
+		Dur("pong_wait", wsConfig.PongWait).
+		Dur("ping_period", wsConfig.PingPeriod).
+		Int64("max_message_size", wsConfig.MaxMessageSize).
+		Int("read_buffer_size", wsConfig.ReadBufferSize).
+		Int("write_buffer_size", wsConfig.WriteBufferSize).
+		Int("max_connections_per_user", wsConfig.MaxConnectionsPerUser).
+		Int("max_code_size", wsConfig.MaxCodeSize).
+		Msg("websocket config")
+	hub := ws.NewHub(wsConfig)
+
 	// ---- services ----
-	claudeService := service.NewClaudeService()
-	userService := service.NewUserService(db)
-	matchService := service.NewMatchService(db, claudeService)
-	repService := service.NewReputationService(db)
+	claudeModels, err := service.ClaudeModelsFromEnv()
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid Claude model configuration")
+	}
+	claudeMode, err := service.ClaudeModeFromEnv()
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid Claude mode")
+	}
+	claudeMaxTokens, err := service.ClaudeMaxTokensFromEnv()
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid Claude max tokens configuration")
+	}
+	log.Info().Str("mode", claudeMode).Interface("models", claudeModels).Interface("max_tokens", claudeMaxTokens).Msg("claude models loaded")
+	claudeService := service.NewClaudeService(claudeMode, claudeModels, claudeMaxTokens)
+	auditService := service.NewAuditService(db)
+	userService := service.NewUserService(db, auditService, hub)
+	scoring, err := service.ScoringConfigFromEnv()
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid compatibility scoring weights")
+	}
+	log.Info().Interface("weights", scoring).Msg("compatibility scoring weights loaded")
+	matchService := service.NewMatchService(db, claudeService, scoring)
+	repWeights, err := service.ReputationWeightsFromEnv()
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid reputation weights")
+	}
+	log.Info().Interface("weights", repWeights).Msg("reputation weights loaded")
+	repService := service.NewReputationService(db, repWeights)
+	onboardingService := service.NewOnboardingService(db, claudeService)
+	blockService := service.NewBlockService(db)
+	tokenService := service.NewTokenService(db)
+	skillService := service.NewSkillService(db)
+	challengeService := service.NewChallengeService(db)
+	projectSuggestionService := service.NewProjectSuggestionService(db, claudeService)
+	statsService := service.NewStatsService(db)
+	moderationService := service.NewModerationService(db, userService, tokenService, hub)
+	idempotencyService := service.NewIdempotencyService(db)
+	apiKeyService := service.NewAPIKeyService(db)
+	if err := moderationService.SeedAdmins(context.Background(), splitIDs(os.Getenv("ADMIN_USER_IDS")),
+		os.Getenv("ADMIN_EMAIL"), os.Getenv("ADMIN_PASSWORD")); err != nil {
+		log.Fatal().Err(err).Msg("failed to seed admin accounts")
+	}
 
-	// ---- websocket hub ----
-	hub := ws.NewHub()
+	// ---- websocket hooks ----
+	notificationService := service.NewNotificationService(db, hub)
+	messageModerator := service.NewMessageModerator(db, claudeService)
+	hub.OnChatMessage(func(msg *domain.Message) {
+		notificationService.NotifyNewMessage(msg)
+		messageModerator.Check(msg)
+	})
+	sessionSweeper := service.NewSessionSweeper(db, auditService)
+	hub.OnActivity(sessionSweeper.RecordActivity)
+	presenceService := service.NewPresenceService(db, hub)
+	hub.OnPresenceChange(presenceService.PresenceChanged)
 	go hub.Run()
 
+	// ---- background workers ----
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	go service.NewDigestWorker(db, matchService).Run(workerCtx)
+	go repService.RunDecayRefresh(workerCtx)
+	go idempotencyService.RunCleanup(workerCtx)
+	go sessionSweeper.Run(workerCtx)
+	go messageModerator.Run(workerCtx)
+	go presenceService.Run(workerCtx)
+
 	// ---- services (oauth) ----
-	oauthService := service.NewOAuthService(db, userService)
+	oauthConfig, err := service.OAuthConfigFromEnv()
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid OAuth configuration")
+	}
+	log.Info().Strs("providers", oauthConfig.EnabledProviders()).Msg("OAuth providers loaded")
+	oauthService := service.NewOAuthService(db, userService, oauthConfig)
+
+	// ---- allowed origins (CORS and websocket) ----
+	allowedOrigins, err := middleware.LoadAllowedOrigins()
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid CORS configuration")
+	}
+	log.Info().Strs("origins", allowedOrigins).Msg("CORS origins loaded")
+
+	// ---- request timeouts ----
+	timeouts, err := middleware.TimeoutConfigFromEnv()
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid request timeout configuration")
+	}
+	log.Info().Dur("default", timeouts.Default).Dur("ai", timeouts.AI).Msg("request timeouts loaded")
 
 	// ---- handlers ----
-	authHandler := handler.NewAuthHandler(userService)
-	oauthHandler := handler.NewOAuthHandler(oauthService)
-	userHandler := handler.NewUserHandler(userService)
-	assessmentHandler := handler.NewAssessmentHandler(claudeService, db)
-	matchHandler := handler.NewMatchHandler(matchService, claudeService, db)
-	repHandler := handler.NewReputationHandler(repService, db)
-	wsHandler := handler.NewWebSocketHandler(hub, db)
-	msgHandler := handler.NewMessageHandler(db, hub)
+	authHandler := handler.NewAuthHandler(userService, auditService, tokenService)
+	oauthHandler := handler.NewOAuthHandler(oauthService, auditService, tokenService)
+	userHandler := handler.NewUserHandler(userService, blockService, presenceService, hub)
+	statsHandler := handler.NewStatsHandler(statsService)
+	assessmentHandler := handler.NewAssessmentHandler(claudeService, challengeService, projectSuggestionService, db)
+	matchHandler := handler.NewMatchHandler(matchService, claudeService, db, hub, notificationService)
+	repHandler := handler.NewReputationHandler(repService, db, notificationService)
+	wsHandler := handler.NewWebSocketHandler(hub, db, moderationService, allowedOrigins)
+	msgHandler := handler.NewMessageHandler(db, hub, notificationService, messageModerator)
+	auditHandler := handler.NewAuditHandler(auditService)
+	onboardingHandler := handler.NewOnboardingHandler(onboardingService)
+	notificationHandler := handler.NewNotificationHandler(notificationService)
+	skillHandler := handler.NewSkillHandler(skillService, auditService)
+	moderationHandler := handler.NewModerationHandler(moderationService, auditService)
+	challengeHandler := handler.NewChallengeHandler(challengeService)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService, auditService)
 
 	// ---- echo ----
 	e := echo.New()
@@ -95,10 +198,11 @@ func main() {
 	// ---- global middleware ----
 	e.Use(middleware.RequestLoggerMiddleware())
 	e.Use(echomw.Recover())
-	e.Use(middleware.CORSMiddleware())
+	e.Use(middleware.CORSMiddleware(allowedOrigins))
 	e.Use(middleware.SecurityHeadersMiddleware())
 	e.Use(middleware.RateLimitMiddleware(100, time.Minute))
 	e.Use(middleware.RequestSizeLimitMiddleware(10 * 1024 * 1024)) // 10 MB
+	e.Use(middleware.RequestTimeoutMiddleware(timeouts.Default))
 
 	// ---- health routes ----
 	e.GET("/health", healthCheck)
@@ -109,42 +213,110 @@ func main() {
 	authGroup := api.Group("/auth")
 	authGroup.POST("/register", authHandler.Register)
 	authGroup.POST("/login", authHandler.Login)
+	authGroup.POST("/refresh", authHandler.Refresh)
+	authGroup.POST("/logout", authHandler.Logout)
 
 	// OAuth routes
-	authGroup.GET("/google/login", oauthHandler.GoogleLogin)
-	authGroup.GET("/google/callback", oauthHandler.GoogleCallback)
-	authGroup.GET("/github/login", oauthHandler.GitHubLogin)
-	authGroup.GET("/github/callback", oauthHandler.GitHubCallback)
+	authGroup.GET("/google/login", oauthHandler.Login("google"))
+	authGroup.GET("/google/callback", oauthHandler.Callback("google"))
+	authGroup.GET("/github/login", oauthHandler.Login("github"))
+	authGroup.GET("/github/callback", oauthHandler.Callback("github"))
+	authGroup.GET("/gitlab/login", oauthHandler.Login("gitlab"))
+	authGroup.GET("/gitlab/callback", oauthHandler.Callback("gitlab"))
+
+	// Public stats
+	api.GET("/stats", statsHandler.GetPublicStats)
+	api.GET("/reputation/formula", repHandler.GetReputationFormula)
 
 	// ---- protected routes ----
 	protected := api.Group("")
-	protected.Use(middleware.JWTMiddleware())
+	protected.Use(middleware.JWTMiddleware(moderationService, apiKeyService))
+	protected.Use(middleware.ActivityMiddleware(presenceService))
+	idempotent := middleware.IdempotencyMiddleware(idempotencyService)
+	// Recalculation runs several aggregation queries per user.
+	recalcLimit := middleware.UserRateLimitMiddleware("reputation-recalculate", 5, 10*time.Minute)
+	// Routes that wait on Claude get the longer deadline.
+	aiTimeout := middleware.RequestTimeout(timeouts.AI)
+	// Account and key management can't be done with an API key.
+	sessionOnly := middleware.RequireSession()
 
 	// Auth
 	protected.GET("/auth/me", authHandler.GetMe)
+	protected.PUT("/auth/password", authHandler.ChangePassword, sessionOnly)
 
 	// Users
 	protected.GET("/users", userHandler.GetUsers)
+	protected.POST("/users/batch", userHandler.GetUsersBatch)
+	protected.GET("/users/me/network", matchHandler.GetMyNetwork)
+	protected.GET("/users/me/stats", userHandler.GetMyStats)
+	protected.GET("/users/me/export", userHandler.ExportMyData, middleware.RequestTimeout(timeouts.Export))
+	protected.GET("/users/me/project-suggestions", assessmentHandler.GetMyProjectSuggestions, aiTimeout)
+	protected.DELETE("/users/me", authHandler.DeleteAccount, sessionOnly)
+	protected.POST("/users/me/api-keys", apiKeyHandler.CreateAPIKey, sessionOnly)
+	protected.GET("/users/me/api-keys", apiKeyHandler.GetAPIKeys, sessionOnly)
+	protected.DELETE("/users/me/api-keys/:id", apiKeyHandler.RevokeAPIKey, sessionOnly)
+	protected.POST("/users/me/import-github", onboardingHandler.ImportGitHub, aiTimeout)
 	protected.GET("/users/:id", userHandler.GetUser)
 	protected.PUT("/users/:id", userHandler.UpdateUser)
 	protected.POST("/users/:id/skills", userHandler.AddUserSkill)
+	protected.POST("/users/:id/skills/bulk", userHandler.BulkAddUserSkills)
 	protected.GET("/users/:id/reputation", userHandler.GetUserReputation)
+	protected.POST("/users/:id/reputation/recalculate", repHandler.RecalculateReputation, recalcLimit)
+	protected.GET("/users/:id/trust-score", repHandler.GetTrustScore)
+	protected.GET("/users/:id/badges", repHandler.GetUserBadges)
+	protected.GET("/users/:id/presence", userHandler.GetUserPresence)
+	protected.GET("/users/:id/similar", matchHandler.GetSimilarUsers)
+	protected.POST("/users/:id/block", userHandler.BlockUser)
+	protected.DELETE("/users/:id/block", userHandler.UnblockUser)
+	protected.POST("/users/:id/report", userHandler.ReportUser)
+
+	// Skills
+	protected.GET("/skills", skillHandler.SearchSkills)
+	protected.GET("/skills/categories", skillHandler.GetSkillCategories)
+
+	// Onboarding
+	protected.GET("/onboarding/suggested-skills", onboardingHandler.GetSuggestedSkills, aiTimeout)
+
+	// Challenges
+	protected.GET("/challenges", challengeHandler.ListChallenges)
+	protected.GET("/challenges/:id", challengeHandler.GetChallenge)
 
 	// Assessments
-	protected.POST("/assessments", assessmentHandler.SubmitCode)
-	protected.POST("/assessments/hint", assessmentHandler.GetHint)
+	protected.POST("/assessments", assessmentHandler.SubmitCode, aiTimeout)
+	protected.POST("/assessments/hint", assessmentHandler.GetHint, aiTimeout)
 	protected.GET("/assessments/history", assessmentHandler.GetAssessmentHistory)
-	protected.GET("/projects/suggestions", assessmentHandler.GetProjectSuggestions)
+	protected.GET("/assessments/stats", assessmentHandler.GetAssessmentStats)
+	protected.GET("/assessments/:id", assessmentHandler.GetAssessment)
+	protected.GET("/projects/suggestions", assessmentHandler.GetProjectSuggestions, aiTimeout)
 
 	// Matches
-	protected.GET("/matches/suggestions", matchHandler.GetMatchSuggestions)
-	protected.POST("/matches/request", matchHandler.SendMatchRequest)
-	protected.PUT("/matches/request/:id/accept", matchHandler.AcceptMatchRequest)
+	protected.GET("/matches/suggestions", matchHandler.GetMatchSuggestions, aiTimeout)
+	protected.GET("/matches/mentees", matchHandler.GetMentees)
+	protected.GET("/matches/digest", matchHandler.GetMatchDigest)
+	protected.GET("/matches/compatibility/:userId", matchHandler.GetCompatibility)
+	protected.GET("/matches/with/:userId", matchHandler.GetMatchWith)
+	protected.POST("/matches/request", matchHandler.SendMatchRequest, aiTimeout, idempotent)
+	protected.GET("/matches/request/:id", matchHandler.GetMatchRequest)
+	protected.PUT("/matches/request/:id/accept", matchHandler.AcceptMatchRequest, aiTimeout)
 	protected.PUT("/matches/request/:id/reject", matchHandler.RejectMatchRequest)
 	protected.GET("/matches", matchHandler.GetMyMatches)
 	protected.GET("/matches/requests/pending", matchHandler.GetPendingRequests)
-	protected.GET("/matches/:id/insights", matchHandler.GetMatchInsights)
-	protected.GET("/matches/:id/suggestions", matchHandler.GetCollaborationSuggestions)
+	protected.GET("/matches/:id/summary", matchHandler.GetMatchSummary)
+	protected.GET("/matches/:id/insights", matchHandler.GetMatchInsights, aiTimeout)
+	protected.POST("/matches/:id/insights/regenerate", matchHandler.RegenerateMatchInsights, aiTimeout)
+	protected.GET("/matches/:id/suggestions", matchHandler.GetCollaborationSuggestions, aiTimeout)
+	protected.GET("/matches/:id/predict-success", matchHandler.PredictSessionSuccess, aiTimeout)
+	protected.GET("/matches/:id/sessions", matchHandler.GetMatchSessions)
+	protected.GET("/matches/:id/activity", matchHandler.GetMatchActivity)
+	protected.POST("/matches/:id/sessions/propose", matchHandler.ProposeSession)
+	protected.GET("/matches/:id/sessions/proposals", matchHandler.GetSessionProposals)
+	protected.PUT("/matches/:id/sessions/proposals/:proposalId/accept", matchHandler.AcceptSessionProposal)
+	protected.PUT("/matches/:id/sessions/proposals/:proposalId/decline", matchHandler.DeclineSessionProposal)
+	protected.DELETE("/matches/:id", matchHandler.Unmatch)
+	protected.POST("/matches/:id/restore", matchHandler.RestoreMatch)
+
+	// Compatibility
+	protected.POST("/compatibility/matrix", matchHandler.GetCompatibilityMatrix)
 
 	// Messages
 	protected.GET("/matches/:matchId/messages", msgHandler.GetMessages)
@@ -152,10 +324,37 @@ func main() {
 	protected.PUT("/messages/read", msgHandler.MarkMessagesRead)
 
 	// Reputation & Ratings
-	protected.POST("/ratings", repHandler.SubmitRating)
+	protected.POST("/ratings", repHandler.SubmitRating, idempotent)
+	protected.PUT("/ratings/:id", repHandler.UpdateRating)
+	protected.DELETE("/ratings/:id", repHandler.RetractRating)
 	protected.POST("/sessions/:id/feedback", repHandler.SubmitSessionFeedback)
 	protected.GET("/ratings/received", repHandler.GetMyRatings)
+	protected.GET("/ratings/trends", repHandler.GetRatingTrends)
+	protected.GET("/ratings/pending", repHandler.GetPendingRatings)
 	protected.GET("/leaderboard", repHandler.GetLeaderboard)
+	protected.GET("/leaderboard/skill/:skillName", repHandler.GetSkillLeaderboard)
+
+	// Notifications
+	protected.GET("/notifications", notificationHandler.GetNotifications)
+	protected.GET("/notifications/unread-count", notificationHandler.GetUnreadCount)
+	protected.PUT("/notifications/:id/read", notificationHandler.MarkNotificationRead)
+
+	// Admin
+	admin := protected.Group("/admin")
+	admin.Use(middleware.RequireRole(string(domain.RoleAdmin)))
+	admin.GET("/audit-logs", auditHandler.GetAuditLogs)
+	admin.POST("/skills/merge", skillHandler.MergeSkills)
+	admin.GET("/scoring-weights", matchHandler.GetScoringConfig)
+	admin.GET("/reports", moderationHandler.GetReportedUsers)
+	admin.GET("/stats", statsHandler.GetAdminStats)
+	// Pool stats, connection counts and the Claude mode aren't for the public.
+	admin.GET("/health", healthDetailed(hub, claudeMode))
+	admin.POST("/reputation/recalculate", repHandler.RecalculateAllReputations, recalcLimit)
+	admin.POST("/users/:id/ban", moderationHandler.BanUser)
+	admin.DELETE("/users/:id/ban", moderationHandler.UnbanUser)
+	admin.DELETE("/messages/:id", moderationHandler.DeleteMessage)
+	admin.GET("/moderation/messages", moderationHandler.GetFlaggedMessages)
+	admin.POST("/moderation/messages/:id/review", moderationHandler.ReviewFlaggedMessage)
 
 	// WebSocket (auth is done inside the handler via query param)
 	e.GET("/ws", wsHandler.HandleWebSocket)
@@ -179,6 +378,7 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	sig := <-quit
 	log.Info().Str("signal", sig.String()).Msg("shutting down server")
+	stopWorkers()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -190,6 +390,17 @@ func main() {
 	log.Info().Msg("server exited")
 }
 
+// splitIDs parses a comma-separated list such as ADMIN_USER_IDS.
+func splitIDs(raw string) []string {
+	var ids []string
+	for _, id := range strings.Split(raw, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
 // ---------------------------------------------------------------------------
 // Health check handlers
 // ---------------------------------------------------------------------------
@@ -219,3 +430,74 @@ func healthDB(c echo.Context) error {
 		"status": "ok",
 	})
 }
+
+// ComponentHealth is the status of one dependency in /api/admin/health.
+type ComponentHealth struct {
+	Status   string      `json:"status"` // ok, down or not_configured
+	Critical bool        `json:"critical"`
+	Error    string      `json:"error,omitempty"`
+	Details  interface{} `json:"details,omitempty"`
+}
+
+// healthDetailed reports each dependency separately so a database outage can
+// be told apart from a missing AI key. It returns 503 only when a critical
+// component is down.
+func healthDetailed(hub *ws.Hub, claudeMode string) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		components := make(map[string]ComponentHealth, 3)
+
+		dbHealth := ComponentHealth{Status: "ok", Critical: true}
+		if sqlDB, err := database.GetDB().DB(); err != nil {
+			dbHealth.Status = "down"
+			dbHealth.Error = "cannot get database handle"
+		} else {
+			ctx, cancel := context.WithTimeout(c.Request().Context(), 2*time.Second)
+			err := sqlDB.PingContext(ctx)
+			cancel()
+			if err != nil {
+				dbHealth.Status = "down"
+				dbHealth.Error = "database ping failed"
+			}
+			stats := sqlDB.Stats()
+			dbHealth.Details = map[string]interface{}{
+				"open_connections": stats.OpenConnections,
+				"in_use":           stats.InUse,
+				"idle":             stats.Idle,
+				"max_open":         stats.MaxOpenConnections,
+				"wait_count":       stats.WaitCount,
+				"wait_duration_ms": stats.WaitDuration.Milliseconds(),
+			}
+		}
+		components["database"] = dbHealth
+
+		aiHealth := ComponentHealth{Status: "ok", Details: map[string]string{"mode": claudeMode}}
+		if claudeMode == service.ClaudeModeLive && os.Getenv("ANTHROPIC_API_KEY") == "" {
+			aiHealth.Status = "not_configured"
+			aiHealth.Error = "ANTHROPIC_API_KEY is not set; AI requests will fail"
+		}
+		components["claude"] = aiHealth
+
+		components["websocket"] = ComponentHealth{
+			Status:  "ok",
+			Details: map[string]int{"connected_clients": hub.ClientCount()},
+		}
+
+		status, code := "ok", http.StatusOK
+		for _, comp := range components {
+			if comp.Status == "ok" {
+				continue
+			}
+			if comp.Critical {
+				status, code = "error", http.StatusServiceUnavailable
+				break
+			}
+			status = "degraded"
+		}
+
+		return c.JSON(code, map[string]interface{}{
+			"status":     status,
+			"time":       time.Now().UTC().Format(time.RFC3339),
+			"components": components,
+		})
+	}
+}
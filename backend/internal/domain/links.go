@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var ErrInvalidProfileURL = errors.New("invalid profile url")
+
+var (
+	githubUsernameRe = regexp.MustCompile(`^[A-Za-z0-9](?:[A-Za-z0-9-]{0,37}[A-Za-z0-9])?$`)
+	// linkedinSlugRe matches the vanity part of linkedin.com/in/<slug>.
+	// Non-ASCII slugs arrive percent-encoded.
+	linkedinSlugRe = regexp.MustCompile(`^[A-Za-z0-9%_-]{3,100}$`)
+)
+
+// IsGitHubUsername reports whether s is a valid GitHub login.
+func IsGitHubUsername(s string) bool {
+	return githubUsernameRe.MatchString(s)
+}
+
+// NormalizeGitHubURL turns a link to a GitHub profile, with or without
+// scheme, "www." or trailing slash, into https://github.com/<username>.
+// An empty string stays empty so the link can be cleared; anything else
+// fails with ErrInvalidProfileURL.
+func NormalizeGitHubURL(s string) (string, error) {
+	if strings.TrimSpace(s) == "" {
+		return "", nil
+	}
+	segments, ok := profilePath(s, "github.com", false)
+	if !ok || len(segments) != 1 || !IsGitHubUsername(segments[0]) {
+		return "", fmt.Errorf("%w: github_url must look like https://github.com/username", ErrInvalidProfileURL)
+	}
+	return "https://github.com/" + segments[0], nil
+}
+
+// NormalizeLinkedInURL turns a link to a LinkedIn member profile, including
+// country subdomains such as uk.linkedin.com, into
+// https://www.linkedin.com/in/<slug>. An empty string stays empty; anything
+// else fails with ErrInvalidProfileURL.
+func NormalizeLinkedInURL(s string) (string, error) {
+	if strings.TrimSpace(s) == "" {
+		return "", nil
+	}
+	segments, ok := profilePath(s, "linkedin.com", true)
+	if !ok || len(segments) != 2 || segments[0] != "in" || !linkedinSlugRe.MatchString(segments[1]) {
+		return "", fmt.Errorf("%w: linkedin_url must look like https://www.linkedin.com/in/your-name", ErrInvalidProfileURL)
+	}
+	return "https://www.linkedin.com/in/" + segments[1], nil
+}
+
+// profilePath parses s as an http(s) URL on domain, "www." + domain or, with
+// anySubdomain, any subdomain of it, and returns its non-empty path segments.
+// Query and fragment are ignored.
+func profilePath(s, domain string, anySubdomain bool) ([]string, bool) {
+	s = strings.TrimSpace(s)
+	if !strings.Contains(s, "://") {
+		s = "https://" + s
+	}
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.User != nil || u.Port() != "" {
+		return nil, false
+	}
+	host := strings.ToLower(u.Hostname())
+	switch {
+	case host == domain, host == "www."+domain:
+	case anySubdomain && strings.HasSuffix(host, "."+domain):
+	default:
+		return nil, false
+	}
+
+	var segments []string
+	for _, seg := range strings.Split(u.EscapedPath(), "/") {
+		if seg != "" {
+			segments = append(segments, seg)
+		}
+	}
+	return segments, true
+}
@@ -4,6 +4,9 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"net/url"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -51,6 +54,28 @@ func (j *JSONB) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// StringList stores a list of strings in a PostgreSQL jsonb column.
+type StringList []string
+
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]string(l))
+}
+
+func (l *StringList) Scan(value interface{}) error {
+	if value == nil {
+		*l = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("StringList.Scan: type assertion to []byte failed")
+	}
+	return json.Unmarshal(bytes, (*[]string)(l))
+}
+
 // SkillCategory constrains the category column on skills.
 type SkillCategory string
 
@@ -64,6 +89,12 @@ const (
 	CategoryOther     SkillCategory = "other"
 )
 
+// SkillCategories lists every SkillCategory.
+var SkillCategories = []SkillCategory{
+	CategoryLanguage, CategoryFramework, CategoryTool, CategoryConcept,
+	CategoryDatabase, CategoryDevOps, CategoryOther,
+}
+
 // ProficiencyLevel constrains proficiency on user skills.
 type ProficiencyLevel string
 
@@ -73,6 +104,32 @@ const (
 	Advanced     ProficiencyLevel = "advanced"
 )
 
+// SkillDirection says whether a user wants to teach a skill, learn it, or both.
+type SkillDirection string
+
+const (
+	DirectionTeach SkillDirection = "teach"
+	DirectionLearn SkillDirection = "learn"
+	DirectionBoth  SkillDirection = "both"
+)
+
+// Cadence is how often a user wants to pair, from most to least frequent.
+type Cadence string
+
+const (
+	CadenceDaily      Cadence = "daily"
+	CadenceFewPerWeek Cadence = "few_per_week"
+	CadenceWeekly     Cadence = "weekly"
+	CadenceBiweekly   Cadence = "biweekly"
+	CadenceMonthly    Cadence = "monthly"
+)
+
+// Cadences lists every Cadence in frequency order.
+var Cadences = []Cadence{CadenceDaily, CadenceFewPerWeek, CadenceWeekly, CadenceBiweekly, CadenceMonthly}
+
+// SessionLengths lists the allowed values for PreferredSessionMinutes.
+var SessionLengths = []int{30, 45, 60, 90, 120}
+
 // MatchStatus constrains the status column on matches.
 type MatchStatus string
 
@@ -90,6 +147,109 @@ const (
 	RequestRejected RequestStatus = "rejected"
 )
 
+// UserRole constrains the role column on users and the role JWT claim.
+type UserRole string
+
+const (
+	RoleUser  UserRole = "user"
+	RoleAdmin UserRole = "admin"
+)
+
+// AuditAction constrains the action column on audit_log.
+type AuditAction string
+
+const (
+	AuditLogin           AuditAction = "login"
+	AuditLoginFailed     AuditAction = "login_failed"
+	AuditPasswordChange  AuditAction = "password_change"
+	AuditOAuthLink       AuditAction = "oauth_link"
+	AuditAccountDeletion AuditAction = "account_deletion"
+	AuditAdminAction     AuditAction = "admin_action"
+	AuditSessionIdleEnd  AuditAction = "session_idle_end"
+	AuditAPIKeyCreate    AuditAction = "api_key_create"
+	AuditAPIKeyRevoke    AuditAction = "api_key_revoke"
+)
+
+// MessageType constrains the type column on messages.
+type MessageType string
+
+const (
+	MessageText MessageType = "text"
+	MessageCode MessageType = "code"
+	MessageFile MessageType = "file"
+)
+
+// NotificationType constrains the type column on notifications.
+type NotificationType string
+
+const (
+	NotifyMatchRequest         NotificationType = "match_request"
+	NotifyMatchRequestAccepted NotificationType = "match_request_accepted"
+	NotifyMatchRequestRejected NotificationType = "match_request_rejected"
+	NotifyNewMessage           NotificationType = "new_message"
+	NotifyNewRating            NotificationType = "new_rating"
+	NotifySessionProposed      NotificationType = "session_proposed"
+	NotifySessionAccepted      NotificationType = "session_proposal_accepted"
+	NotifySessionDeclined      NotificationType = "session_proposal_declined"
+)
+
+var (
+	ErrInvalidMessageType     = errors.New("message type must be one of text, code, file")
+	ErrInvalidMessageMetadata = errors.New("invalid message metadata")
+)
+
+// MessageMetadata describes a code snippet or file reference attached to a
+// message. Language applies to code messages; Filename and URL to files.
+type MessageMetadata struct {
+	Language string `json:"language,omitempty"`
+	Filename string `json:"filename,omitempty"`
+	URL      string `json:"url,omitempty"`
+	Size     int64  `json:"size,omitempty"`
+}
+
+// NormalizeMessage validates a message's type and metadata, defaulting an
+// empty type to text, and returns the metadata to store (nil for text). File
+// messages need a filename and an http(s) URL.
+func NormalizeMessage(t MessageType, meta *MessageMetadata) (MessageType, JSONB, error) {
+	if t == "" {
+		t = MessageText
+	}
+
+	var m MessageMetadata
+	switch t {
+	case MessageText:
+		return t, nil, nil
+	case MessageCode:
+		if meta != nil {
+			m.Language = strings.ToLower(strings.TrimSpace(meta.Language))
+		}
+		if len(m.Language) > 32 {
+			return "", nil, fmt.Errorf("%w: language hint is too long", ErrInvalidMessageMetadata)
+		}
+	case MessageFile:
+		if meta == nil {
+			return "", nil, fmt.Errorf("%w: file messages need a filename and url", ErrInvalidMessageMetadata)
+		}
+		m.Filename = strings.TrimSpace(meta.Filename)
+		m.URL = strings.TrimSpace(meta.URL)
+		m.Size = meta.Size
+		if m.Filename == "" || len(m.Filename) > 255 {
+			return "", nil, fmt.Errorf("%w: filename is required and at most 255 characters", ErrInvalidMessageMetadata)
+		}
+		if u, err := url.Parse(m.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return "", nil, fmt.Errorf("%w: url must be an http(s) link", ErrInvalidMessageMetadata)
+		}
+		if m.Size < 0 {
+			return "", nil, fmt.Errorf("%w: size cannot be negative", ErrInvalidMessageMetadata)
+		}
+	default:
+		return "", nil, ErrInvalidMessageType
+	}
+
+	data, _ := json.Marshal(m)
+	return t, JSONB(data), nil
+}
+
 // ---------------------------------------------------------------------------
 // Models
 // ---------------------------------------------------------------------------
@@ -106,18 +266,64 @@ type User struct {
 	LinkedinURL     string         `gorm:"type:varchar(512)" json:"linkedin_url"`
 	GoogleID        string         `gorm:"type:varchar(255);index" json:"-"`
 	GitHubID        string         `gorm:"type:varchar(255);index" json:"-"`
+	GitLabID        string         `gorm:"type:varchar(255);index" json:"-"`
+	GitHubToken     string         `gorm:"column:github_token;type:text" json:"-"` // AES-GCM encrypted, see auth.EncryptSecret
 	ReputationScore float64        `gorm:"type:decimal(10,2);default:0" json:"reputation_score"`
 	TotalSessions   int            `gorm:"default:0" json:"total_sessions"`
 	Badges          JSONB          `gorm:"type:jsonb;default:'[]'" json:"badges"`
+	Role            UserRole       `gorm:"type:varchar(20);not null;default:'user'" json:"role"`
+	BannedUntil     *time.Time     `gorm:"index" json:"banned_until,omitempty"`
 	CreatedAt       time.Time      `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt       time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
 	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
 
+	// Pairing availability; empty / zero means not set. Timezone is an IANA
+	// name such as "Europe/Berlin"; PreferredLanguages are lowercase
+	// programming language names.
+	PreferredCadence        Cadence    `gorm:"type:varchar(20);default:''" json:"preferred_cadence"`
+	PreferredSessionMinutes int        `gorm:"default:0" json:"preferred_session_minutes"`
+	Timezone                string     `gorm:"type:varchar(64);default:''" json:"timezone"`
+	PreferredLanguages      StringList `gorm:"type:jsonb;default:'[]'" json:"preferred_languages"`
+
+	// Presence, kept current by PresenceService: IsOnline while the user has
+	// a websocket open, LastActiveAt on connects, disconnects and API use.
+	IsOnline     bool       `gorm:"default:false" json:"is_online"`
+	LastActiveAt *time.Time `json:"last_active_at,omitempty"`
+
 	// Relations
 	Skills     []UserSkill    `gorm:"foreignKey:UserID" json:"skills,omitempty"`
 	Reputation *UserReputation `gorm:"foreignKey:UserID" json:"reputation,omitempty"`
 }
 
+// Teaches reports whether the user offers to teach this skill.
+func (us UserSkill) Teaches() bool {
+	return us.Direction == DirectionTeach || us.Direction == DirectionBoth || us.Direction == ""
+}
+
+// Learns reports whether the user wants to learn this skill.
+func (us UserSkill) Learns() bool {
+	return us.Direction == DirectionLearn || us.Direction == DirectionBoth || us.Direction == ""
+}
+
+// IsBanned reports whether the user is suspended at now.
+func (u *User) IsBanned(now time.Time) bool {
+	return u.BannedUntil != nil && u.BannedUntil.After(now)
+}
+
+// OAuthID returns the linked identity for provider ("google", "github" or
+// "gitlab").
+func (u *User) OAuthID(provider string) string {
+	switch provider {
+	case "google":
+		return u.GoogleID
+	case "github":
+		return u.GitHubID
+	case "gitlab":
+		return u.GitLabID
+	}
+	return ""
+}
+
 type Skill struct {
 	ID          uint          `gorm:"primaryKey" json:"id"`
 	Name        string        `gorm:"uniqueIndex;type:varchar(100);not null" json:"name" validate:"required"`
@@ -134,6 +340,7 @@ type UserSkill struct {
 	YearsExperience float64          `gorm:"type:decimal(4,1)" json:"years_experience"`
 	CredibilityScore float64         `gorm:"type:decimal(10,2);default:0" json:"credibility_score"`
 	VerifiedByPeers int              `gorm:"default:0" json:"verified_by_peers"`
+	Direction       SkillDirection   `gorm:"type:varchar(10);not null;default:'both'" json:"direction"`
 	CreatedAt       time.Time        `gorm:"autoCreateTime" json:"created_at"`
 
 	// Relations
@@ -150,6 +357,18 @@ type Match struct {
 	Status     MatchStatus `gorm:"type:varchar(20);default:'active';index" json:"status"`
 	CreatedAt  time.Time   `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt  time.Time   `gorm:"autoUpdateTime" json:"updated_at"`
+	// User1HiddenAt and User2HiddenAt are set when that participant deletes
+	// the match from their list; it stays listed for the other one. Hiding
+	// never removes the match, so its messages stay readable to both.
+	User1HiddenAt *time.Time `json:"-"`
+	User2HiddenAt *time.Time `json:"-"`
+	// ArchivedBy is the participant who archived the match, the only one who
+	// may reactivate it. Nil for active matches and ones archived otherwise.
+	ArchivedBy *string `gorm:"type:uuid" json:"archived_by,omitempty"`
+
+	// InsightsGeneratedAt is when AIInsights was last written; used to
+	// throttle on-demand regeneration.
+	InsightsGeneratedAt *time.Time `json:"insights_generated_at,omitempty"`
 
 	// Relations
 	User1    User      `gorm:"foreignKey:User1ID;constraint:OnDelete:CASCADE" json:"user1,omitempty"`
@@ -181,6 +400,11 @@ type Message struct {
 	IsRead     bool      `gorm:"default:false" json:"is_read"`
 	CreatedAt  time.Time `gorm:"autoCreateTime;index" json:"created_at"`
 
+	// Type says how to render Content; Metadata holds a MessageMetadata for
+	// code and file messages.
+	Type     MessageType `gorm:"type:varchar(10);not null;default:'text'" json:"type"`
+	Metadata JSONB       `gorm:"type:jsonb" json:"metadata,omitempty"`
+
 	// Relations
 	Sender   User  `gorm:"foreignKey:SenderID;constraint:OnDelete:CASCADE" json:"sender,omitempty"`
 	Receiver User  `gorm:"foreignKey:ReceiverID;constraint:OnDelete:CASCADE" json:"receiver,omitempty"`
@@ -198,11 +422,59 @@ type CodingSession struct {
 	SuccessRating   float64    `gorm:"type:decimal(3,2)" json:"success_rating"`
 	CreatedAt       time.Time  `gorm:"autoCreateTime" json:"created_at"`
 
+	// LastActivityAt is the last chat message or code change in the match
+	// while the session was open; idle sessions are auto-ended from it.
+	LastActivityAt *time.Time `json:"last_activity_at,omitempty"`
+
 	// Relations
 	Match    Match             `gorm:"foreignKey:MatchID;constraint:OnDelete:CASCADE" json:"match,omitempty"`
 	Feedback []SessionFeedback `gorm:"foreignKey:SessionID" json:"feedback,omitempty"`
 }
 
+// ProposalStatus constrains the status column on session_proposals.
+type ProposalStatus string
+
+const (
+	ProposalPending  ProposalStatus = "pending"
+	ProposalAccepted ProposalStatus = "accepted"
+	ProposalDeclined ProposalStatus = "declined"
+)
+
+// SessionProposal is one participant's suggested time for a coding session
+// in a match, awaiting the other participant's answer. SessionID is set when
+// accepting it scheduled a CodingSession.
+type SessionProposal struct {
+	ID              uint           `gorm:"primaryKey" json:"id"`
+	MatchID         uint           `gorm:"not null;index" json:"match_id"`
+	ProposerID      string         `gorm:"type:uuid;not null" json:"proposer_id"`
+	RecipientID     string         `gorm:"type:uuid;not null" json:"recipient_id"`
+	StartsAt        time.Time      `gorm:"not null" json:"starts_at"`
+	DurationMinutes int            `gorm:"not null" json:"duration_minutes"`
+	Note            string         `gorm:"type:text" json:"note"`
+	Status          ProposalStatus `gorm:"type:varchar(20);default:'pending'" json:"status"`
+	SessionID       *uint          `json:"session_id,omitempty"`
+	RespondedAt     *time.Time     `json:"responded_at,omitempty"`
+	CreatedAt       time.Time      `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// EndsAt is when the proposed session would finish.
+func (p *SessionProposal) EndsAt() time.Time {
+	return p.StartsAt.Add(time.Duration(p.DurationMinutes) * time.Minute)
+}
+
+// Challenge is a coding problem that assessments are scored against.
+// TestCases is a JSON array of {"input", "expected_output"} objects.
+type Challenge struct {
+	ID               string           `gorm:"type:varchar(100);primaryKey" json:"id"`
+	Title            string           `gorm:"type:varchar(200);not null" json:"title"`
+	Prompt           string           `gorm:"type:text;not null" json:"prompt"`
+	ExpectedBehavior string           `gorm:"type:text;not null" json:"expected_behavior"`
+	TestCases        JSONB            `gorm:"type:jsonb;default:'[]'" json:"test_cases"`
+	Difficulty       ProficiencyLevel `gorm:"type:varchar(20);not null;index" json:"difficulty"`
+	CreatedAt        time.Time        `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt        time.Time        `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
 type Assessment struct {
 	ID            uint      `gorm:"primaryKey" json:"id"`
 	UserID        string    `gorm:"type:uuid;not null;index" json:"user_id"`
@@ -231,6 +503,7 @@ type Rating struct {
 	ReliabilityRating   int       `gorm:"type:smallint;not null;check:reliability_rating >= 1 AND reliability_rating <= 5" json:"reliability_rating" validate:"required,min=1,max=5"`
 	Comment             string    `gorm:"type:text" json:"comment"`
 	CreatedAt           time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt           time.Time `gorm:"autoUpdateTime" json:"updated_at"`
 
 	// Relations
 	Rater   User          `gorm:"foreignKey:RaterID;constraint:OnDelete:CASCADE" json:"rater,omitempty"`
@@ -275,6 +548,148 @@ type UserReputation struct {
 	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"user,omitempty"`
 }
 
+// AuditLog records a security-sensitive action for later review.
+type AuditLog struct {
+	ID         uint        `gorm:"primaryKey" json:"id"`
+	Action     AuditAction `gorm:"type:varchar(50);not null;index" json:"action"`
+	ActorID    string      `gorm:"type:varchar(64);index" json:"actor_id"`
+	TargetType string      `gorm:"type:varchar(50)" json:"target_type"`
+	TargetID   string      `gorm:"type:varchar(64);index" json:"target_id"`
+	IP         string      `gorm:"type:varchar(64)" json:"ip"`
+	Metadata   JSONB       `gorm:"type:jsonb;default:'{}'" json:"metadata"`
+	CreatedAt  time.Time   `gorm:"autoCreateTime;index" json:"created_at"`
+}
+
+func (AuditLog) TableName() string {
+	return "audit_log"
+}
+
+// UserBlock records that BlockerID blocked BlockedID. Blocks are listed only
+// to the blocker but stop match requests in both directions.
+type UserBlock struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	BlockerID string    `gorm:"type:uuid;not null;uniqueIndex:idx_user_block" json:"blocker_id"`
+	BlockedID string    `gorm:"type:uuid;not null;uniqueIndex:idx_user_block;index" json:"blocked_id"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// ReportStatus constrains the status column on user_reports.
+type ReportStatus string
+
+const (
+	ReportOpen     ReportStatus = "open"
+	ReportResolved ReportStatus = "resolved"
+)
+
+// UserReport is an abuse report awaiting moderation.
+type UserReport struct {
+	ID         uint         `gorm:"primaryKey" json:"id"`
+	ReporterID string       `gorm:"type:uuid;not null;index" json:"reporter_id"`
+	ReportedID string       `gorm:"type:uuid;not null;index" json:"reported_id"`
+	Reason     string       `gorm:"type:text;not null" json:"reason"`
+	Status     ReportStatus `gorm:"type:varchar(20);default:'open';index" json:"status"`
+	CreatedAt  time.Time    `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// FlagStatus constrains the status column on flagged_messages.
+type FlagStatus string
+
+const (
+	FlagPending   FlagStatus = "pending"
+	FlagDismissed FlagStatus = "dismissed"
+	FlagRemoved   FlagStatus = "removed"
+)
+
+// FlaggedMessage is a chat message the moderation pass thinks is abusive,
+// queued for an admin to review. The message is delivered regardless;
+// Content is a copy so the entry survives the message being deleted.
+type FlaggedMessage struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	MessageID  *uint      `gorm:"uniqueIndex" json:"message_id"`
+	SenderID   string     `gorm:"type:uuid;not null;index" json:"sender_id"`
+	MatchID    uint       `gorm:"not null" json:"match_id"`
+	Content    string     `gorm:"type:text;not null" json:"content"`
+	Category   string     `gorm:"type:varchar(30)" json:"category"`
+	Reason     string     `gorm:"type:text" json:"reason"`
+	Source     string     `gorm:"type:varchar(20);not null" json:"source"`
+	Status     FlagStatus `gorm:"type:varchar(20);default:'pending';index" json:"status"`
+	ReviewedBy *string    `gorm:"type:uuid" json:"reviewed_by,omitempty"`
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// MatchDigest is a user's precomputed match suggestions for one day.
+type MatchDigest struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	UserID      string    `gorm:"type:uuid;not null;uniqueIndex:idx_digest_user_date" json:"user_id"`
+	DigestDate  time.Time `gorm:"type:date;not null;uniqueIndex:idx_digest_user_date" json:"digest_date"`
+	Suggestions JSONB     `gorm:"type:jsonb;default:'[]'" json:"suggestions"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// Notification is one entry in a user's notification feed. Data carries the
+// IDs the client needs to deep-link (match_id, request_id, ...).
+type Notification struct {
+	ID        uint             `gorm:"primaryKey" json:"id"`
+	UserID    string           `gorm:"type:uuid;not null;index" json:"user_id"`
+	Type      NotificationType `gorm:"type:varchar(50);not null" json:"type"`
+	Title     string           `gorm:"type:varchar(255);not null" json:"title"`
+	Body      string           `gorm:"type:text" json:"body"`
+	Data      JSONB            `gorm:"type:jsonb;default:'{}'" json:"data"`
+	ReadAt    *time.Time       `json:"read_at"`
+	CreatedAt time.Time        `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// RefreshToken is a server-side record of an issued refresh token. Only the
+// SHA-256 of the token is stored; revoked or expired rows are rejected.
+type RefreshToken struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    string     `gorm:"type:uuid;not null;index" json:"user_id"`
+	TokenHash string     `gorm:"type:char(64);uniqueIndex;not null" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// APIKeyScope constrains the scope column on api_keys.
+type APIKeyScope string
+
+const (
+	// APIKeyRead allows only GET and HEAD requests.
+	APIKeyRead APIKeyScope = "read"
+	// APIKeyWrite allows every request a signed-in user can make, except
+	// account and key management.
+	APIKeyWrite APIKeyScope = "write"
+)
+
+// APIKey lets a user call the API from their own tools with an X-API-Key
+// header. Only the SHA-256 of the key is stored; Prefix is the start of the
+// key, kept so users can tell their keys apart.
+type APIKey struct {
+	ID         uint        `gorm:"primaryKey" json:"id"`
+	UserID     string      `gorm:"type:uuid;not null;index" json:"user_id"`
+	Name       string      `gorm:"type:varchar(100);not null" json:"name"`
+	Prefix     string      `gorm:"type:varchar(16);not null" json:"prefix"`
+	KeyHash    string      `gorm:"type:char(64);uniqueIndex;not null" json:"-"`
+	Scope      APIKeyScope `gorm:"type:varchar(10);not null;default:'read'" json:"scope"`
+	LastUsedAt *time.Time  `json:"last_used_at"`
+	RevokedAt  *time.Time  `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time   `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// IdempotencyKey records a request made with an Idempotency-Key header so a
+// retry gets the original response instead of repeating the action. Rows
+// with a zero StatusCode are still in progress.
+type IdempotencyKey struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	UserID       string    `gorm:"type:uuid;not null;uniqueIndex:idx_idempotency_user_key" json:"user_id"`
+	Key          string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_idempotency_user_key" json:"key"`
+	RequestHash  string    `gorm:"type:char(64);not null" json:"-"`
+	StatusCode   int       `gorm:"not null;default:0" json:"status_code"`
+	ResponseBody []byte    `gorm:"type:bytea" json:"-"`
+	CreatedAt    time.Time `gorm:"autoCreateTime;index" json:"created_at"`
+}
+
 // ---------------------------------------------------------------------------
 // AllModels returns every model for auto-migration.
 // ---------------------------------------------------------------------------
@@ -288,9 +703,17 @@ func AllModels() []interface{} {
 		&MatchRequest{},
 		&Message{},
 		&CodingSession{},
+		&Challenge{},
 		&Assessment{},
 		&Rating{},
 		&SessionFeedback{},
 		&UserReputation{},
+		&AuditLog{},
+		&MatchDigest{},
+		&UserBlock{},
+		&UserReport{},
+		&Notification{},
+		&RefreshToken{},
+		&IdempotencyKey{},
 	}
 }
@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Length limits for user-supplied text, in characters.
+const (
+	MaxFullNameLength = 100
+	MaxBioLength      = 2000
+	MaxMessageLength  = 10000
+)
+
+var ErrTextTooLong = errors.New("text is too long")
+
+var (
+	// dangerousBlockRe matches elements whose content is never meant to be
+	// shown as text.
+	dangerousBlockRe = regexp.MustCompile(`(?is)<(script|style|iframe|object|embed)\b[^>]*>.*?</(script|style|iframe|object|embed)\s*>`)
+	// tagRe matches HTML tags. A tag name must follow "<" directly, so prose
+	// like "a < b" is left alone.
+	tagRe = regexp.MustCompile(`</?[a-zA-Z][a-zA-Z0-9-]*(\s[^<>]*)?/?>`)
+)
+
+// CleanPlainText prepares a short profile field such as a name or bio: it
+// drops control characters other than newlines and tabs, removes HTML, and
+// trims surrounding whitespace.
+func CleanPlainText(s string) string {
+	s = stripControl(s)
+	s = dangerousBlockRe.ReplaceAllString(s, "")
+	s = tagRe.ReplaceAllString(s, "")
+	return strings.TrimSpace(s)
+}
+
+// SanitizeMessageContent cleans a message body for its type and rejects it
+// with ErrTextTooLong when over MaxMessageLength. Code keeps its indentation
+// and markup, losing only control characters and surrounding blank lines;
+// other messages are cleaned like CleanPlainText, losing all HTML, so an
+// unclosed <script> or an <img onerror=...> can't get through.
+func SanitizeMessageContent(t MessageType, content string) (string, error) {
+	if t == MessageCode {
+		content = stripControl(content)
+		content = strings.TrimRight(strings.TrimLeft(content, "\n"), " \t\n")
+	} else {
+		content = CleanPlainText(content)
+	}
+	if err := CheckLength("content", content, MaxMessageLength); err != nil {
+		return "", err
+	}
+	return content, nil
+}
+
+// CheckLength returns ErrTextTooLong, naming field, when s has more than max
+// characters.
+func CheckLength(field, s string, max int) error {
+	if utf8.RuneCountInString(s) > max {
+		return fmt.Errorf("%w: %s must be at most %d characters", ErrTextTooLong, field, max)
+	}
+	return nil
+}
+
+// stripControl normalizes line endings to "\n" and removes control and
+// invalid UTF-8 characters except newlines and tabs.
+func stripControl(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' {
+			return r
+		}
+		if r == utf8.RuneError || unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
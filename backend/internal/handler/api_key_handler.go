@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/yourusername/skillsync/internal/domain"
+	"github.com/yourusername/skillsync/internal/middleware"
+	"github.com/yourusername/skillsync/internal/service"
+)
+
+// ---------------------------------------------------------------------------
+// Request / Response DTOs
+// ---------------------------------------------------------------------------
+
+// CreateAPIKeyRequest names a new key. Scope defaults to read.
+type CreateAPIKeyRequest struct {
+	Name  string `json:"name" validate:"required,max=100"`
+	Scope string `json:"scope" validate:"omitempty,oneof=read write"`
+}
+
+// CreateAPIKeyResponse is the only response that carries the full key.
+type CreateAPIKeyResponse struct {
+	*domain.APIKey
+	Key string `json:"key"`
+}
+
+// ---------------------------------------------------------------------------
+// Handler
+// ---------------------------------------------------------------------------
+
+type APIKeyHandler struct {
+	apiKeyService *service.APIKeyService
+	auditService  *service.AuditService
+}
+
+func NewAPIKeyHandler(ks *service.APIKeyService, as *service.AuditService) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyService: ks, auditService: as}
+}
+
+// CreateAPIKey handles POST /api/users/me/api-keys
+func (h *APIKeyHandler) CreateAPIKey(c echo.Context) error {
+	userID, err := middleware.ExtractUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+	}
+
+	var req CreateAPIKeyRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	key, raw, err := h.apiKeyService.Create(c.Request().Context(), userID, req.Name, domain.APIKeyScope(req.Scope))
+	if err != nil {
+		switch err {
+		case service.ErrInvalidKeyScope:
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		case service.ErrUserNotFound:
+			return c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		case service.ErrTooManyAPIKeys:
+			return c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+		default:
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to create api key"})
+		}
+	}
+
+	h.auditService.Audit(auditEntry(c, domain.AuditAPIKeyCreate, "api_key", strconv.FormatUint(uint64(key.ID), 10),
+		map[string]interface{}{"name": key.Name, "scope": key.Scope, "prefix": key.Prefix}))
+
+	return c.JSON(http.StatusCreated, CreateAPIKeyResponse{APIKey: key, Key: raw})
+}
+
+// GetAPIKeys handles GET /api/users/me/api-keys
+func (h *APIKeyHandler) GetAPIKeys(c echo.Context) error {
+	userID, err := middleware.ExtractUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+	}
+
+	keys, err := h.apiKeyService.List(c.Request().Context(), userID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to fetch api keys"})
+	}
+
+	return c.JSON(http.StatusOK, listAll(keys, len(keys)))
+}
+
+// RevokeAPIKey handles DELETE /api/users/me/api-keys/:id
+func (h *APIKeyHandler) RevokeAPIKey(c echo.Context) error {
+	userID, err := middleware.ExtractUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid api key id"})
+	}
+
+	if err := h.apiKeyService.Revoke(c.Request().Context(), userID, uint(id)); err != nil {
+		if err == service.ErrAPIKeyNotFound {
+			return c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to revoke api key"})
+	}
+
+	h.auditService.Audit(auditEntry(c, domain.AuditAPIKeyRevoke, "api_key", c.Param("id"), nil))
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "api key revoked"})
+}
@@ -2,7 +2,9 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -29,6 +31,24 @@ type SubmitCodeResponse struct {
 	Analysis   *service.CodeAnalysisResult `json:"analysis"`
 }
 
+type AssessmentDetailResponse struct {
+	Assessment *domain.Assessment          `json:"assessment"`
+	Analysis   *service.CodeAnalysisResult `json:"analysis"`
+}
+
+// LanguageAssessmentStats summarizes the caller's assessments in one
+// language. LatestScore and LatestSkillLevel come from the most recent
+// submission, so comparing LatestScore with AverageScore shows the trend.
+type LanguageAssessmentStats struct {
+	Language         string    `json:"language"`
+	Submissions      int64     `json:"submissions"`
+	AverageScore     float64   `json:"average_score"`
+	BestScore        float64   `json:"best_score"`
+	LatestScore      float64   `json:"latest_score"`
+	LatestSkillLevel string    `json:"latest_skill_level"`
+	LastSubmittedAt  time.Time `json:"last_submitted_at"`
+}
+
 type GetHintRequest struct {
 	Code     string `json:"code" validate:"required"`
 	Language string `json:"language" validate:"required"`
@@ -53,15 +73,20 @@ type ProjectSuggestionsResponse struct {
 // ---------------------------------------------------------------------------
 
 type AssessmentHandler struct {
-	claudeService *service.ClaudeService
-	db            *gorm.DB
+	claudeService      service.ClaudeService
+	challengeService   *service.ChallengeService
+	projectSuggestions *service.ProjectSuggestionService
+	db                 *gorm.DB
 }
 
-func NewAssessmentHandler(cs *service.ClaudeService, db *gorm.DB) *AssessmentHandler {
-	return &AssessmentHandler{claudeService: cs, db: db}
+func NewAssessmentHandler(cs service.ClaudeService, chs *service.ChallengeService, ps *service.ProjectSuggestionService, db *gorm.DB) *AssessmentHandler {
+	return &AssessmentHandler{claudeService: cs, challengeService: chs, projectSuggestions: ps, db: db}
 }
 
 // SubmitCode handles POST /api/assessments
+//
+// challenge_id must name a challenge from GET /api/challenges; the analysis
+// scores the code against that challenge's problem and test cases.
 func (h *AssessmentHandler) SubmitCode(c echo.Context) error {
 	userID, err := middleware.ExtractUserID(c)
 	if err != nil {
@@ -76,8 +101,16 @@ func (h *AssessmentHandler) SubmitCode(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 	}
 
+	challenge, err := h.challengeService.GetChallenge(req.ChallengeID)
+	if err != nil {
+		if err == service.ErrChallengeNotFound {
+			return c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to fetch challenge"})
+	}
+
 	// Run AI analysis.
-	analysis, err := h.claudeService.AnalyzeCode(req.Code, req.Language)
+	analysis, err := h.claudeService.AnalyzeCode(c.Request().Context(), req.Code, req.Language, challenge)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "code analysis failed"})
 	}
@@ -88,7 +121,7 @@ func (h *AssessmentHandler) SubmitCode(c echo.Context) error {
 		ChallengeID:   req.ChallengeID,
 		CodeSubmitted: req.Code,
 		Language:      req.Language,
-		AIScore:       float64(analysis.Score),
+		AIScore:       analysis.Score,
 		SkillLevel:    analysis.SkillLevel,
 		AIFeedback:    marshalJSONB(analysis),
 		CompletedAt:   time.Now(),
@@ -117,7 +150,7 @@ func (h *AssessmentHandler) GetHint(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 	}
 
-	hint, err := h.claudeService.GenerateHint(req.Code, req.Language, req.Problem)
+	hint, err := h.claudeService.GenerateHint(c.Request().Context(), req.Code, req.Language, req.Problem)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to generate hint"})
 	}
@@ -139,9 +172,78 @@ func (h *AssessmentHandler) GetAssessmentHistory(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to fetch assessment history"})
 	}
 
-	return c.JSON(http.StatusOK, map[string]interface{}{
-		"assessments": assessments,
-		"total":       len(assessments),
+	return c.JSON(http.StatusOK, listAll(assessments, len(assessments)))
+}
+
+// GetAssessmentStats handles GET /api/assessments/stats
+//
+// Groups the caller's assessments by language, most-used language first.
+func (h *AssessmentHandler) GetAssessmentStats(c echo.Context) error {
+	userID, err := middleware.ExtractUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+	}
+
+	// The latest submission per language is looked up with DISTINCT ON and
+	// joined onto the aggregates.
+	stats := []LanguageAssessmentStats{}
+	if err := h.db.WithContext(c.Request().Context()).Raw(`
+		SELECT agg.language, agg.submissions, agg.average_score, agg.best_score,
+		       agg.last_submitted_at, latest.ai_score AS latest_score,
+		       latest.skill_level AS latest_skill_level
+		FROM (
+			SELECT language, COUNT(*) AS submissions,
+			       ROUND(AVG(ai_score), 2) AS average_score,
+			       MAX(ai_score) AS best_score, MAX(created_at) AS last_submitted_at
+			FROM assessments
+			WHERE user_id = ?
+			GROUP BY language
+		) agg
+		JOIN (
+			SELECT DISTINCT ON (language) language, ai_score, COALESCE(skill_level, '') AS skill_level
+			FROM assessments
+			WHERE user_id = ?
+			ORDER BY language, created_at DESC, id DESC
+		) latest ON latest.language = agg.language
+		ORDER BY agg.submissions DESC, agg.language`, userID, userID).
+		Scan(&stats).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to fetch assessment stats"})
+	}
+
+	return c.JSON(http.StatusOK, listAll(stats, len(stats)))
+}
+
+// GetAssessment handles GET /api/assessments/:id
+//
+// Returns the caller's assessment with its stored AI feedback decoded. Other
+// users' assessments are reported as not found.
+func (h *AssessmentHandler) GetAssessment(c echo.Context) error {
+	userID, err := middleware.ExtractUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid assessment id"})
+	}
+
+	var assessment domain.Assessment
+	if err := h.db.Where("id = ? AND user_id = ?", id, userID).First(&assessment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, ErrorResponse{Error: "assessment not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to fetch assessment"})
+	}
+
+	var analysis service.CodeAnalysisResult
+	if err := json.Unmarshal(assessment.AIFeedback, &analysis); err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "stored feedback is malformed"})
+	}
+
+	return c.JSON(http.StatusOK, AssessmentDetailResponse{
+		Assessment: &assessment,
+		Analysis:   &analysis,
 	})
 }
 
@@ -162,7 +264,7 @@ func (h *AssessmentHandler) GetProjectSuggestions(c echo.Context) error {
 		level = "intermediate"
 	}
 
-	projects, err := h.claudeService.SuggestProjects(skills, level)
+	projects, err := h.claudeService.SuggestProjects(c.Request().Context(), skills, level)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to generate project suggestions"})
 	}
@@ -170,6 +272,27 @@ func (h *AssessmentHandler) GetProjectSuggestions(c echo.Context) error {
 	return c.JSON(http.StatusOK, ProjectSuggestionsResponse{Projects: projects})
 }
 
+// GetMyProjectSuggestions handles GET /api/users/me/project-suggestions
+//
+// Suggests projects from the caller's own skills and highest proficiency
+// level. Results are cached briefly per user.
+func (h *AssessmentHandler) GetMyProjectSuggestions(c echo.Context) error {
+	userID, err := middleware.ExtractUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+	}
+
+	suggestions, err := h.projectSuggestions.SuggestForUser(c.Request().Context(), userID)
+	if err != nil {
+		if err == service.ErrNoSkills {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to generate project suggestions"})
+	}
+
+	return c.JSON(http.StatusOK, suggestions)
+}
+
 // marshalJSONB marshals any value into a domain.JSONB, falling back to "{}".
 func marshalJSONB(v interface{}) domain.JSONB {
 	data, err := json.Marshal(v)
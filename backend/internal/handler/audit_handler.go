@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/yourusername/skillsync/internal/domain"
+	"github.com/yourusername/skillsync/internal/middleware"
+	"github.com/yourusername/skillsync/internal/service"
+)
+
+// ---------------------------------------------------------------------------
+// Handler
+// ---------------------------------------------------------------------------
+
+type AuditHandler struct {
+	auditService *service.AuditService
+}
+
+func NewAuditHandler(as *service.AuditService) *AuditHandler {
+	return &AuditHandler{auditService: as}
+}
+
+// GetAuditLogs handles GET /api/admin/audit-logs?action=login&actor_id=...&target_id=...&from=...&to=...&page=1&limit=50
+//
+// from/to are RFC 3339 timestamps.
+func (h *AuditHandler) GetAuditLogs(c echo.Context) error {
+	p, err := parsePagination(c, 50)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	filter := service.AuditFilter{
+		Action:   c.QueryParam("action"),
+		ActorID:  c.QueryParam("actor_id"),
+		TargetID: c.QueryParam("target_id"),
+	}
+	if from := c.QueryParam("from"); from != "" {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid from timestamp, expected RFC 3339"})
+		}
+		filter.From = t
+	}
+	if to := c.QueryParam("to"); to != "" {
+		t, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid to timestamp, expected RFC 3339"})
+		}
+		filter.To = t
+	}
+
+	logs, total, err := h.auditService.ListAuditLogs(c.Request().Context(), filter, p.Limit, p.Offset)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to fetch audit logs"})
+	}
+
+	return c.JSON(http.StatusOK, p.List(logs, total))
+}
+
+// auditEntry builds an AuditEntry for the current request, taking the actor
+// from the JWT context (when present) and the client IP from the request.
+func auditEntry(c echo.Context, action domain.AuditAction, targetType, targetID string, metadata map[string]interface{}) service.AuditEntry {
+	actorID, _ := middleware.ExtractUserID(c)
+	return service.AuditEntry{
+		Action:     action,
+		ActorID:    actorID,
+		TargetType: targetType,
+		TargetID:   targetID,
+		IP:         c.RealIP(),
+		Metadata:   metadata,
+	}
+}
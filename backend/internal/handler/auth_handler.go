@@ -1,13 +1,14 @@
 package handler
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/labstack/echo/v4"
 
+	"github.com/yourusername/skillsync/internal/domain"
 	"github.com/yourusername/skillsync/internal/middleware"
 	"github.com/yourusername/skillsync/internal/service"
-	"github.com/yourusername/skillsync/pkg/auth"
 )
 
 // ---------------------------------------------------------------------------
@@ -26,25 +27,38 @@ type LoginRequest struct {
 	Password string `json:"password" validate:"required"`
 }
 
-type AuthResponse struct {
-	Token string      `json:"token"`
-	User  interface{} `json:"user"`
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password" validate:"required,min=8"`
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
 }
 
-type ErrorResponse struct {
-	Error string `json:"error"`
+type AuthResponse struct {
+	*service.TokenPair
+	User interface{} `json:"user"`
 }
 
+type ErrorResponse = middleware.ErrorResponse
+
 // ---------------------------------------------------------------------------
 // Handler
 // ---------------------------------------------------------------------------
 
 type AuthHandler struct {
-	userService *service.UserService
+	userService  *service.UserService
+	auditService *service.AuditService
+	tokenService *service.TokenService
 }
 
-func NewAuthHandler(us *service.UserService) *AuthHandler {
-	return &AuthHandler{userService: us}
+func NewAuthHandler(us *service.UserService, as *service.AuditService, ts *service.TokenService) *AuthHandler {
+	return &AuthHandler{userService: us, auditService: as, tokenService: ts}
 }
 
 // Register handles POST /api/auth/register
@@ -57,7 +71,15 @@ func (h *AuthHandler) Register(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 	}
 
-	user, err := h.userService.CreateUser(req.Email, req.Username, req.Password, req.FullName)
+	fullName := domain.CleanPlainText(req.FullName)
+	if fullName == "" {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "full_name is required"})
+	}
+	if err := domain.CheckLength("full_name", fullName, domain.MaxFullNameLength); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	user, err := h.userService.CreateUser(c.Request().Context(), req.Email, req.Username, req.Password, fullName)
 	if err != nil {
 		switch err {
 		case service.ErrEmailTaken:
@@ -69,14 +91,14 @@ func (h *AuthHandler) Register(c echo.Context) error {
 		}
 	}
 
-	token, err := auth.GenerateToken(user.ID)
+	tokens, err := h.tokenService.Issue(c.Request().Context(), user.ID)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to generate token"})
 	}
 
 	return c.JSON(http.StatusCreated, AuthResponse{
-		Token: token,
-		User:  user,
+		TokenPair: tokens,
+		User:      user,
 	})
 }
 
@@ -90,33 +112,165 @@ func (h *AuthHandler) Login(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 	}
 
-	user, err := h.userService.Authenticate(req.Email, req.Password)
+	user, err := h.userService.Authenticate(c.Request().Context(), req.Email, req.Password)
 	if err != nil {
+		h.auditService.Audit(auditEntry(c, domain.AuditLoginFailed, "user", "",
+			map[string]interface{}{"email": req.Email}))
 		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "invalid email or password"})
 	}
 
-	token, err := auth.GenerateToken(user.ID)
+	tokens, err := h.tokenService.Issue(c.Request().Context(), user.ID)
 	if err != nil {
+		if err == service.ErrUserBanned {
+			return c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+		}
 		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to generate token"})
 	}
 
+	entry := auditEntry(c, domain.AuditLogin, "user", user.ID, map[string]interface{}{"method": "password"})
+	entry.ActorID = user.ID
+	h.auditService.Audit(entry)
+
 	return c.JSON(http.StatusOK, AuthResponse{
-		Token: token,
-		User:  user,
+		TokenPair: tokens,
+		User:      user,
 	})
 }
 
+// Refresh handles POST /api/auth/refresh
+//
+// Exchanges a refresh token for a new access token and refresh token. The
+// presented refresh token is consumed.
+func (h *AuthHandler) Refresh(c echo.Context) error {
+	var req RefreshTokenRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	tokens, err := h.tokenService.Refresh(c.Request().Context(), req.RefreshToken)
+	if err != nil {
+		switch err {
+		case service.ErrInvalidRefreshToken:
+			return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: err.Error()})
+		case service.ErrUserBanned:
+			return c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+		default:
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to refresh token"})
+		}
+	}
+
+	return c.JSON(http.StatusOK, tokens)
+}
+
+// Logout handles POST /api/auth/logout
+//
+// Revokes the given refresh token. The access token stays valid until it
+// expires, so clients should discard it too.
+func (h *AuthHandler) Logout(c echo.Context) error {
+	var req RefreshTokenRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	if err := h.tokenService.Revoke(c.Request().Context(), req.RefreshToken); err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to log out"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "logged out"})
+}
+
 // GetMe handles GET /api/auth/me (protected)
+//
+// Returns the caller with skills and reputation preloaded.
 func (h *AuthHandler) GetMe(c echo.Context) error {
 	userID, err := middleware.ExtractUserID(c)
 	if err != nil {
 		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
 	}
 
-	user, err := h.userService.GetUserWithReputation(userID)
+	user, err := h.userService.GetUserWithReputation(c.Request().Context(), userID)
 	if err != nil {
-		return c.JSON(http.StatusNotFound, ErrorResponse{Error: "user not found"})
+		if err == service.ErrUserNotFound {
+			return c.JSON(http.StatusNotFound, ErrorResponse{Error: "user not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to fetch user"})
 	}
 
 	return c.JSON(http.StatusOK, user)
 }
+
+// ChangePassword handles PUT /api/auth/password (protected)
+func (h *AuthHandler) ChangePassword(c echo.Context) error {
+	userID, err := middleware.ExtractUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+	}
+
+	var req ChangePasswordRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	if err := h.userService.ChangePassword(c.Request().Context(), userID, req.CurrentPassword, req.NewPassword); err != nil {
+		switch err {
+		case service.ErrWrongPassword:
+			return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: err.Error()})
+		case service.ErrUserNotFound:
+			return c.JSON(http.StatusNotFound, ErrorResponse{Error: "user not found"})
+		default:
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to change password"})
+		}
+	}
+
+	h.auditService.Audit(auditEntry(c, domain.AuditPasswordChange, "user", userID, nil))
+
+	// Sign out other devices; their access tokens lapse within the access TTL.
+	// The password is already changed, so don't let a cancelled request skip
+	// this.
+	if err := h.tokenService.RevokeAll(context.Background(), userID); err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "password changed but failed to revoke sessions"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "password changed"})
+}
+
+// DeleteAccount handles DELETE /api/users/me
+//
+// Permanently deletes the caller's account; see UserService.DeleteAccount for
+// what is purged and what is anonymized. Password accounts must send their
+// current password.
+func (h *AuthHandler) DeleteAccount(c echo.Context) error {
+	userID, err := middleware.ExtractUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+	}
+
+	var req DeleteAccountRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+	}
+
+	if err := h.userService.DeleteAccount(c.Request().Context(), userID, req.Password); err != nil {
+		switch err {
+		case service.ErrWrongPassword:
+			return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "password is incorrect"})
+		case service.ErrUserNotFound:
+			return c.JSON(http.StatusNotFound, ErrorResponse{Error: "user not found"})
+		default:
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to delete account"})
+		}
+	}
+
+	h.auditService.Audit(auditEntry(c, domain.AuditAccountDeletion, "user", userID, nil))
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "account deleted"})
+}
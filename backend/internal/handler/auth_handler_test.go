@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/yourusername/skillsync/internal/domain"
+	"github.com/yourusername/skillsync/internal/service"
+)
+
+func TestChangePasswordWritesAuditLog(t *testing.T) {
+	t.Setenv("AUDIT_LOG_ENABLED", "true")
+	db := testDB(t)
+	user := createTestUser(t, db)
+	t.Cleanup(func() {
+		db.Where("actor_id = ?", user.ID).Delete(&domain.AuditLog{})
+	})
+
+	audit := service.NewAuditService(db)
+	h := NewAuthHandler(service.NewUserService(db, audit, nil), audit, service.NewTokenService(db))
+
+	e := newTestEcho()
+	req := httptest.NewRequest(http.MethodPut, "/api/auth/password",
+		strings.NewReader(`{"new_password":"correct-horse-battery"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(echo.HeaderXRealIP, "203.0.113.7")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("user_id", user.ID)
+
+	if err := h.ChangePassword(c); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body.String())
+	}
+
+	var logs []domain.AuditLog
+	if err := db.Where("actor_id = ? AND action = ?", user.ID, domain.AuditPasswordChange).Find(&logs).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 1 {
+		t.Fatalf("%d password_change audit rows, want 1", len(logs))
+	}
+	if logs[0].TargetID != user.ID || logs[0].TargetType != "user" || logs[0].IP != "203.0.113.7" {
+		t.Fatalf("audit row = %+v", logs[0])
+	}
+}
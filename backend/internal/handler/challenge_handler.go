@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/yourusername/skillsync/internal/service"
+)
+
+// ---------------------------------------------------------------------------
+// Handler
+// ---------------------------------------------------------------------------
+
+type ChallengeHandler struct {
+	challengeService *service.ChallengeService
+}
+
+func NewChallengeHandler(cs *service.ChallengeService) *ChallengeHandler {
+	return &ChallengeHandler{challengeService: cs}
+}
+
+// ListChallenges handles GET /api/challenges?difficulty=beginner
+func (h *ChallengeHandler) ListChallenges(c echo.Context) error {
+	challenges, err := h.challengeService.ListChallenges(c.QueryParam("difficulty"))
+	if err != nil {
+		if err == service.ErrInvalidChallengeLevel {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to list challenges"})
+	}
+
+	return c.JSON(http.StatusOK, listAll(challenges, len(challenges)))
+}
+
+// GetChallenge handles GET /api/challenges/:id
+func (h *ChallengeHandler) GetChallenge(c echo.Context) error {
+	challenge, err := h.challengeService.GetChallenge(c.Param("id"))
+	if err != nil {
+		if err == service.ErrChallengeNotFound {
+			return c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to fetch challenge"})
+	}
+
+	return c.JSON(http.StatusOK, challenge)
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/yourusername/skillsync/internal/domain"
+	"github.com/yourusername/skillsync/pkg/database"
+)
+
+var (
+	testDBOnce sync.Once
+	testDBConn *gorm.DB
+	testDBErr  error
+)
+
+// testDB connects to TEST_DATABASE_URL and applies the backend migrations
+// once per run; see service/db_test.go. Tests that need PostgreSQL are
+// skipped when it isn't set.
+func testDB(t testing.TB) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	testDBOnce.Do(func() {
+		testDBConn, testDBErr = gorm.Open(postgres.Open(dsn), &gorm.Config{
+			Logger:         logger.Default.LogMode(logger.Silent),
+			TranslateError: true,
+		})
+		if testDBErr == nil {
+			testDBErr = database.MigrateDB(testDBConn)
+		}
+	})
+	if testDBErr != nil {
+		t.Fatalf("test database: %v", testDBErr)
+	}
+	return testDBConn
+}
+
+// createTestUser inserts a password-less user, removed again when the test
+// ends.
+func createTestUser(t testing.TB, db *gorm.DB) *domain.User {
+	t.Helper()
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		t.Fatal(err)
+	}
+	suffix := hex.EncodeToString(buf)
+	user := domain.User{
+		Email:    "test-" + suffix + "@example.test",
+		Username: "test-" + suffix,
+		FullName: "Test " + suffix,
+		Bio:      "Test user",
+	}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Unscoped().Delete(&domain.User{}, "id = ?", user.ID)
+	})
+	return &user
+}
+
+type testValidator struct {
+	v *validator.Validate
+}
+
+func (tv testValidator) Validate(i interface{}) error {
+	return tv.v.Struct(i)
+}
+
+// newTestEcho returns an Echo instance that validates requests like main's.
+func newTestEcho() *echo.Echo {
+	e := echo.New()
+	e.Validator = testValidator{v: validator.New()}
+	return e
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"os"
 	"strconv"
 	"time"
 
@@ -13,6 +14,7 @@ import (
 	"github.com/yourusername/skillsync/internal/domain"
 	"github.com/yourusername/skillsync/internal/middleware"
 	"github.com/yourusername/skillsync/internal/service"
+	ws "github.com/yourusername/skillsync/internal/websocket"
 )
 
 // ---------------------------------------------------------------------------
@@ -29,22 +31,75 @@ type MatchInsightsResponse struct {
 	Insights *service.PairingInsights `json:"insights"`
 }
 
+type CompatibilityMatrixRequest struct {
+	UserIDs []string `json:"user_ids" validate:"required,min=2,max=25,dive,required"`
+}
+
+type MatchDigestResponse struct {
+	DigestDate  string                     `json:"digest_date"`
+	GeneratedAt time.Time                  `json:"generated_at"`
+	Suggestions []*service.MatchSuggestion `json:"suggestions"`
+}
+
+// MatchSummaryResponse adds the participants' live presence to a
+// service.MatchSummary.
+type MatchSummaryResponse struct {
+	*service.MatchSummary
+	Presence []ParticipantPresence `json:"presence"`
+}
+
+// ParticipantPresence says whether a participant has any socket open and
+// whether one of them is subscribed to the match.
+type ParticipantPresence struct {
+	UserID  string `json:"user_id"`
+	Online  bool   `json:"online"`
+	InMatch bool   `json:"in_match"`
+}
+
 type CollaborationSuggestionsResponse struct {
 	Projects []*service.ProjectSuggestion `json:"projects"`
 }
 
+// MatchLimitResponse is returned with 429 when a match limit is hit.
+type MatchLimitResponse struct {
+	Error string `json:"error"`
+	Kind  string `json:"kind"`
+	Count int64  `json:"count"`
+	Limit int    `json:"limit"`
+}
+
+// ProfileIncompleteResponse is returned with 422 when the caller's profile
+// is too sparse for match suggestions.
+type ProfileIncompleteResponse struct {
+	Error      string `json:"error"`
+	Skills     int    `json:"skills"`
+	MinSkills  int    `json:"min_skills"`
+	MissingBio bool   `json:"missing_bio"`
+}
+
 // ---------------------------------------------------------------------------
 // Handler
 // ---------------------------------------------------------------------------
 
+// defaultInsightsRefreshCooldown is how often a single match's insights may be
+// regenerated on demand. Override with INSIGHTS_REFRESH_COOLDOWN (e.g. "15m").
+const defaultInsightsRefreshCooldown = 10 * time.Minute
+
 type MatchHandler struct {
-	matchService  *service.MatchService
-	claudeService *service.ClaudeService
-	db            *gorm.DB
+	matchService    *service.MatchService
+	claudeService   service.ClaudeService
+	db              *gorm.DB
+	hub             *ws.Hub
+	notifications   *service.NotificationService
+	refreshCooldown time.Duration
 }
 
-func NewMatchHandler(ms *service.MatchService, cs *service.ClaudeService, db *gorm.DB) *MatchHandler {
-	return &MatchHandler{matchService: ms, claudeService: cs, db: db}
+func NewMatchHandler(ms *service.MatchService, cs service.ClaudeService, db *gorm.DB, hub *ws.Hub, ns *service.NotificationService) *MatchHandler {
+	cooldown := defaultInsightsRefreshCooldown
+	if d, err := time.ParseDuration(os.Getenv("INSIGHTS_REFRESH_COOLDOWN")); err == nil && d > 0 {
+		cooldown = d
+	}
+	return &MatchHandler{matchService: ms, claudeService: cs, db: db, hub: hub, notifications: ns, refreshCooldown: cooldown}
 }
 
 // GetMatchSuggestions handles GET /api/matches/suggestions?limit=10
@@ -54,20 +109,26 @@ func (h *MatchHandler) GetMatchSuggestions(c echo.Context) error {
 		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
 	}
 
-	limit, _ := strconv.Atoi(c.QueryParam("limit"))
-	if limit <= 0 || limit > 50 {
-		limit = 10
+	limit, err := parseLimit(c, 10)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 	}
 
-	suggestions, err := h.matchService.FindMatches(userID, limit)
+	suggestions, err := h.matchService.FindMatches(c.Request().Context(), userID, limit)
 	if err != nil {
+		var incomplete *service.ProfileIncompleteError
+		if errors.As(err, &incomplete) {
+			return c.JSON(http.StatusUnprocessableEntity, ProfileIncompleteResponse{
+				Error:      incomplete.Error(),
+				Skills:     incomplete.Skills,
+				MinSkills:  incomplete.MinSkills,
+				MissingBio: incomplete.MissingBio,
+			})
+		}
 		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to find matches"})
 	}
 
-	return c.JSON(http.StatusOK, map[string]interface{}{
-		"suggestions": suggestions,
-		"total":       len(suggestions),
-	})
+	return c.JSON(http.StatusOK, listAll(suggestions, len(suggestions)))
 }
 
 // SendMatchRequest handles POST /api/matches/request
@@ -85,7 +146,12 @@ func (h *MatchHandler) SendMatchRequest(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 	}
 
-	if err := h.matchService.CreateMatchRequest(userID, req.ReceiverID, req.Message); err != nil {
+	matchReq, err := h.matchService.CreateMatchRequest(c.Request().Context(), userID, req.ReceiverID, req.Message)
+	if err != nil {
+		var limitErr *service.MatchLimitError
+		if errors.As(err, &limitErr) {
+			return c.JSON(http.StatusTooManyRequests, matchLimitResponse(limitErr, userID))
+		}
 		switch err {
 		case service.ErrSelfMatch:
 			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
@@ -93,14 +159,58 @@ func (h *MatchHandler) SendMatchRequest(c echo.Context) error {
 			return c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
 		case service.ErrMatchExists:
 			return c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+		case service.ErrUserBlocked:
+			return c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
 		default:
 			return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to send match request"})
 		}
 	}
 
+	h.notifications.NotifyMatchRequest(userID, req.ReceiverID)
+	h.hub.BroadcastToUser(req.ReceiverID, ws.MatchRequestFrame(matchReq))
+
 	return c.JSON(http.StatusCreated, map[string]string{"message": "match request sent"})
 }
 
+// matchLimitResponse describes which limit was hit and whose it is.
+func matchLimitResponse(e *service.MatchLimitError, callerID string) MatchLimitResponse {
+	msg := e.Error()
+	if e.UserID != callerID {
+		msg = "the other user has reached their limit of active matches"
+	}
+	return MatchLimitResponse{Error: msg, Kind: e.Kind, Count: e.Count, Limit: e.Limit}
+}
+
+// GetMatchRequest handles GET /api/matches/request/:id
+//
+// Returns the request with the sender's and receiver's profiles and the AI
+// preview, for either party to review before answering.
+func (h *MatchHandler) GetMatchRequest(c echo.Context) error {
+	userID, err := middleware.ExtractUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+	}
+
+	requestID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request id"})
+	}
+
+	req, err := h.matchService.GetMatchRequest(c.Request().Context(), uint(requestID), userID)
+	if err != nil {
+		switch err {
+		case service.ErrRequestNotFound:
+			return c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		case service.ErrNotRequestParticipant:
+			return c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+		default:
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to fetch match request"})
+		}
+	}
+
+	return c.JSON(http.StatusOK, req)
+}
+
 // AcceptMatchRequest handles PUT /api/matches/request/:id/accept
 func (h *MatchHandler) AcceptMatchRequest(c echo.Context) error {
 	userID, err := middleware.ExtractUserID(c)
@@ -113,8 +223,12 @@ func (h *MatchHandler) AcceptMatchRequest(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request id"})
 	}
 
-	match, err := h.matchService.AcceptMatchRequest(uint(requestID), userID)
+	match, err := h.matchService.AcceptMatchRequest(c.Request().Context(), uint(requestID), userID)
 	if err != nil {
+		var limitErr *service.MatchLimitError
+		if errors.As(err, &limitErr) {
+			return c.JSON(http.StatusTooManyRequests, matchLimitResponse(limitErr, userID))
+		}
 		switch err {
 		case service.ErrRequestNotFound:
 			return c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
@@ -127,6 +241,15 @@ func (h *MatchHandler) AcceptMatchRequest(c echo.Context) error {
 		}
 	}
 
+	senderID := match.User1ID
+	if senderID == userID {
+		senderID = match.User2ID
+	}
+	// The accept transaction has committed by now, so the match exists when
+	// the sender follows match_id.
+	h.notifications.NotifyMatchRequestAnswered(uint(requestID), senderID, userID, true, match.ID)
+	h.hub.BroadcastToUser(senderID, ws.MatchAcceptedFrame(uint(requestID), userID, match.ID))
+
 	return c.JSON(http.StatusOK, match)
 }
 
@@ -165,25 +288,210 @@ func (h *MatchHandler) RejectMatchRequest(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to reject match request"})
 	}
 
+	h.notifications.NotifyMatchRequestAnswered(req.ID, req.SenderID, userID, false, 0)
+	h.hub.BroadcastToUser(req.SenderID, ws.MatchRejectedFrame(req.ID, userID))
+
 	return c.JSON(http.StatusOK, map[string]string{"message": "match request rejected"})
 }
 
-// GetMyMatches handles GET /api/matches
+// PredictSessionSuccess handles GET /api/matches/:id/predict-success
+func (h *MatchHandler) PredictSessionSuccess(c echo.Context) error {
+	userID, err := middleware.ExtractUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+	}
+
+	matchID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid match id"})
+	}
+
+	prediction, err := h.matchService.PredictSessionSuccess(c.Request().Context(), uint(matchID), userID)
+	if err != nil {
+		switch err {
+		case service.ErrMatchNotFound:
+			return c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		case service.ErrNotMatchParticipant:
+			return c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+		default:
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to predict session success"})
+		}
+	}
+
+	return c.JSON(http.StatusOK, prediction)
+}
+
+// GetMatchSessions handles GET /api/matches/:id/sessions
+func (h *MatchHandler) GetMatchSessions(c echo.Context) error {
+	userID, err := middleware.ExtractUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+	}
+
+	matchID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid match id"})
+	}
+
+	sessions, err := h.matchService.GetMatchSessions(c.Request().Context(), uint(matchID), userID)
+	if err != nil {
+		switch err {
+		case service.ErrMatchNotFound:
+			return c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		case service.ErrNotMatchParticipant:
+			return c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+		default:
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to fetch sessions"})
+		}
+	}
+
+	return c.JSON(http.StatusOK, listAll(sessions, len(sessions)))
+}
+
+// GetMatchActivity handles GET /api/matches/:id/activity?limit=50&cursor=...
+//
+// The match timeline, newest first. Pass next_cursor from a response as
+// cursor to fetch the older items.
+func (h *MatchHandler) GetMatchActivity(c echo.Context) error {
+	userID, err := middleware.ExtractUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+	}
+
+	matchID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid match id"})
+	}
+	limit, err := parseLimit(c, 50)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	page, err := h.matchService.GetMatchActivity(c.Request().Context(), uint(matchID), userID, c.QueryParam("cursor"), limit)
+	if err != nil {
+		switch err {
+		case service.ErrInvalidActivityCursor:
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		case service.ErrMatchNotFound:
+			return c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		case service.ErrNotMatchParticipant:
+			return c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+		default:
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to fetch match activity"})
+		}
+	}
+
+	return c.JSON(http.StatusOK, page)
+}
+
+// GetMatchSummary handles GET /api/matches/:id/summary
+//
+// Everything a match detail page needs in one response: the match with both
+// participants, stored AI insights (never regenerated here), session count
+// and minutes, the latest message and live presence.
+func (h *MatchHandler) GetMatchSummary(c echo.Context) error {
+	userID, err := middleware.ExtractUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+	}
+
+	matchID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid match id"})
+	}
+
+	summary, err := h.matchService.GetMatchSummary(c.Request().Context(), uint(matchID), userID)
+	if err != nil {
+		switch err {
+		case service.ErrMatchNotFound:
+			return c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		case service.ErrNotMatchParticipant:
+			return c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+		default:
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to fetch match summary"})
+		}
+	}
+
+	inMatch := make(map[string]bool)
+	for _, id := range h.hub.OnlineUsersForMatch(summary.Match.ID) {
+		inMatch[id] = true
+	}
+	presence := make([]ParticipantPresence, 0, 2)
+	for _, id := range []string{summary.Match.User1ID, summary.Match.User2ID} {
+		presence = append(presence, ParticipantPresence{
+			UserID:  id,
+			Online:  h.hub.IsOnline(id),
+			InMatch: inMatch[id],
+		})
+	}
+
+	return c.JSON(http.StatusOK, MatchSummaryResponse{MatchSummary: summary, Presence: presence})
+}
+
+// GetMatchWith handles GET /api/matches/with/:userId?include_archived=true
+//
+// Returns the caller's active match with userId, for deep-linking from a
+// profile to the conversation. include_archived falls back to the latest
+// archived match. 404 when there is none.
+func (h *MatchHandler) GetMatchWith(c echo.Context) error {
+	userID, err := middleware.ExtractUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+	}
+
+	otherID := c.Param("userId")
+	if otherID == "" {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid user id"})
+	}
+
+	match, err := h.matchService.GetMatchWith(c.Request().Context(), userID, otherID, c.QueryParam("include_archived") == "true")
+	if err != nil {
+		if err == service.ErrMatchNotFound {
+			return c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to fetch match"})
+	}
+	return c.JSON(http.StatusOK, match)
+}
+
+// GetScoringConfig handles GET /api/admin/scoring-weights (admin only)
+//
+// Shows the compatibility weights the server is running with.
+func (h *MatchHandler) GetScoringConfig(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.matchService.ScoringConfig())
+}
+
+// GetMyMatches handles GET /api/matches?status=active&sort=recent&min_score=0
+//
+// status is active (default), inactive, all or hidden (matches the caller
+// deleted); sort is recent (default), score or activity (latest message
+// first).
 func (h *MatchHandler) GetMyMatches(c echo.Context) error {
 	userID, err := middleware.ExtractUserID(c)
 	if err != nil {
 		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
 	}
 
-	matches, err := h.matchService.GetUserMatches(userID)
+	opts := service.MatchQueryOptions{
+		Status: c.QueryParam("status"),
+		Sort:   c.QueryParam("sort"),
+	}
+	if raw := c.QueryParam("min_score"); raw != "" {
+		opts.MinScore, err = strconv.ParseFloat(raw, 64)
+		if err != nil || opts.MinScore < 0 || opts.MinScore > 100 {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "min_score must be a number between 0 and 100"})
+		}
+	}
+
+	matches, err := h.matchService.GetUserMatches(c.Request().Context(), userID, opts)
 	if err != nil {
+		if err == service.ErrInvalidMatchQuery {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		}
 		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to fetch matches"})
 	}
 
-	return c.JSON(http.StatusOK, map[string]interface{}{
-		"matches": matches,
-		"total":   len(matches),
-	})
+	return c.JSON(http.StatusOK, listAll(matches, len(matches)))
 }
 
 // GetPendingRequests handles GET /api/matches/requests/pending
@@ -211,8 +519,20 @@ func (h *MatchHandler) GetPendingRequests(c echo.Context) error {
 	})
 }
 
-// GetMatchInsights handles GET /api/matches/:id/insights
+// GetMatchInsights handles GET /api/matches/:id/insights?refresh=true
+//
+// Stored insights are returned when present. refresh=true forces a new
+// analysis, subject to the per-match refresh cooldown.
 func (h *MatchHandler) GetMatchInsights(c echo.Context) error {
+	return h.matchInsights(c, c.QueryParam("refresh") == "true")
+}
+
+// RegenerateMatchInsights handles POST /api/matches/:id/insights/regenerate
+func (h *MatchHandler) RegenerateMatchInsights(c echo.Context) error {
+	return h.matchInsights(c, true)
+}
+
+func (h *MatchHandler) matchInsights(c echo.Context, refresh bool) error {
 	userID, err := middleware.ExtractUserID(c)
 	if err != nil {
 		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
@@ -237,28 +557,51 @@ func (h *MatchHandler) GetMatchInsights(c echo.Context) error {
 	}
 
 	// Try to decode stored insights first.
-	var insights service.PairingInsights
-	if len(match.AIInsights) > 0 {
-		if err := json.Unmarshal(match.AIInsights, &insights); err == nil && insights.OverallReasoning != "" {
-			return c.JSON(http.StatusOK, MatchInsightsResponse{
-				Match:    &match,
-				Insights: &insights,
-			})
+	if !refresh {
+		var insights service.PairingInsights
+		if len(match.AIInsights) > 0 {
+			if err := json.Unmarshal(match.AIInsights, &insights); err == nil && insights.OverallReasoning != "" {
+				return c.JSON(http.StatusOK, MatchInsightsResponse{
+					Match:    &match,
+					Insights: &insights,
+				})
+			}
+		}
+	}
+
+	// Claim the regeneration slot atomically so concurrent refreshes of the
+	// same match cannot both reach Claude.
+	now := time.Now()
+	claim := h.db.Model(&domain.Match{}).
+		Where("id = ? AND (insights_generated_at IS NULL OR insights_generated_at <= ?)", match.ID, now.Add(-h.refreshCooldown)).
+		Update("insights_generated_at", now)
+	if claim.Error != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to update match"})
+	}
+	if claim.RowsAffected == 0 {
+		retryAfter := h.refreshCooldown
+		if match.InsightsGeneratedAt != nil {
+			retryAfter = time.Until(match.InsightsGeneratedAt.Add(h.refreshCooldown))
 		}
+		c.Response().Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+		return c.JSON(http.StatusTooManyRequests, ErrorResponse{Error: "insights for this match were regenerated recently; try again later"})
 	}
 
-	// Generate fresh insights if none are stored.
-	fresh, err := h.claudeService.GeneratePairingInsights(
+	fresh, err := h.claudeService.GeneratePairingInsights(c.Request().Context(), 
 		match.User1, match.User2,
 		match.User1.Skills, match.User2.Skills,
 	)
 	if err != nil {
+		// Release the slot so a failed call does not block the next attempt.
+		h.db.Model(&domain.Match{}).Where("id = ?", match.ID).
+			Update("insights_generated_at", match.InsightsGeneratedAt)
 		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to generate insights"})
 	}
 
 	// Persist for next time.
 	data, _ := json.Marshal(fresh)
 	h.db.Model(&match).Update("ai_insights", domain.JSONB(data))
+	match.InsightsGeneratedAt = &now
 
 	return c.JSON(http.StatusOK, MatchInsightsResponse{
 		Match:    &match,
@@ -311,7 +654,7 @@ func (h *MatchHandler) GetCollaborationSuggestions(c echo.Context) error {
 		bestLevel = "intermediate"
 	}
 
-	projects, err := h.claudeService.SuggestProjects(combined, bestLevel)
+	projects, err := h.claudeService.SuggestProjects(c.Request().Context(), combined, bestLevel)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to generate collaboration suggestions"})
 	}
@@ -327,3 +670,242 @@ func highestLevel(a, b string) string {
 	}
 	return a
 }
+
+// GetCompatibilityMatrix handles POST /api/compatibility/matrix
+//
+// Returns pairwise compatibility scores for up to 25 users so organizers can
+// split a group into pairs or teams. Users in a block with the caller are
+// dropped from the result.
+func (h *MatchHandler) GetCompatibilityMatrix(c echo.Context) error {
+	userID, err := middleware.ExtractUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+	}
+
+	var req CompatibilityMatrixRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	matrix, err := h.matchService.CompatibilityMatrix(c.Request().Context(), userID, req.UserIDs)
+	if err != nil {
+		switch err {
+		case service.ErrMatrixSize:
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		case service.ErrUserNotFound:
+			return c.JSON(http.StatusNotFound, ErrorResponse{Error: "one or more users not found"})
+		default:
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to compute compatibility matrix"})
+		}
+	}
+
+	return c.JSON(http.StatusOK, matrix)
+}
+
+// GetCompatibility handles GET /api/matches/compatibility/:userId
+//
+// Shows how well the caller would pair with userId, with the score breakdown
+// and shared and complementary skills, without sending a request.
+func (h *MatchHandler) GetCompatibility(c echo.Context) error {
+	userID, err := middleware.ExtractUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+	}
+
+	preview, err := h.matchService.PreviewCompatibility(c.Request().Context(), userID, c.Param("userId"))
+	if err != nil {
+		switch err {
+		case service.ErrSelfMatch:
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		case service.ErrUserNotFound:
+			return c.JSON(http.StatusNotFound, ErrorResponse{Error: "user not found"})
+		default:
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to compute compatibility"})
+		}
+	}
+
+	return c.JSON(http.StatusOK, preview)
+}
+
+// GetSimilarUsers handles GET /api/users/:id/similar?limit=10
+//
+// Lists the users whose skills overlap most with the given user's, for
+// finding peers rather than teaching partners.
+func (h *MatchHandler) GetSimilarUsers(c echo.Context) error {
+	userID, err := middleware.ExtractUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+	}
+
+	limit, err := parseLimit(c, 10)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	similar, err := h.matchService.FindSimilarUsers(c.Request().Context(), userID, c.Param("id"), limit)
+	if err != nil {
+		if err == service.ErrUserNotFound {
+			return c.JSON(http.StatusNotFound, ErrorResponse{Error: "user not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to find similar users"})
+	}
+
+	return c.JSON(http.StatusOK, listAll(similar, len(similar)))
+}
+
+// GetMentees handles GET /api/matches/mentees?page=1&limit=20
+//
+// Lists users learning skills the caller holds at advanced level.
+func (h *MatchHandler) GetMentees(c echo.Context) error {
+	userID, err := middleware.ExtractUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+	}
+
+	p, err := parsePagination(c, 0)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	mentees, total, err := h.matchService.FindMentees(c.Request().Context(), userID, p.Limit, p.Offset)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to find mentees"})
+	}
+
+	return c.JSON(http.StatusOK, p.List(mentees, total))
+}
+
+// Unmatch handles DELETE /api/matches/:id
+//
+// Archives the match: it becomes inactive but stays in both participants'
+// history with its messages. With ?hide=true it is also removed from the
+// caller's match list until restored; the other participant still sees it
+// archived. An already archived match can be hidden too. Either way any
+// running session is ended and both participants' sockets for the match are
+// disconnected.
+func (h *MatchHandler) Unmatch(c echo.Context) error {
+	userID, err := middleware.ExtractUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+	}
+
+	matchID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid match id"})
+	}
+
+	hide := c.QueryParam("hide") == "true"
+	message := "match archived"
+	if hide {
+		err = h.matchService.DeleteMatch(c.Request().Context(), uint(matchID), userID)
+		message = "match deleted"
+	} else {
+		err = h.matchService.Unmatch(c.Request().Context(), uint(matchID), userID)
+	}
+	if err != nil {
+		switch err {
+		case service.ErrMatchNotFound:
+			return c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		case service.ErrNotMatchParticipant:
+			return c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+		case service.ErrMatchNotActive:
+			return c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+		default:
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to unmatch"})
+		}
+	}
+
+	h.hub.EndMatch(uint(matchID))
+
+	return c.JSON(http.StatusOK, map[string]string{"message": message})
+}
+
+// RestoreMatch handles POST /api/matches/:id/restore
+//
+// Brings back a match the caller hid with DELETE ?hide=true and reactivates
+// it if the caller archived it, reopening chat and sessions for both
+// participants. A match the other participant archived stays inactive.
+func (h *MatchHandler) RestoreMatch(c echo.Context) error {
+	userID, err := middleware.ExtractUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+	}
+
+	matchID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid match id"})
+	}
+
+	match, err := h.matchService.RestoreMatch(c.Request().Context(), uint(matchID), userID)
+	if err != nil {
+		var limitErr *service.MatchLimitError
+		if errors.As(err, &limitErr) {
+			return c.JSON(http.StatusTooManyRequests, matchLimitResponse(limitErr, userID))
+		}
+		switch err {
+		case service.ErrMatchNotFound, service.ErrUserNotFound:
+			return c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		case service.ErrNotMatchParticipant, service.ErrUserBlocked, service.ErrNotArchiver:
+			return c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+		case service.ErrMatchActive, service.ErrMatchExists:
+			return c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+		default:
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to restore match"})
+		}
+	}
+
+	if match.Status == domain.MatchActive {
+		h.hub.ReopenMatch(match.ID)
+	}
+
+	return c.JSON(http.StatusOK, match)
+}
+
+// GetMatchDigest handles GET /api/matches/digest
+//
+// Returns the caller's latest precomputed "today's picks".
+func (h *MatchHandler) GetMatchDigest(c echo.Context) error {
+	userID, err := middleware.ExtractUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+	}
+
+	digest, err := h.matchService.GetLatestDigest(c.Request().Context(), userID)
+	if err != nil {
+		if err == service.ErrDigestNotFound {
+			return c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to fetch match digest"})
+	}
+
+	suggestions := []*service.MatchSuggestion{}
+	if len(digest.Suggestions) > 0 {
+		if err := json.Unmarshal(digest.Suggestions, &suggestions); err != nil {
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to decode match digest"})
+		}
+	}
+
+	return c.JSON(http.StatusOK, MatchDigestResponse{
+		DigestDate:  digest.DigestDate.Format("2006-01-02"),
+		GeneratedAt: digest.CreatedAt,
+		Suggestions: suggestions,
+	})
+}
+
+// GetMyNetwork handles GET /api/users/me/network?include_mutuals=true
+func (h *MatchHandler) GetMyNetwork(c echo.Context) error {
+	userID, err := middleware.ExtractUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+	}
+
+	graph, err := h.matchService.GetNetwork(c.Request().Context(), userID, c.QueryParam("include_mutuals") == "true")
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to build network"})
+	}
+
+	return c.JSON(http.StatusOK, graph)
+}
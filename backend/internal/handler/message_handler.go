@@ -12,6 +12,7 @@ import (
 
 	"github.com/yourusername/skillsync/internal/domain"
 	"github.com/yourusername/skillsync/internal/middleware"
+	"github.com/yourusername/skillsync/internal/service"
 	ws "github.com/yourusername/skillsync/internal/websocket"
 )
 
@@ -20,31 +21,30 @@ import (
 // ---------------------------------------------------------------------------
 
 type SendMessageRequest struct {
-	MatchID    uint   `json:"match_id" validate:"required"`
-	ReceiverID string `json:"receiver_id" validate:"required"`
-	Content    string `json:"content" validate:"required"`
+	MatchID    uint                    `json:"match_id" validate:"required"`
+	ReceiverID string                  `json:"receiver_id" validate:"required"`
+	Content    string                  `json:"content"`
+	Type       domain.MessageType      `json:"type"`
+	Metadata   *domain.MessageMetadata `json:"metadata"`
 }
 
 type MarkReadRequest struct {
 	MessageIDs []uint `json:"message_ids" validate:"required,min=1"`
 }
 
-type MessageResponse struct {
-	Messages []domain.Message `json:"messages"`
-	Total    int64            `json:"total"`
-}
-
 // ---------------------------------------------------------------------------
 // Handler
 // ---------------------------------------------------------------------------
 
 type MessageHandler struct {
-	db  *gorm.DB
-	hub *ws.Hub
+	db            *gorm.DB
+	hub           *ws.Hub
+	notifications *service.NotificationService
+	moderator     *service.MessageModerator
 }
 
-func NewMessageHandler(db *gorm.DB, hub *ws.Hub) *MessageHandler {
-	return &MessageHandler{db: db, hub: hub}
+func NewMessageHandler(db *gorm.DB, hub *ws.Hub, ns *service.NotificationService, mm *service.MessageModerator) *MessageHandler {
+	return &MessageHandler{db: db, hub: hub, notifications: ns, moderator: mm}
 }
 
 // GetMessages handles GET /api/matches/:matchId/messages?page=1&limit=50
@@ -59,9 +59,11 @@ func (h *MessageHandler) GetMessages(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid match id"})
 	}
 
+	db := h.db.WithContext(c.Request().Context())
+
 	// Verify participant.
 	var match domain.Match
-	if err := h.db.First(&match, uint(matchID)).Error; err != nil {
+	if err := db.First(&match, uint(matchID)).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return c.JSON(http.StatusNotFound, ErrorResponse{Error: "match not found"})
 		}
@@ -71,33 +73,25 @@ func (h *MessageHandler) GetMessages(c echo.Context) error {
 		return c.JSON(http.StatusForbidden, ErrorResponse{Error: "you are not a participant in this match"})
 	}
 
-	page, _ := strconv.Atoi(c.QueryParam("page"))
-	if page < 1 {
-		page = 1
-	}
-	limit, _ := strconv.Atoi(c.QueryParam("limit"))
-	if limit < 1 || limit > 100 {
-		limit = 50
+	p, err := parsePagination(c, 50)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 	}
-	offset := (page - 1) * limit
 
 	var total int64
-	h.db.Model(&domain.Message{}).Where("match_id = ?", uint(matchID)).Count(&total)
+	db.Model(&domain.Message{}).Where("match_id = ?", uint(matchID)).Count(&total)
 
 	var messages []domain.Message
-	if err := h.db.Preload("Sender").
+	if err := db.Preload("Sender").
 		Where("match_id = ?", uint(matchID)).
 		Order("created_at ASC").
-		Limit(limit).
-		Offset(offset).
+		Limit(p.Limit).
+		Offset(p.Offset).
 		Find(&messages).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to fetch messages"})
 	}
 
-	return c.JSON(http.StatusOK, MessageResponse{
-		Messages: messages,
-		Total:    total,
-	})
+	return c.JSON(http.StatusOK, p.List(messages, total))
 }
 
 // SendMessage handles POST /api/messages (REST fallback when WS is unavailable)
@@ -115,9 +109,11 @@ func (h *MessageHandler) SendMessage(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 	}
 
+	db := h.db.WithContext(c.Request().Context())
+
 	// Verify the match and that the sender is a participant.
 	var match domain.Match
-	if err := h.db.First(&match, req.MatchID).Error; err != nil {
+	if err := db.First(&match, req.MatchID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return c.JSON(http.StatusNotFound, ErrorResponse{Error: "match not found"})
 		}
@@ -127,17 +123,35 @@ func (h *MessageHandler) SendMessage(c echo.Context) error {
 		return c.JSON(http.StatusForbidden, ErrorResponse{Error: "you are not a participant in this match"})
 	}
 
+	msgType, metadata, err := domain.NormalizeMessage(req.Type, req.Metadata)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	content, err := domain.SanitizeMessageContent(msgType, req.Content)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	if content == "" && msgType == domain.MessageFile {
+		content = req.Metadata.Filename
+	}
+	if content == "" {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "content is required"})
+	}
+
 	msg := domain.Message{
 		SenderID:   userID,
 		ReceiverID: req.ReceiverID,
 		MatchID:    req.MatchID,
-		Content:    req.Content,
+		Content:    content,
+		Type:       msgType,
+		Metadata:   metadata,
 	}
-	if err := h.db.Create(&msg).Error; err != nil {
+	if err := db.Create(&msg).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to send message"})
 	}
 
-	// Preload sender for the response and broadcast.
+	// Preload sender for the response and broadcast. The message is stored,
+	// so this runs even if the request has been cancelled.
 	h.db.Preload("Sender").First(&msg, msg.ID)
 
 	// Push through the WebSocket hub so connected clients get it in real time.
@@ -151,6 +165,10 @@ func (h *MessageHandler) SendMessage(c echo.Context) error {
 		h.hub.BroadcastToMatch(req.MatchID, outBytes)
 	}
 
+	h.hub.RecordActivity(req.MatchID)
+	h.notifications.NotifyNewMessage(&msg)
+	h.moderator.Check(&msg)
+
 	return c.JSON(http.StatusCreated, msg)
 }
 
@@ -170,7 +188,7 @@ func (h *MessageHandler) MarkMessagesRead(c echo.Context) error {
 	}
 
 	// Only mark messages where the authenticated user is the receiver.
-	res := h.db.Model(&domain.Message{}).
+	res := h.db.WithContext(c.Request().Context()).Model(&domain.Message{}).
 		Where("id IN ? AND receiver_id = ? AND is_read = false", req.MessageIDs, userID).
 		Update("is_read", true)
 	if res.Error != nil {
@@ -0,0 +1,223 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/yourusername/skillsync/internal/domain"
+	"github.com/yourusername/skillsync/internal/middleware"
+	"github.com/yourusername/skillsync/internal/service"
+)
+
+// ---------------------------------------------------------------------------
+// Request / Response DTOs
+// ---------------------------------------------------------------------------
+
+// BanUserRequest suspends a user either until BannedUntil or for
+// DurationHours from now; exactly one must be set.
+type BanUserRequest struct {
+	BannedUntil   *time.Time `json:"banned_until"`
+	DurationHours int        `json:"duration_hours" validate:"gte=0"`
+	Reason        string     `json:"reason" validate:"required,max=2000"`
+}
+
+// ReviewFlagRequest closes a flag from the moderation queue.
+type ReviewFlagRequest struct {
+	Action string `json:"action" validate:"required,oneof=dismiss remove"`
+}
+
+// ---------------------------------------------------------------------------
+// Handler
+// ---------------------------------------------------------------------------
+
+type ModerationHandler struct {
+	moderationService *service.ModerationService
+	auditService      *service.AuditService
+}
+
+func NewModerationHandler(ms *service.ModerationService, as *service.AuditService) *ModerationHandler {
+	return &ModerationHandler{moderationService: ms, auditService: as}
+}
+
+// GetReportedUsers handles GET /api/admin/reports?status=open|all&page=1&limit=50
+//
+// Defaults to users with at least one open report.
+func (h *ModerationHandler) GetReportedUsers(c echo.Context) error {
+	status := c.QueryParam("status")
+	if status != "" && status != "open" && status != "all" {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "status must be open or all"})
+	}
+	p, err := parsePagination(c, 50)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	users, total, err := h.moderationService.ListReportedUsers(c.Request().Context(), status != "all", p.Limit, p.Offset)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to fetch reported users"})
+	}
+
+	return c.JSON(http.StatusOK, p.List(users, total))
+}
+
+// BanUser handles POST /api/admin/users/:id/ban
+//
+// Banned users are rejected by the JWT middleware and can't log in or
+// refresh until the ban expires or is lifted.
+func (h *ModerationHandler) BanUser(c echo.Context) error {
+	adminID, err := middleware.ExtractUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+	}
+	userID := c.Param("id")
+
+	var req BanUserRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	if (req.BannedUntil == nil) == (req.DurationHours == 0) {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "set exactly one of banned_until or duration_hours"})
+	}
+	until := time.Now().Add(time.Duration(req.DurationHours) * time.Hour)
+	if req.BannedUntil != nil {
+		until = *req.BannedUntil
+	}
+
+	user, err := h.moderationService.BanUser(c.Request().Context(), adminID, userID, until)
+	if err != nil {
+		switch err {
+		case service.ErrCannotBanSelf, service.ErrInvalidBanUntil:
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		case service.ErrUserNotFound:
+			return c.JSON(http.StatusNotFound, ErrorResponse{Error: "user not found"})
+		default:
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to ban user"})
+		}
+	}
+
+	h.auditService.Audit(auditEntry(c, domain.AuditAdminAction, "user", userID,
+		map[string]interface{}{"operation": "ban_user", "banned_until": until, "reason": req.Reason}))
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message":      "user banned",
+		"user_id":      user.ID,
+		"banned_until": until,
+	})
+}
+
+// UnbanUser handles DELETE /api/admin/users/:id/ban
+func (h *ModerationHandler) UnbanUser(c echo.Context) error {
+	userID := c.Param("id")
+
+	if err := h.moderationService.UnbanUser(c.Request().Context(), userID); err != nil {
+		if err == service.ErrUserNotFound {
+			return c.JSON(http.StatusNotFound, ErrorResponse{Error: "user not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to unban user"})
+	}
+
+	h.auditService.Audit(auditEntry(c, domain.AuditAdminAction, "user", userID,
+		map[string]interface{}{"operation": "unban_user"}))
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "user unbanned"})
+}
+
+// DeleteMessage handles DELETE /api/admin/messages/:id
+func (h *ModerationHandler) DeleteMessage(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid message id"})
+	}
+
+	msg, err := h.moderationService.DeleteMessage(c.Request().Context(), uint(id))
+	if err != nil {
+		if err == service.ErrMessageNotFound {
+			return c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to delete message"})
+	}
+
+	h.auditService.Audit(auditEntry(c, domain.AuditAdminAction, "message", c.Param("id"),
+		map[string]interface{}{
+			"operation": "delete_message",
+			"sender_id": msg.SenderID,
+			"match_id":  msg.MatchID,
+			"content":   msg.Content,
+		}))
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "message deleted"})
+}
+
+// GetFlaggedMessages handles GET /api/admin/moderation/messages?status=pending|all&page=1&limit=50
+//
+// Defaults to flags awaiting review, oldest first.
+func (h *ModerationHandler) GetFlaggedMessages(c echo.Context) error {
+	status := c.QueryParam("status")
+	if status != "" && status != "pending" && status != "all" {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "status must be pending or all"})
+	}
+	p, err := parsePagination(c, 50)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	flags, total, err := h.moderationService.ListFlaggedMessages(c.Request().Context(), status != "all", p.Limit, p.Offset)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to fetch flagged messages"})
+	}
+
+	return c.JSON(http.StatusOK, p.List(flags, total))
+}
+
+// ReviewFlaggedMessage handles POST /api/admin/moderation/messages/:id/review
+//
+// "dismiss" keeps the message; "remove" deletes it.
+func (h *ModerationHandler) ReviewFlaggedMessage(c echo.Context) error {
+	adminID, err := middleware.ExtractUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid flag id"})
+	}
+
+	var req ReviewFlagRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	flag, err := h.moderationService.ReviewFlaggedMessage(c.Request().Context(), adminID, uint(id), req.Action)
+	if err != nil {
+		switch err {
+		case service.ErrInvalidFlagAction:
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		case service.ErrFlagNotFound:
+			return c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		case service.ErrFlagAlreadyReviewed:
+			return c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+		default:
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to review flagged message"})
+		}
+	}
+
+	h.auditService.Audit(auditEntry(c, domain.AuditAdminAction, "flagged_message", c.Param("id"),
+		map[string]interface{}{
+			"operation":  "review_flagged_message",
+			"action":     req.Action,
+			"message_id": flag.MessageID,
+			"sender_id":  flag.SenderID,
+			"content":    flag.Content,
+		}))
+
+	return c.JSON(http.StatusOK, flag)
+}
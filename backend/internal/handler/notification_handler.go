@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/yourusername/skillsync/internal/middleware"
+	"github.com/yourusername/skillsync/internal/service"
+)
+
+// ---------------------------------------------------------------------------
+// Request / Response DTOs
+// ---------------------------------------------------------------------------
+
+type UnreadCountResponse struct {
+	Unread int64 `json:"unread"`
+}
+
+// ---------------------------------------------------------------------------
+// Handler
+// ---------------------------------------------------------------------------
+
+type NotificationHandler struct {
+	notificationService *service.NotificationService
+}
+
+func NewNotificationHandler(ns *service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{notificationService: ns}
+}
+
+// GetNotifications handles GET /api/notifications?unread=true&page=1&limit=20
+func (h *NotificationHandler) GetNotifications(c echo.Context) error {
+	userID, err := middleware.ExtractUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+	}
+
+	p, err := parsePagination(c, 0)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	unreadOnly := c.QueryParam("unread") == "true"
+
+	items, total, err := h.notificationService.List(userID, unreadOnly, p.Limit, p.Offset)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to fetch notifications"})
+	}
+
+	return c.JSON(http.StatusOK, p.List(items, total))
+}
+
+// GetUnreadCount handles GET /api/notifications/unread-count
+func (h *NotificationHandler) GetUnreadCount(c echo.Context) error {
+	userID, err := middleware.ExtractUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+	}
+
+	n, err := h.notificationService.UnreadCount(userID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to count notifications"})
+	}
+
+	return c.JSON(http.StatusOK, UnreadCountResponse{Unread: n})
+}
+
+// MarkNotificationRead handles PUT /api/notifications/:id/read
+func (h *NotificationHandler) MarkNotificationRead(c echo.Context) error {
+	userID, err := middleware.ExtractUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid notification id"})
+	}
+
+	if err := h.notificationService.MarkRead(uint(id), userID); err != nil {
+		if err == service.ErrNotificationNotFound {
+			return c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to mark notification read"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "notification marked as read"})
+}
@@ -1,24 +1,28 @@
 package handler
 
 import (
-	"crypto/rand"
-	"encoding/hex"
+	"crypto/subtle"
+	"errors"
 	"net/http"
+	"net/url"
 	"os"
 	"time"
 
 	"github.com/labstack/echo/v4"
 
+	"github.com/yourusername/skillsync/internal/domain"
 	"github.com/yourusername/skillsync/internal/service"
 	"github.com/yourusername/skillsync/pkg/auth"
 )
 
 type OAuthHandler struct {
 	oauthService *service.OAuthService
+	auditService *service.AuditService
+	tokenService *service.TokenService
 }
 
-func NewOAuthHandler(os *service.OAuthService) *OAuthHandler {
-	return &OAuthHandler{oauthService: os}
+func NewOAuthHandler(os *service.OAuthService, as *service.AuditService, ts *service.TokenService) *OAuthHandler {
+	return &OAuthHandler{oauthService: os, auditService: as, tokenService: ts}
 }
 
 func frontendURL() string {
@@ -29,90 +33,126 @@ func frontendURL() string {
 	return u
 }
 
-// generateState creates a random hex string for CSRF protection.
-func generateState() string {
-	b := make([]byte, 16)
-	rand.Read(b)
-	return hex.EncodeToString(b)
+// oauthStateTTL is how long a user has to complete a provider's consent
+// page; both the signed state and its cookie expire after it.
+const oauthStateTTL = 5 * time.Minute
+
+// auditOAuthLogin records a successful OAuth sign-in for the given provider.
+func (h *OAuthHandler) auditOAuthLogin(c echo.Context, provider, userID string) {
+	entry := auditEntry(c, domain.AuditLogin, "user", userID, map[string]interface{}{"method": provider})
+	entry.ActorID = userID
+	h.auditService.Audit(entry)
 }
 
-// setStateCookie stores the OAuth state parameter in a short-lived cookie.
+// setStateCookie stores the OAuth state parameter in a short-lived cookie,
+// marked Secure when APP_ENV=production.
 func setStateCookie(c echo.Context, name, value string) {
 	c.SetCookie(&http.Cookie{
 		Name:     name,
 		Value:    value,
 		Path:     "/",
 		HttpOnly: true,
+		Secure:   os.Getenv("APP_ENV") == "production",
 		SameSite: http.SameSiteLaxMode,
-		MaxAge:   int(5 * time.Minute / time.Second),
+		MaxAge:   int(oauthStateTTL / time.Second),
 	})
 }
 
-// ---------------------------------------------------------------------------
-// Google
-// ---------------------------------------------------------------------------
-
-func (h *OAuthHandler) GoogleLogin(c echo.Context) error {
-	state := generateState()
-	setStateCookie(c, "oauth_state_google", state)
-	return c.Redirect(http.StatusTemporaryRedirect, h.oauthService.GetGoogleLoginURL(state))
-}
-
-func (h *OAuthHandler) GoogleCallback(c echo.Context) error {
-	// Validate state.
-	cookie, err := c.Cookie("oauth_state_google")
-	if err != nil || cookie.Value != c.QueryParam("state") {
-		return c.Redirect(http.StatusTemporaryRedirect, frontendURL()+"/login?error=invalid_state")
-	}
-
-	code := c.QueryParam("code")
-	if code == "" {
-		return c.Redirect(http.StatusTemporaryRedirect, frontendURL()+"/login?error=no_code")
-	}
-
-	user, err := h.oauthService.HandleGoogleCallback(code)
-	if err != nil {
-		return c.Redirect(http.StatusTemporaryRedirect, frontendURL()+"/login?error=oauth_failed")
-	}
-
-	token, err := auth.GenerateToken(user.ID)
-	if err != nil {
-		return c.Redirect(http.StatusTemporaryRedirect, frontendURL()+"/login?error=token_failed")
-	}
-
-	return c.Redirect(http.StatusTemporaryRedirect, frontendURL()+"/dashboard?token="+token)
+// clearStateCookie removes the state cookie once a callback has used it.
+func clearStateCookie(c echo.Context, name string) {
+	c.SetCookie(&http.Cookie{
+		Name:     name,
+		Value:    "",
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   os.Getenv("APP_ENV") == "production",
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   -1,
+	})
 }
 
 // ---------------------------------------------------------------------------
-// GitHub
+// Login / callback
 // ---------------------------------------------------------------------------
 
-func (h *OAuthHandler) GitHubLogin(c echo.Context) error {
-	state := generateState()
-	setStateCookie(c, "oauth_state_github", state)
-	return c.Redirect(http.StatusTemporaryRedirect, h.oauthService.GetGitHubLoginURL(state))
-}
-
-func (h *OAuthHandler) GitHubCallback(c echo.Context) error {
-	cookie, err := c.Cookie("oauth_state_github")
-	if err != nil || cookie.Value != c.QueryParam("state") {
-		return c.Redirect(http.StatusTemporaryRedirect, frontendURL()+"/login?error=invalid_state")
+// Login handles GET /api/auth/<provider>/login
+//
+// Sends the user to the provider's consent page, or back to the frontend
+// login page when the provider isn't configured.
+func (h *OAuthHandler) Login(provider string) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		state, err := auth.GenerateOAuthState(provider, oauthStateTTL)
+		if err != nil {
+			return c.Redirect(http.StatusTemporaryRedirect, frontendURL()+"/login?error=oauth_failed")
+		}
+		loginURL, err := h.oauthService.LoginURL(provider, state)
+		if err != nil {
+			return c.Redirect(http.StatusTemporaryRedirect, frontendURL()+"/login?error=provider_unavailable")
+		}
+		setStateCookie(c, "oauth_state_"+provider, state)
+		return c.Redirect(http.StatusTemporaryRedirect, loginURL)
 	}
+}
 
-	code := c.QueryParam("code")
-	if code == "" {
-		return c.Redirect(http.StatusTemporaryRedirect, frontendURL()+"/login?error=no_code")
+// Callback handles GET /api/auth/<provider>/callback
+func (h *OAuthHandler) Callback(provider string) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		// The state must come back in the browser that started the login,
+		// carry our signature for this provider, be unexpired and unused.
+		cookieName := "oauth_state_" + provider
+		cookie, err := c.Cookie(cookieName)
+		clearStateCookie(c, cookieName)
+		state := c.QueryParam("state")
+		if err != nil || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(state)) != 1 ||
+			auth.ConsumeOAuthState(state, provider) != nil {
+			return c.Redirect(http.StatusTemporaryRedirect, frontendURL()+"/login?error=invalid_state")
+		}
+
+		code := c.QueryParam("code")
+		if code == "" {
+			return c.Redirect(http.StatusTemporaryRedirect, frontendURL()+"/login?error=no_code")
+		}
+
+		user, err := h.oauthService.HandleCallback(c.Request().Context(), provider, code)
+		if err != nil {
+			return c.Redirect(http.StatusTemporaryRedirect, frontendURL()+"/login?error="+oauthErrorCode(err))
+		}
+
+		tokens, err := h.tokenService.Issue(c.Request().Context(), user.ID)
+		if err == service.ErrUserBanned {
+			return c.Redirect(http.StatusTemporaryRedirect, frontendURL()+"/login?error=account_suspended")
+		}
+		if err != nil {
+			return c.Redirect(http.StatusTemporaryRedirect, frontendURL()+"/login?error=token_failed")
+		}
+
+		h.auditOAuthLogin(c, provider, user.ID)
+
+		return c.Redirect(http.StatusTemporaryRedirect, dashboardRedirectURL(tokens))
 	}
+}
 
-	user, err := h.oauthService.HandleGitHubCallback(code)
-	if err != nil {
-		return c.Redirect(http.StatusTemporaryRedirect, frontendURL()+"/login?error=oauth_failed")
-	}
+// dashboardRedirectURL hands both tokens to the frontend after an OAuth login.
+// They go in the fragment, which browsers never send to a server, so they
+// stay out of access logs, proxies and Referer headers.
+func dashboardRedirectURL(tokens *service.TokenPair) string {
+	q := url.Values{}
+	q.Set("token", tokens.AccessToken)
+	q.Set("refresh_token", tokens.RefreshToken)
+	return frontendURL() + "/dashboard#" + q.Encode()
+}
 
-	token, err := auth.GenerateToken(user.ID)
-	if err != nil {
-		return c.Redirect(http.StatusTemporaryRedirect, frontendURL()+"/login?error=token_failed")
+// oauthErrorCode maps a callback error to the error query value the frontend
+// shows on the login page.
+func oauthErrorCode(err error) string {
+	switch {
+	case errors.Is(err, service.ErrOAuthAccountConflict):
+		return "account_conflict"
+	case errors.Is(err, service.ErrOAuthIdentityLinked):
+		return "already_linked"
+	case errors.Is(err, service.ErrOAuthProviderUnavailable):
+		return "provider_unavailable"
+	default:
+		return "oauth_failed"
 	}
-
-	return c.Redirect(http.StatusTemporaryRedirect, frontendURL()+"/dashboard?token="+token)
 }
@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/yourusername/skillsync/internal/service"
+)
+
+// Tokens travel in the fragment, which browsers don't send to servers.
+func TestDashboardRedirectURLKeepsTokensOutOfQuery(t *testing.T) {
+	t.Setenv("FRONTEND_URL", "https://app.example.com")
+
+	u, err := url.Parse(dashboardRedirectURL(&service.TokenPair{AccessToken: "access", RefreshToken: "refresh"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.RawQuery != "" {
+		t.Fatalf("query = %q, want none", u.RawQuery)
+	}
+	frag, err := url.ParseQuery(u.Fragment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Path != "/dashboard" || frag.Get("token") != "access" || frag.Get("refresh_token") != "refresh" {
+		t.Fatalf("redirect = %s, want /dashboard with both tokens in the fragment", u)
+	}
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/yourusername/skillsync/internal/middleware"
+	"github.com/yourusername/skillsync/internal/service"
+)
+
+// ---------------------------------------------------------------------------
+// Request / Response DTOs
+// ---------------------------------------------------------------------------
+
+type SuggestedSkillsResponse struct {
+	Suggestions []service.SkillSuggestion `json:"suggestions"`
+}
+
+type GitHubImportPreviewResponse struct {
+	Skills []service.GitHubSkillImport `json:"skills"`
+}
+
+// ---------------------------------------------------------------------------
+// Handler
+// ---------------------------------------------------------------------------
+
+type OnboardingHandler struct {
+	onboardingService *service.OnboardingService
+}
+
+func NewOnboardingHandler(os *service.OnboardingService) *OnboardingHandler {
+	return &OnboardingHandler{onboardingService: os}
+}
+
+// GetSuggestedSkills handles GET /api/onboarding/suggested-skills?github_username=...&description=...&limit=10
+//
+// Both hints are optional. The result is only a suggestion list for the user
+// to confirm; nothing is added to their profile here.
+func (h *OnboardingHandler) GetSuggestedSkills(c echo.Context) error {
+	userID, err := middleware.ExtractUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+	}
+
+	description := c.QueryParam("description")
+	if len(description) > 1000 {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "description must be at most 1000 characters"})
+	}
+	limit, err := parseLimit(c, 10)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	suggestions, err := h.onboardingService.SuggestSkills(c.Request().Context(), userID, c.QueryParam("github_username"), description, limit)
+	if err != nil {
+		switch err {
+		case service.ErrInvalidGitHubUsername:
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		case service.ErrGitHubUserNotFound:
+			return c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		default:
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to suggest skills"})
+		}
+	}
+
+	return c.JSON(http.StatusOK, SuggestedSkillsResponse{Suggestions: suggestions})
+}
+
+// ImportGitHub handles POST /api/users/me/import-github
+//
+// Returns a preview of skills inferred from the caller's GitHub repositories.
+// Nothing is saved; confirmed entries are added via POST /api/users/:id/skills.
+func (h *OnboardingHandler) ImportGitHub(c echo.Context) error {
+	userID, err := middleware.ExtractUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+	}
+
+	skills, err := h.onboardingService.PreviewGitHubImport(c.Request().Context(), userID)
+	if err != nil {
+		switch err {
+		case service.ErrGitHubNotConnected:
+			return c.JSON(http.StatusPreconditionFailed, ErrorResponse{Error: err.Error()})
+		case service.ErrGitHubRateLimited:
+			return c.JSON(http.StatusServiceUnavailable, ErrorResponse{Error: err.Error()})
+		case service.ErrUserNotFound:
+			return c.JSON(http.StatusNotFound, ErrorResponse{Error: "user not found"})
+		default:
+			return c.JSON(http.StatusBadGateway, ErrorResponse{Error: "failed to import from github"})
+		}
+	}
+
+	return c.JSON(http.StatusOK, GitHubImportPreviewResponse{Skills: skills})
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	defaultPageLimit = 20
+	defaultMaxLimit  = 100
+)
+
+var errPageAndOffset = errors.New("use either page or offset, not both")
+
+// paginationLimits holds the global pagination settings. They are read once,
+// on first use, from PAGINATION_DEFAULT_LIMIT (default 20) and
+// PAGINATION_MAX_LIMIT (default 100).
+var paginationLimits = sync.OnceValue(func() struct{ Default, Max int } {
+	max := envPositiveInt("PAGINATION_MAX_LIMIT", defaultMaxLimit)
+	def := envPositiveInt("PAGINATION_DEFAULT_LIMIT", defaultPageLimit)
+	if def > max {
+		def = max
+	}
+	return struct{ Default, Max int }{def, max}
+})
+
+// Pagination is the parsed page, limit and offset of a list request.
+type Pagination struct {
+	Page   int
+	Limit  int
+	Offset int
+}
+
+// ListResponse is the envelope every list endpoint returns. Items holds the
+// current page; Total counts every item matching the request, so HasMore
+// tells whether a later page exists.
+type ListResponse struct {
+	Items   interface{} `json:"items"`
+	Total   int64       `json:"total"`
+	Page    int         `json:"page"`
+	Limit   int         `json:"limit"`
+	HasMore bool        `json:"has_more"`
+}
+
+// List wraps one page of items out of total in a ListResponse.
+func (p Pagination) List(items interface{}, total int64) ListResponse {
+	return ListResponse{
+		Items:   items,
+		Total:   total,
+		Page:    p.Page,
+		Limit:   p.Limit,
+		HasMore: int64(p.Offset+p.Limit) < total,
+	}
+}
+
+// listAll wraps an unpaginated list of n items as a single full page.
+func listAll(items interface{}, n int) ListResponse {
+	return Pagination{Page: 1, Limit: n}.List(items, int64(n))
+}
+
+// parsePagination reads ?page=, ?limit= and ?offset=. Missing values default
+// to page 1 and defaultLimit (the global default when 0), and offset is
+// derived from page. Values that aren't positive integers, a limit above the
+// global max, or both page and offset are errors meant for a 400 response.
+func parsePagination(c echo.Context, defaultLimit int) (Pagination, error) {
+	limit, err := parseLimit(c, defaultLimit)
+	if err != nil {
+		return Pagination{}, err
+	}
+	p := Pagination{Page: 1, Limit: limit}
+
+	pageParam, offsetParam := c.QueryParam("page"), c.QueryParam("offset")
+	switch {
+	case pageParam != "" && offsetParam != "":
+		return Pagination{}, errPageAndOffset
+	case pageParam != "":
+		page, err := strconv.Atoi(pageParam)
+		if err != nil || page < 1 {
+			return Pagination{}, errors.New("page must be a positive integer")
+		}
+		p.Page = page
+		p.Offset = (page - 1) * limit
+	case offsetParam != "":
+		offset, err := strconv.Atoi(offsetParam)
+		if err != nil || offset < 0 {
+			return Pagination{}, errors.New("offset must be a non-negative integer")
+		}
+		p.Offset = offset
+		p.Page = offset/limit + 1
+	}
+	return p, nil
+}
+
+// parseLimit reads ?limit= alone, for endpoints that return a single ranked
+// list. It follows the same default and max as parsePagination.
+func parseLimit(c echo.Context, defaultLimit int) (int, error) {
+	cfg := paginationLimits()
+	if defaultLimit <= 0 || defaultLimit > cfg.Max {
+		defaultLimit = cfg.Default
+	}
+
+	raw := c.QueryParam("limit")
+	if raw == "" {
+		return defaultLimit, nil
+	}
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit < 1 || limit > cfg.Max {
+		return 0, fmt.Errorf("limit must be between 1 and %d", cfg.Max)
+	}
+	return limit, nil
+}
+
+func envPositiveInt(key string, fallback int) int {
+	n, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || n < 1 {
+		return fallback
+	}
+	return n
+}
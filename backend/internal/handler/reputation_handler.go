@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"strconv"
@@ -28,6 +29,15 @@ type SubmitRatingRequest struct {
 	Comment             string `json:"comment"`
 }
 
+type UpdateRatingRequest struct {
+	OverallRating       int    `json:"overall_rating" validate:"required,min=1,max=5"`
+	CodeQualityRating   int    `json:"code_quality_rating" validate:"required,min=1,max=5"`
+	CommunicationRating int    `json:"communication_rating" validate:"required,min=1,max=5"`
+	HelpfulnessRating   int    `json:"helpfulness_rating" validate:"required,min=1,max=5"`
+	ReliabilityRating   int    `json:"reliability_rating" validate:"required,min=1,max=5"`
+	Comment             string `json:"comment"`
+}
+
 type SubmitFeedbackRequest struct {
 	Enjoyed          bool     `json:"enjoyed"`
 	LearnedSomething bool     `json:"learned_something"`
@@ -44,9 +54,21 @@ type LeaderboardEntry struct {
 	Reputation *domain.UserReputation `json:"reputation"`
 }
 
+// LeaderboardResponse is a ListResponse of LeaderboardEntry items.
 type LeaderboardResponse struct {
-	Category string              `json:"category"`
-	Entries  []*LeaderboardEntry `json:"entries"`
+	Category string `json:"category"`
+	ListResponse
+}
+
+type SkillLeaderboardEntry struct {
+	LeaderboardEntry
+	SkillScore float64 `json:"skill_score"`
+}
+
+// SkillLeaderboardResponse is a ListResponse of SkillLeaderboardEntry items.
+type SkillLeaderboardResponse struct {
+	Skill *domain.Skill `json:"skill"`
+	ListResponse
 }
 
 // ---------------------------------------------------------------------------
@@ -54,12 +76,13 @@ type LeaderboardResponse struct {
 // ---------------------------------------------------------------------------
 
 type ReputationHandler struct {
-	repService *service.ReputationService
-	db         *gorm.DB
+	repService    *service.ReputationService
+	db            *gorm.DB
+	notifications *service.NotificationService
 }
 
-func NewReputationHandler(rs *service.ReputationService, db *gorm.DB) *ReputationHandler {
-	return &ReputationHandler{repService: rs, db: db}
+func NewReputationHandler(rs *service.ReputationService, db *gorm.DB, ns *service.NotificationService) *ReputationHandler {
+	return &ReputationHandler{repService: rs, db: db, notifications: ns}
 }
 
 // SubmitRating handles POST /api/ratings
@@ -77,7 +100,7 @@ func (h *ReputationHandler) SubmitRating(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 	}
 
-	err = h.repService.SubmitRating(
+	err = h.repService.SubmitRating(c.Request().Context(), 
 		userID,
 		req.RatedID,
 		req.SessionID,
@@ -105,9 +128,85 @@ func (h *ReputationHandler) SubmitRating(c echo.Context) error {
 		}
 	}
 
+	h.notifications.NotifyNewRating(req.RatedID, req.SessionID)
+
 	return c.JSON(http.StatusCreated, map[string]string{"message": "rating submitted"})
 }
 
+// UpdateRating handles PUT /api/ratings/:id
+//
+// Lets the rater replace their scores and comment within the edit window
+// (RATING_EDIT_WINDOW, default 48h).
+func (h *ReputationHandler) UpdateRating(c echo.Context) error {
+	userID, err := middleware.ExtractUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+	}
+
+	ratingID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid rating id"})
+	}
+
+	var req UpdateRatingRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	rating, err := h.repService.UpdateRating(c.Request().Context(), uint(ratingID), userID, service.RatingScores{
+		Overall:       req.OverallRating,
+		CodeQuality:   req.CodeQualityRating,
+		Communication: req.CommunicationRating,
+		Helpfulness:   req.HelpfulnessRating,
+		Reliability:   req.ReliabilityRating,
+		Comment:       req.Comment,
+	})
+	if err != nil {
+		return ratingEditError(c, err, "failed to update rating")
+	}
+
+	return c.JSON(http.StatusOK, rating)
+}
+
+// RetractRating handles DELETE /api/ratings/:id
+//
+// Lets the rater withdraw a rating within the same window as UpdateRating.
+func (h *ReputationHandler) RetractRating(c echo.Context) error {
+	userID, err := middleware.ExtractUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+	}
+
+	ratingID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid rating id"})
+	}
+
+	if err := h.repService.RetractRating(c.Request().Context(), uint(ratingID), userID); err != nil {
+		return ratingEditError(c, err, "failed to retract rating")
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "rating retracted"})
+}
+
+func ratingEditError(c echo.Context, err error, fallback string) error {
+	switch err {
+	case service.ErrInvalidRating:
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	case service.ErrRatingNotFound:
+		return c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+	case service.ErrNotRater:
+		return c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+	case service.ErrRatingLocked:
+		return c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fallback})
+	}
+}
+
 // SubmitSessionFeedback handles POST /api/sessions/:id/feedback
 func (h *ReputationHandler) SubmitSessionFeedback(c echo.Context) error {
 	userID, err := middleware.ExtractUserID(c)
@@ -138,7 +237,7 @@ func (h *ReputationHandler) SubmitSessionFeedback(c echo.Context) error {
 		FeedbackText:     req.FeedbackText,
 	}
 
-	err = h.repService.SubmitSessionFeedback(uint(sessionID), userID, input)
+	err = h.repService.SubmitSessionFeedback(c.Request().Context(), uint(sessionID), userID, input)
 	if err != nil {
 		switch err {
 		case service.ErrSessionNotFound:
@@ -237,19 +336,99 @@ func (h *ReputationHandler) GetMyRatings(c echo.Context) error {
 	})
 }
 
+// GetRatingTrends handles GET /api/ratings/trends?window=month&buckets=12
+//
+// Buckets the caller's received ratings by day, week or month for
+// reputation sparklines. buckets defaults per window and is capped at
+// service.MaxTrendBuckets.
+func (h *ReputationHandler) GetRatingTrends(c echo.Context) error {
+	userID, err := middleware.ExtractUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+	}
+
+	buckets, _ := strconv.Atoi(c.QueryParam("buckets"))
+
+	trends, err := h.repService.GetRatingTrends(c.Request().Context(), userID, c.QueryParam("window"), buckets)
+	if err != nil {
+		if err == service.ErrInvalidTrendWindow {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to fetch rating trends"})
+	}
+
+	return c.JSON(http.StatusOK, trends)
+}
+
+// GetPendingRatings handles GET /api/ratings/pending?page=1&limit=20
+//
+// Lists the caller's completed sessions still missing their rating or
+// feedback, with the partner's profile, most recent first.
+func (h *ReputationHandler) GetPendingRatings(c echo.Context) error {
+	userID, err := middleware.ExtractUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+	}
+
+	p, err := parsePagination(c, 0)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	items, total, err := h.repService.GetPendingRatings(c.Request().Context(), userID, p.Limit, p.Offset)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to fetch pending ratings"})
+	}
+
+	return c.JSON(http.StatusOK, p.List(items, total))
+}
+
+// GetUserBadges handles GET /api/users/:id/badges
+//
+// Lists the badges the user has earned with descriptions and earned dates.
+func (h *ReputationHandler) GetUserBadges(c echo.Context) error {
+	id := c.Param("id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid user id"})
+	}
+
+	badges, err := h.repService.GetUserBadges(c.Request().Context(), id)
+	if err != nil {
+		if err == service.ErrUserNotFound {
+			return c.JSON(http.StatusNotFound, ErrorResponse{Error: "user not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to fetch badges"})
+	}
+	return c.JSON(http.StatusOK, badges)
+}
+
+// GetReputationFormula handles GET /api/reputation/formula
+//
+// Shows the category weights and formulas behind every reputation score.
+func (h *ReputationHandler) GetReputationFormula(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.repService.Formula())
+}
+
 // GetLeaderboard handles GET /api/leaderboard?category=overall&limit=20
+//
+// limit defaults to 20 and is capped by the global pagination max as well
+// as the service's leaderboard cap (100).
 func (h *ReputationHandler) GetLeaderboard(c echo.Context) error {
 	category := c.QueryParam("category")
 	if category == "" {
 		category = "overall"
 	}
 
-	limit, _ := strconv.Atoi(c.QueryParam("limit"))
-	if limit <= 0 || limit > 100 {
-		limit = 20
+	limit, err := parseLimit(c, service.DefaultLeaderboardLimit)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 	}
 
-	contributors, err := h.repService.GetTopContributors(category, limit)
+	contributors, err := h.repService.GetTopContributors(c.Request().Context(), category, limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to fetch leaderboard"})
+	}
+	total, err := h.repService.CountRanked(c.Request().Context())
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to fetch leaderboard"})
 	}
@@ -264,7 +443,118 @@ func (h *ReputationHandler) GetLeaderboard(c echo.Context) error {
 	}
 
 	return c.JSON(http.StatusOK, LeaderboardResponse{
-		Category: category,
-		Entries:  entries,
+		Category:     category,
+		ListResponse: Pagination{Page: 1, Limit: limit}.List(entries, total),
+	})
+}
+
+// GetSkillLeaderboard handles GET /api/leaderboard/skill/:skillName?limit=20
+//
+// Ranks holders of the skill by their per-skill credibility score. limit
+// follows the same default and cap as GetLeaderboard.
+func (h *ReputationHandler) GetSkillLeaderboard(c echo.Context) error {
+	limit, err := parseLimit(c, service.DefaultLeaderboardLimit)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	skill, contributors, err := h.repService.GetTopBySkill(c.Request().Context(), c.Param("skillName"), limit)
+	if err != nil {
+		if err == service.ErrSkillNotFound {
+			return c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to fetch skill leaderboard"})
+	}
+	total, err := h.repService.CountRankedBySkill(c.Request().Context(), skill)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to fetch skill leaderboard"})
+	}
+
+	entries := make([]*SkillLeaderboardEntry, len(contributors))
+	for i, c := range contributors {
+		entries[i] = &SkillLeaderboardEntry{
+			LeaderboardEntry: LeaderboardEntry{
+				Rank:       i + 1,
+				User:       &c.User,
+				Reputation: c.Reputation,
+			},
+			SkillScore: c.SkillScore,
+		}
+	}
+
+	return c.JSON(http.StatusOK, SkillLeaderboardResponse{
+		Skill:        skill,
+		ListResponse: Pagination{Page: 1, Limit: limit}.List(entries, total),
 	})
 }
+
+// RecalculateReputation handles POST /api/users/:id/reputation/recalculate
+//
+// Recomputes a user's reputation synchronously and returns the fresh scores.
+// Only the user themselves or an admin may call it.
+func (h *ReputationHandler) RecalculateReputation(c echo.Context) error {
+	userID, err := middleware.ExtractUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+	}
+
+	id := c.Param("id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid user id"})
+	}
+	if id != userID && middleware.ExtractRole(c) != string(domain.RoleAdmin) {
+		return c.JSON(http.StatusForbidden, ErrorResponse{Error: "you can only recalculate your own reputation"})
+	}
+
+	rep, err := h.repService.RecalculateUserReputation(c.Request().Context(), id)
+	if err != nil {
+		if err == service.ErrUserNotFound {
+			return c.JSON(http.StatusNotFound, ErrorResponse{Error: "user not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to recalculate reputation"})
+	}
+
+	return c.JSON(http.StatusOK, rep)
+}
+
+// RecalculateAllReputations handles POST /api/admin/reputation/recalculate (admin only)
+//
+// Starts recomputing every user's reputation in the background and returns
+// 202 with the number of users queued.
+func (h *ReputationHandler) RecalculateAllReputations(c echo.Context) error {
+	// The run outlives the request, so it must not inherit its cancellation.
+	n, err := h.repService.RecalculateAll(context.WithoutCancel(c.Request().Context()))
+	if err != nil {
+		if err == service.ErrRecalculationRunning {
+			return c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to start recalculation"})
+	}
+
+	return c.JSON(http.StatusAccepted, map[string]interface{}{
+		"message": "reputation recalculation started",
+		"users":   n,
+	})
+}
+
+// GetTrustScore handles GET /api/users/:id/trust-score
+//
+// Returns the composite trust score with its component breakdown and the
+// weights used. Scores are cached briefly and refreshed on new ratings or
+// feedback.
+func (h *ReputationHandler) GetTrustScore(c echo.Context) error {
+	id := c.Param("id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid user id"})
+	}
+
+	ts, err := h.repService.GetTrustScore(c.Request().Context(), id)
+	if err != nil {
+		if err == service.ErrUserNotFound {
+			return c.JSON(http.StatusNotFound, ErrorResponse{Error: "user not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to compute trust score"})
+	}
+
+	return c.JSON(http.StatusOK, ts)
+}
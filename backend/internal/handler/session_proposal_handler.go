@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/yourusername/skillsync/internal/domain"
+	"github.com/yourusername/skillsync/internal/middleware"
+	"github.com/yourusername/skillsync/internal/service"
+)
+
+// ---------------------------------------------------------------------------
+// Request / Response DTOs
+// ---------------------------------------------------------------------------
+
+// ProposeSessionRequest suggests a session time to the other participant.
+// DurationMinutes defaults to 60.
+type ProposeSessionRequest struct {
+	StartsAt        time.Time `json:"starts_at" validate:"required"`
+	DurationMinutes int       `json:"duration_minutes" validate:"omitempty,min=15,max=480"`
+	Note            string    `json:"note" validate:"max=500"`
+}
+
+// AcceptProposalRequest optionally schedules the coding session right away.
+type AcceptProposalRequest struct {
+	CreateSession bool `json:"create_session"`
+}
+
+// ---------------------------------------------------------------------------
+// Handlers
+// ---------------------------------------------------------------------------
+
+// ProposeSession handles POST /api/matches/:id/sessions/propose
+func (h *MatchHandler) ProposeSession(c echo.Context) error {
+	userID, err := middleware.ExtractUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+	}
+	matchID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid match id"})
+	}
+
+	var req ProposeSessionRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	proposal, err := h.matchService.ProposeSession(c.Request().Context(), uint(matchID), userID, req.StartsAt, req.DurationMinutes, req.Note)
+	if err != nil {
+		switch err {
+		case service.ErrProposalInPast, service.ErrProposalTooFar:
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		case service.ErrMatchNotFound:
+			return c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		case service.ErrNotMatchParticipant:
+			return c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+		case service.ErrMatchNotActive, service.ErrProposalOverlap:
+			return c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+		default:
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to propose session"})
+		}
+	}
+
+	h.notifications.NotifySessionProposed(proposal)
+
+	return c.JSON(http.StatusCreated, proposal)
+}
+
+// GetSessionProposals handles GET /api/matches/:id/sessions/proposals?include_past=true
+//
+// Upcoming proposals of the match, soonest first, in every status.
+func (h *MatchHandler) GetSessionProposals(c echo.Context) error {
+	userID, err := middleware.ExtractUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+	}
+	matchID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid match id"})
+	}
+
+	proposals, err := h.matchService.GetSessionProposals(c.Request().Context(), uint(matchID), userID, c.QueryParam("include_past") == "true")
+	if err != nil {
+		switch err {
+		case service.ErrMatchNotFound:
+			return c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		case service.ErrNotMatchParticipant:
+			return c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+		default:
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to fetch session proposals"})
+		}
+	}
+
+	return c.JSON(http.StatusOK, listAll(proposals, len(proposals)))
+}
+
+// AcceptSessionProposal handles PUT /api/matches/:id/sessions/proposals/:proposalId/accept
+func (h *MatchHandler) AcceptSessionProposal(c echo.Context) error {
+	return h.answerSessionProposal(c, true)
+}
+
+// DeclineSessionProposal handles PUT /api/matches/:id/sessions/proposals/:proposalId/decline
+func (h *MatchHandler) DeclineSessionProposal(c echo.Context) error {
+	return h.answerSessionProposal(c, false)
+}
+
+func (h *MatchHandler) answerSessionProposal(c echo.Context, accept bool) error {
+	userID, err := middleware.ExtractUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+	}
+	matchID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid match id"})
+	}
+	proposalID, err := strconv.ParseUint(c.Param("proposalId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid proposal id"})
+	}
+
+	ctx := c.Request().Context()
+	var proposal *domain.SessionProposal
+	if accept {
+		var req AcceptProposalRequest
+		// The body is optional.
+		if c.Request().ContentLength > 0 {
+			if err := c.Bind(&req); err != nil {
+				return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+			}
+		}
+		proposal, err = h.matchService.AcceptSessionProposal(ctx, uint(matchID), uint(proposalID), userID, req.CreateSession)
+	} else {
+		proposal, err = h.matchService.DeclineSessionProposal(ctx, uint(matchID), uint(proposalID), userID)
+	}
+	if err != nil {
+		switch err {
+		case service.ErrProposalNotFound:
+			return c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		case service.ErrNotProposalRecipient:
+			return c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+		case service.ErrProposalNotPending, service.ErrProposalInPast, service.ErrMatchNotActive:
+			return c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+		default:
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to answer session proposal"})
+		}
+	}
+
+	h.notifications.NotifySessionProposalAnswered(proposal)
+
+	return c.JSON(http.StatusOK, proposal)
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/yourusername/skillsync/internal/domain"
+	"github.com/yourusername/skillsync/internal/service"
+)
+
+// ---------------------------------------------------------------------------
+// Request / Response DTOs
+// ---------------------------------------------------------------------------
+
+type SkillSearchResponse struct {
+	Skills []service.SkillSearchResult `json:"skills"`
+}
+
+type SkillCategoriesResponse struct {
+	Categories []domain.SkillCategory `json:"categories"`
+}
+
+type MergeSkillsRequest struct {
+	SourceID uint `json:"source_id" validate:"required"`
+	TargetID uint `json:"target_id" validate:"required"`
+}
+
+// ---------------------------------------------------------------------------
+// Handler
+// ---------------------------------------------------------------------------
+
+type SkillHandler struct {
+	skillService *service.SkillService
+	auditService *service.AuditService
+}
+
+func NewSkillHandler(ss *service.SkillService, as *service.AuditService) *SkillHandler {
+	return &SkillHandler{skillService: ss, auditService: as}
+}
+
+// SearchSkills handles GET /api/skills?q=nod&limit=10
+//
+// Autocomplete over the existing catalog; an empty q lists the most popular
+// skills.
+func (h *SkillHandler) SearchSkills(c echo.Context) error {
+	limit, err := parseLimit(c, 10)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	skills, err := h.skillService.SearchSkills(c.QueryParam("q"), limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to search skills"})
+	}
+
+	return c.JSON(http.StatusOK, SkillSearchResponse{Skills: skills})
+}
+
+// GetSkillCategories handles GET /api/skills/categories
+func (h *SkillHandler) GetSkillCategories(c echo.Context) error {
+	return c.JSON(http.StatusOK, SkillCategoriesResponse{Categories: domain.SkillCategories})
+}
+
+// MergeSkills handles POST /api/admin/skills/merge (admin only)
+//
+// Moves every user of source_id onto target_id and deletes source_id.
+func (h *SkillHandler) MergeSkills(c echo.Context) error {
+	var req MergeSkillsRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	skill, err := h.skillService.MergeSkills(req.SourceID, req.TargetID)
+	if err != nil {
+		switch err {
+		case service.ErrSkillMergeSelf:
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		case service.ErrSkillNotFound:
+			return c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		default:
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to merge skills"})
+		}
+	}
+
+	h.auditService.Audit(auditEntry(c, domain.AuditAdminAction, "skill", strconv.FormatUint(uint64(req.TargetID), 10),
+		map[string]interface{}{"operation": "merge_skills", "source_id": req.SourceID}))
+
+	return c.JSON(http.StatusOK, skill)
+}
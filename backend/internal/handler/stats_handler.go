@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/yourusername/skillsync/internal/service"
+)
+
+// ---------------------------------------------------------------------------
+// Handler
+// ---------------------------------------------------------------------------
+
+type StatsHandler struct {
+	statsService *service.StatsService
+}
+
+func NewStatsHandler(ss *service.StatsService) *StatsHandler {
+	return &StatsHandler{statsService: ss}
+}
+
+// GetPublicStats handles GET /api/stats
+//
+// Unauthenticated, for the landing page: user, match and session totals and
+// the most listed skills.
+func (h *StatsHandler) GetPublicStats(c echo.Context) error {
+	stats, err := h.statsService.GetPlatformStats(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to compute stats"})
+	}
+	return c.JSON(http.StatusOK, stats.PublicPlatformStats)
+}
+
+// GetAdminStats handles GET /api/admin/stats (admin only)
+//
+// The public stats plus message volume and the average match score.
+func (h *StatsHandler) GetAdminStats(c echo.Context) error {
+	stats, err := h.statsService.GetPlatformStats(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to compute stats"})
+	}
+	return c.JSON(http.StatusOK, stats)
+}
@@ -1,14 +1,19 @@
 package handler
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
 
+	"github.com/yourusername/skillsync/internal/domain"
 	"github.com/yourusername/skillsync/internal/middleware"
 	"github.com/yourusername/skillsync/internal/service"
+	ws "github.com/yourusername/skillsync/internal/websocket"
 )
 
 // ---------------------------------------------------------------------------
@@ -21,20 +26,49 @@ type UpdateUserRequest struct {
 	AvatarURL   *string `json:"avatar_url"`
 	GithubURL   *string `json:"github_url"`
 	LinkedinURL *string `json:"linkedin_url"`
+
+	PreferredCadence        *string `json:"preferred_cadence" validate:"omitempty,oneof=daily few_per_week weekly biweekly monthly"`
+	PreferredSessionMinutes *int    `json:"preferred_session_minutes" validate:"omitempty,oneof=30 45 60 90 120"`
+	Timezone                *string `json:"timezone" validate:"omitempty,timezone"`
+	// PreferredLanguages replaces the whole list when present; [] clears it.
+	PreferredLanguages []string `json:"preferred_languages" validate:"omitempty,max=10,dive,max=50"`
 }
 
 type AddSkillRequest struct {
 	SkillName   string  `json:"skill_name" validate:"required"`
 	Proficiency string  `json:"proficiency" validate:"required,oneof=beginner intermediate advanced"`
 	Years       float64 `json:"years_experience" validate:"gte=0"`
+	Direction   string  `json:"direction" validate:"omitempty,oneof=teach learn both"`
+}
+
+// AddSkillResponse confirms an added skill. Suggestions lists existing
+// catalog skills a newly created one is a typo away from.
+type AddSkillResponse struct {
+	Message     string   `json:"message"`
+	Suggestions []string `json:"suggestions,omitempty"`
 }
 
-type PaginatedUsersResponse struct {
-	Users  interface{} `json:"users"`
-	Total  int64       `json:"total"`
-	Page   int         `json:"page"`
-	Limit  int         `json:"limit"`
-	Pages  int         `json:"pages"`
+// BulkAddSkillsRequest carries the entries for POST /api/users/:id/skills/bulk.
+// Entries are validated individually so one bad item doesn't reject the batch.
+type BulkAddSkillsRequest struct {
+	Skills []AddSkillRequest `json:"skills" validate:"required,min=1"`
+}
+
+type BulkAddSkillsResponse struct {
+	Results []service.BulkSkillResult `json:"results"`
+	Added   int                       `json:"added"`
+	Skipped int                       `json:"skipped"`
+	Invalid int                       `json:"invalid"`
+	Failed  int                       `json:"failed"`
+}
+
+// BatchUsersRequest carries the ids for POST /api/users/batch.
+type BatchUsersRequest struct {
+	IDs []string `json:"ids" validate:"required,min=1,dive,uuid"`
+}
+
+type ReportUserRequest struct {
+	Reason string `json:"reason" validate:"required,max=2000"`
 }
 
 // ---------------------------------------------------------------------------
@@ -42,49 +76,90 @@ type PaginatedUsersResponse struct {
 // ---------------------------------------------------------------------------
 
 type UserHandler struct {
-	userService *service.UserService
+	userService     *service.UserService
+	blockService    *service.BlockService
+	presenceService *service.PresenceService
+	hub             *ws.Hub
 }
 
-func NewUserHandler(us *service.UserService) *UserHandler {
-	return &UserHandler{userService: us}
+func NewUserHandler(us *service.UserService, bs *service.BlockService, ps *service.PresenceService, hub *ws.Hub) *UserHandler {
+	return &UserHandler{userService: us, blockService: bs, presenceService: ps, hub: hub}
 }
 
-// GetUsers handles GET /api/users?skills=go,python&level=advanced&page=1&limit=20
+// GetUsers handles GET /api/users?skills=go,python&level=advanced&min_reputation=80&min_rating=4&has_completed_sessions=true&online=true&languages=go,rust&timezone=Europe/Berlin&timezone_range=3&page=1&limit=20
+//
+// min_reputation is the 0-100 overall score, min_rating the 1-5 average
+// rating. online=true keeps only users with an open websocket connection.
+// languages matches any preferred language; timezone keeps users whose UTC
+// offset is within timezone_range hours (default 3) of the given zone's.
 func (h *UserHandler) GetUsers(c echo.Context) error {
-	page, _ := strconv.Atoi(c.QueryParam("page"))
-	if page < 1 {
-		page = 1
-	}
-	limit, _ := strconv.Atoi(c.QueryParam("limit"))
-	if limit < 1 || limit > 100 {
-		limit = 20
+	p, err := parsePagination(c, 0)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 	}
 
-	var skills []string
+	filter := service.UserSearchFilter{
+		Level:  c.QueryParam("level"),
+		Search: c.QueryParam("search"),
+	}
 	if s := c.QueryParam("skills"); s != "" {
-		skills = strings.Split(s, ",")
+		filter.Skills = strings.Split(s, ",")
+	}
+	if v := c.QueryParam("min_reputation"); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f < 0 || f > 100 {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "min_reputation must be between 0 and 100"})
+		}
+		filter.MinReputation = f
+	}
+	if v := c.QueryParam("min_rating"); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f < 0 || f > 5 {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "min_rating must be between 0 and 5"})
+		}
+		filter.MinRating = f
+	}
+	if v := c.QueryParam("has_completed_sessions"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "has_completed_sessions must be true or false"})
+		}
+		filter.HasCompletedSessions = b
+	}
+	if v := c.QueryParam("online"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "online must be true or false"})
+		}
+		if b {
+			filter.OnlineUserIDs = h.hub.OnlineUserIDs()
+		}
 	}
-	level := c.QueryParam("level")
-	search := c.QueryParam("search")
 
-	offset := (page - 1) * limit
-	users, total, err := h.userService.SearchUsers(skills, level, search, limit, offset)
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to search users"})
+	if v := c.QueryParam("languages"); v != "" {
+		filter.Languages = service.NormalizeLanguages(strings.Split(v, ","))
+	}
+	if v := c.QueryParam("timezone"); v != "" {
+		filter.Timezone = v
+		filter.TimezoneRange = 3
+		if r := c.QueryParam("timezone_range"); r != "" {
+			n, err := strconv.Atoi(r)
+			if err != nil || n < 0 || n > 12 {
+				return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "timezone_range must be between 0 and 12"})
+			}
+			filter.TimezoneRange = n
+		}
 	}
 
-	pages := int(total) / limit
-	if int(total)%limit != 0 {
-		pages++
+	users, total, err := h.userService.SearchUsers(c.Request().Context(), filter, p.Limit, p.Offset)
+	if err != nil {
+		if err == service.ErrInvalidTimezone {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to search users"})
 	}
 
-	return c.JSON(http.StatusOK, PaginatedUsersResponse{
-		Users: users,
-		Total: total,
-		Page:  page,
-		Limit: limit,
-		Pages: pages,
-	})
+	return c.JSON(http.StatusOK, p.List(users, total))
 }
 
 // GetUser handles GET /api/users/:id
@@ -94,7 +169,7 @@ func (h *UserHandler) GetUser(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid user id"})
 	}
 
-	user, err := h.userService.GetUser(id)
+	user, err := h.userService.GetUser(c.Request().Context(), id)
 	if err != nil {
 		if err == service.ErrUserNotFound {
 			return c.JSON(http.StatusNotFound, ErrorResponse{Error: "user not found"})
@@ -105,6 +180,43 @@ func (h *UserHandler) GetUser(c echo.Context) error {
 	return c.JSON(http.StatusOK, user)
 }
 
+// GetUserPresence handles GET /api/users/:id/presence
+//
+// is_online reflects the user's open websocket connections right now.
+func (h *UserHandler) GetUserPresence(c echo.Context) error {
+	presence, err := h.presenceService.GetPresence(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		if err == service.ErrUserNotFound {
+			return c.JSON(http.StatusNotFound, ErrorResponse{Error: "user not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to fetch presence"})
+	}
+	return c.JSON(http.StatusOK, presence)
+}
+
+// GetUsersBatch handles POST /api/users/batch
+//
+// Looks up to service.MaxBatchUsers users by id in one query, returned in
+// request order with skills. Unknown ids are omitted.
+func (h *UserHandler) GetUsersBatch(c echo.Context) error {
+	var req BatchUsersRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	if len(req.IDs) > service.MaxBatchUsers {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "at most " + strconv.Itoa(service.MaxBatchUsers) + " ids per request"})
+	}
+
+	users, err := h.userService.GetUsersByIDs(c.Request().Context(), req.IDs)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to fetch users"})
+	}
+	return c.JSON(http.StatusOK, users)
+}
+
 // UpdateUser handles PUT /api/users/:id (protected - owner only)
 func (h *UserHandler) UpdateUser(c echo.Context) error {
 	id := c.Param("id")
@@ -124,32 +236,63 @@ func (h *UserHandler) UpdateUser(c echo.Context) error {
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
 	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
 
 	updates := make(map[string]interface{})
 	if req.FullName != nil {
-		updates["full_name"] = *req.FullName
+		name := domain.CleanPlainText(*req.FullName)
+		if err := domain.CheckLength("full_name", name, domain.MaxFullNameLength); err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		}
+		updates["full_name"] = name
 	}
 	if req.Bio != nil {
-		updates["bio"] = *req.Bio
+		bio := domain.CleanPlainText(*req.Bio)
+		if err := domain.CheckLength("bio", bio, domain.MaxBioLength); err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		}
+		updates["bio"] = bio
 	}
 	if req.AvatarURL != nil {
 		updates["avatar_url"] = *req.AvatarURL
 	}
 	if req.GithubURL != nil {
-		updates["github_url"] = *req.GithubURL
+		u, err := domain.NormalizeGitHubURL(*req.GithubURL)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		}
+		updates["github_url"] = u
 	}
 	if req.LinkedinURL != nil {
-		updates["linkedin_url"] = *req.LinkedinURL
+		u, err := domain.NormalizeLinkedInURL(*req.LinkedinURL)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		}
+		updates["linkedin_url"] = u
+	}
+	if req.PreferredCadence != nil {
+		updates["preferred_cadence"] = *req.PreferredCadence
+	}
+	if req.PreferredSessionMinutes != nil {
+		updates["preferred_session_minutes"] = *req.PreferredSessionMinutes
+	}
+	if req.Timezone != nil {
+		updates["timezone"] = *req.Timezone
+	}
+	if req.PreferredLanguages != nil {
+		updates["preferred_languages"] = domain.StringList(service.NormalizeLanguages(req.PreferredLanguages))
 	}
 
-	if err := h.userService.UpdateProfile(id, updates); err != nil {
+	if err := h.userService.UpdateProfile(c.Request().Context(), id, updates); err != nil {
 		if err == service.ErrUserNotFound {
 			return c.JSON(http.StatusNotFound, ErrorResponse{Error: "user not found"})
 		}
 		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to update profile"})
 	}
 
-	user, _ := h.userService.GetUser(id)
+	user, _ := h.userService.GetUser(c.Request().Context(), id)
 	return c.JSON(http.StatusOK, user)
 }
 
@@ -176,18 +319,79 @@ func (h *UserHandler) AddUserSkill(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 	}
 
-	if err := h.userService.AddSkill(id, req.SkillName, req.Proficiency, req.Years); err != nil {
+	suggestions, err := h.userService.AddSkill(c.Request().Context(), id, req.SkillName, req.Proficiency, req.Direction, req.Years)
+	if err != nil {
 		switch err {
 		case service.ErrSkillExists:
 			return c.JSON(http.StatusConflict, ErrorResponse{Error: "skill already added"})
-		case service.ErrInvalidLevel:
+		case service.ErrInvalidLevel, service.ErrInvalidDirection:
 			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 		default:
 			return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to add skill"})
 		}
 	}
 
-	return c.JSON(http.StatusCreated, map[string]string{"message": "skill added"})
+	return c.JSON(http.StatusCreated, AddSkillResponse{Message: "skill added", Suggestions: suggestions})
+}
+
+// BulkAddUserSkills handles POST /api/users/:id/skills/bulk
+//
+// Adds up to service.MaxBulkSkills skills at once and reports, per entry,
+// whether it was added, skipped as a duplicate, invalid or failed.
+func (h *UserHandler) BulkAddUserSkills(c echo.Context) error {
+	id := c.Param("id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid user id"})
+	}
+
+	authUserID, err := middleware.ExtractUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+	}
+	if authUserID != id {
+		return c.JSON(http.StatusForbidden, ErrorResponse{Error: "you can only add skills to your own profile"})
+	}
+
+	var req BulkAddSkillsRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	if len(req.Skills) > service.MaxBulkSkills {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "at most " + strconv.Itoa(service.MaxBulkSkills) + " skills per request"})
+	}
+
+	items := make([]service.BulkSkillInput, len(req.Skills))
+	for i, s := range req.Skills {
+		items[i] = service.BulkSkillInput{
+			SkillName:   s.SkillName,
+			Proficiency: s.Proficiency,
+			Direction:   s.Direction,
+			Years:       s.Years,
+		}
+	}
+
+	results, err := h.userService.BulkAddSkills(c.Request().Context(), id, items)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to add skills"})
+	}
+
+	resp := BulkAddSkillsResponse{Results: results}
+	for _, r := range results {
+		switch r.Status {
+		case service.BulkSkillAdded:
+			resp.Added++
+		case service.BulkSkillSkipped:
+			resp.Skipped++
+		case service.BulkSkillInvalid:
+			resp.Invalid++
+		case service.BulkSkillFailed:
+			resp.Failed++
+		}
+	}
+	return c.JSON(http.StatusOK, resp)
 }
 
 // GetUserReputation handles GET /api/users/:id/reputation
@@ -197,7 +401,7 @@ func (h *UserHandler) GetUserReputation(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid user id"})
 	}
 
-	user, err := h.userService.GetUserWithReputation(id)
+	user, err := h.userService.GetUserWithReputation(c.Request().Context(), id)
 	if err != nil {
 		if err == service.ErrUserNotFound {
 			return c.JSON(http.StatusNotFound, ErrorResponse{Error: "user not found"})
@@ -207,3 +411,118 @@ func (h *UserHandler) GetUserReputation(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, user.Reputation)
 }
+
+// GetMyStats handles GET /api/users/me/stats
+//
+// Pairing activity for the dashboard: matches, partners, sessions, hours
+// paired, most paired skill and acceptance rate of sent requests.
+func (h *UserHandler) GetMyStats(c echo.Context) error {
+	userID, err := middleware.ExtractUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+	}
+
+	stats, err := h.userService.GetStats(c.Request().Context(), userID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to compute stats"})
+	}
+
+	return c.JSON(http.StatusOK, stats)
+}
+
+// ExportMyData handles GET /api/users/me/export
+//
+// Streams the caller's data as a JSON attachment. Once streaming starts the
+// status is already 200, so a later failure can only truncate the document.
+func (h *UserHandler) ExportMyData(c echo.Context) error {
+	userID, err := middleware.ExtractUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+	res.Header().Set(echo.HeaderContentDisposition,
+		fmt.Sprintf(`attachment; filename="skillsync-export-%s.json"`, time.Now().UTC().Format("2006-01-02")))
+
+	if err := h.userService.ExportUserData(c.Request().Context(), userID, res); err != nil {
+		if res.Committed {
+			log.Error().Err(err).Str("user_id", userID).Msg("data export failed mid-stream")
+			return nil
+		}
+		res.Header().Del(echo.HeaderContentDisposition)
+		if err == service.ErrUserNotFound {
+			return c.JSON(http.StatusNotFound, ErrorResponse{Error: "user not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to export data"})
+	}
+	return nil
+}
+
+// BlockUser handles POST /api/users/:id/block
+//
+// The block is only visible to the caller, but match requests are refused in
+// both directions and pending ones are rejected.
+func (h *UserHandler) BlockUser(c echo.Context) error {
+	authUserID, err := middleware.ExtractUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+	}
+
+	if err := h.blockService.Block(authUserID, c.Param("id")); err != nil {
+		switch err {
+		case service.ErrCannotBlockSelf:
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		case service.ErrUserNotFound:
+			return c.JSON(http.StatusNotFound, ErrorResponse{Error: "user not found"})
+		default:
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to block user"})
+		}
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "user blocked"})
+}
+
+// UnblockUser handles DELETE /api/users/:id/block
+func (h *UserHandler) UnblockUser(c echo.Context) error {
+	authUserID, err := middleware.ExtractUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+	}
+
+	if err := h.blockService.Unblock(authUserID, c.Param("id")); err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to unblock user"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "user unblocked"})
+}
+
+// ReportUser handles POST /api/users/:id/report
+func (h *UserHandler) ReportUser(c echo.Context) error {
+	authUserID, err := middleware.ExtractUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+	}
+
+	var req ReportUserRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	report, err := h.blockService.Report(authUserID, c.Param("id"), req.Reason)
+	if err != nil {
+		switch err {
+		case service.ErrCannotReportSelf:
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		case service.ErrUserNotFound:
+			return c.JSON(http.StatusNotFound, ErrorResponse{Error: "user not found"})
+		default:
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to submit report"})
+		}
+	}
+
+	return c.JSON(http.StatusCreated, report)
+}
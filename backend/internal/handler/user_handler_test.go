@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+)
+
+func TestUpdateUserRequestCadenceValidation(t *testing.T) {
+	v := validator.New()
+	str := func(s string) *string { return &s }
+	num := func(n int) *int { return &n }
+
+	tests := []struct {
+		name  string
+		req   UpdateUserRequest
+		valid bool
+	}{
+		{"unset", UpdateUserRequest{}, true},
+		{"allowed", UpdateUserRequest{PreferredCadence: str("few_per_week"), PreferredSessionMinutes: num(90)}, true},
+		{"unknown cadence", UpdateUserRequest{PreferredCadence: str("hourly")}, false},
+		{"odd session length", UpdateUserRequest{PreferredSessionMinutes: num(50)}, false},
+	}
+	for _, tt := range tests {
+		if err := v.Struct(tt.req); (err == nil) != tt.valid {
+			t.Errorf("%s: validation error %v, want valid=%v", tt.name, err, tt.valid)
+		}
+	}
+}
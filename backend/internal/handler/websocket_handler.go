@@ -1,7 +1,6 @@
 package handler
 
 import (
-	"errors"
 	"net/http"
 	"strconv"
 
@@ -10,38 +9,70 @@ import (
 	"github.com/rs/zerolog/log"
 	"gorm.io/gorm"
 
-	"github.com/yourusername/skillsync/internal/domain"
+	"github.com/yourusername/skillsync/internal/middleware"
 	"github.com/yourusername/skillsync/pkg/auth"
 	ws "github.com/yourusername/skillsync/internal/websocket"
 )
 
-var upgrader = websocket.Upgrader{
-	ReadBufferSize:  1024,
-	WriteBufferSize: 1024,
-	CheckOrigin: func(r *http.Request) bool {
-		// In production, restrict this to your frontend's origin.
-		return true
-	},
-}
-
 type WebSocketHandler struct {
-	hub *ws.Hub
-	db  *gorm.DB
+	hub         *ws.Hub
+	db          *gorm.DB
+	bans        middleware.BanChecker
+	checkOrigin func(r *http.Request) bool
+	upgrader    websocket.Upgrader
 }
 
-func NewWebSocketHandler(hub *ws.Hub, db *gorm.DB) *WebSocketHandler {
-	return &WebSocketHandler{hub: hub, db: db}
+// NewWebSocketHandler accepts upgrades only from allowedOrigins, the same
+// list CORS uses (see middleware.LoadAllowedOrigins). Buffer sizes come from
+// the hub's Config. Suspended users are refused by bans, the checker the JWT
+// middleware uses.
+func NewWebSocketHandler(hub *ws.Hub, db *gorm.DB, bans middleware.BanChecker, allowedOrigins []string) *WebSocketHandler {
+	checkOrigin := middleware.OriginChecker(allowedOrigins)
+	cfg := hub.Config()
+	return &WebSocketHandler{
+		hub:         hub,
+		db:          db,
+		bans:        bans,
+		checkOrigin: checkOrigin,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  cfg.ReadBufferSize,
+			WriteBufferSize: cfg.WriteBufferSize,
+			CheckOrigin:     checkOrigin,
+		},
+	}
 }
 
-// HandleWebSocket handles GET /ws?token=xxx&match_id=1
+// HandleWebSocket handles GET /ws?token=xxx[&match_id=1]
 //
 // Flow:
-//  1. Read token + match_id from query params
+//  1. Read token + optional match_id from query params
 //  2. Validate JWT
-//  3. Verify user is a participant in the match
+//  3. Verify user is a participant in the match, if given
 //  4. Upgrade to WebSocket
 //  5. Create Client, register with Hub, start read/write pumps
+//
+// One connection can serve several matches. Clients join and leave rooms
+// with {"type":"subscribe","data":{"match_id":2}} and "unsubscribe", and
+// put match_id in chat_message, typing_indicator and code_change data; it
+// may be left out while subscribed to a single match. A connection opened
+// with match_id closes when that match ends and no other is subscribed.
+//
+// A text frame from the server may carry several messages, one JSON object
+// per line; clients must split frames on "\n" before parsing.
+//
+// The JWT is checked at upgrade; an open connection is not closed when it
+// expires. Clients that rotate tokens send
+// {"type":"refresh_token","data":{"token":"<new jwt>"}}, and a rejected
+// token closes the connection with one of these codes:
+//
+//	4002 refresh token invalid   - reconnect with a fresh token
+//	4003 token for another user  - do not retry on this connection
 func (h *WebSocketHandler) HandleWebSocket(c echo.Context) error {
+	if !h.checkOrigin(c.Request()) {
+		log.Warn().Str("origin", c.Request().Header.Get("Origin")).Msg("ws upgrade rejected: origin not allowed")
+		return c.JSON(http.StatusForbidden, ErrorResponse{Error: "origin not allowed"})
+	}
+
 	// --- authenticate via query param (WebSocket can't send headers) ---
 	token := c.QueryParam("token")
 	if token == "" {
@@ -49,42 +80,52 @@ func (h *WebSocketHandler) HandleWebSocket(c echo.Context) error {
 	}
 
 	claims, err := auth.ValidateToken(token)
-	if err != nil {
+	if err != nil || claims.ExpiresAt == nil {
 		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "invalid or expired token"})
 	}
 	userID := claims.UserID
 
-	// --- parse match_id ---
-	matchIDStr := c.QueryParam("match_id")
-	matchID64, err2 := strconv.ParseUint(matchIDStr, 10, 64)
-	if err2 != nil || matchID64 == 0 {
-		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid or missing match_id"})
+	if h.bans != nil && h.bans.IsBanned(userID) {
+		return c.JSON(http.StatusForbidden, ErrorResponse{Error: "account is suspended"})
 	}
-	matchID := uint(matchID64)
 
-	// --- verify the match exists and the user is a participant ---
-	var match domain.Match
-	if err := h.db.First(&match, "id = ?", matchID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return c.JSON(http.StatusNotFound, ErrorResponse{Error: "match not found"})
+	// --- optional initial match: must exist, be active and include the user ---
+	var matchIDs []uint
+	if matchIDStr := c.QueryParam("match_id"); matchIDStr != "" {
+		matchID64, err := strconv.ParseUint(matchIDStr, 10, 64)
+		if err != nil || matchID64 == 0 {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid match_id"})
 		}
-		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to fetch match"})
-	}
-	if match.User1ID != userID && match.User2ID != userID {
-		return c.JSON(http.StatusForbidden, ErrorResponse{Error: "you are not a participant in this match"})
+		if err := ws.CheckMatchAccess(h.db, userID, uint(matchID64)); err != nil {
+			switch err {
+			case ws.ErrMatchNotFound:
+				return c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+			case ws.ErrNotParticipant:
+				return c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+			case ws.ErrMatchNotActive:
+				return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			default:
+				return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to fetch match"})
+			}
+		}
+		matchIDs = append(matchIDs, uint(matchID64))
 	}
-	if match.Status != domain.MatchActive {
-		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "match is not active"})
+
+	// --- per-user connection cap ---
+	if !h.hub.AcquireConnection(userID) {
+		log.Warn().Str("user_id", userID).Msg("ws upgrade rejected: too many connections")
+		return c.JSON(http.StatusTooManyRequests, ErrorResponse{Error: "too many open connections"})
 	}
 
 	// --- upgrade to WebSocket ---
-	conn, err := upgrader.Upgrade(c.Response(), c.Request(), nil)
+	conn, err := h.upgrader.Upgrade(c.Response(), c.Request(), middleware.SecurityHeaders())
 	if err != nil {
+		h.hub.ReleaseConnection(userID)
 		log.Error().Err(err).Msg("ws upgrade failed")
 		return nil // Upgrade already wrote an HTTP error
 	}
 
-	client := ws.NewClient(h.hub, conn, userID, matchID, h.db)
+	client := ws.NewClient(h.hub, conn, userID, h.db, matchIDs...)
 	h.hub.Register(client)
 
 	// Start pumps in their own goroutines.
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	ws "github.com/yourusername/skillsync/internal/websocket"
+)
+
+func TestHandleWebSocketOrigin(t *testing.T) {
+	e := echo.New()
+	h := NewWebSocketHandler(ws.NewHub(ws.DefaultConfig()), nil, nil, []string{"https://app.example.com"})
+
+	// Without a token an accepted origin gets as far as authentication.
+	tests := []struct {
+		name   string
+		origin string
+		want   int
+	}{
+		{"allowed", "https://app.example.com", http.StatusUnauthorized},
+		{"disallowed", "https://evil.example.com", http.StatusForbidden},
+		{"missing", "", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/ws", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			rec := httptest.NewRecorder()
+			if err := h.HandleWebSocket(e.NewContext(req, rec)); err != nil {
+				t.Fatal(err)
+			}
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package middleware
+
+import "github.com/labstack/echo/v4"
+
+// ActivityRecorder records that a user just made an authenticated request.
+type ActivityRecorder interface {
+	Touch(userID string)
+}
+
+// ActivityMiddleware reports the authenticated user of each request to
+// activity. It must run after JWTMiddleware.
+func ActivityMiddleware(activity ActivityRecorder) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if userID, err := ExtractUserID(c); err == nil {
+				activity.Touch(userID)
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RequireRole returns Echo middleware that only lets through users whose
+// token carries the given role claim. Must sit behind JWTMiddleware.
+func RequireRole(role string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if ExtractRole(c) != role {
+				return c.JSON(http.StatusForbidden, map[string]string{
+					"error": role + " access required",
+				})
+			}
+			return next(c)
+		}
+	}
+}
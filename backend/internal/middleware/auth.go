@@ -10,14 +10,43 @@ import (
 	"github.com/yourusername/skillsync/pkg/auth"
 )
 
-const userIDKey = "user_id"
+const (
+	userIDKey      = "user_id"
+	roleKey        = "role"
+	apiKeyScopeKey = "api_key_scope"
+
+	// APIKeyHeader carries an API key in place of a Bearer token.
+	APIKeyHeader = "X-API-Key"
+	// apiKeyReadScope mirrors domain.APIKeyRead.
+	apiKeyReadScope = "read"
+)
+
+// BanChecker reports whether a user is currently suspended.
+type BanChecker interface {
+	IsBanned(userID string) bool
+}
+
+// APIKeyResolver resolves a raw API key to its owner and scope.
+type APIKeyResolver interface {
+	ResolveAPIKey(key string) (userID, scope string, err error)
+}
 
 // JWTMiddleware returns Echo middleware that validates a Bearer token from the
-// Authorization header and stores the authenticated user_id in the context.
-func JWTMiddleware() echo.MiddlewareFunc {
+// Authorization header and stores the authenticated user_id and role in the
+// context. Tokens of users that bans reports as suspended are rejected.
+//
+// When keys is non-nil, a request without an Authorization header may
+// authenticate with an X-API-Key header instead. Key requests never get a
+// role, and read-scoped keys are limited to GET and HEAD.
+func JWTMiddleware(bans BanChecker, keys APIKeyResolver) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			header := c.Request().Header.Get("Authorization")
+			if header == "" && keys != nil {
+				if key := c.Request().Header.Get(APIKeyHeader); key != "" {
+					return authenticateAPIKey(c, next, bans, keys, key)
+				}
+			}
 			if header == "" {
 				return c.JSON(http.StatusUnauthorized, map[string]string{
 					"error": "missing authorization header",
@@ -38,7 +67,59 @@ func JWTMiddleware() echo.MiddlewareFunc {
 				})
 			}
 
+			if bans != nil && bans.IsBanned(claims.UserID) {
+				return c.JSON(http.StatusForbidden, map[string]string{
+					"error": "account is suspended",
+				})
+			}
+
 			c.Set(userIDKey, claims.UserID)
+			c.Set(roleKey, claims.Role)
+			return next(c)
+		}
+	}
+}
+
+func authenticateAPIKey(c echo.Context, next echo.HandlerFunc, bans BanChecker, keys APIKeyResolver, key string) error {
+	userID, scope, err := keys.ResolveAPIKey(key)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "invalid or revoked api key",
+		})
+	}
+
+	if bans != nil && bans.IsBanned(userID) {
+		return c.JSON(http.StatusForbidden, map[string]string{
+			"error": "account is suspended",
+		})
+	}
+
+	if scope == apiKeyReadScope {
+		switch c.Request().Method {
+		case http.MethodGet, http.MethodHead:
+		default:
+			return c.JSON(http.StatusForbidden, map[string]string{
+				"error": "api key is read-only",
+			})
+		}
+	}
+
+	c.Set(userIDKey, userID)
+	c.Set(apiKeyScopeKey, scope)
+	return next(c)
+}
+
+// RequireSession returns Echo middleware that rejects requests
+// authenticated with an API key, for account and key management routes.
+// Must sit behind JWTMiddleware.
+func RequireSession() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if ExtractAPIKeyScope(c) != "" {
+				return c.JSON(http.StatusForbidden, map[string]string{
+					"error": "this endpoint requires a signed-in session, not an api key",
+				})
+			}
 			return next(c)
 		}
 	}
@@ -53,3 +134,17 @@ func ExtractUserID(c echo.Context) (string, error) {
 	}
 	return id, nil
 }
+
+// ExtractRole returns the authenticated user's role claim, or "" when the
+// token carries none.
+func ExtractRole(c echo.Context) string {
+	role, _ := c.Get(roleKey).(string)
+	return role
+}
+
+// ExtractAPIKeyScope returns the scope of the API key that authenticated
+// the request, or "" when it used a JWT.
+func ExtractAPIKeyScope(c echo.Context) string {
+	scope, _ := c.Get(apiKeyScopeKey).(string)
+	return scope
+}
@@ -1,42 +1,103 @@
 package middleware
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
 	"os"
 	"strings"
 
 	"github.com/labstack/echo/v4"
 )
 
-// CORSMiddleware returns Echo middleware that sets CORS headers based on
-// the CORS_ALLOWED_ORIGINS env var (comma-separated). Falls back to
-// localhost origins for development.
-func CORSMiddleware() echo.MiddlewareFunc {
-	raw := os.Getenv("CORS_ALLOWED_ORIGINS")
+// defaultDevOrigins are allowed when no origins are configured outside
+// production.
+var defaultDevOrigins = []string{"http://localhost:3000", "http://localhost:5173"}
+
+// LoadAllowedOrigins reads the comma-separated ALLOWED_ORIGINS env var
+// (CORS_ALLOWED_ORIGINS is still honoured as a fallback) and validates each
+// entry as scheme://host[:port] or "*". An empty list falls back to the local
+// dev servers, except when APP_ENV=production, where it is an error, as is
+// "*".
+func LoadAllowedOrigins() ([]string, error) {
+	production := os.Getenv("APP_ENV") == "production"
+
+	raw := os.Getenv("ALLOWED_ORIGINS")
 	if raw == "" {
-		raw = "http://localhost:3000,http://localhost:5173"
+		raw = os.Getenv("CORS_ALLOWED_ORIGINS")
+	}
+
+	var origins []string
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if part == "*" {
+			if production {
+				return nil, errors.New(`ALLOWED_ORIGINS may not contain "*" in production`)
+			}
+			origins = append(origins, part)
+			continue
+		}
+		u, err := url.Parse(strings.TrimSuffix(part, "/"))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+			u.Path != "" || u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+			return nil, fmt.Errorf("invalid origin %q in ALLOWED_ORIGINS: want scheme://host[:port]", part)
+		}
+		origins = append(origins, u.Scheme+"://"+u.Host)
+	}
+
+	if len(origins) == 0 {
+		if production {
+			return nil, errors.New("ALLOWED_ORIGINS must list at least one origin in production")
+		}
+		origins = append(origins, defaultDevOrigins...)
+	}
+	return origins, nil
+}
+
+// OriginChecker returns a websocket.Upgrader CheckOrigin func that accepts
+// the same origins as CORSMiddleware. Requests without an Origin header come
+// from non-browser clients, which cross-site hijacking can't target, so they
+// are allowed.
+func OriginChecker(origins []string) func(r *http.Request) bool {
+	originSet := make(map[string]bool, len(origins))
+	for _, o := range origins {
+		originSet[o] = true
 	}
-	allowed := strings.Split(raw, ",")
-	originSet := make(map[string]bool, len(allowed))
-	for _, o := range allowed {
-		originSet[strings.TrimSpace(o)] = true
+
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		return origin == "" || originSet[origin] || originSet["*"]
+	}
+}
+
+// CORSMiddleware returns Echo middleware that sets CORS headers for requests
+// from any of the given origins. Use LoadAllowedOrigins to build the list.
+func CORSMiddleware(origins []string) echo.MiddlewareFunc {
+	originSet := make(map[string]bool, len(origins))
+	for _, o := range origins {
+		originSet[o] = true
 	}
 
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			origin := c.Request().Header.Get("Origin")
 
-			if originSet[origin] || originSet["*"] {
+			if origin != "" && (originSet[origin] || originSet["*"]) {
 				c.Response().Header().Set("Access-Control-Allow-Origin", origin)
+				c.Response().Header().Add("Vary", "Origin")
 			}
 
 			c.Response().Header().Set("Access-Control-Allow-Credentials", "true")
 			c.Response().Header().Set("Access-Control-Allow-Methods",
 				"GET, POST, PUT, PATCH, DELETE, OPTIONS")
 			c.Response().Header().Set("Access-Control-Allow-Headers",
-				"Origin, Content-Type, Accept, Authorization, X-Request-ID")
+				"Origin, Content-Type, Accept, Authorization, X-Request-ID, Idempotency-Key, X-API-Key")
 			c.Response().Header().Set("Access-Control-Expose-Headers",
-				"X-Request-ID")
+				"X-Request-ID, X-RateLimit-Limit, X-RateLimit-Remaining, X-RateLimit-Reset, Retry-After, Idempotent-Replayed")
 			c.Response().Header().Set("Access-Control-Max-Age", "86400")
 
 			if c.Request().Method == http.MethodOptions {
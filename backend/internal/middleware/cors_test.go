@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+var testOrigins = []string{"https://app.example.com", "http://localhost:5173"}
+
+func TestOriginChecker(t *testing.T) {
+	check := OriginChecker(testOrigins)
+
+	tests := []struct {
+		name   string
+		origin string
+		want   bool
+	}{
+		{"allowed", "https://app.example.com", true},
+		{"allowed dev server", "http://localhost:5173", true},
+		{"disallowed", "https://evil.example.com", false},
+		{"scheme mismatch", "http://app.example.com", false},
+		{"missing", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/ws", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if got := check(req); got != tt.want {
+				t.Fatalf("OriginChecker(%q) = %v, want %v", tt.origin, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOriginCheckerWildcard(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/ws", nil)
+	req.Header.Set("Origin", "https://anything.example.com")
+	if !OriginChecker([]string{"*"})(req) {
+		t.Fatal(`"*" rejected an origin`)
+	}
+}
+
+func TestCORSMiddlewareOrigins(t *testing.T) {
+	e := echo.New()
+	h := CORSMiddleware(testOrigins)(func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	tests := []struct {
+		name   string
+		origin string
+		want   string
+	}{
+		{"allowed", "https://app.example.com", "https://app.example.com"},
+		{"disallowed", "https://evil.example.com", ""},
+		{"missing", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/users/me", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			rec := httptest.NewRecorder()
+			if err := h(e.NewContext(req, rec)); err != nil {
+				t.Fatal(err)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tt.want {
+				t.Fatalf("Access-Control-Allow-Origin = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadAllowedOrigins(t *testing.T) {
+	t.Setenv("CORS_ALLOWED_ORIGINS", "")
+
+	t.Setenv("APP_ENV", "development")
+	t.Setenv("ALLOWED_ORIGINS", "https://app.example.com/, *")
+	origins, err := LoadAllowedOrigins()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(origins) != 2 || origins[0] != "https://app.example.com" || origins[1] != "*" {
+		t.Fatalf("origins = %q", origins)
+	}
+
+	t.Setenv("ALLOWED_ORIGINS", "")
+	if origins, err := LoadAllowedOrigins(); err != nil || len(origins) != len(defaultDevOrigins) {
+		t.Fatalf("dev default = %q, %v", origins, err)
+	}
+
+	t.Setenv("ALLOWED_ORIGINS", "app.example.com")
+	if _, err := LoadAllowedOrigins(); err == nil {
+		t.Fatal("origin without a scheme accepted")
+	}
+
+	t.Setenv("APP_ENV", "production")
+	t.Setenv("ALLOWED_ORIGINS", "*")
+	if _, err := LoadAllowedOrigins(); err == nil {
+		t.Fatal(`"*" accepted in production`)
+	}
+	t.Setenv("ALLOWED_ORIGINS", "")
+	if _, err := LoadAllowedOrigins(); err == nil {
+		t.Fatal("empty list accepted in production")
+	}
+}
+
+// Browsers only send and read the headers the API's features rely on when
+// CORS lists them.
+func TestCORSMiddlewareHeaders(t *testing.T) {
+	e := echo.New()
+	h := CORSMiddleware(testOrigins)(func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+	req := httptest.NewRequest(http.MethodOptions, "/api/matches", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	rec := httptest.NewRecorder()
+	if err := h(e.NewContext(req, rec)); err != nil {
+		t.Fatal(err)
+	}
+
+	for header, want := range map[string][]string{
+		"Access-Control-Allow-Headers":  {"Idempotency-Key", "X-API-Key"},
+		"Access-Control-Expose-Headers": {"Idempotent-Replayed"},
+	} {
+		got := strings.Split(rec.Header().Get(header), ", ")
+		for _, name := range want {
+			if !slices.Contains(got, name) {
+				t.Errorf("%s = %q, missing %s", header, rec.Header().Get(header), name)
+			}
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+
+	"github.com/yourusername/skillsync/internal/service"
+)
+
+const maxIdempotencyKeyLen = 255
+
+// ErrorResponse is the JSON error body, shared with the handlers.
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// IdempotencyStore claims idempotency keys and stores their responses.
+// It is implemented by service.IdempotencyService.
+type IdempotencyStore interface {
+	Begin(userID, key, requestHash string) (*service.IdempotentResponse, error)
+	Complete(userID, key string, status int, body []byte) error
+	Release(userID, key string) error
+}
+
+// IdempotencyMiddleware makes a route safe to retry. When the request
+// carries an Idempotency-Key header, the first response for that key is
+// stored and replayed for later requests with the same key and body, marked
+// with an Idempotent-Replayed header. Server errors are not stored, and
+// neither is anything when the handler panics, so the request can be
+// retried. Requests without the header pass straight through. Must sit
+// behind JWTMiddleware.
+func IdempotencyMiddleware(store IdempotencyStore) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := c.Request().Header.Get("Idempotency-Key")
+			if key == "" {
+				return next(c)
+			}
+			if len(key) > maxIdempotencyKeyLen {
+				return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Idempotency-Key must be at most 255 characters"})
+			}
+			userID, err := ExtractUserID(c)
+			if err != nil {
+				return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+			}
+
+			body, err := io.ReadAll(c.Request().Body)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+			}
+			c.Request().Body = io.NopCloser(bytes.NewReader(body))
+
+			sum := sha256.Sum256(append([]byte(c.Request().Method+" "+c.Path()+"\n"), body...))
+			stored, err := store.Begin(userID, key, hex.EncodeToString(sum[:]))
+			switch err {
+			case nil:
+			case service.ErrIdempotencyInProgress:
+				return c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+			case service.ErrIdempotencyKeyReused:
+				return c.JSON(http.StatusUnprocessableEntity, ErrorResponse{Error: err.Error()})
+			default:
+				return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to check idempotency key"})
+			}
+			if stored != nil {
+				c.Response().Header().Set("Idempotent-Replayed", "true")
+				return c.Blob(stored.StatusCode, echo.MIMEApplicationJSONCharsetUTF8, stored.Body)
+			}
+
+			// Until a response is stored, the claim is dropped on the way
+			// out, including when next panics; otherwise retries would get
+			// 409 until the key expires.
+			completed := false
+			defer func() {
+				if completed {
+					return
+				}
+				if err := store.Release(userID, key); err != nil {
+					log.Error().Err(err).Str("user_id", userID).Msg("failed to release idempotency key")
+				}
+			}()
+
+			rec := &responseRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = rec
+			handlerErr := next(c)
+			if handlerErr != nil {
+				c.Error(handlerErr)
+			}
+
+			status := c.Response().Status
+			if status < http.StatusInternalServerError {
+				if err := store.Complete(userID, key, status, rec.body.Bytes()); err != nil {
+					log.Error().Err(err).Str("user_id", userID).Msg("failed to record idempotency key")
+				} else {
+					completed = true
+				}
+			}
+			return nil
+		}
+	}
+}
+
+// responseRecorder copies the response body as it is written.
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,182 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/yourusername/skillsync/internal/service"
+)
+
+// memoryIdempotencyStore mirrors service.IdempotencyService in memory.
+type memoryIdempotencyStore struct {
+	mu   sync.Mutex
+	keys map[string]*memoryIdempotencyKey
+}
+
+type memoryIdempotencyKey struct {
+	hash   string
+	status int
+	body   []byte
+}
+
+func newMemoryIdempotencyStore() *memoryIdempotencyStore {
+	return &memoryIdempotencyStore{keys: make(map[string]*memoryIdempotencyKey)}
+}
+
+func (s *memoryIdempotencyStore) Begin(userID, key, requestHash string) (*service.IdempotentResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k, ok := s.keys[userID+"/"+key]
+	if !ok {
+		s.keys[userID+"/"+key] = &memoryIdempotencyKey{hash: requestHash}
+		return nil, nil
+	}
+	if k.hash != requestHash {
+		return nil, service.ErrIdempotencyKeyReused
+	}
+	if k.status == 0 {
+		return nil, service.ErrIdempotencyInProgress
+	}
+	return &service.IdempotentResponse{StatusCode: k.status, Body: k.body}, nil
+}
+
+func (s *memoryIdempotencyStore) Complete(userID, key string, status int, body []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if k, ok := s.keys[userID+"/"+key]; ok {
+		k.status, k.body = status, append([]byte(nil), body...)
+	}
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Release(userID, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if k, ok := s.keys[userID+"/"+key]; ok && k.status == 0 {
+		delete(s.keys, userID+"/"+key)
+	}
+	return nil
+}
+
+// serveIdempotent runs one POST with an Idempotency-Key through h.
+func serveIdempotent(e *echo.Echo, h echo.HandlerFunc, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/things", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set("Idempotency-Key", key)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/api/things")
+	c.Set(userIDKey, "user-1")
+	h(c)
+	return rec
+}
+
+func TestIdempotencyConcurrentDuplicates(t *testing.T) {
+	const n = 10
+	e := echo.New()
+	store := newMemoryIdempotencyStore()
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	h := IdempotencyMiddleware(store)(func(c echo.Context) error {
+		calls.Add(1)
+		<-release
+		return c.JSON(http.StatusCreated, map[string]string{"id": "42"})
+	})
+
+	codes := make(chan int, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes <- serveIdempotent(e, h, "submit-1", `{"name":"x"}`).Code
+		}()
+	}
+
+	// Every duplicate is turned away while the first is still running.
+	for i := 0; i < n-1; i++ {
+		if code := <-codes; code != http.StatusConflict {
+			t.Fatalf("duplicate got %d, want %d", code, http.StatusConflict)
+		}
+	}
+	close(release)
+	wg.Wait()
+	if code := <-codes; code != http.StatusCreated {
+		t.Fatalf("first request got %d, want %d", code, http.StatusCreated)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("handler ran %d times, want 1", got)
+	}
+
+	rec := serveIdempotent(e, h, "submit-1", `{"name":"x"}`)
+	if rec.Code != http.StatusCreated || rec.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("retry got %d replayed=%q, want replayed %d", rec.Code, rec.Header().Get("Idempotent-Replayed"), http.StatusCreated)
+	}
+	if !strings.Contains(rec.Body.String(), `"42"`) {
+		t.Fatalf("replayed body = %s", rec.Body.String())
+	}
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("handler ran %d times after replay, want 1", got)
+	}
+}
+
+func TestIdempotencyKeyReusedForDifferentBody(t *testing.T) {
+	e := echo.New()
+	h := IdempotencyMiddleware(newMemoryIdempotencyStore())(func(c echo.Context) error {
+		return c.JSON(http.StatusCreated, map[string]string{"ok": "true"})
+	})
+
+	serveIdempotent(e, h, "submit-2", `{"name":"a"}`)
+	if rec := serveIdempotent(e, h, "submit-2", `{"name":"b"}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("got %d, want %d", rec.Code, http.StatusUnprocessableEntity)
+	}
+}
+
+func TestIdempotencyReleasedAfterPanic(t *testing.T) {
+	e := echo.New()
+	store := newMemoryIdempotencyStore()
+
+	var calls atomic.Int32
+	h := IdempotencyMiddleware(store)(func(c echo.Context) error {
+		if calls.Add(1) == 1 {
+			panic("handler bug")
+		}
+		return c.JSON(http.StatusCreated, map[string]string{"ok": "true"})
+	})
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("expected the panic to propagate")
+			}
+		}()
+		serveIdempotent(e, h, "submit-3", `{}`)
+	}()
+
+	if rec := serveIdempotent(e, h, "submit-3", `{}`); rec.Code != http.StatusCreated {
+		t.Fatalf("retry after panic got %d, want %d", rec.Code, http.StatusCreated)
+	}
+}
+
+func TestIdempotencyServerErrorNotStored(t *testing.T) {
+	e := echo.New()
+	var calls atomic.Int32
+	h := IdempotencyMiddleware(newMemoryIdempotencyStore())(func(c echo.Context) error {
+		if calls.Add(1) == 1 {
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "boom"})
+		}
+		return c.JSON(http.StatusCreated, map[string]string{"ok": "true"})
+	})
+
+	serveIdempotent(e, h, "submit-4", `{}`)
+	if rec := serveIdempotent(e, h, "submit-4", `{}`); rec.Code != http.StatusCreated {
+		t.Fatalf("retry after 500 got %d, want %d", rec.Code, http.StatusCreated)
+	}
+}
@@ -0,0 +1,119 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+)
+
+// ---------------------------------------------------------------------------
+// Redis sliding-window rate limiter (per IP, shared across instances)
+// ---------------------------------------------------------------------------
+
+const (
+	defaultRateLimitPrefix = "skillsync"
+	redisRateLimitTimeout  = 100 * time.Millisecond
+)
+
+// slidingWindowScript keeps one sorted-set entry per allowed request, scored
+// by Redis server time in microseconds so instances with skewed clocks agree.
+// It returns {allowed (0 or 1), requests in the window, µs until the oldest
+// leaves it}.
+//
+//	KEYS[1] = bucket key
+//	ARGV[1] = window (µs), ARGV[2] = limit, ARGV[3] = unique member suffix
+var slidingWindowScript = redis.NewScript(`
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+local window = tonumber(ARGV[1])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local allowed = 0
+if redis.call('ZCARD', KEYS[1]) < tonumber(ARGV[2]) then
+	redis.call('ZADD', KEYS[1], now, now .. '-' .. ARGV[3])
+	redis.call('PEXPIRE', KEYS[1], math.ceil(window / 1000))
+	allowed = 1
+end
+local count = redis.call('ZCARD', KEYS[1])
+local reset = 0
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window - now
+end
+return {allowed, count, reset}
+`)
+
+type redisRateLimiter struct {
+	client   *redis.Client
+	prefix   string
+	limit    int
+	window   time.Duration
+	fallback *rateLimiter
+}
+
+// newRedisRateLimiter connects to redisURL. Keys are namespaced as
+// "<prefix>:ratelimit:<limit>/<window>:<ip>" so several deployments, and
+// several limiters in one deployment, can share a Redis instance. When Redis
+// is unreachable a request is checked against an in-memory limiter instead,
+// so each instance still enforces the limit on its own.
+func newRedisRateLimiter(redisURL, prefix string, limit int, window time.Duration) (*redisRateLimiter, error) {
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid REDIS_URL: %w", err)
+	}
+	return &redisRateLimiter{
+		client:   redis.NewClient(opts),
+		prefix:   fmt.Sprintf("%s:ratelimit:%d/%s:", prefix, limit, window),
+		limit:    limit,
+		window:   window,
+		fallback: newRateLimiter(limit, window),
+	}, nil
+}
+
+func (rl *redisRateLimiter) allow(ip string) rateLimitState {
+	ctx, cancel := context.WithTimeout(context.Background(), redisRateLimitTimeout)
+	defer cancel()
+
+	res, err := slidingWindowScript.Run(ctx, rl.client, []string{rl.prefix + ip},
+		rl.window.Microseconds(), rl.limit, strconv.FormatUint(rand.Uint64(), 36)).Int64Slice()
+	if err == nil && len(res) != 3 {
+		err = fmt.Errorf("unexpected rate limit script result %v", res)
+	}
+	if err != nil {
+		log.Warn().Err(err).Msg("redis rate limiter unavailable; using in-memory limit")
+		return rl.fallback.allow(ip)
+	}
+	return rateLimitState{
+		Allowed:   res[0] == 1,
+		Limit:     rl.limit,
+		Remaining: max(rl.limit-int(res[1]), 0),
+		Reset:     time.Now().Add(time.Duration(res[2]) * time.Microsecond),
+	}
+}
+
+// newLimiterFromEnv returns the Redis limiter when REDIS_URL is set and the
+// in-memory one otherwise. RATE_LIMIT_PREFIX overrides the Redis key
+// namespace (default "skillsync").
+func newLimiterFromEnv(limit int, window time.Duration) limiter {
+	redisURL := os.Getenv("REDIS_URL")
+	if redisURL == "" {
+		return newRateLimiter(limit, window)
+	}
+
+	prefix := os.Getenv("RATE_LIMIT_PREFIX")
+	if prefix == "" {
+		prefix = defaultRateLimitPrefix
+	}
+	rl, err := newRedisRateLimiter(redisURL, prefix, limit, window)
+	if err != nil {
+		log.Error().Err(err).Msg("redis rate limiter disabled; using in-memory limit")
+		return newRateLimiter(limit, window)
+	}
+	log.Info().Str("prefix", prefix).Int("limit", limit).Dur("window", window).Msg("using redis rate limiter")
+	return rl
+}
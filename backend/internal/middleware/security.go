@@ -1,24 +1,38 @@
 package middleware
 
 import (
+	"math"
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/labstack/echo/v4"
 )
 
+// SecurityHeaders returns the security-related headers set on every
+// response. The websocket handler passes them to the upgrader, which writes
+// its 101 response itself and ignores headers set by middleware.
+func SecurityHeaders() http.Header {
+	h := http.Header{}
+	h.Set("X-Frame-Options", "DENY")
+	h.Set("X-Content-Type-Options", "nosniff")
+	h.Set("X-XSS-Protection", "1; mode=block")
+	h.Set("Referrer-Policy", "strict-origin-when-cross-origin")
+	h.Set("Strict-Transport-Security", "max-age=31536000; includeSubDomains")
+	h.Set("Permissions-Policy", "camera=(), microphone=(), geolocation=()")
+	return h
+}
+
 // SecurityHeadersMiddleware sets common security-related HTTP headers.
 func SecurityHeadersMiddleware() echo.MiddlewareFunc {
+	headers := SecurityHeaders()
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			h := c.Response().Header()
-			h.Set("X-Frame-Options", "DENY")
-			h.Set("X-Content-Type-Options", "nosniff")
-			h.Set("X-XSS-Protection", "1; mode=block")
-			h.Set("Referrer-Policy", "strict-origin-when-cross-origin")
-			h.Set("Strict-Transport-Security", "max-age=31536000; includeSubDomains")
-			h.Set("Permissions-Policy", "camera=(), microphone=(), geolocation=()")
+			for k, v := range headers {
+				h[k] = v
+			}
 			return next(c)
 		}
 	}
@@ -44,6 +58,22 @@ func RequestSizeLimitMiddleware(maxBytes int64) echo.MiddlewareFunc {
 // In-memory sliding-window rate limiter (per IP)
 // ---------------------------------------------------------------------------
 
+// limiter records a request from key, if its quota allows one now, and
+// reports the quota.
+type limiter interface {
+	allow(key string) rateLimitState
+}
+
+// rateLimitState is a key's quota right after a request was checked.
+// Remaining counts the requests still allowed in the current window; Reset
+// is when the oldest counted request leaves the window and frees a slot.
+type rateLimitState struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
 type rateLimiter struct {
 	mu       sync.Mutex
 	visitors map[string]*visitor
@@ -71,7 +101,7 @@ func newRateLimiter(limit int, window time.Duration) *rateLimiter {
 	return rl
 }
 
-func (rl *rateLimiter) allow(ip string) bool {
+func (rl *rateLimiter) allow(ip string) rateLimitState {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 
@@ -93,12 +123,21 @@ func (rl *rateLimiter) allow(ip string) bool {
 	}
 	v.timestamps = valid
 
-	if len(v.timestamps) >= rl.limit {
-		return false
+	allowed := len(v.timestamps) < rl.limit
+	if allowed {
+		v.timestamps = append(v.timestamps, now)
 	}
 
-	v.timestamps = append(v.timestamps, now)
-	return true
+	state := rateLimitState{
+		Allowed:   allowed,
+		Limit:     rl.limit,
+		Remaining: rl.limit - len(v.timestamps),
+		Reset:     now,
+	}
+	if len(v.timestamps) > 0 {
+		state.Reset = v.timestamps[0].Add(rl.window)
+	}
+	return state
 }
 
 func (rl *rateLimiter) cleanup() {
@@ -122,17 +161,57 @@ func (rl *rateLimiter) cleanup() {
 }
 
 // RateLimitMiddleware limits each IP to `limit` requests per `window`.
-// Default: 100 requests per minute.
+// Default: 100 requests per minute. With REDIS_URL set the count is shared by
+// every instance through Redis; otherwise it is kept in memory.
+//
+// Every response carries X-RateLimit-Limit, X-RateLimit-Remaining and
+// X-RateLimit-Reset (Unix seconds at which a slot frees up); a 429 also
+// carries Retry-After.
 func RateLimitMiddleware(limit int, window time.Duration) echo.MiddlewareFunc {
-	rl := newRateLimiter(limit, window)
+	rl := newLimiterFromEnv(limit, window)
 
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			ip := c.RealIP()
-			if !rl.allow(ip) {
-				return c.JSON(http.StatusTooManyRequests, map[string]string{
-					"error": "rate limit exceeded, try again later",
-				})
+			state := rl.allow(c.RealIP())
+			h := c.Response().Header()
+			h.Set("X-RateLimit-Limit", strconv.Itoa(state.Limit))
+			h.Set("X-RateLimit-Remaining", strconv.Itoa(state.Remaining))
+			h.Set("X-RateLimit-Reset", strconv.FormatInt(state.Reset.Unix(), 10))
+			if !state.Allowed {
+				return rateLimited(c, state)
+			}
+			return next(c)
+		}
+	}
+}
+
+// rateLimited writes the 429 response for a denied request.
+func rateLimited(c echo.Context, state rateLimitState) error {
+	// Round up so a client waiting Retry-After seconds is let through.
+	wait := int64(math.Ceil(time.Until(state.Reset).Seconds()))
+	c.Response().Header().Set("Retry-After", strconv.FormatInt(max(wait, 1), 10))
+	return c.JSON(http.StatusTooManyRequests, map[string]string{
+		"error": "rate limit exceeded, try again later",
+	})
+}
+
+// UserRateLimitMiddleware limits each authenticated user to `limit` requests
+// per `window` on the routes it wraps, for endpoints expensive enough to need
+// a tighter cap than the per-IP limit. scope keeps the counts of separately
+// limited routes apart. Must sit behind JWTMiddleware.
+func UserRateLimitMiddleware(scope string, limit int, window time.Duration) echo.MiddlewareFunc {
+	rl := newLimiterFromEnv(limit, window)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			userID, err := ExtractUserID(c)
+			if err != nil {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			}
+			// Only Retry-After is sent; the X-RateLimit headers describe the
+			// per-IP limit.
+			if state := rl.allow(scope + ":user:" + userID); !state.Allowed {
+				return rateLimited(c, state)
 			}
 			return next(c)
 		}
@@ -0,0 +1,152 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// TimeoutConfig holds the request deadlines. Default applies to every
+// request; AI is the longer deadline for routes that wait on Claude, and
+// Export the one for streaming a user's data export, which grows with the
+// account.
+type TimeoutConfig struct {
+	Default time.Duration
+	AI      time.Duration
+	Export  time.Duration
+}
+
+// DefaultTimeoutConfig returns the built-in deadlines.
+func DefaultTimeoutConfig() TimeoutConfig {
+	return TimeoutConfig{Default: 15 * time.Second, AI: 60 * time.Second, Export: 5 * time.Minute}
+}
+
+// TimeoutConfigFromEnv reads REQUEST_TIMEOUT, REQUEST_TIMEOUT_AI and
+// REQUEST_TIMEOUT_EXPORT (Go durations such as 15s) over the defaults.
+func TimeoutConfigFromEnv() (TimeoutConfig, error) {
+	cfg := DefaultTimeoutConfig()
+	for _, o := range []struct {
+		key string
+		dst *time.Duration
+	}{
+		{"REQUEST_TIMEOUT", &cfg.Default},
+		{"REQUEST_TIMEOUT_AI", &cfg.AI},
+		{"REQUEST_TIMEOUT_EXPORT", &cfg.Export},
+	} {
+		v := os.Getenv(o.key)
+		if v == "" {
+			continue
+		}
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return TimeoutConfig{}, fmt.Errorf("%s must be a positive duration, got %q", o.key, v)
+		}
+		*o.dst = d
+	}
+	return cfg, nil
+}
+
+// timeoutParentKey holds the request context from before the default
+// deadline was applied, so RequestTimeout can replace rather than nest it.
+const timeoutParentKey = "timeout_parent_ctx"
+
+// RequestTimeoutMiddleware gives every request a deadline of d. Handlers
+// pass the request context to services, so hitting the deadline cancels
+// their queries and Claude calls; whatever the handler then writes is
+// dropped and the client gets 504 instead. WebSocket upgrades are skipped
+// since the connection outlives the request.
+func RequestTimeoutMiddleware(d time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if c.IsWebSocket() {
+				return next(c)
+			}
+
+			parent := c.Request().Context()
+			c.Set(timeoutParentKey, parent)
+			ctx, cancel := context.WithTimeout(parent, d)
+			defer cancel()
+			c.SetRequest(c.Request().WithContext(ctx))
+
+			res := c.Response()
+			original := res.Writer
+			tw := &timeoutWriter{ResponseWriter: original, c: c}
+			res.Writer = tw
+			err := next(c)
+			res.Writer = original
+
+			if tw.timedOut || (!res.Committed && deadlineExceeded(c)) {
+				res.Committed = false
+				res.Status = http.StatusOK
+				res.Size = 0
+				return c.JSON(http.StatusGatewayTimeout, map[string]string{
+					"error": "request timed out",
+				})
+			}
+			return err
+		}
+	}
+}
+
+// RequestTimeout overrides the default deadline for a route, e.g. for AI
+// endpoints that legitimately take longer. The new deadline is measured
+// from the start of the request, not from when this middleware runs.
+func RequestTimeout(d time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			parent, ok := c.Get(timeoutParentKey).(context.Context)
+			if !ok {
+				parent = c.Request().Context()
+			}
+			ctx, cancel := context.WithTimeout(parent, d)
+			defer cancel()
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
+
+func deadlineExceeded(c echo.Context) bool {
+	return errors.Is(c.Request().Context().Err(), context.DeadlineExceeded)
+}
+
+// timeoutWriter discards a response that is started after the request's
+// deadline has passed. A response already under way is left alone.
+type timeoutWriter struct {
+	http.ResponseWriter
+	c           echo.Context
+	wroteHeader bool
+	timedOut    bool
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	if !w.wroteHeader && deadlineExceeded(w.c) {
+		w.timedOut = true
+	}
+	w.wroteHeader = true
+	if w.timedOut {
+		return
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *timeoutWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.timedOut {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *timeoutWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok && !w.timedOut {
+		f.Flush()
+	}
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestTimeoutConfigFromEnv(t *testing.T) {
+	t.Setenv("REQUEST_TIMEOUT", "")
+	t.Setenv("REQUEST_TIMEOUT_AI", "")
+	t.Setenv("REQUEST_TIMEOUT_EXPORT", "10m")
+	cfg, err := TimeoutConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := DefaultTimeoutConfig()
+	want.Export = 10 * time.Minute
+	if cfg != want {
+		t.Fatalf("config = %+v, want %+v", cfg, want)
+	}
+
+	t.Setenv("REQUEST_TIMEOUT_EXPORT", "-1s")
+	if _, err := TimeoutConfigFromEnv(); err == nil {
+		t.Fatal("negative REQUEST_TIMEOUT_EXPORT accepted")
+	}
+}
+
+// A route override replaces the default deadline instead of nesting inside
+// it, so a long export isn't cut off at the default.
+func TestRequestTimeoutOverridesDefault(t *testing.T) {
+	e := echo.New()
+	e.Use(RequestTimeoutMiddleware(20 * time.Millisecond))
+	e.GET("/export", func(c echo.Context) error {
+		select {
+		case <-time.After(60 * time.Millisecond):
+			return c.String(http.StatusOK, "done")
+		case <-c.Request().Context().Done():
+			return c.Request().Context().Err()
+		}
+	}, RequestTimeout(time.Second))
+	e.GET("/other", func(c echo.Context) error {
+		<-c.Request().Context().Done()
+		return c.Request().Context().Err()
+	})
+
+	for _, tt := range []struct {
+		path string
+		want int
+	}{
+		{"/export", http.StatusOK},
+		{"/other", http.StatusGatewayTimeout},
+	} {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rec.Code != tt.want {
+			t.Errorf("GET %s = %d, want %d", tt.path, rec.Code, tt.want)
+		}
+	}
+}
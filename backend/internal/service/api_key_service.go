@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/yourusername/skillsync/internal/domain"
+	"github.com/yourusername/skillsync/pkg/auth"
+)
+
+var (
+	ErrAPIKeyNotFound  = errors.New("api key not found")
+	ErrTooManyAPIKeys  = errors.New("api key limit reached; revoke an unused key first")
+	ErrInvalidAPIKey   = errors.New("invalid or revoked api key")
+	ErrInvalidKeyScope = errors.New("scope must be read or write")
+)
+
+const (
+	// maxAPIKeysPerUser bounds the active keys one user can hold.
+	maxAPIKeysPerUser = 10
+	// apiKeyUseThrottle bounds how often last_used_at is written per key.
+	apiKeyUseThrottle = time.Minute
+)
+
+// APIKeyService issues and resolves per-user API keys for programmatic
+// access. Keys never carry the admin role: admin routes need a JWT.
+type APIKeyService struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyService(db *gorm.DB) *APIKeyService {
+	return &APIKeyService{db: db}
+}
+
+// ---------------------------------------------------------------------------
+// Issue / List / Revoke
+// ---------------------------------------------------------------------------
+
+// Create issues a new key for userID and returns it with the raw key, which
+// is not stored and cannot be recovered. scope defaults to read.
+func (s *APIKeyService) Create(ctx context.Context, userID, name string, scope domain.APIKeyScope) (*domain.APIKey, string, error) {
+	switch scope {
+	case "":
+		scope = domain.APIKeyRead
+	case domain.APIKeyRead, domain.APIKeyWrite:
+	default:
+		return nil, "", ErrInvalidKeyScope
+	}
+
+	raw, prefix, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		return nil, "", err
+	}
+	key := domain.APIKey{
+		UserID:  userID,
+		Name:    name,
+		Prefix:  prefix,
+		KeyHash: hash,
+		Scope:   scope,
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Lock the owner so concurrent creates can't exceed the limit.
+		var user domain.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").First(&user, "id = ?", userID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrUserNotFound
+			}
+			return fmt.Errorf("failed to fetch user: %w", err)
+		}
+
+		var active int64
+		if err := tx.Model(&domain.APIKey{}).
+			Where("user_id = ? AND revoked_at IS NULL", userID).
+			Count(&active).Error; err != nil {
+			return fmt.Errorf("failed to count api keys: %w", err)
+		}
+		if active >= maxAPIKeysPerUser {
+			return ErrTooManyAPIKeys
+		}
+
+		if err := tx.Create(&key).Error; err != nil {
+			return fmt.Errorf("failed to store api key: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return &key, raw, nil
+}
+
+// List returns userID's active keys, newest first.
+func (s *APIKeyService) List(ctx context.Context, userID string) ([]domain.APIKey, error) {
+	keys := []domain.APIKey{}
+	if err := s.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Order("created_at DESC, id DESC").
+		Find(&keys).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch api keys: %w", err)
+	}
+	return keys, nil
+}
+
+// Revoke invalidates one of userID's keys. Keys of other users and keys
+// already revoked are reported as not found.
+func (s *APIKeyService) Revoke(ctx context.Context, userID string, id uint) error {
+	res := s.db.WithContext(ctx).Model(&domain.APIKey{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", time.Now())
+	if res.Error != nil {
+		return fmt.Errorf("failed to revoke api key: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// Resolve
+// ---------------------------------------------------------------------------
+
+// ResolveAPIKey returns the owner and scope of an active key and records
+// its use. Keys of deleted accounts are invalid. It implements
+// middleware.APIKeyResolver.
+func (s *APIKeyService) ResolveAPIKey(raw string) (string, string, error) {
+	var key domain.APIKey
+	err := s.db.Select("api_keys.id", "api_keys.user_id", "api_keys.scope").
+		Joins("JOIN users ON users.id = api_keys.user_id AND users.deleted_at IS NULL").
+		Where("api_keys.key_hash = ? AND api_keys.revoked_at IS NULL", auth.HashAPIKey(raw)).
+		First(&key).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", "", ErrInvalidAPIKey
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("failed to fetch api key: %w", err)
+	}
+
+	// The condition keeps a busy key from writing on every request.
+	now := time.Now()
+	s.db.Model(&domain.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", key.ID, now.Add(-apiKeyUseThrottle)).
+		Update("last_used_at", now)
+
+	return key.UserID, string(key.Scope), nil
+}
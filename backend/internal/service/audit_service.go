@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+
+	"github.com/yourusername/skillsync/internal/domain"
+)
+
+// AuditEntry is the input DTO for Audit.
+type AuditEntry struct {
+	Action     domain.AuditAction
+	ActorID    string
+	TargetType string
+	TargetID   string
+	IP         string
+	Metadata   map[string]interface{}
+}
+
+// AuditFilter narrows the results of ListAuditLogs. Zero values are ignored.
+type AuditFilter struct {
+	Action   string
+	ActorID  string
+	TargetID string
+	From     time.Time
+	To       time.Time
+}
+
+// AuditService writes and queries the audit_log table. Writing can be turned
+// off with AUDIT_LOG_ENABLED=false.
+type AuditService struct {
+	db      *gorm.DB
+	enabled bool
+}
+
+func NewAuditService(db *gorm.DB) *AuditService {
+	return &AuditService{
+		db:      db,
+		enabled: getEnv("AUDIT_LOG_ENABLED", "true") != "false",
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Audit
+// ---------------------------------------------------------------------------
+
+// Audit records a sensitive action. It is best-effort: a failed insert never
+// fails the caller's action, but is logged at error level with alert=true so
+// it can be picked up by log-based alerting.
+func (s *AuditService) Audit(entry AuditEntry) {
+	if s == nil || !s.enabled {
+		return
+	}
+
+	metadata := domain.JSONB("{}")
+	if len(entry.Metadata) > 0 {
+		if data, err := json.Marshal(entry.Metadata); err == nil {
+			metadata = domain.JSONB(data)
+		}
+	}
+
+	row := domain.AuditLog{
+		Action:     entry.Action,
+		ActorID:    entry.ActorID,
+		TargetType: entry.TargetType,
+		TargetID:   entry.TargetID,
+		IP:         entry.IP,
+		Metadata:   metadata,
+	}
+	if err := s.db.Create(&row).Error; err != nil {
+		log.Error().Err(err).
+			Bool("alert", true).
+			Str("action", string(entry.Action)).
+			Str("actor_id", entry.ActorID).
+			Str("target_id", entry.TargetID).
+			Msg("failed to write audit log")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// ListAuditLogs
+// ---------------------------------------------------------------------------
+
+func (s *AuditService) ListAuditLogs(ctx context.Context, filter AuditFilter, limit, offset int) ([]domain.AuditLog, int64, error) {
+	query := s.db.WithContext(ctx).Model(&domain.AuditLog{})
+
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.ActorID != "" {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.TargetID != "" {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at <= ?", filter.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count audit logs: %w", err)
+	}
+
+	var logs []domain.AuditLog
+	err := query.
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&logs).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch audit logs: %w", err)
+	}
+
+	return logs, total, nil
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+
+	"github.com/yourusername/skillsync/internal/domain"
+)
+
+// BadgeInput is what badge rules are evaluated against.
+type BadgeInput struct {
+	Reputation *domain.UserReputation
+	// AdvancedSkills is the number of skills the user lists at advanced
+	// proficiency.
+	AdvancedSkills int
+}
+
+// BadgeRule awards a badge whenever Eval returns true. Rules are pure so they
+// can be checked in isolation; adding a badge only means appending a rule.
+type BadgeRule struct {
+	Name        string
+	Description string
+	Eval        func(in BadgeInput) bool
+}
+
+// EarnedBadge is one entry of the users.badges JSONB array.
+type EarnedBadge struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// EarnedAt is nil for badges awarded before earn dates were recorded.
+	EarnedAt *time.Time `json:"earned_at"`
+}
+
+// DefaultBadgeRules are the badges the ReputationService awards.
+var DefaultBadgeRules = []BadgeRule{
+	{
+		Name:        "Top Contributor",
+		Description: "Maintained an overall reputation score above 90",
+		Eval:        func(in BadgeInput) bool { return in.Reputation.OverallScore >= 90 },
+	},
+	{
+		Name:        "Code Master",
+		Description: "Achieved a code quality score above 95",
+		Eval:        func(in BadgeInput) bool { return in.Reputation.CodeQualityScore >= 95 },
+	},
+	{
+		Name:        "Session Guru",
+		Description: "Completed over 50 pair-programming sessions",
+		Eval:        func(in BadgeInput) bool { return in.Reputation.CompletedSessions >= 50 },
+	},
+	{
+		Name:        "Networking Pro",
+		Description: "Successfully matched with over 25 developers",
+		Eval:        func(in BadgeInput) bool { return in.Reputation.SuccessfulMatches >= 25 },
+	},
+	{
+		Name:        "Highly Rated",
+		Description: "Maintained a 4.8+ average rating with at least 10 reviews",
+		Eval: func(in BadgeInput) bool {
+			return in.Reputation.AverageRating >= 4.8 && in.Reputation.TotalRatings >= 10
+		},
+	},
+	{
+		Name:        "Polyglot",
+		Description: "Lists five or more skills at advanced proficiency",
+		Eval:        func(in BadgeInput) bool { return in.AdvancedSkills >= 5 },
+	},
+	{
+		Name:        "Mentor",
+		Description: "Kept a helpfulness score of 85+ across at least 20 sessions",
+		Eval: func(in BadgeInput) bool {
+			return in.Reputation.HelpfulnessScore >= 85 && in.Reputation.CompletedSessions >= 20
+		},
+	},
+}
+
+// awardBadges evaluates the service's badge rules and appends newly earned
+// badges to the user's badges JSONB. Badges already earned keep their
+// original EarnedAt and are never revoked. db is the caller's transaction,
+// which holds the lock on the user row.
+func (s *ReputationService) awardBadges(db *gorm.DB, userID string, rep *domain.UserReputation) {
+	var user domain.User
+	// Unscoped: a failed query would abort the caller's transaction, and
+	// CalculateUserReputation also runs for deleted users.
+	if err := db.Unscoped().Select("id", "badges").First(&user, "id = ?", userID).Error; err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Msg("failed to load badges")
+		return
+	}
+
+	earned, err := decodeBadges(user.Badges)
+	if err != nil {
+		// Rewriting the array would lose whatever is stored there.
+		log.Warn().Err(err).Str("user_id", userID).Msg("not awarding badges: stored badges are malformed")
+		return
+	}
+	have := make(map[string]bool, len(earned))
+	for _, b := range earned {
+		have[b.Name] = true
+	}
+
+	var advanced int64
+	db.Model(&domain.UserSkill{}).
+		Where("user_id = ? AND proficiency_level = ?", userID, domain.Advanced).
+		Count(&advanced)
+
+	in := BadgeInput{Reputation: rep, AdvancedSkills: int(advanced)}
+	now := time.Now()
+	added := false
+	for _, rule := range s.badgeRules {
+		if have[rule.Name] || !rule.Eval(in) {
+			continue
+		}
+		earned = append(earned, EarnedBadge{Name: rule.Name, Description: rule.Description, EarnedAt: &now})
+		added = true
+	}
+	if !added {
+		return
+	}
+
+	data, _ := json.Marshal(earned)
+	db.Model(&domain.User{}).Where("id = ?", userID).
+		Update("badges", domain.JSONB(data))
+}
+
+// decodeBadges parses a users.badges array.
+func decodeBadges(raw domain.JSONB) ([]EarnedBadge, error) {
+	var earned []EarnedBadge
+	if len(raw) == 0 {
+		return earned, nil
+	}
+	if err := json.Unmarshal(raw, &earned); err != nil {
+		return nil, fmt.Errorf("failed to decode badges: %w", err)
+	}
+	return earned, nil
+}
+
+// GetUserBadges returns the badges userID has earned, oldest first, with
+// descriptions filled in from the badge rules where the stored entry lacks
+// one.
+func (s *ReputationService) GetUserBadges(ctx context.Context, userID string) ([]EarnedBadge, error) {
+	var user domain.User
+	if err := s.db.WithContext(ctx).Select("id", "badges").First(&user, "id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch user: %w", err)
+	}
+
+	earned, err := decodeBadges(user.Badges)
+	if err != nil {
+		return nil, err
+	}
+	descriptions := make(map[string]string, len(s.badgeRules))
+	for _, rule := range s.badgeRules {
+		descriptions[rule.Name] = rule.Description
+	}
+	badges := make([]EarnedBadge, 0, len(earned))
+	for _, b := range earned {
+		if b.Description == "" {
+			b.Description = descriptions[b.Name]
+		}
+		badges = append(badges, b)
+	}
+	return badges, nil
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/yourusername/skillsync/internal/domain"
+)
+
+func badgeRule(t *testing.T, name string) BadgeRule {
+	t.Helper()
+	for _, r := range DefaultBadgeRules {
+		if r.Name == name {
+			return r
+		}
+	}
+	t.Fatalf("no badge rule %q", name)
+	return BadgeRule{}
+}
+
+func TestDefaultBadgeRules(t *testing.T) {
+	tests := []struct {
+		badge string
+		in    BadgeInput
+		want  bool
+	}{
+		{"Top Contributor", BadgeInput{Reputation: &domain.UserReputation{OverallScore: 90}}, true},
+		{"Top Contributor", BadgeInput{Reputation: &domain.UserReputation{OverallScore: 89.99}}, false},
+		{"Code Master", BadgeInput{Reputation: &domain.UserReputation{CodeQualityScore: 95}}, true},
+		{"Code Master", BadgeInput{Reputation: &domain.UserReputation{CodeQualityScore: 94}}, false},
+		{"Session Guru", BadgeInput{Reputation: &domain.UserReputation{CompletedSessions: 50}}, true},
+		{"Session Guru", BadgeInput{Reputation: &domain.UserReputation{CompletedSessions: 49}}, false},
+		{"Networking Pro", BadgeInput{Reputation: &domain.UserReputation{SuccessfulMatches: 25}}, true},
+		{"Networking Pro", BadgeInput{Reputation: &domain.UserReputation{SuccessfulMatches: 24}}, false},
+		{"Highly Rated", BadgeInput{Reputation: &domain.UserReputation{AverageRating: 4.8, TotalRatings: 10}}, true},
+		{"Highly Rated", BadgeInput{Reputation: &domain.UserReputation{AverageRating: 5, TotalRatings: 9}}, false},
+		{"Highly Rated", BadgeInput{Reputation: &domain.UserReputation{AverageRating: 4.7, TotalRatings: 40}}, false},
+		{"Polyglot", BadgeInput{Reputation: &domain.UserReputation{}, AdvancedSkills: 5}, true},
+		{"Polyglot", BadgeInput{Reputation: &domain.UserReputation{}, AdvancedSkills: 4}, false},
+		{"Mentor", BadgeInput{Reputation: &domain.UserReputation{HelpfulnessScore: 85, CompletedSessions: 20}}, true},
+		{"Mentor", BadgeInput{Reputation: &domain.UserReputation{HelpfulnessScore: 99, CompletedSessions: 19}}, false},
+		{"Mentor", BadgeInput{Reputation: &domain.UserReputation{HelpfulnessScore: 84, CompletedSessions: 60}}, false},
+	}
+	for _, tt := range tests {
+		if got := badgeRule(t, tt.badge).Eval(tt.in); got != tt.want {
+			t.Errorf("%s with %+v = %v, want %v", tt.badge, *tt.in.Reputation, got, tt.want)
+		}
+	}
+}
+
+func TestAwardBadgesKeepsEarnedAt(t *testing.T) {
+	db := testDB(t)
+	s := NewReputationService(db, DefaultReputationWeights())
+	user := createTestUser(t, db)
+
+	earlier := time.Now().Add(-30 * 24 * time.Hour).UTC().Truncate(time.Second)
+	stored, _ := json.Marshal([]EarnedBadge{{Name: "Top Contributor", EarnedAt: &earlier}})
+	if err := db.Model(user).Update("badges", domain.JSONB(stored)).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	rep := &domain.UserReputation{OverallScore: 95, CompletedSessions: 50}
+	s.awardBadges(db, user.ID, rep)
+	s.awardBadges(db, user.ID, rep)
+
+	badges, err := s.GetUserBadges(context.Background(), user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(badges) != 2 {
+		t.Fatalf("got %d badges, want Top Contributor and Session Guru once each: %+v", len(badges), badges)
+	}
+	if badges[0].Name != "Top Contributor" || badges[0].EarnedAt == nil || !badges[0].EarnedAt.Equal(earlier) {
+		t.Fatalf("first badge = %+v, want Top Contributor earned %v", badges[0], earlier)
+	}
+	if badges[0].Description == "" {
+		t.Fatal("description not filled in from the rule")
+	}
+	if badges[1].Name != "Session Guru" || badges[1].EarnedAt == nil || badges[1].EarnedAt.Before(earlier) {
+		t.Fatalf("second badge = %+v", badges[1])
+	}
+}
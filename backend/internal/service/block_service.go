@@ -0,0 +1,122 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/yourusername/skillsync/internal/domain"
+)
+
+var (
+	ErrCannotBlockSelf  = errors.New("you cannot block yourself")
+	ErrCannotReportSelf = errors.New("you cannot report yourself")
+	ErrUserBlocked      = errors.New("match requests between these users are blocked")
+)
+
+// BlockService manages user blocks and abuse reports.
+type BlockService struct {
+	db *gorm.DB
+}
+
+func NewBlockService(db *gorm.DB) *BlockService {
+	return &BlockService{db: db}
+}
+
+// ---------------------------------------------------------------------------
+// Block / Unblock
+// ---------------------------------------------------------------------------
+
+// Block records that blockerID blocked blockedID. Blocking twice is a no-op.
+func (s *BlockService) Block(blockerID, blockedID string) error {
+	if blockerID == blockedID {
+		return ErrCannotBlockSelf
+	}
+	if err := s.requireUser(blockedID); err != nil {
+		return err
+	}
+
+	block := domain.UserBlock{BlockerID: blockerID, BlockedID: blockedID}
+	if err := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&block).Error; err != nil {
+		return fmt.Errorf("failed to block user: %w", err)
+	}
+
+	// Pending requests either way are moot now.
+	s.db.Model(&domain.MatchRequest{}).
+		Where("((sender_id = ? AND receiver_id = ?) OR (sender_id = ? AND receiver_id = ?)) AND status = ?",
+			blockerID, blockedID, blockedID, blockerID, domain.RequestPending).
+		Update("status", domain.RequestRejected)
+
+	return nil
+}
+
+// Unblock removes blockerID's block on blockedID, if any.
+func (s *BlockService) Unblock(blockerID, blockedID string) error {
+	err := s.db.Where("blocker_id = ? AND blocked_id = ?", blockerID, blockedID).
+		Delete(&domain.UserBlock{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to unblock user: %w", err)
+	}
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// Report
+// ---------------------------------------------------------------------------
+
+// Report files an abuse report against reportedID for moderators to review.
+func (s *BlockService) Report(reporterID, reportedID, reason string) (*domain.UserReport, error) {
+	if reporterID == reportedID {
+		return nil, ErrCannotReportSelf
+	}
+	if err := s.requireUser(reportedID); err != nil {
+		return nil, err
+	}
+
+	report := domain.UserReport{
+		ReporterID: reporterID,
+		ReportedID: reportedID,
+		Reason:     reason,
+		Status:     domain.ReportOpen,
+	}
+	if err := s.db.Create(&report).Error; err != nil {
+		return nil, fmt.Errorf("failed to save report: %w", err)
+	}
+	return &report, nil
+}
+
+// ---------------------------------------------------------------------------
+// Internal helpers
+// ---------------------------------------------------------------------------
+
+func (s *BlockService) requireUser(id string) error {
+	var n int64
+	if err := s.db.Model(&domain.User{}).Where("id = ?", id).Count(&n).Error; err != nil {
+		return fmt.Errorf("failed to fetch user: %w", err)
+	}
+	if n == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// blockedUserIDs returns everyone userID has blocked or been blocked by.
+func blockedUserIDs(db *gorm.DB, userID string) []string {
+	var ids []string
+	db.Model(&domain.UserBlock{}).
+		Where("blocker_id = ? OR blocked_id = ?", userID, userID).
+		Select("CASE WHEN blocker_id = ? THEN blocked_id ELSE blocker_id END", userID).
+		Scan(&ids)
+	return ids
+}
+
+// isBlocked reports whether either user has blocked the other.
+func isBlocked(db *gorm.DB, a, b string) bool {
+	var n int64
+	db.Model(&domain.UserBlock{}).
+		Where("(blocker_id = ? AND blocked_id = ?) OR (blocker_id = ? AND blocked_id = ?)", a, b, b, a).
+		Count(&n)
+	return n > 0
+}
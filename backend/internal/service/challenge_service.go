@@ -0,0 +1,60 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/yourusername/skillsync/internal/domain"
+)
+
+var (
+	ErrChallengeNotFound     = errors.New("challenge not found")
+	ErrInvalidChallengeLevel = errors.New("difficulty must be beginner, intermediate or advanced")
+)
+
+// ChallengeService serves the catalog of coding challenges that assessments
+// are scored against.
+type ChallengeService struct {
+	db *gorm.DB
+}
+
+func NewChallengeService(db *gorm.DB) *ChallengeService {
+	return &ChallengeService{db: db}
+}
+
+// ListChallenges returns every challenge, optionally limited to one
+// difficulty, easiest first.
+func (s *ChallengeService) ListChallenges(difficulty string) ([]domain.Challenge, error) {
+	query := s.db.Model(&domain.Challenge{})
+	if difficulty != "" {
+		switch level := domain.ProficiencyLevel(difficulty); level {
+		case domain.Beginner, domain.Intermediate, domain.Advanced:
+			query = query.Where("difficulty = ?", level)
+		default:
+			return nil, ErrInvalidChallengeLevel
+		}
+	}
+
+	var challenges []domain.Challenge
+	if err := query.
+		Order("CASE difficulty WHEN 'beginner' THEN 0 WHEN 'intermediate' THEN 1 ELSE 2 END").
+		Order("title ASC").
+		Find(&challenges).Error; err != nil {
+		return nil, fmt.Errorf("failed to list challenges: %w", err)
+	}
+	return challenges, nil
+}
+
+// GetChallenge returns a single challenge by id.
+func (s *ChallengeService) GetChallenge(id string) (*domain.Challenge, error) {
+	var challenge domain.Challenge
+	if err := s.db.First(&challenge, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrChallengeNotFound
+		}
+		return nil, fmt.Errorf("failed to get challenge: %w", err)
+	}
+	return &challenge, nil
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/yourusername/skillsync/internal/domain"
+)
+
+func TestClaudeModeFromEnv(t *testing.T) {
+	tests := []struct {
+		mode, key, want string
+		wantErr         bool
+	}{
+		{"", "", ClaudeModeMock, false},
+		{"", "sk-test", ClaudeModeLive, false},
+		{"mock", "sk-test", ClaudeModeMock, false},
+		{" LIVE ", "", ClaudeModeLive, false},
+		{"offline", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Setenv("CLAUDE_MODE", tt.mode)
+		t.Setenv("ANTHROPIC_API_KEY", tt.key)
+		got, err := ClaudeModeFromEnv()
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("CLAUDE_MODE=%q key=%q: got %q, %v; want %q", tt.mode, tt.key, got, err, tt.want)
+		}
+	}
+
+	if _, ok := NewClaudeService(ClaudeModeMock, DefaultClaudeModels(), ClaudeMaxTokens{}).(*MockClaudeService); !ok {
+		t.Fatal("mock mode did not select MockClaudeService")
+	}
+}
+
+func TestMockClaudeAnalyzeCodeDeterministic(t *testing.T) {
+	ctx := context.Background()
+	claude := NewMockClaudeService(DefaultClaudeModels())
+	challenge := &domain.Challenge{ID: "c1", Title: "Two Sum"}
+
+	a, err := claude.AnalyzeCode(ctx, "func main() {}", "go", challenge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := claude.AnalyzeCode(ctx, "func main() {}", "go", challenge)
+	if a.Score != b.Score || a.SkillLevel != b.SkillLevel {
+		t.Fatalf("same input scored %v/%s then %v/%s", a.Score, a.SkillLevel, b.Score, b.SkillLevel)
+	}
+	if a.Score < 50 || a.Score > 90 {
+		t.Fatalf("score %v outside 50-90", a.Score)
+	}
+}
+
+// MESSAGE_MODERATION=claude runs its checks through the injected
+// ClaudeService; the mock applies the keyword filter.
+func TestMessageModeratorWithMockClaude(t *testing.T) {
+	t.Setenv("MESSAGE_MODERATION", MessageModerationClaude)
+	t.Setenv("MESSAGE_MODERATION_KEYWORDS", "")
+	m := NewMessageModerator(nil, NewMockClaudeService(DefaultClaudeModels()))
+
+	v, source := m.verdict(context.Background(), "you are an IDIOT")
+	if !v.Flagged || source != MessageModerationClaude {
+		t.Fatalf("abusive message: flagged=%v source=%q", v.Flagged, source)
+	}
+	v, _ = m.verdict(context.Background(), "shall we pair on the parser tomorrow?")
+	if v.Flagged {
+		t.Fatalf("clean message flagged: %+v", v)
+	}
+}
+
+func TestSuggestForUserWithMockClaude(t *testing.T) {
+	db := testDB(t)
+	user := createTestUser(t, db)
+	skill := createTestSkill(t, db)
+	addTestSkill(t, db, user, skill, domain.Advanced, domain.DirectionTeach)
+
+	s := NewProjectSuggestionService(db, NewMockClaudeService(DefaultClaudeModels()))
+	got, err := s.SuggestForUser(context.Background(), user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Level != string(domain.Advanced) || len(got.Projects) == 0 {
+		t.Fatalf("suggestions = %+v", got)
+	}
+	if again, _ := s.SuggestForUser(context.Background(), user.ID); again != got {
+		t.Fatal("second call was not served from the cache")
+	}
+
+	empty := createTestUser(t, db)
+	if _, err := s.SuggestForUser(context.Background(), empty.ID); err != ErrNoSkills {
+		t.Fatalf("user without skills: %v, want %v", err, ErrNoSkills)
+	}
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// ClaudeModels selects the model used by each ClaudeService operation. Each
+// can be overridden with the env var noted on the field.
+type ClaudeModels struct {
+	Analyze    anthropic.Model `json:"analyze"`     // CLAUDE_MODEL_ANALYZE
+	Hint       anthropic.Model `json:"hint"`        // CLAUDE_MODEL_HINT
+	MatchScore anthropic.Model `json:"match_score"` // CLAUDE_MODEL_MATCH_SCORE
+	Projects   anthropic.Model `json:"projects"`    // CLAUDE_MODEL_PROJECTS
+	Insights   anthropic.Model `json:"insights"`    // CLAUDE_MODEL_INSIGHTS
+	Prediction anthropic.Model `json:"prediction"`  // CLAUDE_MODEL_PREDICTION
+	Skills     anthropic.Model `json:"skills"`      // CLAUDE_MODEL_SKILLS
+	Moderation anthropic.Model `json:"moderation"`  // CLAUDE_MODEL_MODERATION
+}
+
+// DefaultClaudeModels uses Sonnet for the long-form operations and Haiku for
+// the quick ones.
+func DefaultClaudeModels() ClaudeModels {
+	return ClaudeModels{
+		Analyze:    anthropic.ModelClaudeSonnet4_5,
+		Hint:       anthropic.ModelClaudeHaiku4_5,
+		MatchScore: anthropic.ModelClaudeHaiku4_5,
+		Projects:   anthropic.ModelClaudeSonnet4_5,
+		Insights:   anthropic.ModelClaudeSonnet4_5,
+		Prediction: anthropic.ModelClaudeHaiku4_5,
+		Skills:     anthropic.ModelClaudeHaiku4_5,
+		Moderation: anthropic.ModelClaudeHaiku4_5,
+	}
+}
+
+// claudeModelPattern accepts model IDs and aliases such as
+// "claude-sonnet-4-5" or "claude-3-5-haiku-20241022".
+var claudeModelPattern = regexp.MustCompile(`^claude-[a-z0-9]+(?:[-.][a-z0-9]+)*$`)
+
+// ClaudeModelsFromEnv starts from DefaultClaudeModels and applies any
+// CLAUDE_MODEL_* overrides, rejecting values that aren't Claude model IDs.
+func ClaudeModelsFromEnv() (ClaudeModels, error) {
+	m := DefaultClaudeModels()
+	overrides := []struct {
+		key string
+		dst *anthropic.Model
+	}{
+		{"CLAUDE_MODEL_ANALYZE", &m.Analyze},
+		{"CLAUDE_MODEL_HINT", &m.Hint},
+		{"CLAUDE_MODEL_MATCH_SCORE", &m.MatchScore},
+		{"CLAUDE_MODEL_PROJECTS", &m.Projects},
+		{"CLAUDE_MODEL_INSIGHTS", &m.Insights},
+		{"CLAUDE_MODEL_PREDICTION", &m.Prediction},
+		{"CLAUDE_MODEL_SKILLS", &m.Skills},
+		{"CLAUDE_MODEL_MODERATION", &m.Moderation},
+	}
+	for _, o := range overrides {
+		v := os.Getenv(o.key)
+		if v == "" {
+			continue
+		}
+		if !claudeModelPattern.MatchString(v) {
+			return m, fmt.Errorf("%s: %q is not a valid Claude model id", o.key, v)
+		}
+		*o.dst = anthropic.Model(v)
+	}
+	return m, nil
+}
+
+// ClaudeMaxTokens is the output token budget of each ClaudeService
+// operation. Each can be overridden with the env var noted on the field.
+type ClaudeMaxTokens struct {
+	Analyze    int64 `json:"analyze"`     // CLAUDE_MAX_TOKENS_ANALYZE
+	Hint       int64 `json:"hint"`        // CLAUDE_MAX_TOKENS_HINT
+	MatchScore int64 `json:"match_score"` // CLAUDE_MAX_TOKENS_MATCH_SCORE
+	Projects   int64 `json:"projects"`    // CLAUDE_MAX_TOKENS_PROJECTS
+	Insights   int64 `json:"insights"`    // CLAUDE_MAX_TOKENS_INSIGHTS
+	Prediction int64 `json:"prediction"`  // CLAUDE_MAX_TOKENS_PREDICTION
+	Skills     int64 `json:"skills"`      // CLAUDE_MAX_TOKENS_SKILLS
+	Moderation int64 `json:"moderation"`  // CLAUDE_MAX_TOKENS_MODERATION
+}
+
+// DefaultClaudeMaxTokens leaves room for the longest JSON each operation
+// returns; project suggestions and pairing insights need the most.
+func DefaultClaudeMaxTokens() ClaudeMaxTokens {
+	return ClaudeMaxTokens{
+		Analyze:    1536,
+		Hint:       256,
+		MatchScore: 256,
+		Projects:   2048,
+		Insights:   1536,
+		Prediction: 512,
+		Skills:     512,
+		Moderation: 256,
+	}
+}
+
+// ClaudeMaxTokensFromEnv starts from DefaultClaudeMaxTokens and applies any
+// CLAUDE_MAX_TOKENS_* overrides, which must be positive integers.
+func ClaudeMaxTokensFromEnv() (ClaudeMaxTokens, error) {
+	t := DefaultClaudeMaxTokens()
+	overrides := []struct {
+		key string
+		dst *int64
+	}{
+		{"CLAUDE_MAX_TOKENS_ANALYZE", &t.Analyze},
+		{"CLAUDE_MAX_TOKENS_HINT", &t.Hint},
+		{"CLAUDE_MAX_TOKENS_MATCH_SCORE", &t.MatchScore},
+		{"CLAUDE_MAX_TOKENS_PROJECTS", &t.Projects},
+		{"CLAUDE_MAX_TOKENS_INSIGHTS", &t.Insights},
+		{"CLAUDE_MAX_TOKENS_PREDICTION", &t.Prediction},
+		{"CLAUDE_MAX_TOKENS_SKILLS", &t.Skills},
+		{"CLAUDE_MAX_TOKENS_MODERATION", &t.Moderation},
+	}
+	for _, o := range overrides {
+		v := os.Getenv(o.key)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n <= 0 {
+			return t, fmt.Errorf("%s: %q is not a positive integer", o.key, v)
+		}
+		*o.dst = n
+	}
+	return t, nil
+}
@@ -3,10 +3,17 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
 	"github.com/rs/zerolog/log"
 
 	"github.com/yourusername/skillsync/internal/domain"
@@ -17,32 +24,81 @@ import (
 // ---------------------------------------------------------------------------
 
 type CodeAnalysisResult struct {
-	Score          int      `json:"score"`
+	Score          float64  `json:"score"`
 	SkillLevel     string   `json:"skill_level"`
 	Strengths      []string `json:"strengths"`
 	Improvements   []string `json:"improvements"`
 	CodeQuality    string   `json:"code_quality"`
-	Readability    int      `json:"readability"`
-	Efficiency     int      `json:"efficiency"`
+	Readability    float64  `json:"readability"`
+	Efficiency     float64  `json:"efficiency"`
 	ErrorHandling  bool     `json:"error_handling"`
 	Recommendation string   `json:"recommendation"`
 }
 
+// UnmarshalJSON accepts score, readability and efficiency as integers, floats
+// or numeric strings, and clamps them to 0-100, 1-10 and 1-10 respectively.
+// Claude is asked for integers but does not always comply.
+func (r *CodeAnalysisResult) UnmarshalJSON(data []byte) error {
+	type alias CodeAnalysisResult
+	aux := struct {
+		*alias
+		Score       json.Number `json:"score"`
+		Readability json.Number `json:"readability"`
+		Efficiency  json.Number `json:"efficiency"`
+	}{alias: (*alias)(r)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	var err error
+	if r.Score, err = clampedNumber(aux.Score, 0, 100); err != nil {
+		return fmt.Errorf("score: %w", err)
+	}
+	if r.Readability, err = clampedNumber(aux.Readability, 1, 10); err != nil {
+		return fmt.Errorf("readability: %w", err)
+	}
+	if r.Efficiency, err = clampedNumber(aux.Efficiency, 1, 10); err != nil {
+		return fmt.Errorf("efficiency: %w", err)
+	}
+	return nil
+}
+
+// clampedNumber parses n and clamps it to [lo, hi]. A missing value becomes lo.
+func clampedNumber(n json.Number, lo, hi float64) (float64, error) {
+	if n == "" {
+		return lo, nil
+	}
+	v, err := n.Float64()
+	if err != nil {
+		return 0, err
+	}
+	return math.Max(lo, math.Min(v, hi)), nil
+}
+
 type ProjectSuggestion struct {
-	Title            string   `json:"title"`
-	Description      string   `json:"description"`
-	SkillsUsed       []string `json:"skills_used"`
-	Difficulty       string   `json:"difficulty"`
-	EstimatedHours   int      `json:"estimated_hours"`
-	LearningOutcomes []string `json:"learning_outcomes"`
+	Title            string                  `json:"title"`
+	Description      string                  `json:"description"`
+	SkillsUsed       []string                `json:"skills_used"`
+	Difficulty       domain.ProficiencyLevel `json:"difficulty"`
+	EstimatedHours   int                     `json:"estimated_hours"`
+	LearningOutcomes []string                `json:"learning_outcomes"`
 }
 
+// PairingRecommendation constrains the recommendation in PairingInsights.
+type PairingRecommendation string
+
+const (
+	RecommendPair     PairingRecommendation = "pair"
+	RecommendConsider PairingRecommendation = "consider"
+	RecommendSkip     PairingRecommendation = "skip"
+)
+
 type PairingInsights struct {
-	OverallReasoning      string   `json:"overall_reasoning"`
-	SkillComplement       string   `json:"skill_complement"`
-	LearningOpportunities []string `json:"learning_opportunities"`
-	CollaborationIdeas    []string `json:"collaboration_ideas"`
-	Recommendation        string   `json:"recommendation"`
+	OverallReasoning      string                `json:"overall_reasoning"`
+	SkillComplement       string                `json:"skill_complement"`
+	LearningOpportunities []string              `json:"learning_opportunities"`
+	CollaborationIdeas    []string              `json:"collaboration_ideas"`
+	Recommendation        PairingRecommendation `json:"recommendation"`
 }
 
 type SuccessPrediction struct {
@@ -57,20 +113,111 @@ type SuccessPrediction struct {
 // Service
 // ---------------------------------------------------------------------------
 
-type ClaudeService struct {
-	client *anthropic.Client
+const (
+	defaultClaudeTimeout     = 30 * time.Second
+	defaultClaudeMaxAttempts = 3
+	claudeBaseBackoff        = 500 * time.Millisecond
+	claudeMaxBackoff         = 8 * time.Second
+	// claudeMaxTokensCeiling caps the larger budget a truncated response is
+	// retried with.
+	claudeMaxTokensCeiling = 8192
+)
+
+// ErrClaudeTruncated is returned when a response still hits its token budget
+// after being retried with a larger one.
+var ErrClaudeTruncated = errors.New("claude response was truncated at the max_tokens limit")
+
+// ClaudeService is the set of AI operations the rest of the app depends on.
+// AnthropicClaudeService calls the API; MockClaudeService returns canned
+// results for local development and CI. Calls stop early when ctx is done,
+// so pass the request context from handlers.
+type ClaudeService interface {
+	Models() ClaudeModels
+	AnalyzeCode(ctx context.Context, code, language string, challenge *domain.Challenge) (*CodeAnalysisResult, error)
+	GenerateHint(ctx context.Context, code, language, problem string) (string, error)
+	CalculateMatchScore(ctx context.Context, user1Skills, user2Skills []string, user1Goals, user2Goals string) (float64, string, error)
+	SuggestProjects(ctx context.Context, skills []string, skillLevel string) ([]*ProjectSuggestion, error)
+	GeneratePairingInsights(ctx context.Context, user1, user2 domain.User, user1Skills, user2Skills []domain.UserSkill) (*PairingInsights, error)
+	PredictSessionSuccess(ctx context.Context, user1Rep, user2Rep domain.UserReputation) (*SuccessPrediction, error)
+	MapDescriptionToSkills(ctx context.Context, description string, catalog []string) ([]SkillRelevance, error)
+	ModerateMessage(ctx context.Context, content string) (*ModerationVerdict, error)
+}
+
+const (
+	ClaudeModeLive = "live"
+	ClaudeModeMock = "mock"
+)
+
+// ClaudeModeFromEnv reads CLAUDE_MODE ("live" or "mock"). When it is unset
+// the mock is used if ANTHROPIC_API_KEY is empty.
+func ClaudeModeFromEnv() (string, error) {
+	switch mode := strings.ToLower(strings.TrimSpace(os.Getenv("CLAUDE_MODE"))); mode {
+	case ClaudeModeLive, ClaudeModeMock:
+		return mode, nil
+	case "":
+		if os.Getenv("ANTHROPIC_API_KEY") == "" {
+			return ClaudeModeMock, nil
+		}
+		return ClaudeModeLive, nil
+	default:
+		return "", fmt.Errorf("CLAUDE_MODE: %q must be %q or %q", mode, ClaudeModeLive, ClaudeModeMock)
+	}
+}
+
+// NewClaudeService returns the implementation selected by mode. maxTokens
+// only applies to the live implementation.
+func NewClaudeService(mode string, models ClaudeModels, maxTokens ClaudeMaxTokens) ClaudeService {
+	if mode == ClaudeModeMock {
+		return NewMockClaudeService(models)
+	}
+	return NewAnthropicClaudeService(models, maxTokens)
+}
+
+// AnthropicClaudeService implements ClaudeService against the Anthropic
+// Messages API.
+type AnthropicClaudeService struct {
+	client      *anthropic.Client
+	models      ClaudeModels
+	maxTokens   ClaudeMaxTokens
+	timeout     time.Duration
+	maxAttempts int
+}
+
+// NewAnthropicClaudeService builds the Claude client using the given
+// per-operation models and token budgets. Per-attempt timeout and retry count are read from
+// CLAUDE_TIMEOUT (e.g. "30s") and CLAUDE_MAX_ATTEMPTS.
+func NewAnthropicClaudeService(models ClaudeModels, maxTokens ClaudeMaxTokens) *AnthropicClaudeService {
+	// Retries are handled by call so the SDK's own retry loop is disabled.
+	client := anthropic.NewClient(option.WithMaxRetries(0)) // reads ANTHROPIC_API_KEY from env
+
+	maxAttempts := getEnvInt("CLAUDE_MAX_ATTEMPTS", defaultClaudeMaxAttempts)
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	return &AnthropicClaudeService{
+		client:      &client,
+		models:      models,
+		maxTokens:   maxTokens,
+		timeout:     getEnvDuration("CLAUDE_TIMEOUT", defaultClaudeTimeout),
+		maxAttempts: maxAttempts,
+	}
 }
 
-func NewClaudeService() *ClaudeService {
-	client := anthropic.NewClient() // reads ANTHROPIC_API_KEY from env
-	return &ClaudeService{client: &client}
+// Models returns the model used for each operation.
+func (s *AnthropicClaudeService) Models() ClaudeModels {
+	return s.models
 }
 
 // ---------------------------------------------------------------------------
 // AnalyzeCode
 // ---------------------------------------------------------------------------
 
-func (s *ClaudeService) AnalyzeCode(code, language string) (*CodeAnalysisResult, error) {
+// AnalyzeCode reviews a submission against criteria for its language (see
+// formatReviewCriteria). When challenge is non-nil the prompt
+// includes its description, expected behavior and test cases, and the score
+// is weighted toward correctness against that problem.
+func (s *AnthropicClaudeService) AnalyzeCode(ctx context.Context, code, language string, challenge *domain.Challenge) (*CodeAnalysisResult, error) {
 	prompt := fmt.Sprintf(`Analyze the following %s code and return a JSON object with exactly these fields:
 {
   "score": <int 0-100>,
@@ -83,19 +230,19 @@ func (s *ClaudeService) AnalyzeCode(code, language string) (*CodeAnalysisResult,
   "error_handling": <bool whether code handles errors properly>,
   "recommendation": "<one paragraph recommendation>"
 }
-
+%s%s
 Return ONLY the JSON object, no other text.
 
 Code:
-%s`, language, code)
+%s`, language, formatReviewCriteria(language), formatChallenge(challenge), code)
 
-	raw, err := s.call(anthropic.ModelClaudeSonnet4_5, prompt, "You are an expert code reviewer. Respond only with valid JSON.", 1024)
+	raw, err := s.call(ctx, s.models.Analyze, prompt, "You are an expert code reviewer. Respond only with valid JSON.", s.maxTokens.Analyze)
 	if err != nil {
 		return nil, fmt.Errorf("AnalyzeCode: %w", err)
 	}
 
 	var result CodeAnalysisResult
-	if err := json.Unmarshal([]byte(raw), &result); err != nil {
+	if err := json.Unmarshal([]byte(extractJSON(raw)), &result); err != nil {
 		return nil, fmt.Errorf("AnalyzeCode: failed to parse response: %w", err)
 	}
 	return &result, nil
@@ -105,7 +252,7 @@ Code:
 // GenerateHint
 // ---------------------------------------------------------------------------
 
-func (s *ClaudeService) GenerateHint(code, language, problem string) (string, error) {
+func (s *AnthropicClaudeService) GenerateHint(ctx context.Context, code, language, problem string) (string, error) {
 	prompt := fmt.Sprintf(`A developer is working on the following problem in %s:
 
 Problem: %s
@@ -116,7 +263,7 @@ Their current code:
 Give a helpful hint that guides them toward the solution WITHOUT giving the answer directly.
 Be encouraging and educational. Keep your hint to 2-3 sentences.`, language, problem, code)
 
-	hint, err := s.call(anthropic.ModelClaudeHaiku4_5, prompt, "You are a supportive coding mentor. Give hints, never full solutions.", 256)
+	hint, err := s.call(ctx, s.models.Hint, prompt, "You are a supportive coding mentor. Give hints, never full solutions.", s.maxTokens.Hint)
 	if err != nil {
 		return "", fmt.Errorf("GenerateHint: %w", err)
 	}
@@ -127,7 +274,7 @@ Be encouraging and educational. Keep your hint to 2-3 sentences.`, language, pro
 // CalculateMatchScore
 // ---------------------------------------------------------------------------
 
-func (s *ClaudeService) CalculateMatchScore(user1Skills, user2Skills []string, user1Goals, user2Goals string) (float64, string, error) {
+func (s *AnthropicClaudeService) CalculateMatchScore(ctx context.Context, user1Skills, user2Skills []string, user1Goals, user2Goals string) (float64, string, error) {
 	prompt := fmt.Sprintf(`Given two developers, calculate how well they would pair for collaborative learning.
 
 User 1 skills: %s
@@ -147,7 +294,7 @@ A high score means they can teach each other effectively.`,
 		strings.Join(user1Skills, ", "), user1Goals,
 		strings.Join(user2Skills, ", "), user2Goals)
 
-	raw, err := s.call(anthropic.ModelClaudeHaiku4_5, prompt, "You are a matching algorithm expert. Respond only with valid JSON.", 256)
+	raw, err := s.call(ctx, s.models.MatchScore, prompt, "You are a matching algorithm expert. Respond only with valid JSON.", s.maxTokens.MatchScore)
 	if err != nil {
 		return 0, "", fmt.Errorf("CalculateMatchScore: %w", err)
 	}
@@ -156,7 +303,7 @@ A high score means they can teach each other effectively.`,
 		Score     float64 `json:"score"`
 		Reasoning string  `json:"reasoning"`
 	}
-	if err := json.Unmarshal([]byte(raw), &result); err != nil {
+	if err := json.Unmarshal([]byte(extractJSON(raw)), &result); err != nil {
 		return 0, "", fmt.Errorf("CalculateMatchScore: failed to parse response: %w", err)
 	}
 	return result.Score, result.Reasoning, nil
@@ -166,7 +313,7 @@ A high score means they can teach each other effectively.`,
 // SuggestProjects
 // ---------------------------------------------------------------------------
 
-func (s *ClaudeService) SuggestProjects(skills []string, skillLevel string) ([]*ProjectSuggestion, error) {
+func (s *AnthropicClaudeService) SuggestProjects(ctx context.Context, skills []string, skillLevel string) ([]*ProjectSuggestion, error) {
 	prompt := fmt.Sprintf(`Suggest exactly 3 collaborative coding projects for a developer with these skills: %s
 Skill level: %s
 
@@ -183,15 +330,23 @@ Return ONLY a JSON array with exactly 3 objects, each having:
 Projects should be practical, interesting, and appropriate for the skill level.`,
 		strings.Join(skills, ", "), skillLevel)
 
-	raw, err := s.call(anthropic.ModelClaudeSonnet4_5, prompt, "You are a senior developer who suggests engaging projects. Respond only with valid JSON.", 1024)
+	raw, err := s.call(ctx, s.models.Projects, prompt, "You are a senior developer who suggests engaging projects. Respond only with valid JSON.", s.maxTokens.Projects)
 	if err != nil {
 		return nil, fmt.Errorf("SuggestProjects: %w", err)
 	}
 
 	var results []*ProjectSuggestion
-	if err := json.Unmarshal([]byte(raw), &results); err != nil {
+	if err := json.Unmarshal([]byte(extractJSON(raw)), &results); err != nil {
 		return nil, fmt.Errorf("SuggestProjects: failed to parse response: %w", err)
 	}
+
+	// Off-spec difficulties fall back to the requested level.
+	fallback := normalizeDifficulty(skillLevel, domain.Intermediate)
+	for _, p := range results {
+		if p != nil {
+			p.Difficulty = normalizeDifficulty(string(p.Difficulty), fallback)
+		}
+	}
 	return results, nil
 }
 
@@ -199,7 +354,8 @@ Projects should be practical, interesting, and appropriate for the skill level.`
 // GeneratePairingInsights
 // ---------------------------------------------------------------------------
 
-func (s *ClaudeService) GeneratePairingInsights(
+func (s *AnthropicClaudeService) GeneratePairingInsights(
+	ctx context.Context,
 	user1, user2 domain.User,
 	user1Skills, user2Skills []domain.UserSkill,
 ) (*PairingInsights, error) {
@@ -229,15 +385,16 @@ Return ONLY a JSON object:
 		user1.FullName, u1s, user1.ReputationScore, user1.TotalSessions,
 		user2.FullName, u2s, user2.ReputationScore, user2.TotalSessions)
 
-	raw, err := s.call(anthropic.ModelClaudeSonnet4_5, prompt, "You are an expert at building effective developer teams. Respond only with valid JSON.", 1024)
+	raw, err := s.call(ctx, s.models.Insights, prompt, "You are an expert at building effective developer teams. Respond only with valid JSON.", s.maxTokens.Insights)
 	if err != nil {
 		return nil, fmt.Errorf("GeneratePairingInsights: %w", err)
 	}
 
 	var result PairingInsights
-	if err := json.Unmarshal([]byte(raw), &result); err != nil {
+	if err := json.Unmarshal([]byte(extractJSON(raw)), &result); err != nil {
 		return nil, fmt.Errorf("GeneratePairingInsights: failed to parse response: %w", err)
 	}
+	result.Recommendation = normalizeRecommendation(string(result.Recommendation))
 	return &result, nil
 }
 
@@ -245,7 +402,7 @@ Return ONLY a JSON object:
 // PredictSessionSuccess
 // ---------------------------------------------------------------------------
 
-func (s *ClaudeService) PredictSessionSuccess(user1Rep, user2Rep domain.UserReputation) (*SuccessPrediction, error) {
+func (s *AnthropicClaudeService) PredictSessionSuccess(ctx context.Context, user1Rep, user2Rep domain.UserReputation) (*SuccessPrediction, error) {
 	prompt := fmt.Sprintf(`Predict the success of a pair-programming session between two developers based on their reputation data.
 
 Developer 1 reputation:
@@ -277,25 +434,151 @@ Return ONLY a JSON object:
 		user2Rep.HelpfulnessScore, user2Rep.ReliabilityScore,
 		user2Rep.AverageRating, user2Rep.CompletedSessions, user2Rep.SuccessfulMatches)
 
-	raw, err := s.call(anthropic.ModelClaudeHaiku4_5, prompt, "You are a data-driven session-success predictor. Respond only with valid JSON.", 512)
+	raw, err := s.call(ctx, s.models.Prediction, prompt, "You are a data-driven session-success predictor. Respond only with valid JSON.", s.maxTokens.Prediction)
 	if err != nil {
 		return nil, fmt.Errorf("PredictSessionSuccess: %w", err)
 	}
 
 	var result SuccessPrediction
-	if err := json.Unmarshal([]byte(raw), &result); err != nil {
+	if err := json.Unmarshal([]byte(extractJSON(raw)), &result); err != nil {
 		return nil, fmt.Errorf("PredictSessionSuccess: failed to parse response: %w", err)
 	}
 	return &result, nil
 }
 
+// ---------------------------------------------------------------------------
+// MapDescriptionToSkills
+// ---------------------------------------------------------------------------
+
+// SkillRelevance is one catalog skill picked by MapDescriptionToSkills.
+type SkillRelevance struct {
+	Skill     string  `json:"skill"`
+	Relevance float64 `json:"relevance"`
+}
+
+// MapDescriptionToSkills asks Claude which catalog skills fit a free-text
+// description of what a developer does. Only names from catalog are returned.
+func (s *AnthropicClaudeService) MapDescriptionToSkills(ctx context.Context, description string, catalog []string) ([]SkillRelevance, error) {
+	prompt := fmt.Sprintf(`A developer describes what they do as:
+
+"%s"
+
+From this skill catalog ONLY: %s
+
+Pick up to 8 skills they most likely use or should start with, most relevant first.
+Return ONLY a JSON array of objects:
+[
+  {"skill": "<exact catalog name>", "relevance": <float 0-100>}
+]`, description, strings.Join(catalog, ", "))
+
+	raw, err := s.call(ctx, s.models.Skills, prompt, "You map developer descriptions to a fixed skill catalog. Respond only with valid JSON.", s.maxTokens.Skills)
+	if err != nil {
+		return nil, fmt.Errorf("MapDescriptionToSkills: %w", err)
+	}
+
+	var results []SkillRelevance
+	if err := json.Unmarshal([]byte(extractJSON(raw)), &results); err != nil {
+		return nil, fmt.Errorf("MapDescriptionToSkills: failed to parse response: %w", err)
+	}
+
+	// Drop anything the model made up and canonicalize the casing.
+	byLower := make(map[string]string, len(catalog))
+	for _, name := range catalog {
+		byLower[strings.ToLower(name)] = name
+	}
+	valid := make([]SkillRelevance, 0, len(results))
+	for _, r := range results {
+		name, ok := byLower[strings.ToLower(strings.TrimSpace(r.Skill))]
+		if !ok {
+			continue
+		}
+		r.Skill = name
+		r.Relevance = math.Max(0, math.Min(r.Relevance, 100))
+		valid = append(valid, r)
+	}
+	return valid, nil
+}
+
+// ---------------------------------------------------------------------------
+// ModerateMessage
+// ---------------------------------------------------------------------------
+
+// ModerationVerdict says whether a chat message looks abusive and why.
+type ModerationVerdict struct {
+	Flagged  bool   `json:"flagged"`
+	Category string `json:"category"`
+	Reason   string `json:"reason"`
+}
+
+// ModerateMessage asks Claude whether a chat message between two pairing
+// partners is abusive. Blunt technical criticism is not.
+func (s *AnthropicClaudeService) ModerateMessage(ctx context.Context, content string) (*ModerationVerdict, error) {
+	prompt := fmt.Sprintf(`A developer sent this chat message to their pair-programming partner:
+
+"%s"
+
+Is it abusive? Flag harassment, hate speech, threats, sexual content and
+personal insults. Do NOT flag blunt code review, technical disagreement or
+mild profanity that isn't aimed at the person.
+
+Return ONLY a JSON object:
+{
+  "flagged": <true|false>,
+  "category": "<harassment|hate|threat|sexual|insult|none>",
+  "reason": "<one short sentence>"
+}`, content)
+
+	raw, err := s.call(ctx, s.models.Moderation, prompt, "You are a content moderator for a developer community. Respond only with valid JSON.", s.maxTokens.Moderation)
+	if err != nil {
+		return nil, fmt.Errorf("ModerateMessage: %w", err)
+	}
+
+	var verdict ModerationVerdict
+	if err := json.Unmarshal([]byte(extractJSON(raw)), &verdict); err != nil {
+		return nil, fmt.Errorf("ModerateMessage: failed to parse response: %w", err)
+	}
+	return &verdict, nil
+}
+
 // ---------------------------------------------------------------------------
 // Internal helpers
 // ---------------------------------------------------------------------------
 
-// call makes a single Messages API request and returns the text content.
-func (s *ClaudeService) call(model anthropic.Model, userPrompt, systemPrompt string, maxTokens int64) (string, error) {
-	resp, err := s.client.Messages.New(context.Background(), anthropic.MessageNewParams{
+// call makes a Messages API request and returns the text content. A response
+// cut off at maxTokens is retried once with double the budget (capped at
+// claudeMaxTokensCeiling); if that is truncated too, ErrClaudeTruncated is
+// returned rather than partial output that would fail to parse.
+func (s *AnthropicClaudeService) call(ctx context.Context, model anthropic.Model, userPrompt, systemPrompt string, maxTokens int64) (string, error) {
+	resp, err := s.send(ctx, model, userPrompt, systemPrompt, maxTokens)
+	if err != nil {
+		return "", err
+	}
+	if resp.StopReason == anthropic.StopReasonMaxTokens {
+		larger := min(maxTokens*2, claudeMaxTokensCeiling)
+		log.Warn().
+			Str("model", string(model)).
+			Int64("max_tokens", maxTokens).
+			Int64("retry_max_tokens", larger).
+			Msg("claude response truncated")
+		if larger <= maxTokens {
+			return "", ErrClaudeTruncated
+		}
+		if resp, err = s.send(ctx, model, userPrompt, systemPrompt, larger); err != nil {
+			return "", err
+		}
+		if resp.StopReason == anthropic.StopReasonMaxTokens {
+			log.Error().Str("model", string(model)).Int64("max_tokens", larger).Msg("claude response truncated after retry")
+			return "", ErrClaudeTruncated
+		}
+	}
+	return extractText(resp), nil
+}
+
+// send makes one Messages API request. Each attempt is bounded by s.timeout;
+// overloaded, rate-limited and server errors are retried with exponential
+// backoff up to s.maxAttempts. Once ctx is done no further attempt is made.
+func (s *AnthropicClaudeService) send(ctx context.Context, model anthropic.Model, userPrompt, systemPrompt string, maxTokens int64) (*anthropic.Message, error) {
+	params := anthropic.MessageNewParams{
 		Model:     model,
 		MaxTokens: maxTokens,
 		System: []anthropic.TextBlockParam{
@@ -305,13 +588,75 @@ func (s *ClaudeService) call(model anthropic.Model, userPrompt, systemPrompt str
 			anthropic.NewUserMessage(anthropic.NewTextBlock(userPrompt)),
 		},
 		Temperature: anthropic.Float(0.3),
-	})
-	if err != nil {
-		log.Error().Err(err).Str("model", string(model)).Msg("claude api call failed")
-		return "", fmt.Errorf("claude api error: %w", err)
 	}
 
-	return extractText(resp), nil
+	var lastErr error
+	for attempt := 1; attempt <= s.maxAttempts; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, s.timeout)
+		resp, err := s.client.Messages.New(attemptCtx, params)
+		cancel()
+		if err == nil {
+			log.Debug().
+				Str("model", string(model)).
+				Str("stop_reason", string(resp.StopReason)).
+				Int64("output_tokens", resp.Usage.OutputTokens).
+				Msg("claude api call complete")
+			return resp, nil
+		}
+		lastErr = err
+
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("claude api call abandoned: %w", ctx.Err())
+		}
+		if !isRetryableClaudeError(err) || attempt == s.maxAttempts {
+			break
+		}
+
+		wait := claudeBackoff(attempt)
+		log.Warn().Err(err).
+			Str("model", string(model)).
+			Int("attempt", attempt).
+			Dur("retry_in", wait).
+			Msg("claude api call failed, retrying")
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, fmt.Errorf("claude api call abandoned: %w", ctx.Err())
+		}
+	}
+
+	log.Error().Err(lastErr).Str("model", string(model)).Msg("claude api call failed")
+	return nil, fmt.Errorf("claude api error: %w", lastErr)
+}
+
+// isRetryableClaudeError reports whether err is transient: a per-attempt
+// timeout, or a 429 / 500 / 529 (overloaded) response. Everything else, such
+// as bad requests and auth failures, fails fast.
+func isRetryableClaudeError(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var apiErr *anthropic.Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, 529:
+		return true
+	}
+	return false
+}
+
+// claudeBackoff returns the delay before the next attempt: exponential in the
+// attempt number, capped at claudeMaxBackoff, with jitter over its upper half.
+func claudeBackoff(attempt int) time.Duration {
+	d := claudeBaseBackoff << (attempt - 1)
+	if d <= 0 || d > claudeMaxBackoff {
+		d = claudeMaxBackoff
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
 }
 
 // extractText pulls the concatenated text out of a Message response.
@@ -325,6 +670,109 @@ func extractText(msg *anthropic.Message) string {
 	return strings.TrimSpace(b.String())
 }
 
+// extractJSON isolates the JSON value in a model response. It drops markdown
+// code fences and any prose before the first '{' or '[' and after its matching
+// closing bracket. If no balanced value is found the trimmed input is returned
+// so the caller's json.Unmarshal reports the error.
+func extractJSON(raw string) string {
+	text := strings.TrimSpace(raw)
+
+	if strings.HasPrefix(text, "```") {
+		text = strings.TrimPrefix(text, "```")
+		if nl := strings.IndexByte(text, '\n'); nl >= 0 {
+			text = text[nl+1:] // drop the language tag line, e.g. ```json
+		}
+		if end := strings.LastIndex(text, "```"); end >= 0 {
+			text = text[:end]
+		}
+		text = strings.TrimSpace(text)
+	}
+
+	start := strings.IndexAny(text, "{[")
+	if start < 0 {
+		return text
+	}
+
+	depth := 0
+	inString := false
+	escaped := false
+	for i := start; i < len(text); i++ {
+		ch := text[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case ch == '\\':
+				escaped = true
+			case ch == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch ch {
+		case '"':
+			inString = true
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+			if depth == 0 {
+				return text[start : i+1]
+			}
+		}
+	}
+	return text[start:]
+}
+
+// normalizeDifficulty lowercases a model-supplied difficulty and maps it onto
+// a ProficiencyLevel, returning fallback for anything off-spec.
+func normalizeDifficulty(raw string, fallback domain.ProficiencyLevel) domain.ProficiencyLevel {
+	switch level := domain.ProficiencyLevel(strings.ToLower(strings.TrimSpace(raw))); level {
+	case domain.Beginner, domain.Intermediate, domain.Advanced:
+		return level
+	}
+	return fallback
+}
+
+// normalizeRecommendation lowercases a model-supplied recommendation and maps
+// it onto pair / consider / skip. Off-spec values become "consider".
+func normalizeRecommendation(raw string) PairingRecommendation {
+	switch rec := PairingRecommendation(strings.Trim(strings.ToLower(strings.TrimSpace(raw)), ".!")); rec {
+	case RecommendPair, RecommendConsider, RecommendSkip:
+		return rec
+	}
+	return RecommendConsider
+}
+
+// formatChallenge renders the challenge section of the AnalyzeCode prompt,
+// or nothing when the submission isn't tied to a known challenge.
+func formatChallenge(c *domain.Challenge) string {
+	if c == nil {
+		return ""
+	}
+	tests := strings.TrimSpace(string(c.TestCases))
+	if tests == "" || tests == "[]" {
+		tests = "none provided"
+	}
+	return fmt.Sprintf(`
+The code is a submission for the %s challenge %q.
+
+Problem:
+%s
+
+Expected behavior:
+%s
+
+Test cases (JSON):
+%s
+
+Base the score mainly on whether the code solves this problem correctly,
+including the test cases; code quality is secondary. An incorrect solution
+must not score above 50.
+`, c.Difficulty, c.Title, c.Prompt, c.ExpectedBehavior, tests)
+}
+
 // formatSkills turns a slice of UserSkill into a readable string.
 func formatSkills(skills []domain.UserSkill) string {
 	if len(skills) == 0 {
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestExtractJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{"bare", `{"score": 80}`, `{"score": 80}`},
+		{"fenced", "```json\n{\"score\": 80}\n```", `{"score": 80}`},
+		{"fenced without tag", "```\n[1, 2]\n```", `[1, 2]`},
+		{"prefixed", `Here is the analysis: {"score": 80}`, `{"score": 80}`},
+		{"trailing text", `{"score": 80} Let me know if you need more.`, `{"score": 80}`},
+		{"prose around fence", "Sure!\n```json\n{\"a\": [1]}\n```\nHope that helps.", `{"a": [1]}`},
+		{"brackets in strings", `Result: {"note": "use } and ] carefully", "x": {"y": "\"}"}} done`, `{"note": "use } and ] carefully", "x": {"y": "\"}"}}`},
+		{"array", `The projects: [{"title": "CLI"}, {"title": "Chat"}].`, `[{"title": "CLI"}, {"title": "Chat"}]`},
+		{"no json", `  I can't help with that.  `, `I can't help with that.`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := extractJSON(tt.raw)
+			if got != tt.want {
+				t.Fatalf("extractJSON(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+			if tt.name != "no json" && !json.Valid([]byte(got)) {
+				t.Fatalf("extracted %q is not valid JSON", got)
+			}
+		})
+	}
+}
+
+func TestCodeAnalysisResultScores(t *testing.T) {
+	tests := []struct {
+		name                           string
+		raw                            string
+		score, readability, efficiency float64
+		wantErr                        bool
+	}{
+		{"integers", `{"score": 85, "readability": 7, "efficiency": 8}`, 85, 7, 8, false},
+		{"floats", `{"score": 85.5, "readability": 7.5, "efficiency": 6.25}`, 85.5, 7.5, 6.25, false},
+		{"strings", `{"score": "72", "readability": "6", "efficiency": "9.5"}`, 72, 6, 9.5, false},
+		{"clamped", `{"score": 130, "readability": 0, "efficiency": 11}`, 100, 1, 10, false},
+		{"negative", `{"score": -4.5, "readability": 3, "efficiency": 3}`, 0, 3, 3, false},
+		{"missing", `{"skill_level": "beginner"}`, 0, 1, 1, false},
+		{"not a number", `{"score": "high"}`, 0, 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r CodeAnalysisResult
+			err := json.Unmarshal([]byte(tt.raw), &r)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %+v, want an error", r)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.Score != tt.score || r.Readability != tt.readability || r.Efficiency != tt.efficiency {
+				t.Fatalf("got score=%v readability=%v efficiency=%v, want %v/%v/%v",
+					r.Score, r.Readability, r.Efficiency, tt.score, tt.readability, tt.efficiency)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+)
+
+// genericReviewCriteria is used for languages without their own entry in
+// reviewCriteria.
+const genericReviewCriteria = `- Correctness and handling of edge cases
+- Clear naming and structure
+- Appropriate error handling for the language
+- Reasonable time and space complexity`
+
+// reviewCriteria lists what AnalyzeCode asks Claude to weigh for each
+// language, keyed by canonical language name (see canonicalLanguage).
+var reviewCriteria = map[string]string{
+	"go": `- Errors are checked, wrapped with context (fmt.Errorf with %w) and not silently discarded
+- Goroutines have a clear exit path; no leaks from unbuffered sends or missing context cancellation
+- Shared state is guarded (mutexes, channels) and free of data races
+- defer is used for cleanup such as closing files, bodies and unlocking
+- Idiomatic style: small interfaces, early returns, gofmt formatting`,
+	"python": `- Pythonic constructs: comprehensions, context managers, unpacking, enumerate/zip
+- Specific exceptions are caught rather than bare except clauses
+- No mutable default arguments or shadowed builtins
+- PEP 8 naming and layout; type hints where they aid clarity
+- Appropriate use of the standard library instead of reimplementing it`,
+	"javascript": `- Promises and async/await are handled; no unhandled rejections or floating promises
+- const/let instead of var, and strict equality (===)
+- No accidental globals, prototype mutation or implicit type coercion bugs
+- Avoids blocking the event loop with heavy synchronous work
+- Clear module boundaries and pure functions where practical`,
+	"typescript": `- Types are precise: no needless any, sound use of unions, generics and narrowing
+- Promises and async/await are handled; no floating promises
+- Null and undefined are handled explicitly (strictNullChecks-friendly)
+- const/let and strict equality; no implicit type coercion bugs
+- Interfaces and types model the domain clearly`,
+	"java": `- Resources are closed with try-with-resources; exceptions are not swallowed
+- Checked vs unchecked exceptions are used appropriately
+- Collections and generics are used with correct types; no raw types
+- Immutability and encapsulation (final fields, private state) where sensible
+- equals/hashCode consistency and thread safety of shared state`,
+	"rust": `- Errors propagate with Result and ?; no unwrap/expect on recoverable failures
+- Ownership and borrowing are idiomatic, without needless clone or Rc<RefCell<_>>
+- unsafe is absent or justified and minimal
+- Iterators, pattern matching and enums are used idiomatically
+- Clippy-clean style`,
+	"cpp": `- RAII manages resources; no raw new/delete or leaks
+- Smart pointers and references are used correctly; no dangling references
+- const-correctness and move semantics where they matter
+- No undefined behavior: bounds, integer overflow, uninitialized values
+- Standard library algorithms and containers instead of hand-rolled ones`,
+	"csharp": `- IDisposable resources are disposed with using
+- async/await is used correctly: no async void, .Result or .Wait deadlocks
+- Exceptions are specific and not swallowed
+- LINQ and properties are used idiomatically without hidden performance costs
+- Nullable reference types are respected`,
+	"ruby": `- Idiomatic Ruby: blocks, Enumerable methods, guard clauses
+- Exceptions are rescued specifically, not with bare rescue
+- No monkey patching of core classes
+- Clear method names following Ruby conventions (predicates end in ?, bang methods)
+- Small methods and objects with single responsibilities`,
+}
+
+// languageAliases maps common spellings onto reviewCriteria keys.
+var languageAliases = map[string]string{
+	"golang":  "go",
+	"py":      "python",
+	"python3": "python",
+	"js":      "javascript",
+	"node":    "javascript",
+	"nodejs":  "javascript",
+	"ts":      "typescript",
+	"c++":     "cpp",
+	"cxx":     "cpp",
+	"c#":      "csharp",
+	"cs":      "csharp",
+	"rb":      "ruby",
+	"rs":      "rust",
+}
+
+// canonicalLanguage lowercases language and resolves common aliases.
+func canonicalLanguage(language string) string {
+	l := strings.ToLower(strings.TrimSpace(language))
+	if alias, ok := languageAliases[l]; ok {
+		return alias
+	}
+	return l
+}
+
+// formatReviewCriteria renders the review criteria section of the
+// AnalyzeCode prompt, falling back to generic criteria for languages without
+// their own.
+func formatReviewCriteria(language string) string {
+	criteria, ok := reviewCriteria[canonicalLanguage(language)]
+	if !ok {
+		criteria = genericReviewCriteria
+	}
+	return fmt.Sprintf(`
+Judge the code as an experienced %s developer would. Weigh in particular:
+%s
+Reflect these in "strengths", "improvements", "error_handling" and the score.
+`, language, criteria)
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/yourusername/skillsync/internal/domain"
+	"github.com/yourusername/skillsync/pkg/database"
+)
+
+var (
+	testDBOnce sync.Once
+	testDBConn *gorm.DB
+	testDBErr  error
+)
+
+// testDB connects to TEST_DATABASE_URL and applies the backend migrations
+// once per run. Tests that need PostgreSQL are skipped when it isn't set;
+// the database must already have the base schema
+// (scripts/setup-database.sh).
+func testDB(t testing.TB) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	testDBOnce.Do(func() {
+		testDBConn, testDBErr = gorm.Open(postgres.Open(dsn), &gorm.Config{
+			Logger:         logger.Default.LogMode(logger.Silent),
+			TranslateError: true,
+		})
+		if testDBErr == nil {
+			testDBErr = database.MigrateDB(testDBConn)
+		}
+	})
+	if testDBErr != nil {
+		t.Fatalf("test database: %v", testDBErr)
+	}
+	return testDBConn
+}
+
+// testSuffix returns a random hex string for unique test data.
+func testSuffix(t testing.TB) string {
+	t.Helper()
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		t.Fatal(err)
+	}
+	return hex.EncodeToString(buf)
+}
+
+// createTestUser inserts a user with a unique email and username, removed
+// again when the test ends.
+func createTestUser(t testing.TB, db *gorm.DB) *domain.User {
+	t.Helper()
+	suffix := testSuffix(t)
+	user := domain.User{
+		Email:    "test-" + suffix + "@example.test",
+		Username: "test-" + suffix,
+		FullName: "Test " + suffix,
+		Bio:      "Test user",
+	}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Unscoped().Delete(&domain.User{}, "id = ?", user.ID)
+	})
+	return &user
+}
+
+// createTestMatch inserts an active match between a and b.
+func createTestMatch(t testing.TB, db *gorm.DB, a, b *domain.User) *domain.Match {
+	t.Helper()
+	match := domain.Match{User1ID: a.ID, User2ID: b.ID, MatchScore: 75, Status: domain.MatchActive}
+	if err := db.Create(&match).Error; err != nil {
+		t.Fatalf("create match: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Delete(&domain.Match{}, match.ID)
+	})
+	return &match
+}
+
+// createTestSkill inserts a language skill with a unique name.
+func createTestSkill(t testing.TB, db *gorm.DB) *domain.Skill {
+	t.Helper()
+	skill := domain.Skill{Name: "test-skill-" + testSuffix(t), Category: domain.CategoryLanguage}
+	if err := db.Create(&skill).Error; err != nil {
+		t.Fatalf("create skill: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Delete(&domain.Skill{}, skill.ID)
+	})
+	return &skill
+}
+
+// addTestSkill gives user skill at level in direction.
+func addTestSkill(t testing.TB, db *gorm.DB, user *domain.User, skill *domain.Skill, level domain.ProficiencyLevel, direction domain.SkillDirection) {
+	t.Helper()
+	us := domain.UserSkill{UserID: user.ID, SkillID: skill.ID, ProficiencyLevel: level, Direction: direction}
+	if err := db.Create(&us).Error; err != nil {
+		t.Fatalf("add skill: %v", err)
+	}
+}
+
+// createTestSession inserts a finished hour-long coding session in match.
+func createTestSession(t testing.TB, db *gorm.DB, match *domain.Match) *domain.CodingSession {
+	t.Helper()
+	ended := time.Now().Add(-time.Hour)
+	session := domain.CodingSession{
+		MatchID:         match.ID,
+		StartedAt:       ended.Add(-time.Hour),
+		EndedAt:         &ended,
+		DurationMinutes: 60,
+	}
+	if err := db.Create(&session).Error; err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Where("session_id = ?", session.ID).Delete(&domain.Rating{})
+		db.Delete(&domain.CodingSession{}, session.ID)
+	})
+	return &session
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/yourusername/skillsync/internal/domain"
+)
+
+const (
+	defaultDigestInterval     = 24 * time.Hour
+	defaultDigestTopN         = 5
+	defaultDigestActiveWithin = 14 * 24 * time.Hour
+)
+
+// DigestWorker periodically stores each user's top match suggestions in
+// match_digests so GET /api/matches/digest can serve a stable daily list
+// without recomputing scores and AI insights on every page load.
+//
+// DIGEST_INTERVAL sets how often it runs (default 24h) and DIGEST_TOP_N how
+// many suggestions are kept per user (default 5). Only users active within
+// DIGEST_ACTIVE_WITHIN (default 336h) get one. DIGEST_INTERVAL=off disables
+// the worker.
+type DigestWorker struct {
+	db           *gorm.DB
+	matches      *MatchService
+	interval     time.Duration
+	topN         int
+	activeWithin time.Duration
+	enabled      bool
+}
+
+func NewDigestWorker(db *gorm.DB, matches *MatchService) *DigestWorker {
+	topN := getEnvInt("DIGEST_TOP_N", defaultDigestTopN)
+	if topN <= 0 || topN > 50 {
+		topN = defaultDigestTopN
+	}
+	return &DigestWorker{
+		db:           db,
+		matches:      matches,
+		interval:     getEnvDuration("DIGEST_INTERVAL", defaultDigestInterval),
+		topN:         topN,
+		activeWithin: getEnvDuration("DIGEST_ACTIVE_WITHIN", defaultDigestActiveWithin),
+		enabled:      getEnv("DIGEST_INTERVAL", "") != "off",
+	}
+}
+
+// Run generates digests once at startup and then on every tick until ctx is
+// cancelled. Call as a goroutine: go worker.Run(ctx)
+func (w *DigestWorker) Run(ctx context.Context) {
+	if !w.enabled {
+		log.Info().Msg("match digest worker disabled")
+		return
+	}
+
+	log.Info().Dur("interval", w.interval).Int("top_n", w.topN).Msg("match digest worker started")
+
+	w.RunOnce(ctx)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce builds today's digest for every recently active user who has
+// listed at least one skill and hasn't had a digest within the interval. A
+// digest's created_at records when it was last generated, so a restart
+// doesn't rebuild digests that are still current. Users are processed one at
+// a time to keep the load on Claude predictable.
+func (w *DigestWorker) RunOnce(ctx context.Context) {
+	now := time.Now()
+	today := now.UTC().Truncate(24 * time.Hour)
+
+	var userIDs []string
+	err := w.db.Model(&domain.User{}).
+		Where("users.last_active_at >= ?", now.Add(-w.activeWithin)).
+		Where("EXISTS (SELECT 1 FROM user_skills WHERE user_skills.user_id = users.id)").
+		Where("NOT EXISTS (SELECT 1 FROM match_digests WHERE match_digests.user_id = users.id AND (match_digests.digest_date = ? OR match_digests.created_at > ?))",
+			today, now.Add(-w.interval)).
+		Pluck("id", &userIDs).Error
+	if err != nil {
+		log.Error().Err(err).Msg("match digest: failed to list users")
+		return
+	}
+
+	var built, failed int
+	for _, userID := range userIDs {
+		if ctx.Err() != nil {
+			break
+		}
+
+		suggestions, err := w.matches.FindMatches(ctx, userID, w.topN)
+		if errors.Is(err, ErrProfileIncomplete) {
+			continue
+		}
+		if err != nil {
+			failed++
+			log.Warn().Err(err).Str("user_id", userID).Msg("match digest: FindMatches failed")
+			continue
+		}
+		data, _ := json.Marshal(suggestions)
+
+		digest := domain.MatchDigest{
+			UserID:      userID,
+			DigestDate:  today,
+			Suggestions: domain.JSONB(data),
+		}
+		err = w.db.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "digest_date"}},
+			DoUpdates: clause.AssignmentColumns([]string{"suggestions", "created_at"}),
+		}).Create(&digest).Error
+		if err != nil {
+			failed++
+			log.Warn().Err(err).Str("user_id", userID).Msg("match digest: failed to store digest")
+			continue
+		}
+		built++
+	}
+
+	log.Info().Int("built", built).Int("failed", failed).Msg("match digest run completed")
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yourusername/skillsync/internal/domain"
+)
+
+func TestDigestRunOnceSkipsInactiveAndRecentlyDigested(t *testing.T) {
+	db := testDB(t)
+	t.Setenv("DIGEST_INTERVAL", "24h")
+	t.Setenv("DIGEST_ACTIVE_WITHIN", "72h")
+	w := NewDigestWorker(db, NewMatchService(db, nil, DefaultScoringConfig()))
+
+	skill := createTestSkill(t, db)
+	now := time.Now()
+	user := func(lastActive *time.Time) *domain.User {
+		u := createTestUser(t, db)
+		addTestSkill(t, db, u, skill, domain.Intermediate, domain.DirectionBoth)
+		db.Model(u).Update("last_active_at", lastActive)
+		t.Cleanup(func() { db.Where("user_id = ?", u.ID).Delete(&domain.MatchDigest{}) })
+		return u
+	}
+	recent := now.Add(-time.Hour)
+	stale := now.Add(-30 * 24 * time.Hour)
+
+	active := user(&recent)
+	inactive := user(&stale)
+	never := user(nil)
+
+	// Digested two hours ago under yesterday's date, as when a restart
+	// follows a run just before midnight: still within the interval.
+	digested := user(&recent)
+	yesterday := now.UTC().Truncate(24 * time.Hour).Add(-24 * time.Hour)
+	prior := domain.MatchDigest{UserID: digested.ID, DigestDate: yesterday, Suggestions: domain.JSONB("[]")}
+	if err := db.Create(&prior).Error; err != nil {
+		t.Fatal(err)
+	}
+	db.Model(&prior).Update("created_at", now.Add(-2*time.Hour))
+
+	w.RunOnce(context.Background())
+
+	count := func(u *domain.User) int64 {
+		var n int64
+		db.Model(&domain.MatchDigest{}).Where("user_id = ?", u.ID).Count(&n)
+		return n
+	}
+	if count(active) != 1 {
+		t.Error("active user got no digest")
+	}
+	if count(inactive) != 0 || count(never) != 0 {
+		t.Error("inactive user got a digest")
+	}
+	if count(digested) != 1 {
+		t.Error("user digested within the interval got another")
+	}
+
+	// A second run the same day changes nothing.
+	var before domain.MatchDigest
+	db.Where("user_id = ?", active.ID).First(&before)
+	w.RunOnce(context.Background())
+	var after domain.MatchDigest
+	db.Where("user_id = ?", active.ID).First(&after)
+	if !after.CreatedAt.Equal(before.CreatedAt) {
+		t.Error("second run rebuilt a current digest")
+	}
+}
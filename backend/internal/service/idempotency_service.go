@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/yourusername/skillsync/internal/domain"
+)
+
+var (
+	ErrIdempotencyInProgress = errors.New("a request with this idempotency key is still being processed")
+	ErrIdempotencyKeyReused  = errors.New("this idempotency key was already used for a different request")
+)
+
+// IdempotentResponse is a stored response replayed for a repeated key.
+type IdempotentResponse struct {
+	StatusCode int
+	Body       []byte
+}
+
+// IdempotencyService stores the outcome of requests sent with an
+// Idempotency-Key header. Keys are scoped per user and forgotten after
+// IDEMPOTENCY_TTL (default 24h).
+type IdempotencyService struct {
+	db  *gorm.DB
+	ttl time.Duration
+}
+
+func NewIdempotencyService(db *gorm.DB) *IdempotencyService {
+	return &IdempotencyService{
+		db:  db,
+		ttl: getEnvDuration("IDEMPOTENCY_TTL", 24*time.Hour),
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Begin / Complete / Release
+// ---------------------------------------------------------------------------
+
+// Begin claims key for userID. It returns (nil, nil) when the caller should
+// process the request and then call Complete or Release, or the stored
+// response when the key was already completed for the same request.
+func (s *IdempotencyService) Begin(userID, key, requestHash string) (*IdempotentResponse, error) {
+	// An expired key may be reused as if it were new.
+	s.db.Where("user_id = ? AND key = ? AND created_at < ?", userID, key, time.Now().Add(-s.ttl)).
+		Delete(&domain.IdempotencyKey{})
+
+	row := domain.IdempotencyKey{UserID: userID, Key: key, RequestHash: requestHash}
+	res := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&row)
+	if res.Error != nil {
+		return nil, fmt.Errorf("failed to claim idempotency key: %w", res.Error)
+	}
+	if res.RowsAffected == 1 {
+		return nil, nil
+	}
+
+	var existing domain.IdempotencyKey
+	if err := s.db.Where("user_id = ? AND key = ?", userID, key).First(&existing).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch idempotency key: %w", err)
+	}
+	if existing.RequestHash != requestHash {
+		return nil, ErrIdempotencyKeyReused
+	}
+	if existing.StatusCode == 0 {
+		return nil, ErrIdempotencyInProgress
+	}
+	return &IdempotentResponse{StatusCode: existing.StatusCode, Body: existing.ResponseBody}, nil
+}
+
+// Complete stores the response for a key claimed with Begin.
+func (s *IdempotencyService) Complete(userID, key string, status int, body []byte) error {
+	err := s.db.Model(&domain.IdempotencyKey{}).
+		Where("user_id = ? AND key = ?", userID, key).
+		Updates(map[string]interface{}{"status_code": status, "response_body": body}).Error
+	if err != nil {
+		return fmt.Errorf("failed to store idempotent response: %w", err)
+	}
+	return nil
+}
+
+// Release drops an unfinished claim so the request can be retried, e.g.
+// after a server error.
+func (s *IdempotencyService) Release(userID, key string) error {
+	err := s.db.Where("user_id = ? AND key = ? AND status_code = 0", userID, key).
+		Delete(&domain.IdempotencyKey{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
+
+// RunCleanup deletes expired keys once an hour. Call as a goroutine.
+func (s *IdempotencyService) RunCleanup(ctx context.Context) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			res := s.db.Where("created_at < ?", time.Now().Add(-s.ttl)).Delete(&domain.IdempotencyKey{})
+			if res.Error != nil {
+				log.Error().Err(res.Error).Msg("idempotency cleanup failed")
+				continue
+			}
+			if res.RowsAffected > 0 {
+				log.Info().Int64("deleted", res.RowsAffected).Msg("expired idempotency keys removed")
+			}
+		}
+	}
+}
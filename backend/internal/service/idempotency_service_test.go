@@ -0,0 +1,50 @@
+package service
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestIdempotencyBeginConcurrentDuplicates(t *testing.T) {
+	db := testDB(t)
+	user := createTestUser(t, db)
+	s := NewIdempotencyService(db)
+	key := "submit-" + testSuffix(t)
+
+	const n = 10
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = s.Begin(user.ID, key, "hash")
+		}(i)
+	}
+	wg.Wait()
+
+	claimed := 0
+	for _, err := range errs {
+		switch err {
+		case nil:
+			claimed++
+		case ErrIdempotencyInProgress:
+		default:
+			t.Fatalf("Begin: %v", err)
+		}
+	}
+	if claimed != 1 {
+		t.Fatalf("%d requests claimed the key, want 1", claimed)
+	}
+
+	if err := s.Complete(user.ID, key, 201, []byte(`{"id":1}`)); err != nil {
+		t.Fatal(err)
+	}
+	stored, err := s.Begin(user.ID, key, "hash")
+	if err != nil || stored == nil || stored.StatusCode != 201 {
+		t.Fatalf("Begin after Complete = %+v, %v; want stored 201", stored, err)
+	}
+	if _, err := s.Begin(user.ID, key, "other"); err != ErrIdempotencyKeyReused {
+		t.Fatalf("Begin with another body = %v, want %v", err, ErrIdempotencyKeyReused)
+	}
+}
@@ -0,0 +1,255 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/yourusername/skillsync/internal/domain"
+)
+
+var ErrInvalidActivityCursor = errors.New("invalid activity cursor")
+
+// Activity item types. Items with the same timestamp are ordered by type.
+const (
+	ActivitySessionStarted      = "session_started"
+	ActivitySessionEnded        = "session_ended"
+	ActivityRatingSubmitted     = "rating_submitted"
+	ActivityMessage             = "message"
+	ActivityInsightsRegenerated = "insights_regenerated"
+)
+
+// activityPreviewLength caps the message text carried in a feed item.
+const activityPreviewLength = 120
+
+// MatchActivity is one event on a match timeline. Data holds the IDs and
+// the little else a timeline row needs; the full objects have their own
+// endpoints.
+type MatchActivity struct {
+	Type string                 `json:"type"`
+	At   time.Time              `json:"at"`
+	Data map[string]interface{} `json:"data"`
+
+	id uint
+}
+
+// ActivityPage is one page of a match timeline, newest first. When HasMore
+// is set, NextCursor fetches the older items.
+type ActivityPage struct {
+	Items      []MatchActivity `json:"items"`
+	HasMore    bool            `json:"has_more"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+}
+
+// activityCursor is the position of the last item of a page. Items are
+// ordered by time, then type and source ID, all descending, so the cursor
+// is exact even when several events share a timestamp.
+type activityCursor struct {
+	at  time.Time
+	typ string
+	id  uint
+}
+
+func (c activityCursor) String() string {
+	return strconv.FormatInt(c.at.UnixNano(), 10) + ":" + c.typ + ":" + strconv.FormatUint(uint64(c.id), 10)
+}
+
+func parseActivityCursor(s string) (*activityCursor, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return nil, ErrInvalidActivityCursor
+	}
+	nanos, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidActivityCursor
+	}
+	id, err := strconv.ParseUint(parts[2], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidActivityCursor
+	}
+	return &activityCursor{at: time.Unix(0, nanos), typ: parts[1], id: uint(id)}, nil
+}
+
+// follows reports whether a comes after the cursor in the feed.
+func (c *activityCursor) follows(a *MatchActivity) bool {
+	if !a.At.Equal(c.at) {
+		return a.At.Before(c.at)
+	}
+	if a.Type != c.typ {
+		return a.Type < c.typ
+	}
+	return a.id < c.id
+}
+
+// scope limits a source query of items of typ, timed by column and
+// identified by idColumn, to those that follow the cursor.
+func (c *activityCursor) scope(q *gorm.DB, typ, column, idColumn string) *gorm.DB {
+	switch {
+	case c == nil:
+		return q
+	case typ > c.typ:
+		return q.Where(column+" < ?", c.at)
+	case typ < c.typ:
+		return q.Where(column+" <= ?", c.at)
+	default:
+		return q.Where("("+column+" < ? OR ("+column+" = ? AND "+idColumn+" < ?))", c.at, c.at, c.id)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// GetMatchActivity
+// ---------------------------------------------------------------------------
+
+// GetMatchActivity returns up to limit timeline items of matchID for one of
+// its participants, newest first, starting after cursor ("" for the
+// newest). Messages, session starts and ends, ratings and the last
+// insights regeneration are read from their own tables and merged here.
+func (s *MatchService) GetMatchActivity(ctx context.Context, matchID uint, userID, cursor string, limit int) (*ActivityPage, error) {
+	var cur *activityCursor
+	if cursor != "" {
+		var err error
+		if cur, err = parseActivityCursor(cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	db := s.db.WithContext(ctx)
+	match, err := s.participantMatch(db, matchID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// limit+1 items from each source fill the page and tell whether more
+	// remain.
+	fetch := limit + 1
+	var items []MatchActivity
+
+	var messages []domain.Message
+	if err := cur.scope(db.Select("id", "sender_id", "type", "content", "created_at"), ActivityMessage, "created_at", "id").
+		Where("match_id = ?", matchID).
+		Order("created_at DESC, id DESC").
+		Limit(fetch).
+		Find(&messages).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch messages: %w", err)
+	}
+	for _, m := range messages {
+		data := map[string]interface{}{
+			"message_id":   m.ID,
+			"sender_id":    m.SenderID,
+			"message_type": m.Type,
+		}
+		if m.Type == domain.MessageText {
+			data["preview"] = activityPreview(m.Content)
+		}
+		items = append(items, MatchActivity{Type: ActivityMessage, At: m.CreatedAt, Data: data, id: m.ID})
+	}
+
+	// Sessions scheduled from an accepted proposal start in the future;
+	// they join the feed once they begin.
+	now := time.Now()
+	var started []domain.CodingSession
+	if err := cur.scope(db.Select("id", "started_at"), ActivitySessionStarted, "started_at", "id").
+		Where("match_id = ? AND started_at <= ?", matchID, now).
+		Order("started_at DESC, id DESC").
+		Limit(fetch).
+		Find(&started).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch sessions: %w", err)
+	}
+	for _, cs := range started {
+		items = append(items, MatchActivity{
+			Type: ActivitySessionStarted,
+			At:   cs.StartedAt,
+			Data: map[string]interface{}{"session_id": cs.ID},
+			id:   cs.ID,
+		})
+	}
+
+	var ended []domain.CodingSession
+	if err := cur.scope(db.Select("id", "ended_at", "duration_minutes"), ActivitySessionEnded, "ended_at", "id").
+		Where("match_id = ? AND ended_at IS NOT NULL", matchID).
+		Order("ended_at DESC, id DESC").
+		Limit(fetch).
+		Find(&ended).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch sessions: %w", err)
+	}
+	for _, cs := range ended {
+		items = append(items, MatchActivity{
+			Type: ActivitySessionEnded,
+			At:   *cs.EndedAt,
+			Data: map[string]interface{}{"session_id": cs.ID, "duration_minutes": cs.DurationMinutes},
+			id:   cs.ID,
+		})
+	}
+
+	var ratings []domain.Rating
+	if err := cur.scope(db.Select("ratings.id", "ratings.rater_id", "ratings.rated_id", "ratings.session_id", "ratings.created_at"), ActivityRatingSubmitted, "ratings.created_at", "ratings.id").
+		Joins("JOIN coding_sessions ON coding_sessions.id = ratings.session_id").
+		Where("coding_sessions.match_id = ?", matchID).
+		Order("ratings.created_at DESC, ratings.id DESC").
+		Limit(fetch).
+		Find(&ratings).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch ratings: %w", err)
+	}
+	for _, r := range ratings {
+		items = append(items, MatchActivity{
+			Type: ActivityRatingSubmitted,
+			At:   r.CreatedAt,
+			Data: map[string]interface{}{
+				"rating_id":  r.ID,
+				"session_id": r.SessionID,
+				"rater_id":   r.RaterID,
+				"rated_id":   r.RatedID,
+			},
+			id: r.ID,
+		})
+	}
+
+	// Only the latest regeneration is recorded.
+	if match.InsightsGeneratedAt != nil {
+		item := MatchActivity{
+			Type: ActivityInsightsRegenerated,
+			At:   *match.InsightsGeneratedAt,
+			Data: map[string]interface{}{"match_id": match.ID},
+			id:   match.ID,
+		}
+		if cur == nil || cur.follows(&item) {
+			items = append(items, item)
+		}
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		a, b := &items[i], &items[j]
+		if !a.At.Equal(b.At) {
+			return a.At.After(b.At)
+		}
+		if a.Type != b.Type {
+			return a.Type > b.Type
+		}
+		return a.id > b.id
+	})
+
+	page := &ActivityPage{Items: []MatchActivity{}}
+	if len(items) > limit {
+		last := items[limit-1]
+		page.HasMore = true
+		page.NextCursor = activityCursor{at: last.At, typ: last.Type, id: last.id}.String()
+		items = items[:limit]
+	}
+	page.Items = append(page.Items, items...)
+	return page, nil
+}
+
+// activityPreview shortens message text for a feed item.
+func activityPreview(content string) string {
+	content = strings.Join(strings.Fields(content), " ")
+	if r := []rune(content); len(r) > activityPreviewLength {
+		return string(r[:activityPreviewLength]) + "…"
+	}
+	return content
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/yourusername/skillsync/internal/domain"
+)
+
+// ErrProfileIncomplete is matched (via errors.Is) by every
+// *ProfileIncompleteError.
+var ErrProfileIncomplete = errors.New("profile is incomplete")
+
+// MatchEligibility is what a profile needs before FindMatches suggests it to
+// others or suggests others to it: at least MinSkills skills and, with
+// RequireBio, a bio. Users below it can still browse and send requests.
+// Set with MATCH_MIN_SKILLS (default 1) and MATCH_REQUIRE_BIO (default
+// true).
+type MatchEligibility struct {
+	MinSkills  int  `json:"min_skills"`
+	RequireBio bool `json:"require_bio"`
+}
+
+func matchEligibilityFromEnv() MatchEligibility {
+	return MatchEligibility{
+		MinSkills:  max(getEnvInt("MATCH_MIN_SKILLS", 1), 0),
+		RequireBio: getEnv("MATCH_REQUIRE_BIO", "true") != "false",
+	}
+}
+
+// ProfileIncompleteError says what a user must add to their profile to get
+// match suggestions.
+type ProfileIncompleteError struct {
+	Skills     int
+	MinSkills  int
+	MissingBio bool
+}
+
+func (e *ProfileIncompleteError) Error() string {
+	var missing []string
+	if e.Skills < e.MinSkills {
+		n := e.MinSkills - e.Skills
+		noun := "skills"
+		if n == 1 {
+			noun = "skill"
+		}
+		missing = append(missing, fmt.Sprintf("%d more %s", n, noun))
+	}
+	if e.MissingBio {
+		missing = append(missing, "a bio")
+	}
+	return "add " + strings.Join(missing, " and ") + " to your profile to get match suggestions"
+}
+
+func (e *ProfileIncompleteError) Is(target error) bool {
+	return target == ErrProfileIncomplete
+}
+
+// check returns a *ProfileIncompleteError when user, whose skills must be
+// loaded, falls short.
+func (e MatchEligibility) check(user *domain.User) error {
+	missingBio := e.RequireBio && strings.TrimSpace(user.Bio) == ""
+	if len(user.Skills) >= e.MinSkills && !missingBio {
+		return nil
+	}
+	return &ProfileIncompleteError{Skills: len(user.Skills), MinSkills: e.MinSkills, MissingBio: missingBio}
+}
+
+// scope limits a users query to eligible profiles.
+func (e MatchEligibility) scope(db *gorm.DB) *gorm.DB {
+	if e.MinSkills > 0 {
+		db = db.Where("(SELECT COUNT(*) FROM user_skills WHERE user_skills.user_id = users.id) >= ?", e.MinSkills)
+	}
+	if e.RequireBio {
+		db = db.Where("TRIM(COALESCE(users.bio, '')) <> ''")
+	}
+	return db
+}
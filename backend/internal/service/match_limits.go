@@ -0,0 +1,90 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/yourusername/skillsync/internal/domain"
+)
+
+// ErrMatchLimitReached is matched (via errors.Is) by every *MatchLimitError.
+var ErrMatchLimitReached = errors.New("match limit reached")
+
+// Kinds of per-user match limits.
+const (
+	LimitActiveMatches   = "active_matches"
+	LimitPendingRequests = "pending_requests"
+)
+
+// MatchLimits caps how many active matches and pending outbound requests a
+// user may have at once. Zero disables a cap. Set with MAX_ACTIVE_MATCHES
+// (default 50) and MAX_PENDING_REQUESTS (default 25).
+type MatchLimits struct {
+	MaxActiveMatches   int `json:"max_active_matches"`
+	MaxPendingRequests int `json:"max_pending_requests"`
+}
+
+func matchLimitsFromEnv() MatchLimits {
+	return MatchLimits{
+		MaxActiveMatches:   getEnvInt("MAX_ACTIVE_MATCHES", 50),
+		MaxPendingRequests: getEnvInt("MAX_PENDING_REQUESTS", 25),
+	}
+}
+
+// MatchLimitError reports which limit a user hit, with their current count.
+type MatchLimitError struct {
+	UserID string
+	Kind   string
+	Count  int64
+	Limit  int
+}
+
+func (e *MatchLimitError) Error() string {
+	what := "active matches"
+	if e.Kind == LimitPendingRequests {
+		what = "pending match requests"
+	}
+	return fmt.Sprintf("match limit reached: %d of %d %s", e.Count, e.Limit, what)
+}
+
+func (e *MatchLimitError) Is(target error) bool {
+	return target == ErrMatchLimitReached
+}
+
+// checkActiveMatchLimit fails when userID already has the maximum number of
+// active matches.
+func (s *MatchService) checkActiveMatchLimit(userID string) error {
+	max := s.limits.MaxActiveMatches
+	if max <= 0 {
+		return nil
+	}
+	var n int64
+	if err := s.db.Model(&domain.Match{}).
+		Where("(user1_id = ? OR user2_id = ?) AND status = ?", userID, userID, domain.MatchActive).
+		Count(&n).Error; err != nil {
+		return fmt.Errorf("failed to count active matches: %w", err)
+	}
+	if n >= int64(max) {
+		return &MatchLimitError{UserID: userID, Kind: LimitActiveMatches, Count: n, Limit: max}
+	}
+	return nil
+}
+
+// checkPendingRequestLimit fails when userID already has the maximum number
+// of outbound requests awaiting an answer.
+func (s *MatchService) checkPendingRequestLimit(userID string) error {
+	max := s.limits.MaxPendingRequests
+	if max <= 0 {
+		return nil
+	}
+	var n int64
+	if err := s.db.Model(&domain.MatchRequest{}).
+		Where("sender_id = ? AND status = ?", userID, domain.RequestPending).
+		Count(&n).Error; err != nil {
+		return fmt.Errorf("failed to count pending requests: %w", err)
+	}
+	if n >= int64(max) {
+		return &MatchLimitError{UserID: userID, Kind: LimitPendingRequests, Count: n, Limit: max}
+	}
+	return nil
+}
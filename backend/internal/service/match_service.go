@@ -1,15 +1,18 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
 	"sort"
+	"sync"
 	"time"
 
 	"github.com/rs/zerolog/log"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/yourusername/skillsync/internal/domain"
 )
@@ -20,7 +23,15 @@ var (
 	ErrSelfMatch           = errors.New("cannot match with yourself")
 	ErrRequestNotFound     = errors.New("match request not found")
 	ErrNotRequestReceiver  = errors.New("only the receiver can accept or reject this request")
+	ErrNotRequestParticipant = errors.New("you are not the sender or receiver of this request")
 	ErrRequestNotPending   = errors.New("match request is no longer pending")
+	ErrMatrixSize          = errors.New("between 2 and 25 distinct user ids are required")
+	ErrMatchNotFound       = errors.New("match not found")
+	ErrNotMatchParticipant = errors.New("you are not a participant in this match")
+	ErrMatchNotActive      = errors.New("match is not active")
+	ErrMatchActive         = errors.New("match is already active")
+	ErrNotArchiver         = errors.New("only the participant who archived this match can reactivate it; send a new match request instead")
+	ErrDigestNotFound      = errors.New("no match digest has been generated yet")
 )
 
 // MatchSuggestion is returned by FindMatches.
@@ -30,103 +41,420 @@ type MatchSuggestion struct {
 	AIInsights          *PairingInsights `json:"ai_insights,omitempty"`
 	CommonSkills        []string        `json:"common_skills"`
 	ComplementarySkills []string        `json:"complementary_skills"`
+	TeachingOpportunities []TeachingOpportunity `json:"teaching_opportunities"`
+	Breakdown           *CompatibilityBreakdown `json:"breakdown,omitempty"`
+}
+
+// TeachingOpportunity is a skill one user wants to learn and the other can
+// teach.
+type TeachingOpportunity struct {
+	Skill     string `json:"skill"`
+	TeacherID string `json:"teacher_id"`
+	LearnerID string `json:"learner_id"`
 }
 
 type MatchService struct {
-	db      *gorm.DB
-	claude  *ClaudeService
+	db          *gorm.DB
+	claude      ClaudeService
+	scoring     ScoringConfig
+	limits      MatchLimits
+	eligibility MatchEligibility
+	predictions *predictionCache
+	// candidateMultiplier times the limit is how many candidates FindMatches
+	// scores; scoringWorkers is how many score them at once.
+	candidateMultiplier int
+	scoringWorkers      int
 }
 
-func NewMatchService(db *gorm.DB, claude *ClaudeService) *MatchService {
-	return &MatchService{db: db, claude: claude}
+const (
+	defaultMatchCandidateMultiplier = 5
+	defaultMatchScoringWorkers      = 4
+)
+
+// NewMatchService builds the service with the given compatibility weights;
+// validate them first (ScoringConfigFromEnv does).
+func NewMatchService(db *gorm.DB, claude ClaudeService, scoring ScoringConfig) *MatchService {
+	return &MatchService{
+		db:          db,
+		claude:      claude,
+		scoring:     scoring,
+		limits:      matchLimitsFromEnv(),
+		eligibility: matchEligibilityFromEnv(),
+		predictions: newPredictionCache(getEnvDuration("PREDICTION_CACHE_TTL", defaultPredictionCacheTTL), maxPredictionCacheEntries),
+		candidateMultiplier: max(getEnvInt("MATCH_CANDIDATE_MULTIPLIER", defaultMatchCandidateMultiplier), 1),
+		scoringWorkers:      max(getEnvInt("MATCH_SCORING_WORKERS", defaultMatchScoringWorkers), 1),
+	}
 }
 
 // ---------------------------------------------------------------------------
 // CalculateCompatibility
 // ---------------------------------------------------------------------------
 
-func (s *MatchService) CalculateCompatibility(user1ID, user2ID string) (float64, error) {
+func (s *MatchService) CalculateCompatibility(ctx context.Context, user1ID, user2ID string) (float64, error) {
+	b, err := s.ExplainCompatibility(ctx, user1ID, user2ID)
+	if err != nil {
+		return 0, err
+	}
+	return b.Total, nil
+}
+
+// ExplainCompatibility returns the per-component breakdown behind
+// CalculateCompatibility.
+func (s *MatchService) ExplainCompatibility(ctx context.Context, user1ID, user2ID string) (*CompatibilityBreakdown, error) {
+	db := s.db.WithContext(ctx)
+
 	if user1ID == user2ID {
-		return 0, ErrSelfMatch
+		return nil, ErrSelfMatch
 	}
 
 	// Load both users with skills.
 	var u1, u2 domain.User
-	if err := s.db.Preload("Skills.Skill").First(&u1, "id = ?", user1ID).Error; err != nil {
-		return 0, fmt.Errorf("user1 not found: %w", err)
+	if err := db.Preload("Skills.Skill").First(&u1, "id = ?", user1ID).Error; err != nil {
+		return nil, fmt.Errorf("user1 not found: %w", err)
 	}
-	if err := s.db.Preload("Skills.Skill").First(&u2, "id = ?", user2ID).Error; err != nil {
-		return 0, fmt.Errorf("user2 not found: %w", err)
+	if err := db.Preload("Skills.Skill").First(&u2, "id = ?", user2ID).Error; err != nil {
+		return nil, fmt.Errorf("user2 not found: %w", err)
 	}
 
 	// Load reputations.
 	var rep1, rep2 domain.UserReputation
-	s.db.Where("user_id = ?", user1ID).First(&rep1)
-	s.db.Where("user_id = ?", user2ID).First(&rep2)
+	db.Where("user_id = ?", user1ID).First(&rep1)
+	db.Where("user_id = ?", user2ID).First(&rep2)
+
+	b := compatibilityBreakdown(s.scoring, u1, u2, rep1, rep2)
+	return &b, nil
+}
+
+// CompatibilityPreview is how well the caller would pair with another user,
+// computed without creating a match request.
+type CompatibilityPreview struct {
+	UserID                string                 `json:"user_id"`
+	Breakdown             CompatibilityBreakdown `json:"breakdown"`
+	CommonSkills          []string               `json:"common_skills"`
+	ComplementarySkills   []string               `json:"complementary_skills"`
+	TeachingOpportunities []TeachingOpportunity  `json:"teaching_opportunities"`
+}
+
+// PreviewCompatibility scores callerID against targetID and lists their
+// common and complementary skills. It returns ErrUserNotFound for an unknown
+// target or one in a block with the caller.
+func (s *MatchService) PreviewCompatibility(ctx context.Context, callerID, targetID string) (*CompatibilityPreview, error) {
+	db := s.db.WithContext(ctx)
+
+	if callerID == targetID {
+		return nil, ErrSelfMatch
+	}
+	for _, id := range blockedUserIDs(db, callerID) {
+		if id == targetID {
+			return nil, ErrUserNotFound
+		}
+	}
+
+	var caller, target domain.User
+	if err := db.Preload("Skills.Skill").First(&target, "id = ?", targetID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch user: %w", err)
+	}
+	if err := db.Preload("Skills.Skill").First(&caller, "id = ?", callerID).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch caller: %w", err)
+	}
+
+	var rep1, rep2 domain.UserReputation
+	db.Where("user_id = ?", callerID).First(&rep1)
+	db.Where("user_id = ?", targetID).First(&rep2)
+
+	common, comp, teaching := classifySkills(caller.Skills, target.Skills)
+	return &CompatibilityPreview{
+		UserID:                targetID,
+		Breakdown:             compatibilityBreakdown(s.scoring, caller, target, rep1, rep2),
+		CommonSkills:          common,
+		ComplementarySkills:   comp,
+		TeachingOpportunities: teaching,
+	}, nil
+}
+
+// SimilarUser is a user ranked by how much their skill set overlaps another
+// user's.
+type SimilarUser struct {
+	User         *domain.User `json:"user"`
+	Similarity   float64      `json:"similarity"`
+	CommonSkills []string     `json:"common_skills"`
+}
+
+// similarCandidatePool caps how many users sharing a skill are scored by
+// FindSimilarUsers; those sharing the most skills are considered first.
+const similarCandidatePool = 200
+
+// FindSimilarUsers returns up to limit (default 10, max 50) users with the
+// highest skillSimilarity to userID, most similar first. Only users sharing
+// at least one skill are considered. viewerID's blocks are excluded, and
+// ErrUserNotFound is returned for an unknown userID or one in a block with
+// the viewer.
+func (s *MatchService) FindSimilarUsers(ctx context.Context, viewerID, userID string, limit int) ([]*SimilarUser, error) {
+	db := s.db.WithContext(ctx)
+
+	if limit <= 0 || limit > 50 {
+		limit = 10
+	}
+
+	blocked := blockedUserIDs(db, viewerID)
+	for _, id := range blocked {
+		if id == userID {
+			return nil, ErrUserNotFound
+		}
+	}
+
+	var user domain.User
+	if err := db.Preload("Skills.Skill").First(&user, "id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch user: %w", err)
+	}
+	if len(user.Skills) == 0 {
+		return []*SimilarUser{}, nil
+	}
+
+	skillIDs := make([]uint, len(user.Skills))
+	for i, sk := range user.Skills {
+		skillIDs[i] = sk.SkillID
+	}
+	exclude := append([]string{userID}, blocked...)
+
+	var candidateIDs []string
+	if err := db.Model(&domain.UserSkill{}).
+		Select("user_id").
+		Where("skill_id IN ? AND user_id NOT IN ?", skillIDs, exclude).
+		Group("user_id").
+		Order("COUNT(*) DESC").
+		Limit(similarCandidatePool).
+		Pluck("user_id", &candidateIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to find candidates: %w", err)
+	}
+	if len(candidateIDs) == 0 {
+		return []*SimilarUser{}, nil
+	}
+
+	var candidates []domain.User
+	if err := db.Preload("Skills.Skill").Where("id IN ?", candidateIDs).Find(&candidates).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch candidates: %w", err)
+	}
+
+	results := make([]*SimilarUser, 0, len(candidates))
+	for i := range candidates {
+		common, _, _ := classifySkills(user.Skills, candidates[i].Skills)
+		results = append(results, &SimilarUser{
+			User:         &candidates[i],
+			Similarity:   math.Round(skillSimilarity(user.Skills, candidates[i].Skills)*100) / 100,
+			CommonSkills: common,
+		})
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Similarity != results[j].Similarity {
+			return results[i].Similarity > results[j].Similarity
+		}
+		return results[i].User.ID < results[j].User.ID
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// ScoringConfig returns the compatibility weights in effect.
+func (s *MatchService) ScoringConfig() ScoringConfig {
+	return s.scoring
+}
 
-	skillSim := skillSimilarity(u1.Skills, u2.Skills)
-	goalsAlign := goalsAlignment(u1, u2)
-	compSkills := complementaryScore(u1.Skills, u2.Skills)
-	repCompat := reputationCompatibility(rep1, rep2)
+// CompatibilityBreakdown explains a compatibility score. Every component is
+// 0-100; Total is their weighted sum.
+type CompatibilityBreakdown struct {
+	SkillSimilarity float64 `json:"skill_similarity"`
+	GoalsAlignment  float64 `json:"goals_alignment"`
+	Complementary   float64 `json:"complementary"`
+	Reputation      float64 `json:"reputation"`
+	Cadence         float64 `json:"cadence"`
+	Total           float64 `json:"total"`
+}
+
+// compatibilityScore is the weighted score shared by CalculateCompatibility
+// and CompatibilityMatrix.
+func compatibilityScore(w ScoringConfig, u1, u2 domain.User, rep1, rep2 domain.UserReputation) float64 {
+	return compatibilityBreakdown(w, u1, u2, rep1, rep2).Total
+}
 
-	score := skillSim*0.40 + goalsAlign*0.30 + compSkills*0.20 + repCompat*0.10
+func compatibilityBreakdown(w ScoringConfig, u1, u2 domain.User, rep1, rep2 domain.UserReputation) CompatibilityBreakdown {
+	b := CompatibilityBreakdown{
+		SkillSimilarity: skillSimilarity(u1.Skills, u2.Skills),
+		GoalsAlignment:  goalsAlignment(u1, u2),
+		Complementary:   complementaryScore(u1.Skills, u2.Skills),
+		Reputation:      reputationCompatibility(rep1, rep2),
+		Cadence:         cadenceCompatibility(u1, u2),
+	}
 
-	return math.Round(score*100) / 100, nil
+	score := b.SkillSimilarity*w.SkillSimilarity + b.GoalsAlignment*w.GoalsAlignment +
+		b.Complementary*w.Complementary + b.Reputation*w.Reputation + b.Cadence*w.Cadence
+
+	b.Total = math.Round(score*100) / 100
+	return b
+}
+
+// ---------------------------------------------------------------------------
+// CompatibilityMatrix
+// ---------------------------------------------------------------------------
+
+// MaxMatrixUsers caps the group size accepted by CompatibilityMatrix.
+const MaxMatrixUsers = 25
+
+// CompatibilityMatrix is the all-pairs result for a group. Scores[i][j] is the
+// compatibility of UserIDs[i] and UserIDs[j]; the diagonal is zero.
+type CompatibilityMatrix struct {
+	UserIDs []string    `json:"user_ids"`
+	Scores  [][]float64 `json:"scores"`
+}
+
+// CompatibilityMatrix scores every pair in userIDs. Users and reputations are
+// loaded in one query each, so the cost is independent of the pair count.
+// Duplicate IDs are collapsed, keeping the first occurrence, and users in a
+// block with callerID are left out.
+func (s *MatchService) CompatibilityMatrix(ctx context.Context, callerID string, userIDs []string) (*CompatibilityMatrix, error) {
+	db := s.db.WithContext(ctx)
+
+	ids := make([]string, 0, len(userIDs))
+	seen := make(map[string]bool, len(userIDs))
+	for _, id := range blockedUserIDs(db, callerID) {
+		seen[id] = true
+	}
+	for _, id := range userIDs {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	if len(ids) < 2 || len(ids) > MaxMatrixUsers {
+		return nil, ErrMatrixSize
+	}
+
+	var users []domain.User
+	if err := db.Preload("Skills.Skill").Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to load users: %w", err)
+	}
+	if len(users) != len(ids) {
+		return nil, ErrUserNotFound
+	}
+	byID := make(map[string]domain.User, len(users))
+	for _, u := range users {
+		byID[u.ID] = u
+	}
+
+	var reps []domain.UserReputation
+	if err := db.Where("user_id IN ?", ids).Find(&reps).Error; err != nil {
+		return nil, fmt.Errorf("failed to load reputations: %w", err)
+	}
+	repByID := make(map[string]domain.UserReputation, len(reps))
+	for _, r := range reps {
+		repByID[r.UserID] = r
+	}
+
+	scores := make([][]float64, len(ids))
+	for i := range scores {
+		scores[i] = make([]float64, len(ids))
+	}
+	for i := 0; i < len(ids); i++ {
+		for j := i + 1; j < len(ids); j++ {
+			sc := compatibilityScore(s.scoring, byID[ids[i]], byID[ids[j]], repByID[ids[i]], repByID[ids[j]])
+			scores[i][j] = sc
+			scores[j][i] = sc
+		}
+	}
+
+	return &CompatibilityMatrix{UserIDs: ids, Scores: scores}, nil
 }
 
 // ---------------------------------------------------------------------------
 // FindMatches
 // ---------------------------------------------------------------------------
 
-func (s *MatchService) FindMatches(userID string, limit int) ([]*MatchSuggestion, error) {
+func (s *MatchService) FindMatches(ctx context.Context, userID string, limit int) ([]*MatchSuggestion, error) {
+	db := s.db.WithContext(ctx)
+
 	if limit <= 0 || limit > 50 {
 		limit = 10
 	}
 
 	// Load the requesting user.
 	var user domain.User
-	if err := s.db.Preload("Skills.Skill").First(&user, "id = ?", userID).Error; err != nil {
+	if err := db.Preload("Skills.Skill").First(&user, "id = ?", userID).Error; err != nil {
 		return nil, fmt.Errorf("user not found: %w", err)
 	}
+	if err := s.eligibility.check(&user); err != nil {
+		return nil, err
+	}
 
-	// IDs to exclude: self + existing active matches + pending outbound requests.
+	// IDs to exclude: self + existing active matches + matches the user
+	// deleted + pending outbound requests.
 	excludeIDs := []string{userID}
 
 	var matchedIDs []string
-	s.db.Model(&domain.Match{}).
-		Where("(user1_id = ? OR user2_id = ?) AND status = ?", userID, userID, domain.MatchActive).
+	db.Model(&domain.Match{}).
+		Where("(user1_id = ? OR user2_id = ?) AND (status = ? OR "+matchHiddenBy+")",
+			userID, userID, domain.MatchActive, userID, userID).
 		Select("CASE WHEN user1_id = ? THEN user2_id ELSE user1_id END", userID).
 		Scan(&matchedIDs)
 	excludeIDs = append(excludeIDs, matchedIDs...)
 
 	var pendingIDs []string
-	s.db.Model(&domain.MatchRequest{}).
+	db.Model(&domain.MatchRequest{}).
 		Where("sender_id = ? AND status = ?", userID, domain.RequestPending).
 		Pluck("receiver_id", &pendingIDs)
 	excludeIDs = append(excludeIDs, pendingIDs...)
+	excludeIDs = append(excludeIDs, blockedUserIDs(db, userID)...)
 
-	// Candidate pool: up to 5x the limit so we can score and rank.
+	// Candidate pool: up to MATCH_CANDIDATE_MULTIPLIER (default 5) times the
+	// limit so we can score and rank. Incomplete profiles aren't suggested.
 	var candidates []domain.User
-	s.db.Preload("Skills.Skill").
+	db.Preload("Skills.Skill").
+		Scopes(s.eligibility.scope).
 		Where("id NOT IN ?", excludeIDs).
-		Limit(limit * 5).
+		Limit(limit * s.candidateMultiplier).
 		Find(&candidates)
 
+	// Load every reputation involved at once rather than per candidate.
+	repIDs := make([]string, 0, len(candidates)+1)
+	repIDs = append(repIDs, userID)
+	for _, c := range candidates {
+		repIDs = append(repIDs, c.ID)
+	}
+	var reps []domain.UserReputation
+	if err := db.Where("user_id IN ?", repIDs).Find(&reps).Error; err != nil {
+		return nil, fmt.Errorf("failed to load reputations: %w", err)
+	}
+	repByID := make(map[string]domain.UserReputation, len(reps))
+	for _, r := range reps {
+		repByID[r.UserID] = r
+	}
+
 	// Score every candidate.
 	type scored struct {
-		user  *domain.User
-		score float64
-	}
-	results := make([]scored, 0, len(candidates))
-	for i := range candidates {
-		sc, err := s.CalculateCompatibility(userID, candidates[i].ID)
-		if err != nil {
-			continue
-		}
-		results = append(results, scored{user: &candidates[i], score: sc})
+		user      *domain.User
+		score     float64
+		breakdown *CompatibilityBreakdown
 	}
+	results := make([]scored, len(candidates))
+	s.scoreConcurrently(len(candidates), func(i int) {
+		b := compatibilityBreakdown(s.scoring, user, candidates[i], repByID[userID], repByID[candidates[i].ID])
+		results[i] = scored{user: &candidates[i], score: b.Total, breakdown: &b}
+	})
 
-	sort.Slice(results, func(i, j int) bool { return results[i].score > results[j].score })
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].score != results[j].score {
+			return results[i].score > results[j].score
+		}
+		return results[i].user.ID < results[j].user.ID
+	})
 
 	if len(results) > limit {
 		results = results[:limit]
@@ -135,16 +463,18 @@ func (s *MatchService) FindMatches(userID string, limit int) ([]*MatchSuggestion
 	// Build suggestions; enrich top 3 with AI insights.
 	suggestions := make([]*MatchSuggestion, len(results))
 	for i, r := range results {
-		common, comp := classifySkills(user.Skills, r.user.Skills)
+		common, comp, teaching := classifySkills(user.Skills, r.user.Skills)
 		suggestion := &MatchSuggestion{
 			User:                r.user,
 			MatchScore:          r.score,
 			CommonSkills:        common,
 			ComplementarySkills: comp,
+			TeachingOpportunities: teaching,
+			Breakdown:           r.breakdown,
 		}
 
 		if i < 3 && s.claude != nil {
-			insights, err := s.claude.GeneratePairingInsights(user, *r.user, user.Skills, r.user.Skills)
+			insights, err := s.claude.GeneratePairingInsights(ctx, user, *r.user, user.Skills, r.user.Skills)
 			if err != nil {
 				log.Warn().Err(err).Str("candidate", r.user.ID).Msg("failed to generate AI insights")
 			} else {
@@ -158,47 +488,195 @@ func (s *MatchService) FindMatches(userID string, limit int) ([]*MatchSuggestion
 	return suggestions, nil
 }
 
+// scoreConcurrently calls score for every index below n on up to
+// MATCH_SCORING_WORKERS (default 4) goroutines and waits for them. score must
+// only write to state owned by its index.
+func (s *MatchService) scoreConcurrently(n int, score func(i int)) {
+	workers := min(s.scoringWorkers, n)
+	next := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				score(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+}
+
+// ---------------------------------------------------------------------------
+// FindMentees
+// ---------------------------------------------------------------------------
+
+// MenteeSuggestion is returned by FindMentees.
+type MenteeSuggestion struct {
+	User            *domain.User `json:"user"`
+	Score           float64      `json:"score"`
+	TeachableSkills []string     `json:"teachable_skills"`
+}
+
+// FindMentees returns users who are learning (hold at beginner level) skills
+// the caller holds at advanced level, excluding anyone in a block with them. Candidates are ranked by how much of
+// their learning the caller can cover, then by engagement (completed
+// sessions). Results are paginated with limit/offset; the total is the number
+// of candidates before pagination.
+func (s *MatchService) FindMentees(ctx context.Context, userID string, limit, offset int) ([]*MenteeSuggestion, int64, error) {
+	db := s.db.WithContext(ctx)
+
+	var mentorSkills []domain.UserSkill
+	if err := db.Preload("Skill").
+		Where("user_id = ? AND proficiency_level = ?", userID, domain.Advanced).
+		Find(&mentorSkills).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to load skills: %w", err)
+	}
+	if len(mentorSkills) == 0 {
+		return []*MenteeSuggestion{}, 0, nil
+	}
+	teachable := make(map[uint]string, len(mentorSkills))
+	skillIDs := make([]uint, len(mentorSkills))
+	for i, sk := range mentorSkills {
+		teachable[sk.SkillID] = sk.Skill.Name
+		skillIDs[i] = sk.SkillID
+	}
+
+	// Every beginner-level skill of every user learning at least one of the
+	// caller's advanced skills.
+	var learning []domain.UserSkill
+	err := db.
+		Where("proficiency_level = ? AND user_id <> ?", domain.Beginner, userID).
+		Where("user_id NOT IN (?)", db.Model(&domain.UserBlock{}).
+			Select("CASE WHEN blocker_id = ? THEN blocked_id ELSE blocker_id END", userID).
+			Where("blocker_id = ? OR blocked_id = ?", userID, userID)).
+		Where("user_id IN (?)", db.Model(&domain.UserSkill{}).
+			Select("user_id").
+			Where("proficiency_level = ? AND skill_id IN ?", domain.Beginner, skillIDs)).
+		Find(&learning).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to load candidate skills: %w", err)
+	}
+
+	type candidate struct {
+		learningCount int
+		matched       []string
+	}
+	candidates := make(map[string]*candidate)
+	for _, us := range learning {
+		c, ok := candidates[us.UserID]
+		if !ok {
+			c = &candidate{}
+			candidates[us.UserID] = c
+		}
+		c.learningCount++
+		if name, ok := teachable[us.SkillID]; ok {
+			c.matched = append(c.matched, name)
+		}
+	}
+	if len(candidates) == 0 {
+		return []*MenteeSuggestion{}, 0, nil
+	}
+
+	ids := make([]string, 0, len(candidates))
+	for id := range candidates {
+		ids = append(ids, id)
+	}
+	var users []domain.User
+	if err := db.Preload("Skills.Skill").Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to load candidates: %w", err)
+	}
+
+	results := make([]*MenteeSuggestion, 0, len(users))
+	for i := range users {
+		c := candidates[users[i].ID]
+		// Centrality: share of the mentee's learning the caller can teach.
+		centrality := float64(len(c.matched)) / float64(c.learningCount)
+		engagement := math.Min(float64(users[i].TotalSessions)/10, 1)
+		score := centrality*70 + engagement*30
+		sort.Strings(c.matched)
+		results = append(results, &MenteeSuggestion{
+			User:            &users[i],
+			Score:           math.Round(score*100) / 100,
+			TeachableSkills: c.matched,
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].User.ID < results[j].User.ID
+	})
+
+	total := int64(len(results))
+	if offset >= len(results) {
+		return []*MenteeSuggestion{}, total, nil
+	}
+	end := offset + limit
+	if end > len(results) {
+		end = len(results)
+	}
+	return results[offset:end], total, nil
+}
+
 // ---------------------------------------------------------------------------
 // CreateMatchRequest
 // ---------------------------------------------------------------------------
 
-func (s *MatchService) CreateMatchRequest(senderID, receiverID string, message string) error {
+// CreateMatchRequest stores a pending request from senderID to receiverID and
+// returns it with the sender's profile loaded.
+func (s *MatchService) CreateMatchRequest(ctx context.Context, senderID, receiverID string, message string) (*domain.MatchRequest, error) {
+	db := s.db.WithContext(ctx)
+
 	if senderID == receiverID {
-		return ErrSelfMatch
+		return nil, ErrSelfMatch
+	}
+	if isBlocked(db, senderID, receiverID) {
+		return nil, ErrUserBlocked
 	}
 
 	// Check for existing pending request in either direction.
 	var count int64
-	s.db.Model(&domain.MatchRequest{}).
+	db.Model(&domain.MatchRequest{}).
 		Where(
 			"((sender_id = ? AND receiver_id = ?) OR (sender_id = ? AND receiver_id = ?)) AND status = ?",
 			senderID, receiverID, receiverID, senderID, domain.RequestPending,
 		).Count(&count)
 	if count > 0 {
-		return ErrMatchRequestExists
+		return nil, ErrMatchRequestExists
 	}
 
 	// Check for existing active match.
-	s.db.Model(&domain.Match{}).
-		Where(
-			"((user1_id = ? AND user2_id = ?) OR (user1_id = ? AND user2_id = ?)) AND status = ?",
-			senderID, receiverID, receiverID, senderID, domain.MatchActive,
-		).Count(&count)
+	db.Model(&domain.Match{}).Scopes(betweenUsers(senderID, receiverID)).
+		Where("status = ?", domain.MatchActive).
+		Count(&count)
 	if count > 0 {
-		return ErrMatchExists
+		return nil, ErrMatchExists
+	}
+
+	if err := s.checkPendingRequestLimit(senderID); err != nil {
+		return nil, err
+	}
+	if err := s.checkActiveMatchLimit(senderID); err != nil {
+		return nil, err
 	}
 
 	// Generate AI preview insights.
 	var previewJSON domain.JSONB
 	if s.claude != nil {
 		var sender, receiver domain.User
-		s.db.Preload("Skills.Skill").First(&sender, senderID)
-		s.db.Preload("Skills.Skill").First(&receiver, receiverID)
+		db.Preload("Skills.Skill").First(&sender, senderID)
+		db.Preload("Skills.Skill").First(&receiver, receiverID)
 
 		senderSkillNames := skillNames(sender.Skills)
 		receiverSkillNames := skillNames(receiver.Skills)
 
-		_, reasoning, err := s.claude.CalculateMatchScore(
+		_, reasoning, err := s.claude.CalculateMatchScore(ctx, 
 			senderSkillNames, receiverSkillNames,
 			sender.Bio, receiver.Bio,
 		)
@@ -221,19 +699,58 @@ func (s *MatchService) CreateMatchRequest(senderID, receiverID string, message s
 		Message:           message,
 		AIPreviewInsights: previewJSON,
 	}
-	if err := s.db.Create(&req).Error; err != nil {
-		return fmt.Errorf("failed to create match request: %w", err)
+	if err := db.Create(&req).Error; err != nil {
+		// Lost a race with a concurrent request for the same pair; see
+		// idx_match_requests_pending_pair.
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrMatchRequestExists
+		}
+		return nil, fmt.Errorf("failed to create match request: %w", err)
+	}
+
+	if err := db.First(&req.Sender, "id = ?", senderID).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch sender: %w", err)
 	}
-	return nil
+	return &req, nil
+}
+
+// ---------------------------------------------------------------------------
+// GetMatchRequest
+// ---------------------------------------------------------------------------
+
+// GetMatchRequest returns a match request with both users' profiles (skills
+// and reputation included) for its sender or receiver.
+func (s *MatchService) GetMatchRequest(ctx context.Context, requestID uint, userID string) (*domain.MatchRequest, error) {
+	var req domain.MatchRequest
+	err := s.db.WithContext(ctx).
+		Preload("Sender.Skills.Skill").Preload("Sender.Reputation").
+		Preload("Receiver.Skills.Skill").Preload("Receiver.Reputation").
+		First(&req, "id = ?", requestID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRequestNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch request: %w", err)
+	}
+
+	if req.SenderID != userID && req.ReceiverID != userID {
+		return nil, ErrNotRequestParticipant
+	}
+	if len(req.AIPreviewInsights) == 0 || string(req.AIPreviewInsights) == "null" {
+		req.AIPreviewInsights = domain.JSONB("{}")
+	}
+	return &req, nil
 }
 
 // ---------------------------------------------------------------------------
 // AcceptMatchRequest
 // ---------------------------------------------------------------------------
 
-func (s *MatchService) AcceptMatchRequest(requestID uint, userID string) (*domain.Match, error) {
+func (s *MatchService) AcceptMatchRequest(ctx context.Context, requestID uint, userID string) (*domain.Match, error) {
+	db := s.db.WithContext(ctx)
+
 	var req domain.MatchRequest
-	if err := s.db.First(&req, "id = ?", requestID).Error; err != nil {
+	if err := db.First(&req, "id = ?", requestID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrRequestNotFound
 		}
@@ -247,17 +764,24 @@ func (s *MatchService) AcceptMatchRequest(requestID uint, userID string) (*domai
 		return nil, ErrRequestNotPending
 	}
 
+	// Either side may have filled up since the request was sent.
+	for _, id := range []string{req.ReceiverID, req.SenderID} {
+		if err := s.checkActiveMatchLimit(id); err != nil {
+			return nil, err
+		}
+	}
+
 	// Calculate compatibility score for the new match.
-	score, _ := s.CalculateCompatibility(req.SenderID, req.ReceiverID)
+	score, _ := s.CalculateCompatibility(ctx, req.SenderID, req.ReceiverID)
 
 	// Generate full AI insights.
 	var insightsJSON domain.JSONB
 	if s.claude != nil {
 		var sender, receiver domain.User
-		s.db.Preload("Skills.Skill").First(&sender, req.SenderID)
-		s.db.Preload("Skills.Skill").First(&receiver, req.ReceiverID)
+		db.Preload("Skills.Skill").First(&sender, req.SenderID)
+		db.Preload("Skills.Skill").First(&receiver, req.ReceiverID)
 
-		insights, err := s.claude.GeneratePairingInsights(sender, receiver, sender.Skills, receiver.Skills)
+		insights, err := s.claude.GeneratePairingInsights(ctx, sender, receiver, sender.Skills, receiver.Skills)
 		if err != nil {
 			log.Warn().Err(err).Msg("failed to generate full AI insights on accept")
 		} else {
@@ -271,7 +795,7 @@ func (s *MatchService) AcceptMatchRequest(requestID uint, userID string) (*domai
 
 	// Use a transaction: update request + create match.
 	var match domain.Match
-	err := s.db.Transaction(func(tx *gorm.DB) error {
+	err := db.Transaction(func(tx *gorm.DB) error {
 		now := time.Now()
 		if err := tx.Model(&req).Updates(map[string]interface{}{
 			"status":       domain.RequestAccepted,
@@ -294,7 +818,7 @@ func (s *MatchService) AcceptMatchRequest(requestID uint, userID string) (*domai
 	}
 
 	// Re-load with relations.
-	s.db.Preload("User1").Preload("User2").First(&match, match.ID)
+	db.Preload("User1").Preload("User2").First(&match, match.ID)
 	return &match, nil
 }
 
@@ -302,17 +826,644 @@ func (s *MatchService) AcceptMatchRequest(requestID uint, userID string) (*domai
 // GetUserMatches
 // ---------------------------------------------------------------------------
 
-func (s *MatchService) GetUserMatches(userID string) ([]*domain.Match, error) {
-	var matches []*domain.Match
-	err := s.db.
+// MatchQueryOptions filters and orders GetUserMatches. The zero value lists
+// active matches, newest first.
+type MatchQueryOptions struct {
+	Status   string  // "active" (default), "inactive", "all" or "hidden"
+	Sort     string  // "recent" (default), "score" or "activity"
+	MinScore float64 // matches scoring below this are left out
+}
+
+var ErrInvalidMatchQuery = errors.New("status must be active, inactive, all or hidden and sort must be recent, score or activity")
+
+// MatchListItem is a match as listed by GetUserMatches, with a preview of its
+// latest message. LastMessage is nil when nothing has been sent yet.
+type MatchListItem struct {
+	*domain.Match
+	LastMessage *LastMessagePreview `json:"last_message"`
+}
+
+// LastMessagePreview is the most recent message in a match. Unread is true
+// when the caller received it and has not read it.
+type LastMessagePreview struct {
+	ID        uint               `json:"id"`
+	SenderID  string             `json:"sender_id"`
+	Content   string             `json:"content"`
+	Type      domain.MessageType `json:"type"`
+	CreatedAt time.Time          `json:"created_at"`
+	Unread    bool               `json:"unread"`
+}
+
+func (s *MatchService) GetUserMatches(ctx context.Context, userID string, opts MatchQueryOptions) ([]*MatchListItem, error) {
+	db := s.db.WithContext(ctx)
+
+	q := db.
 		Preload("User1").Preload("User2").
-		Where("(user1_id = ? OR user2_id = ?) AND status = ?", userID, userID, domain.MatchActive).
-		Order("created_at DESC").
-		Find(&matches).Error
+		Where("(matches.user1_id = ? OR matches.user2_id = ?)", userID, userID)
+
+	switch opts.Status {
+	case "", string(domain.MatchActive):
+		q = q.Scopes(notHiddenBy(userID)).Where("matches.status = ?", domain.MatchActive)
+	case string(domain.MatchInactive):
+		q = q.Scopes(notHiddenBy(userID)).Where("matches.status = ?", domain.MatchInactive)
+	case "all":
+		q = q.Scopes(notHiddenBy(userID))
+	case "hidden":
+		q = q.Where(matchHiddenBy, userID, userID)
+	default:
+		return nil, ErrInvalidMatchQuery
+	}
+
+	if opts.MinScore > 0 {
+		q = q.Where("matches.match_score >= ?", opts.MinScore)
+	}
+
+	switch opts.Sort {
+	case "", "recent":
+		q = q.Order("matches.created_at DESC")
+	case "score":
+		q = q.Order("matches.match_score DESC").Order("matches.created_at DESC")
+	case "activity":
+		// Matches without messages sort last, by when they were made. The
+		// lateral subquery only reads the listed matches' messages, through
+		// idx_messages_match_created.
+		q = q.Joins("LEFT JOIN LATERAL (SELECT MAX(created_at) AS last_message_at FROM messages WHERE messages.match_id = matches.id) activity ON true").
+			Order("activity.last_message_at DESC NULLS LAST").
+			Order("matches.created_at DESC")
+	default:
+		return nil, ErrInvalidMatchQuery
+	}
+
+	var matches []*domain.Match
+	if err := q.Find(&matches).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch matches: %w", err)
+	}
+
+	items := make([]*MatchListItem, len(matches))
+	if len(matches) == 0 {
+		return items, nil
+	}
+	ids := make([]uint, len(matches))
+	for i, m := range matches {
+		ids[i] = m.ID
+	}
+
+	// One DISTINCT ON query for every match's latest message.
+	var latest []struct {
+		MatchID uint
+		LastMessagePreview
+	}
+	if err := db.Raw(`
+		SELECT DISTINCT ON (match_id)
+			match_id, id, sender_id, content, type, created_at,
+			(receiver_id = ? AND NOT is_read) AS unread
+		FROM messages
+		WHERE match_id IN ?
+		ORDER BY match_id, created_at DESC, id DESC`, userID, ids).
+		Scan(&latest).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch last messages: %w", err)
+	}
+	byMatch := make(map[uint]*LastMessagePreview, len(latest))
+	for i := range latest {
+		byMatch[latest[i].MatchID] = &latest[i].LastMessagePreview
+	}
+
+	for i, m := range matches {
+		items[i] = &MatchListItem{Match: m, LastMessage: byMatch[m.ID]}
+	}
+	return items, nil
+}
+
+// ---------------------------------------------------------------------------
+// GetMatchSessions
+// ---------------------------------------------------------------------------
+
+// MatchSessionSummary is a coding session in a match's history, with what
+// the caller still owes for it. Code snapshots are left out.
+type MatchSessionSummary struct {
+	ID              uint       `json:"id"`
+	MatchID         uint       `json:"match_id"`
+	StartedAt       time.Time  `json:"started_at"`
+	EndedAt         *time.Time `json:"ended_at"`
+	DurationMinutes int        `json:"duration_minutes"`
+	SessionNotes    string     `json:"session_notes"`
+	SuccessRating   float64    `json:"success_rating"`
+	HasRated        bool       `json:"has_rated"`
+	HasFeedback     bool       `json:"has_feedback"`
+}
+
+// GetMatchSessions lists the coding sessions of a match, newest first, for
+// one of its participants.
+func (s *MatchService) GetMatchSessions(ctx context.Context, matchID uint, userID string) ([]MatchSessionSummary, error) {
+	db := s.db.WithContext(ctx)
+
+	var match domain.Match
+	if err := db.First(&match, matchID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMatchNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch match: %w", err)
+	}
+	if match.User1ID != userID && match.User2ID != userID {
+		return nil, ErrNotMatchParticipant
+	}
+
+	sessions := []MatchSessionSummary{}
+	err := db.Model(&domain.CodingSession{}).
+		Select(`coding_sessions.id, coding_sessions.match_id, coding_sessions.started_at,
+			coding_sessions.ended_at, coding_sessions.duration_minutes,
+			coding_sessions.session_notes, coding_sessions.success_rating,
+			EXISTS (SELECT 1 FROM ratings WHERE ratings.session_id = coding_sessions.id AND ratings.rater_id = ?) AS has_rated,
+			EXISTS (SELECT 1 FROM session_feedbacks WHERE session_feedbacks.session_id = coding_sessions.id AND session_feedbacks.user_id = ?) AS has_feedback`,
+			userID, userID).
+		Where("coding_sessions.match_id = ?", matchID).
+		Order("coding_sessions.started_at DESC").
+		Scan(&sessions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// ---------------------------------------------------------------------------
+// GetMatchSummary
+// ---------------------------------------------------------------------------
+
+// MatchSummary gathers what a match detail page shows. Insights are the
+// stored ones, nil when none have been generated; they are never generated
+// here.
+type MatchSummary struct {
+	Match        *domain.Match       `json:"match"`
+	Insights     *PairingInsights    `json:"insights"`
+	SessionCount int64               `json:"session_count"`
+	TotalMinutes int64               `json:"total_minutes"`
+	LastMessage  *LastMessagePreview `json:"last_message"`
+}
+
+// GetMatchSummary returns the match with both participants, its stored
+// insights, session totals and latest message, for one of its participants.
+func (s *MatchService) GetMatchSummary(ctx context.Context, matchID uint, userID string) (*MatchSummary, error) {
+	db := s.db.WithContext(ctx)
+
+	var match domain.Match
+	if err := db.Preload("User1.Skills.Skill").Preload("User2.Skills.Skill").
+		First(&match, matchID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMatchNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch match: %w", err)
+	}
+	if match.User1ID != userID && match.User2ID != userID {
+		return nil, ErrNotMatchParticipant
+	}
+
+	summary := &MatchSummary{Match: &match}
+
+	var insights PairingInsights
+	if len(match.AIInsights) > 0 {
+		if err := json.Unmarshal(match.AIInsights, &insights); err == nil && insights.OverallReasoning != "" {
+			summary.Insights = &insights
+		}
+	}
+
+	var totals struct {
+		Count   int64
+		Minutes int64
+	}
+	if err := db.Model(&domain.CodingSession{}).
+		Select("COUNT(*) AS count, COALESCE(SUM(duration_minutes), 0) AS minutes").
+		Where("match_id = ?", matchID).
+		Scan(&totals).Error; err != nil {
+		return nil, fmt.Errorf("failed to count sessions: %w", err)
+	}
+	summary.SessionCount = totals.Count
+	summary.TotalMinutes = totals.Minutes
+
+	var last []LastMessagePreview
+	if err := db.Raw(`
+		SELECT id, sender_id, content, type, created_at,
+			(receiver_id = ? AND NOT is_read) AS unread
+		FROM messages
+		WHERE match_id = ?
+		ORDER BY created_at DESC, id DESC
+		LIMIT 1`, userID, matchID).
+		Scan(&last).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch last message: %w", err)
+	}
+	if len(last) > 0 {
+		summary.LastMessage = &last[0]
+	}
+
+	return summary, nil
+}
+
+// ---------------------------------------------------------------------------
+// GetMatchWith
+// ---------------------------------------------------------------------------
+
+// betweenUsers restricts a matches query to matches between a and b, in
+// either user1/user2 order.
+func betweenUsers(a, b string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("((user1_id = ? AND user2_id = ?) OR (user1_id = ? AND user2_id = ?))", a, b, b, a)
+	}
+}
+
+// GetMatchWith returns userID's match with otherID, with both users loaded.
+// The active match wins; with includeArchived the most recent archived match
+// is returned when there is no active one. Hidden matches are never returned.
+func (s *MatchService) GetMatchWith(ctx context.Context, userID, otherID string, includeArchived bool) (*domain.Match, error) {
+	q := s.db.WithContext(ctx).Preload("User1").Preload("User2").Scopes(betweenUsers(userID, otherID), notHiddenBy(userID))
+	if includeArchived {
+		q = q.Order(clause.Expr{SQL: "status = ? DESC", Vars: []interface{}{domain.MatchActive}})
+	} else {
+		q = q.Where("status = ?", domain.MatchActive)
+	}
+
+	var match domain.Match
+	if err := q.Order("created_at DESC").Order("id DESC").First(&match).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMatchNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch match: %w", err)
+	}
+	return &match, nil
+}
+
+// ---------------------------------------------------------------------------
+// GetLatestDigest
+// ---------------------------------------------------------------------------
+
+// GetLatestDigest returns the most recent digest stored by DigestWorker.
+func (s *MatchService) GetLatestDigest(ctx context.Context, userID string) (*domain.MatchDigest, error) {
+	var digest domain.MatchDigest
+	err := s.db.WithContext(ctx).Where("user_id = ?", userID).Order("digest_date DESC").First(&digest).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrDigestNotFound
+	}
 	if err != nil {
+		return nil, fmt.Errorf("failed to fetch match digest: %w", err)
+	}
+	return &digest, nil
+}
+
+// ---------------------------------------------------------------------------
+// GetNetwork
+// ---------------------------------------------------------------------------
+
+const (
+	maxNetworkPartners = 100
+	maxNetworkMutuals  = 200
+)
+
+// NetworkGraph is a user's match history shaped for graph rendering.
+// Mutuals lists the IDs of users the caller's partners are actively matched
+// with; other users' matches are never included as nodes or edges.
+type NetworkGraph struct {
+	Nodes     []NetworkNode `json:"nodes"`
+	Edges     []NetworkEdge `json:"edges"`
+	Mutuals   []string      `json:"mutuals,omitempty"`
+	Truncated bool          `json:"truncated"`
+}
+
+// NetworkNode is a user in the graph. Degree is 0 for the caller and 1 for
+// people they matched with.
+type NetworkNode struct {
+	ID        string `json:"id"`
+	Username  string `json:"username"`
+	FullName  string `json:"full_name"`
+	AvatarURL string `json:"avatar_url"`
+	Degree    int    `json:"degree"`
+}
+
+// NetworkEdge is one of the caller's matches.
+type NetworkEdge struct {
+	Source            string             `json:"source"`
+	Target            string             `json:"target"`
+	MatchID           uint               `json:"match_id"`
+	Status            domain.MatchStatus `json:"status"`
+	MatchScore        float64            `json:"match_score"`
+	Sessions          int64              `json:"sessions"`
+	CompletedSessions int64              `json:"completed_sessions"`
+	AvgSuccessRating  float64            `json:"avg_success_rating"`
+}
+
+// GetNetwork returns the caller's matches (active and past) as a graph, with
+// per-match session stats. With includeMutuals it also lists the IDs of
+// users the caller's partners are actively matched with. Blocked users are
+// left out and both levels are capped; Truncated reports whether a cap was
+// hit.
+func (s *MatchService) GetNetwork(ctx context.Context, userID string, includeMutuals bool) (*NetworkGraph, error) {
+	db := s.db.WithContext(ctx)
+
+	blocked := make(map[string]bool)
+	for _, id := range blockedUserIDs(db, userID) {
+		blocked[id] = true
+	}
+
+	var matches []domain.Match
+	if err := db.
+		Where("user1_id = ? OR user2_id = ?", userID, userID).
+		Scopes(notHiddenBy(userID)).
+		Order("created_at DESC").
+		Limit(maxNetworkPartners + 1).
+		Find(&matches).Error; err != nil {
 		return nil, fmt.Errorf("failed to fetch matches: %w", err)
 	}
-	return matches, nil
+
+	graph := &NetworkGraph{Nodes: []NetworkNode{}, Edges: []NetworkEdge{}}
+	if len(matches) > maxNetworkPartners {
+		matches = matches[:maxNetworkPartners]
+		graph.Truncated = true
+	}
+
+	degree := map[string]int{userID: 0}
+	matchIDs := make([]uint, 0, len(matches))
+	for _, m := range matches {
+		other := m.User1ID
+		if other == userID {
+			other = m.User2ID
+		}
+		if blocked[other] {
+			continue
+		}
+		if _, ok := degree[other]; !ok {
+			degree[other] = 1
+		}
+		matchIDs = append(matchIDs, m.ID)
+		graph.Edges = append(graph.Edges, NetworkEdge{
+			Source:     userID,
+			Target:     other,
+			MatchID:    m.ID,
+			Status:     m.Status,
+			MatchScore: m.MatchScore,
+		})
+	}
+
+	// Session stats for the caller's matches in one grouped query.
+	if len(matchIDs) > 0 {
+		type stat struct {
+			MatchID    uint
+			Total      int64
+			Completed  int64
+			AvgSuccess float64
+		}
+		var stats []stat
+		if err := db.Model(&domain.CodingSession{}).
+			Select("match_id, COUNT(*) AS total, COUNT(ended_at) AS completed, COALESCE(AVG(success_rating) FILTER (WHERE ended_at IS NOT NULL), 0) AS avg_success").
+			Where("match_id IN ?", matchIDs).
+			Group("match_id").
+			Scan(&stats).Error; err != nil {
+			return nil, fmt.Errorf("failed to aggregate sessions: %w", err)
+		}
+		byMatch := make(map[uint]stat, len(stats))
+		for _, st := range stats {
+			byMatch[st.MatchID] = st
+		}
+		for i := range graph.Edges {
+			st := byMatch[graph.Edges[i].MatchID]
+			graph.Edges[i].Sessions = st.Total
+			graph.Edges[i].CompletedSessions = st.Completed
+			graph.Edges[i].AvgSuccessRating = math.Round(st.AvgSuccess*100) / 100
+		}
+	}
+
+	if includeMutuals && len(degree) > 1 {
+		partners := make([]string, 0, len(degree)-1)
+		for id, d := range degree {
+			if d == 1 {
+				partners = append(partners, id)
+			}
+		}
+
+		var second []domain.Match
+		if err := db.Select("user1_id", "user2_id").
+			Where("(user1_id IN ? OR user2_id IN ?) AND user1_id <> ? AND user2_id <> ? AND status = ?",
+				partners, partners, userID, userID, domain.MatchActive).
+			Order("created_at DESC").
+			Limit(maxNetworkMutuals + 1).
+			Find(&second).Error; err != nil {
+			return nil, fmt.Errorf("failed to fetch mutual matches: %w", err)
+		}
+		if len(second) > maxNetworkMutuals {
+			second = second[:maxNetworkMutuals]
+			graph.Truncated = true
+		}
+		seen := make(map[string]bool)
+		for _, m := range second {
+			for _, id := range []string{m.User1ID, m.User2ID} {
+				if _, known := degree[id]; known || blocked[id] || seen[id] {
+					continue
+				}
+				seen[id] = true
+				graph.Mutuals = append(graph.Mutuals, id)
+			}
+		}
+		sort.Strings(graph.Mutuals)
+	}
+
+	ids := make([]string, 0, len(degree))
+	for id := range degree {
+		ids = append(ids, id)
+	}
+	var users []domain.User
+	if err := db.Select("id", "username", "full_name", "avatar_url").
+		Where("id IN ?", ids).
+		Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch users: %w", err)
+	}
+	for _, u := range users {
+		graph.Nodes = append(graph.Nodes, NetworkNode{
+			ID:        u.ID,
+			Username:  u.Username,
+			FullName:  u.FullName,
+			AvatarURL: u.AvatarURL,
+			Degree:    degree[u.ID],
+		})
+	}
+	sort.Slice(graph.Nodes, func(i, j int) bool {
+		if graph.Nodes[i].Degree != graph.Nodes[j].Degree {
+			return graph.Nodes[i].Degree < graph.Nodes[j].Degree
+		}
+		return graph.Nodes[i].Username < graph.Nodes[j].Username
+	})
+
+	return graph, nil
+}
+
+// ---------------------------------------------------------------------------
+// Unmatch
+// ---------------------------------------------------------------------------
+
+// Unmatch archives a match on behalf of one of its participants: it becomes
+// inactive, stays in both users' history with its messages, and any coding
+// session still running on it is ended. Callers holding live connections for
+// the match (the websocket hub) are responsible for closing them.
+func (s *MatchService) Unmatch(ctx context.Context, matchID uint, userID string) error {
+	db := s.db.WithContext(ctx)
+
+	match, err := s.participantMatch(db, matchID, userID)
+	if err != nil {
+		return err
+	}
+	if match.Status != domain.MatchActive {
+		return ErrMatchNotActive
+	}
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		return archiveMatch(tx, match, userID)
+	}); err != nil {
+		return fmt.Errorf("failed to unmatch: %w", err)
+	}
+	return nil
+}
+
+// DeleteMatch hides a match from userID's list, archiving it first if it is
+// still active. The other participant still sees it archived, and nothing is
+// removed: its messages stay readable to both and RestoreMatch brings it
+// back.
+func (s *MatchService) DeleteMatch(ctx context.Context, matchID uint, userID string) error {
+	db := s.db.WithContext(ctx)
+
+	match, err := s.participantMatch(db, matchID, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if match.Status == domain.MatchActive {
+			if err := archiveMatch(tx, match, userID); err != nil {
+				return err
+			}
+		}
+		column, _ := hiddenBy(match, userID)
+		return tx.Model(match).Update(column, time.Now()).Error
+	}); err != nil {
+		return fmt.Errorf("failed to delete match: %w", err)
+	}
+	return nil
+}
+
+// RestoreMatch undoes DeleteMatch for userID. A match userID archived is
+// also reactivated, so sessions and chat work again; one the other
+// participant archived only comes back into userID's list, since reopening
+// it needs the partner's agreement through a new match request. It returns
+// ErrMatchActive for a match that is active and not hidden, ErrNotArchiver
+// for one that is neither hidden nor archived by userID, ErrUserBlocked if
+// either user has blocked the other, ErrMatchExists if the pair has matched
+// again since, and a *MatchLimitError if either user is at their active
+// match limit.
+func (s *MatchService) RestoreMatch(ctx context.Context, matchID uint, userID string) (*domain.Match, error) {
+	db := s.db.WithContext(ctx)
+
+	match, err := s.participantMatch(db, matchID, userID)
+	if err != nil {
+		return nil, err
+	}
+	column, hiddenAt := hiddenBy(match, userID)
+	if match.Status == domain.MatchActive && hiddenAt == nil {
+		return nil, ErrMatchActive
+	}
+
+	archiver := match.Status != domain.MatchActive && match.ArchivedBy != nil && *match.ArchivedBy == userID
+	if match.Status != domain.MatchActive && !archiver && hiddenAt == nil {
+		return nil, ErrNotArchiver
+	}
+
+	updates := map[string]interface{}{column: nil}
+	if archiver {
+		if isBlocked(db, match.User1ID, match.User2ID) {
+			return nil, ErrUserBlocked
+		}
+		partnerID := match.User1ID
+		if partnerID == userID {
+			partnerID = match.User2ID
+		}
+		var partner domain.User
+		if err := db.Select("id").First(&partner, "id = ?", partnerID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrUserNotFound
+			}
+			return nil, fmt.Errorf("failed to fetch partner: %w", err)
+		}
+		var count int64
+		if err := db.Model(&domain.Match{}).Scopes(betweenUsers(match.User1ID, match.User2ID)).
+			Where("status = ? AND id <> ?", domain.MatchActive, match.ID).
+			Count(&count).Error; err != nil {
+			return nil, fmt.Errorf("failed to check existing matches: %w", err)
+		}
+		if count > 0 {
+			return nil, ErrMatchExists
+		}
+		for _, id := range []string{match.User1ID, match.User2ID} {
+			if err := s.checkActiveMatchLimit(id); err != nil {
+				return nil, err
+			}
+		}
+		updates["status"] = domain.MatchActive
+		updates["archived_by"] = nil
+	}
+
+	if err := db.Model(match).Updates(updates).Error; err != nil {
+		return nil, fmt.Errorf("failed to restore match: %w", err)
+	}
+	if err := db.Preload("User1").Preload("User2").First(match, match.ID).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch match: %w", err)
+	}
+	return match, nil
+}
+
+// hiddenBy returns the column recording whether userID hid match, and its
+// value.
+func hiddenBy(match *domain.Match, userID string) (string, *time.Time) {
+	if match.User1ID == userID {
+		return "user1_hidden_at", match.User1HiddenAt
+	}
+	return "user2_hidden_at", match.User2HiddenAt
+}
+
+// matchHiddenBy is the condition, taking the user ID twice, for matches
+// that user deleted from their list.
+const matchHiddenBy = "((matches.user1_id = ? AND matches.user1_hidden_at IS NOT NULL) OR (matches.user2_id = ? AND matches.user2_hidden_at IS NOT NULL))"
+
+// notHiddenBy keeps matches that userID hasn't deleted from their list.
+func notHiddenBy(userID string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("NOT "+matchHiddenBy, userID, userID)
+	}
+}
+
+// participantMatch loads matchID through db and checks that userID takes
+// part in it.
+func (s *MatchService) participantMatch(db *gorm.DB, matchID uint, userID string) (*domain.Match, error) {
+	var match domain.Match
+	if err := db.First(&match, matchID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMatchNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch match: %w", err)
+	}
+	if match.User1ID != userID && match.User2ID != userID {
+		return nil, ErrNotMatchParticipant
+	}
+	return &match, nil
+}
+
+// archiveMatch marks match inactive and ends any session still open on it.
+func archiveMatch(tx *gorm.DB, match *domain.Match, userID string) error {
+	now := time.Now()
+	if err := tx.Model(match).Updates(map[string]interface{}{
+		"status":      domain.MatchInactive,
+		"archived_by": userID,
+	}).Error; err != nil {
+		return err
+	}
+	return tx.Model(&domain.CodingSession{}).
+		Where("match_id = ? AND ended_at IS NULL", match.ID).
+		Updates(map[string]interface{}{
+			"ended_at":         now,
+			"duration_minutes": gorm.Expr("FLOOR(EXTRACT(EPOCH FROM (? - started_at)) / 60)", now),
+		}).Error
 }
 
 // ---------------------------------------------------------------------------
@@ -363,27 +1514,59 @@ func goalsAlignment(u1, u2 domain.User) float64 {
 	return 65
 }
 
-// complementaryScore rewards users whose skills do NOT overlap, so they can
-// teach each other.
+// complementaryScore rewards pairs where one user teaches what the other
+// wants to learn, counted in both directions. A teach skill only covers a
+// learn skill when the teacher is more proficient than the learner. The score
+// is the share of both users' learn skills that the other can cover.
 func complementaryScore(s1, s2 []domain.UserSkill) float64 {
-	if len(s1) == 0 || len(s2) == 0 {
+	wanted := learnCount(s1) + learnCount(s2)
+	if wanted == 0 {
 		return 30
 	}
 
-	set1 := make(map[uint]bool, len(s1))
-	for _, sk := range s1 {
-		set1[sk.SkillID] = true
+	covered := len(teachingOpportunities(s1, s2)) + len(teachingOpportunities(s2, s1))
+	return float64(covered) / float64(wanted) * 100
+}
+
+func learnCount(skills []domain.UserSkill) int {
+	n := 0
+	for _, sk := range skills {
+		if sk.Learns() {
+			n++
+		}
 	}
+	return n
+}
 
-	var unique int
-	for _, sk := range s2 {
-		if !set1[sk.SkillID] {
-			unique++
+// teachingOpportunities returns the learner's skills the teacher can teach.
+func teachingOpportunities(teacher, learner []domain.UserSkill) []domain.UserSkill {
+	teaches := make(map[uint]domain.ProficiencyLevel, len(teacher))
+	for _, sk := range teacher {
+		if sk.Teaches() {
+			teaches[sk.SkillID] = sk.ProficiencyLevel
+		}
+	}
+
+	var out []domain.UserSkill
+	for _, sk := range learner {
+		level, ok := teaches[sk.SkillID]
+		if ok && sk.Learns() && proficiencyRank(level) > proficiencyRank(sk.ProficiencyLevel) {
+			out = append(out, sk)
 		}
 	}
+	return out
+}
 
-	total := len(s2)
-	return (float64(unique) / float64(total)) * 100
+func proficiencyRank(l domain.ProficiencyLevel) int {
+	switch l {
+	case domain.Beginner:
+		return 1
+	case domain.Intermediate:
+		return 2
+	case domain.Advanced:
+		return 3
+	}
+	return 0
 }
 
 // reputationCompatibility returns 0-100 based on how close two users'
@@ -396,8 +1579,53 @@ func reputationCompatibility(r1, r2 domain.UserReputation) float64 {
 	return 100 - diff
 }
 
-// classifySkills splits skills into common and complementary lists.
-func classifySkills(s1, s2 []domain.UserSkill) (common, complementary []string) {
+// cadenceCompatibility returns 0-100 based on how close two users' preferred
+// pairing frequency, session length and timezones are. Unset preferences are
+// neutral.
+func cadenceCompatibility(u1, u2 domain.User) float64 {
+	cadence := 50.0
+	i, j := cadenceIndex(u1.PreferredCadence), cadenceIndex(u2.PreferredCadence)
+	if i >= 0 && j >= 0 {
+		steps := math.Abs(float64(i - j))
+		cadence = 100 - steps*100/float64(len(domain.Cadences)-1)
+	}
+
+	length := 50.0
+	if u1.PreferredSessionMinutes > 0 && u2.PreferredSessionMinutes > 0 {
+		diff := math.Abs(float64(u1.PreferredSessionMinutes - u2.PreferredSessionMinutes))
+		length = math.Max(0, 100-diff/90*100)
+	}
+
+	return cadence*0.5 + length*0.2 + timezoneProximity(u1.Timezone, u2.Timezone)*0.3
+}
+
+// timezoneProximity returns 100 for users in the same UTC offset, falling
+// linearly to 0 at 12 hours apart. Unset or unknown timezones are neutral.
+func timezoneProximity(tz1, tz2 string) float64 {
+	o1, err1 := utcOffsetHours(tz1)
+	o2, err2 := utcOffsetHours(tz2)
+	if err1 != nil || err2 != nil {
+		return 50
+	}
+	diff := math.Abs(o1 - o2)
+	if diff > 12 {
+		diff = 24 - diff
+	}
+	return 100 - diff/12*100
+}
+
+func cadenceIndex(c domain.Cadence) int {
+	for i, v := range domain.Cadences {
+		if v == c {
+			return i
+		}
+	}
+	return -1
+}
+
+// classifySkills splits skills into common and complementary lists, and
+// labels the teaching opportunities between the two users in each direction.
+func classifySkills(s1, s2 []domain.UserSkill) (common, complementary []string, teaching []TeachingOpportunity) {
 	nameByID := make(map[uint]string)
 	set1 := make(map[uint]bool)
 
@@ -446,7 +1674,22 @@ func classifySkills(s1, s2 []domain.UserSkill) (common, complementary []string)
 	if complementary == nil {
 		complementary = []string{}
 	}
-	return common, complementary
+
+	teaching = []TeachingOpportunity{}
+	for _, sk := range teachingOpportunities(s1, s2) {
+		teaching = append(teaching, TeachingOpportunity{Skill: nameByID[sk.SkillID], TeacherID: firstUserID(s1), LearnerID: sk.UserID})
+	}
+	for _, sk := range teachingOpportunities(s2, s1) {
+		teaching = append(teaching, TeachingOpportunity{Skill: nameByID[sk.SkillID], TeacherID: firstUserID(s2), LearnerID: sk.UserID})
+	}
+	return common, complementary, teaching
+}
+
+func firstUserID(skills []domain.UserSkill) string {
+	if len(skills) == 0 {
+		return ""
+	}
+	return skills[0].UserID
 }
 
 // skillNames extracts bare skill name strings from UserSkill relations.
@@ -0,0 +1,539 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/yourusername/skillsync/internal/domain"
+)
+
+// benchCandidates builds n users with overlapping skill sets, as FindMatches
+// has them after preloading.
+func benchCandidates(n int) []domain.User {
+	levels := []domain.ProficiencyLevel{domain.Beginner, domain.Intermediate, domain.Advanced}
+	directions := []domain.SkillDirection{domain.DirectionTeach, domain.DirectionLearn, domain.DirectionBoth}
+	users := make([]domain.User, n)
+	for i := range users {
+		u := domain.User{ID: fmt.Sprintf("user-%04d", i), Bio: "bio"}
+		for k := 0; k < 6; k++ {
+			id := uint((i*7 + k*3) % 40)
+			u.Skills = append(u.Skills, domain.UserSkill{
+				SkillID:          id,
+				ProficiencyLevel: levels[(i+k)%3],
+				Direction:        directions[(i*k)%3],
+				Skill:            domain.Skill{ID: id, Name: "skill-" + strconv.Itoa(int(id))},
+			})
+		}
+		users[i] = u
+	}
+	return users
+}
+
+// BenchmarkScoreCandidates scores a full candidate pool (limit 50 times the
+// default multiplier) from preloaded data, sequentially and on the default
+// worker pool.
+func BenchmarkScoreCandidates(b *testing.B) {
+	candidates := benchCandidates(50 * defaultMatchCandidateMultiplier)
+	user := candidates[0]
+	reps := make(map[string]domain.UserReputation, len(candidates))
+	for i, c := range candidates {
+		reps[c.ID] = domain.UserReputation{UserID: c.ID, OverallScore: float64(i % 100), TotalRatings: i % 20}
+	}
+
+	for _, workers := range []int{1, defaultMatchScoringWorkers} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			s := &MatchService{scoring: DefaultScoringConfig(), scoringWorkers: workers}
+			results := make([]float64, len(candidates))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				s.scoreConcurrently(len(candidates), func(j int) {
+					results[j] = compatibilityBreakdown(s.scoring, user, candidates[j], reps[user.ID], reps[candidates[j].ID]).Total
+				})
+			}
+		})
+	}
+}
+
+// BenchmarkFindMatches compares FindMatches, which batch-loads candidates
+// and reputations, with scoring the same pool one pair at a time through
+// ExplainCompatibility, as FindMatches did before. Needs TEST_DATABASE_URL.
+func BenchmarkFindMatches(b *testing.B) {
+	db := testDB(b)
+	skills := make([]*domain.Skill, 8)
+	for i := range skills {
+		skills[i] = createTestSkill(b, db)
+	}
+	levels := []domain.ProficiencyLevel{domain.Beginner, domain.Intermediate, domain.Advanced}
+
+	const limit = 20
+	users := make([]*domain.User, limit*defaultMatchCandidateMultiplier+1)
+	for i := range users {
+		users[i] = createTestUser(b, db)
+		for k := 0; k < 3; k++ {
+			skill := skills[(i+k*3)%len(skills)]
+			addTestSkill(b, db, users[i], skill, levels[(i+k)%3], domain.DirectionBoth)
+		}
+	}
+	caller := users[0]
+
+	ctx := context.Background()
+	s := NewMatchService(db, nil, DefaultScoringConfig())
+
+	b.Run("per-candidate", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, c := range users[1:] {
+				if _, err := s.ExplainCompatibility(ctx, caller.ID, c.ID); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+	b.Run("batched", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := s.FindMatches(ctx, caller.ID, limit); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestCompatibilityScoreSymmetric(t *testing.T) {
+	users := benchCandidates(12)
+	scoring := DefaultScoringConfig()
+	for i := range users {
+		for j := range users {
+			ab := compatibilityScore(scoring, users[i], users[j], domain.UserReputation{OverallScore: 40}, domain.UserReputation{OverallScore: 70})
+			ba := compatibilityScore(scoring, users[j], users[i], domain.UserReputation{OverallScore: 70}, domain.UserReputation{OverallScore: 40})
+			if math.Abs(ab-ba) > 1e-9 {
+				t.Fatalf("score(%s, %s) = %v but score(%s, %s) = %v", users[i].ID, users[j].ID, ab, users[j].ID, users[i].ID, ba)
+			}
+		}
+	}
+}
+
+// complementaryScore feeds every scoring path (FindMatches, pairwise
+// compatibility and the group matrix), so pin down how it counts coverage.
+func TestComplementaryScore(t *testing.T) {
+	skill := func(id uint, level domain.ProficiencyLevel, dir domain.SkillDirection) domain.UserSkill {
+		return domain.UserSkill{SkillID: id, ProficiencyLevel: level, Direction: dir}
+	}
+
+	tests := []struct {
+		name   string
+		s1, s2 []domain.UserSkill
+		want   float64
+	}{
+		{
+			name: "nobody learns",
+			s1:   []domain.UserSkill{skill(1, domain.Advanced, domain.DirectionTeach)},
+			s2:   []domain.UserSkill{skill(2, domain.Advanced, domain.DirectionTeach)},
+			want: 30,
+		},
+		{
+			name: "one way covered",
+			s1:   []domain.UserSkill{skill(1, domain.Advanced, domain.DirectionTeach)},
+			s2:   []domain.UserSkill{skill(1, domain.Beginner, domain.DirectionLearn)},
+			want: 100,
+		},
+		{
+			name: "both ways covered",
+			s1:   []domain.UserSkill{skill(1, domain.Advanced, domain.DirectionTeach), skill(2, domain.Beginner, domain.DirectionLearn)},
+			s2:   []domain.UserSkill{skill(1, domain.Beginner, domain.DirectionLearn), skill(2, domain.Intermediate, domain.DirectionTeach)},
+			want: 100,
+		},
+		{
+			name: "half of the learn skills covered",
+			s1:   []domain.UserSkill{skill(1, domain.Advanced, domain.DirectionTeach), skill(2, domain.Beginner, domain.DirectionLearn)},
+			s2:   []domain.UserSkill{skill(1, domain.Beginner, domain.DirectionLearn)},
+			want: 50,
+		},
+		{
+			name: "teacher not more proficient",
+			s1:   []domain.UserSkill{skill(1, domain.Intermediate, domain.DirectionTeach)},
+			s2:   []domain.UserSkill{skill(1, domain.Intermediate, domain.DirectionLearn)},
+			want: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := complementaryScore(tt.s1, tt.s2); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("complementaryScore(s1, s2) = %v, want %v", got, tt.want)
+			}
+			if got := complementaryScore(tt.s2, tt.s1); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("complementaryScore(s2, s1) = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompatibilityMatrix(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	s := NewMatchService(db, nil, DefaultScoringConfig())
+
+	skills := []*domain.Skill{createTestSkill(t, db), createTestSkill(t, db), createTestSkill(t, db)}
+	levels := []domain.ProficiencyLevel{domain.Beginner, domain.Intermediate, domain.Advanced}
+	directions := []domain.SkillDirection{domain.DirectionTeach, domain.DirectionLearn, domain.DirectionBoth}
+	caller := createTestUser(t, db)
+	ids := make([]string, 4)
+	for i := range ids {
+		u := createTestUser(t, db)
+		for k, sk := range skills[:i%3+1] {
+			addTestSkill(t, db, u, sk, levels[(i+k)%3], directions[(i+k)%3])
+		}
+		ids[i] = u.ID
+	}
+
+	// Duplicates are dropped.
+	m, err := s.CompatibilityMatrix(ctx, caller.ID, append(ids, ids[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.UserIDs) != len(ids) || len(m.Scores) != len(ids) {
+		t.Fatalf("matrix is %dx%d, want %dx%d", len(m.UserIDs), len(m.Scores), len(ids), len(ids))
+	}
+	for i := range m.UserIDs {
+		if m.Scores[i][i] != 0 {
+			t.Errorf("diagonal [%d][%d] = %v, want 0", i, i, m.Scores[i][i])
+		}
+		for j := i + 1; j < len(m.UserIDs); j++ {
+			if m.Scores[i][j] != m.Scores[j][i] {
+				t.Errorf("[%d][%d] = %v but [%d][%d] = %v", i, j, m.Scores[i][j], j, i, m.Scores[j][i])
+			}
+			want, err := s.CalculateCompatibility(ctx, m.UserIDs[i], m.UserIDs[j])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(m.Scores[i][j]-want) > 1e-9 {
+				t.Errorf("[%d][%d] = %v, CalculateCompatibility = %v", i, j, m.Scores[i][j], want)
+			}
+		}
+	}
+
+	if _, err := s.CompatibilityMatrix(ctx, caller.ID, ids[:1]); err != ErrMatrixSize {
+		t.Fatalf("single user: %v, want %v", err, ErrMatrixSize)
+	}
+}
+
+func TestFindMenteesRanksBeginnerLearningCallerSkill(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	s := NewMatchService(db, nil, DefaultScoringConfig())
+
+	taught, other1, other2 := createTestSkill(t, db), createTestSkill(t, db), createTestSkill(t, db)
+	mentor := createTestUser(t, db)
+	addTestSkill(t, db, mentor, taught, domain.Advanced, domain.DirectionTeach)
+
+	// Learning only the mentor's skill: all of their goals are covered.
+	focused := createTestUser(t, db)
+	addTestSkill(t, db, focused, taught, domain.Beginner, domain.DirectionLearn)
+
+	// Learning it among several others.
+	broad := createTestUser(t, db)
+	addTestSkill(t, db, broad, taught, domain.Beginner, domain.DirectionLearn)
+	addTestSkill(t, db, broad, other1, domain.Beginner, domain.DirectionLearn)
+	addTestSkill(t, db, broad, other2, domain.Beginner, domain.DirectionLearn)
+
+	// Already past beginner.
+	intermediate := createTestUser(t, db)
+	addTestSkill(t, db, intermediate, taught, domain.Intermediate, domain.DirectionLearn)
+
+	blocked := createTestUser(t, db)
+	addTestSkill(t, db, blocked, taught, domain.Beginner, domain.DirectionLearn)
+	block := domain.UserBlock{BlockerID: blocked.ID, BlockedID: mentor.ID}
+	if err := db.Create(&block).Error; err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Delete(&domain.UserBlock{}, block.ID) })
+
+	got, total, err := s.FindMentees(ctx, mentor.ID, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 2 || len(got) != 2 {
+		t.Fatalf("got %d of %d mentees, want 2 of 2", len(got), total)
+	}
+	if got[0].User.ID != focused.ID || got[1].User.ID != broad.ID {
+		t.Fatalf("ranking = %s, %s; want focused beginner first", got[0].User.Username, got[1].User.Username)
+	}
+	if got[0].Score < 70 || got[0].Score <= got[1].Score {
+		t.Fatalf("scores = %v, %v", got[0].Score, got[1].Score)
+	}
+	if len(got[0].TeachableSkills) != 1 || got[0].TeachableSkills[0] != taught.Name {
+		t.Fatalf("teachable skills = %v, want [%s]", got[0].TeachableSkills, taught.Name)
+	}
+
+	page, total, err := s.FindMentees(ctx, mentor.ID, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 2 || len(page) != 1 || page[0].User.ID != broad.ID {
+		t.Fatalf("second page = %d results of %d", len(page), total)
+	}
+}
+
+func TestUnmatchEndsRunningSession(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	s := NewMatchService(db, nil, DefaultScoringConfig())
+
+	a, b := createTestUser(t, db), createTestUser(t, db)
+	match := createTestMatch(t, db, a, b)
+	running := domain.CodingSession{MatchID: match.ID, StartedAt: time.Now().Add(-30 * time.Minute)}
+	if err := db.Create(&running).Error; err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Delete(&domain.CodingSession{}, running.ID) })
+
+	if err := s.Unmatch(ctx, match.ID, b.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	var got domain.Match
+	db.First(&got, match.ID)
+	if got.Status != domain.MatchInactive {
+		t.Fatalf("match status = %s, want %s", got.Status, domain.MatchInactive)
+	}
+	var session domain.CodingSession
+	db.First(&session, running.ID)
+	if session.EndedAt == nil || session.DurationMinutes < 29 {
+		t.Fatalf("session ended_at=%v duration=%d, want ended after ~30 minutes", session.EndedAt, session.DurationMinutes)
+	}
+
+	if err := s.Unmatch(ctx, match.ID, a.ID); err != ErrMatchNotActive {
+		t.Fatalf("second Unmatch = %v, want %v", err, ErrMatchNotActive)
+	}
+}
+
+// Only the participant who archived a match can reactivate it; the other
+// one just gets it back in their list.
+func TestRestoreMatchReactivatesOnlyForArchiver(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	s := NewMatchService(db, nil, DefaultScoringConfig())
+
+	a, b := createTestUser(t, db), createTestUser(t, db)
+	match := createTestMatch(t, db, a, b)
+	if err := s.DeleteMatch(ctx, match.ID, a.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.RestoreMatch(ctx, match.ID, b.ID); err != ErrNotArchiver {
+		t.Fatalf("partner RestoreMatch = %v, want %v", err, ErrNotArchiver)
+	}
+
+	if err := s.DeleteMatch(ctx, match.ID, b.ID); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := s.RestoreMatch(ctx, match.ID, b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.Status != domain.MatchInactive || restored.User2HiddenAt != nil {
+		t.Fatalf("partner restore: status %s, hidden %v; want unhidden and inactive", restored.Status, restored.User2HiddenAt)
+	}
+
+	restored, err = s.RestoreMatch(ctx, match.ID, a.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.Status != domain.MatchActive || restored.ArchivedBy != nil {
+		t.Fatalf("archiver restore: status %s, archived by %v; want active", restored.Status, restored.ArchivedBy)
+	}
+}
+
+func TestCadenceMismatchLowersScore(t *testing.T) {
+	base := benchCandidates(2)
+	scoring := DefaultScoringConfig()
+	score := func(c1, c2 domain.Cadence, m1, m2 int) CompatibilityBreakdown {
+		u1, u2 := base[0], base[1]
+		u1.PreferredCadence, u2.PreferredCadence = c1, c2
+		u1.PreferredSessionMinutes, u2.PreferredSessionMinutes = m1, m2
+		return compatibilityBreakdown(scoring, u1, u2, domain.UserReputation{}, domain.UserReputation{})
+	}
+
+	same := score(domain.CadenceDaily, domain.CadenceDaily, 60, 60)
+	near := score(domain.CadenceDaily, domain.CadenceFewPerWeek, 60, 60)
+	far := score(domain.CadenceDaily, domain.CadenceMonthly, 60, 60)
+	if !(same.Cadence > near.Cadence && near.Cadence > far.Cadence) {
+		t.Fatalf("cadence component: same %v, near %v, far %v", same.Cadence, near.Cadence, far.Cadence)
+	}
+	if !(same.Total > far.Total) {
+		t.Fatalf("total: same cadence %v, mismatched %v", same.Total, far.Total)
+	}
+
+	if long := score(domain.CadenceDaily, domain.CadenceDaily, 30, 120); long.Total >= same.Total {
+		t.Fatalf("session length mismatch scored %v, matched %v", long.Total, same.Total)
+	}
+
+	unset := score("", domain.CadenceMonthly, 0, 60)
+	if unset.Cadence <= far.Cadence || unset.Cadence >= same.Cadence {
+		t.Fatalf("unset preferences = %v, want between %v and %v", unset.Cadence, far.Cadence, same.Cadence)
+	}
+}
+
+func TestGetNetworkReflectsMatchesAndStats(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	s := NewMatchService(db, nil, DefaultScoringConfig())
+
+	me, partner, past, blocked := createTestUser(t, db), createTestUser(t, db), createTestUser(t, db), createTestUser(t, db)
+	active := createTestMatch(t, db, me, partner)
+	archived := createTestMatch(t, db, past, me)
+	db.Model(archived).Update("status", domain.MatchInactive)
+	createTestMatch(t, db, me, blocked)
+	block := domain.UserBlock{BlockerID: me.ID, BlockedID: blocked.ID}
+	if err := db.Create(&block).Error; err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Delete(&domain.UserBlock{}, block.ID) })
+
+	// Two finished sessions rated 4 and 5, and one still running.
+	for _, rating := range []float64{4, 5} {
+		done := createTestSession(t, db, active)
+		db.Model(done).Update("success_rating", rating)
+	}
+	running := domain.CodingSession{MatchID: active.ID, StartedAt: time.Now()}
+	if err := db.Create(&running).Error; err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Delete(&domain.CodingSession{}, running.ID) })
+
+	graph, err := s.GetNetwork(ctx, me.ID, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	degrees := make(map[string]int)
+	for _, n := range graph.Nodes {
+		degrees[n.ID] = n.Degree
+	}
+	want := map[string]int{me.ID: 0, partner.ID: 1, past.ID: 1}
+	if len(degrees) != len(want) {
+		t.Fatalf("nodes = %v, want %v", degrees, want)
+	}
+	for id, d := range want {
+		if got, ok := degrees[id]; !ok || got != d {
+			t.Fatalf("node %s degree = %d (present %v), want %d", id, got, ok, d)
+		}
+	}
+
+	if len(graph.Edges) != 2 {
+		t.Fatalf("got %d edges, want 2", len(graph.Edges))
+	}
+	for _, e := range graph.Edges {
+		if e.Source != me.ID {
+			t.Fatalf("edge source = %s, want the caller", e.Source)
+		}
+		switch e.MatchID {
+		case active.ID:
+			if e.Target != partner.ID || e.Status != domain.MatchActive ||
+				e.Sessions != 3 || e.CompletedSessions != 2 || e.AvgSuccessRating != 4.5 {
+				t.Fatalf("active edge = %+v", e)
+			}
+		case archived.ID:
+			if e.Target != past.ID || e.Status != domain.MatchInactive || e.Sessions != 0 {
+				t.Fatalf("archived edge = %+v", e)
+			}
+		default:
+			t.Fatalf("unexpected edge %+v", e)
+		}
+	}
+	if graph.Truncated {
+		t.Fatal("graph reported truncated")
+	}
+}
+
+func TestGetNetworkMutualsAreIDsOnly(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	s := NewMatchService(db, nil, DefaultScoringConfig())
+
+	me, p1, p2 := createTestUser(t, db), createTestUser(t, db), createTestUser(t, db)
+	mutual, blocked, former := createTestUser(t, db), createTestUser(t, db), createTestUser(t, db)
+	createTestMatch(t, db, me, p1)
+	createTestMatch(t, db, p2, me)
+	createTestMatch(t, db, p1, p2) // between two partners: not a mutual
+	createTestMatch(t, db, p1, mutual)
+	createTestMatch(t, db, mutual, p2)
+	createTestMatch(t, db, p2, blocked)
+	inactive := createTestMatch(t, db, p1, former)
+	db.Model(inactive).Update("status", domain.MatchInactive)
+	block := domain.UserBlock{BlockerID: blocked.ID, BlockedID: me.ID}
+	if err := db.Create(&block).Error; err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Delete(&domain.UserBlock{}, block.ID) })
+
+	graph, err := s.GetNetwork(ctx, me.ID, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(graph.Mutuals) != 1 || graph.Mutuals[0] != mutual.ID {
+		t.Fatalf("mutuals = %v, want [%s]", graph.Mutuals, mutual.ID)
+	}
+	if len(graph.Nodes) != 3 || len(graph.Edges) != 2 {
+		t.Fatalf("got %d nodes and %d edges, want only the caller's 3 and 2", len(graph.Nodes), len(graph.Edges))
+	}
+	for _, e := range graph.Edges {
+		if e.Source != me.ID {
+			t.Fatalf("edge %+v is not one of the caller's matches", e)
+		}
+	}
+
+	without, err := s.GetNetwork(ctx, me.ID, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if without.Mutuals != nil {
+		t.Fatalf("mutuals without include_mutuals = %v", without.Mutuals)
+	}
+}
+
+func TestGetUserMatchesSortsByActivity(t *testing.T) {
+	db := testDB(t)
+	s := NewMatchService(db, nil, DefaultScoringConfig())
+
+	user := createTestUser(t, db)
+	quiet := createTestMatch(t, db, user, createTestUser(t, db))
+	older := createTestMatch(t, db, user, createTestUser(t, db))
+	newer := createTestMatch(t, db, user, createTestUser(t, db))
+
+	now := time.Now()
+	for _, m := range []struct {
+		match *domain.Match
+		at    time.Time
+	}{
+		{older, now.Add(-2 * time.Hour)},
+		{newer, now.Add(-time.Hour)},
+		{older, now.Add(-3 * time.Hour)},
+	} {
+		msg := domain.Message{SenderID: m.match.User2ID, ReceiverID: user.ID, MatchID: m.match.ID, Content: "hi", Type: domain.MessageText}
+		if err := db.Create(&msg).Error; err != nil {
+			t.Fatal(err)
+		}
+		db.Model(&msg).Update("created_at", m.at)
+		t.Cleanup(func() { db.Delete(&domain.Message{}, msg.ID) })
+	}
+
+	items, err := s.GetUserMatches(context.Background(), user.ID, MatchQueryOptions{Sort: "activity"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []uint{newer.ID, older.ID, quiet.ID}
+	if len(items) != len(want) {
+		t.Fatalf("got %d matches, want %d", len(items), len(want))
+	}
+	for i, id := range want {
+		if items[i].ID != id {
+			t.Fatalf("match %d is %d, want %d", i, items[i].ID, id)
+		}
+	}
+	if items[1].LastMessage == nil || !items[1].LastMessage.CreatedAt.Equal(now.Add(-2*time.Hour).Truncate(time.Microsecond)) {
+		t.Errorf("older match preview = %+v, want its latest message", items[1].LastMessage)
+	}
+}
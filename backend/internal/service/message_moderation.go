@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+
+	"github.com/yourusername/skillsync/internal/domain"
+)
+
+const (
+	MessageModerationOff     = "off"
+	MessageModerationKeyword = "keyword"
+	MessageModerationClaude  = "claude"
+
+	// moderationQueueSize bounds the messages waiting to be checked; when
+	// it is full new messages go unchecked rather than slowing sends.
+	moderationQueueSize = 256
+	// moderationCheckTimeout bounds one Claude moderation call.
+	moderationCheckTimeout = 20 * time.Second
+)
+
+// DefaultModerationKeywords is the keyword filter used when
+// MESSAGE_MODERATION_KEYWORDS is unset. Matching is case-insensitive on
+// whole words.
+var DefaultModerationKeywords = []string{
+	"idiot", "moron", "retard", "retarded", "bitch", "faggot", "whore",
+	"slut", "kys", "kill yourself", "go die",
+}
+
+var defaultKeywordPatterns = compileKeywords(DefaultModerationKeywords)
+
+// MessageModerator checks chat messages for abuse in the background and
+// queues suspect ones in flagged_messages for admins to review. Messages
+// are always delivered; a flag only feeds the moderation queue and the
+// reported-users list.
+//
+// MESSAGE_MODERATION selects the check: "off" (default), "keyword" for the
+// keyword filter alone, or "claude" to ask Claude and fall back to the
+// keyword filter when the call fails. MESSAGE_MODERATION_KEYWORDS replaces
+// the default comma-separated keyword list.
+type MessageModerator struct {
+	db       *gorm.DB
+	claude   ClaudeService
+	mode     string
+	keywords []keywordPattern
+	queue    chan *domain.Message
+}
+
+func NewMessageModerator(db *gorm.DB, claude ClaudeService) *MessageModerator {
+	mode := strings.ToLower(strings.TrimSpace(getEnv("MESSAGE_MODERATION", MessageModerationOff)))
+	switch mode {
+	case MessageModerationKeyword, MessageModerationClaude:
+	default:
+		if mode != MessageModerationOff {
+			log.Warn().Str("mode", mode).Msg("unknown MESSAGE_MODERATION; moderation disabled")
+		}
+		mode = MessageModerationOff
+	}
+
+	keywords := defaultKeywordPatterns
+	if raw := getEnv("MESSAGE_MODERATION_KEYWORDS", ""); raw != "" {
+		var list []string
+		for _, k := range strings.Split(raw, ",") {
+			if k = strings.TrimSpace(k); k != "" {
+				list = append(list, k)
+			}
+		}
+		keywords = compileKeywords(list)
+	}
+
+	return &MessageModerator{
+		db:       db,
+		claude:   claude,
+		mode:     mode,
+		keywords: keywords,
+		queue:    make(chan *domain.Message, moderationQueueSize),
+	}
+}
+
+// Mode returns the configured moderation mode.
+func (m *MessageModerator) Mode() string {
+	return m.mode
+}
+
+// Check queues msg for moderation without blocking. Only text messages are
+// checked; code and file contents are left alone.
+func (m *MessageModerator) Check(msg *domain.Message) {
+	if m.mode == MessageModerationOff || msg.Type != domain.MessageText || msg.Content == "" {
+		return
+	}
+	cp := *msg
+	select {
+	case m.queue <- &cp:
+	default:
+		log.Warn().Uint("message_id", msg.ID).Msg("moderation queue full; message not checked")
+	}
+}
+
+// Run checks queued messages until ctx is cancelled. Call as a goroutine.
+func (m *MessageModerator) Run(ctx context.Context) {
+	if m.mode == MessageModerationOff {
+		log.Info().Msg("message moderation disabled")
+		return
+	}
+	log.Info().Str("mode", m.mode).Msg("message moderation started")
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg := <-m.queue:
+			m.moderate(ctx, msg)
+		}
+	}
+}
+
+// moderate checks one message and records a flag if it looks abusive.
+func (m *MessageModerator) moderate(ctx context.Context, msg *domain.Message) {
+	verdict, source := m.verdict(ctx, msg.Content)
+	if !verdict.Flagged {
+		return
+	}
+
+	msgID := msg.ID
+	flag := domain.FlaggedMessage{
+		MessageID: &msgID,
+		SenderID:  msg.SenderID,
+		MatchID:   msg.MatchID,
+		Content:   msg.Content,
+		Category:  verdict.Category,
+		Reason:    verdict.Reason,
+		Source:    source,
+		Status:    domain.FlagPending,
+	}
+	if err := m.db.WithContext(ctx).Create(&flag).Error; err != nil {
+		log.Warn().Err(err).Uint("message_id", msg.ID).Msg("failed to queue flagged message")
+		return
+	}
+	log.Info().Uint("message_id", msg.ID).Str("sender_id", msg.SenderID).Str("source", source).Msg("message flagged for moderation")
+}
+
+// verdict runs the configured check and reports which one decided.
+func (m *MessageModerator) verdict(ctx context.Context, content string) (*ModerationVerdict, string) {
+	if m.mode == MessageModerationClaude {
+		callCtx, cancel := context.WithTimeout(ctx, moderationCheckTimeout)
+		v, err := m.claude.ModerateMessage(callCtx, content)
+		cancel()
+		if err == nil {
+			return v, MessageModerationClaude
+		}
+		log.Warn().Err(err).Msg("claude moderation failed; using keyword filter")
+	}
+	return keywordVerdict(content, m.keywords), MessageModerationKeyword
+}
+
+// keywordPattern matches one filter keyword as a whole word or phrase,
+// ignoring case.
+type keywordPattern struct {
+	keyword string
+	re      *regexp.Regexp
+}
+
+func compileKeywords(keywords []string) []keywordPattern {
+	out := make([]keywordPattern, len(keywords))
+	for i, k := range keywords {
+		out[i] = keywordPattern{keyword: k, re: regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(k) + `\b`)}
+	}
+	return out
+}
+
+// keywordVerdict flags content matching any of keywords.
+func keywordVerdict(content string, keywords []keywordPattern) *ModerationVerdict {
+	for _, k := range keywords {
+		if k.re.MatchString(content) {
+			return &ModerationVerdict{
+				Flagged:  true,
+				Category: "keyword",
+				Reason:   "contains \"" + k.keyword + "\"",
+			}
+		}
+	}
+	return &ModerationVerdict{Category: "none"}
+}
@@ -0,0 +1,327 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/yourusername/skillsync/internal/domain"
+)
+
+var (
+	ErrCannotBanSelf   = errors.New("you cannot ban yourself")
+	ErrInvalidBanUntil = errors.New("banned_until must be in the future")
+	ErrMessageNotFound = errors.New("message not found")
+
+	ErrFlagNotFound        = errors.New("flagged message not found")
+	ErrFlagAlreadyReviewed = errors.New("flagged message has already been reviewed")
+	ErrInvalidFlagAction   = errors.New("action must be dismiss or remove")
+)
+
+// ReportedUser summarizes the abuse reports filed against one user.
+type ReportedUser struct {
+	UserID       string `json:"user_id"`
+	Username     string `json:"username"`
+	FullName     string `json:"full_name"`
+	TotalReports int64  `json:"total_reports"`
+	OpenReports  int64  `json:"open_reports"`
+	// FlaggedMessages counts the user's messages awaiting review in the
+	// moderation queue.
+	FlaggedMessages int64      `json:"flagged_messages"`
+	LastReportedAt  time.Time  `json:"last_reported_at"`
+	BannedUntil     *time.Time `json:"banned_until,omitempty"`
+}
+
+// SocketCloser closes a user's realtime connections. The websocket hub
+// implements it.
+type SocketCloser interface {
+	DisconnectUser(userID string)
+}
+
+// ModerationService backs the admin moderation endpoints: reviewing reports,
+// suspending users and removing abusive messages. It also seeds admin
+// accounts at startup.
+type ModerationService struct {
+	db     *gorm.DB
+	users  *UserService
+	tokens *TokenService
+	// sockets, if set, closes a banned user's open connections.
+	sockets SocketCloser
+}
+
+func NewModerationService(db *gorm.DB, users *UserService, tokens *TokenService, sockets SocketCloser) *ModerationService {
+	return &ModerationService{db: db, users: users, tokens: tokens, sockets: sockets}
+}
+
+// ---------------------------------------------------------------------------
+// Admin seeding
+// ---------------------------------------------------------------------------
+
+// SeedAdmins grants the admin role to every user in ids and to the account
+// with adminEmail, creating that account with adminPassword if it doesn't
+// exist yet. Empty inputs are skipped.
+func (s *ModerationService) SeedAdmins(ctx context.Context, ids []string, adminEmail, adminPassword string) error {
+	db := s.db.WithContext(ctx)
+
+	if len(ids) > 0 {
+		if err := db.Model(&domain.User{}).Where("id IN ?", ids).
+			Update("role", domain.RoleAdmin).Error; err != nil {
+			return fmt.Errorf("failed to promote admins: %w", err)
+		}
+	}
+
+	if adminEmail == "" {
+		return nil
+	}
+	var user domain.User
+	err := db.Where("email = ?", adminEmail).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if adminPassword == "" {
+			log.Warn().Str("email", adminEmail).Msg("admin account missing and no ADMIN_PASSWORD set; not seeding")
+			return nil
+		}
+		username := strings.SplitN(adminEmail, "@", 2)[0]
+		created, err := s.users.CreateUser(ctx, adminEmail, username, adminPassword, "Administrator")
+		if err != nil {
+			return fmt.Errorf("failed to create admin account: %w", err)
+		}
+		user = *created
+		log.Info().Str("email", adminEmail).Msg("seeded admin account")
+	} else if err != nil {
+		return fmt.Errorf("failed to fetch admin account: %w", err)
+	}
+
+	if user.Role == domain.RoleAdmin {
+		return nil
+	}
+	if err := db.Model(&user).Update("role", domain.RoleAdmin).Error; err != nil {
+		return fmt.Errorf("failed to promote admin: %w", err)
+	}
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// Reports
+// ---------------------------------------------------------------------------
+
+// ListReportedUsers returns users with abuse reports against them or
+// messages in the moderation queue, those with the most open reports and
+// then flagged messages first. With openOnly, users whose reports are all
+// resolved and who have no pending flags are left out.
+func (s *ModerationService) ListReportedUsers(ctx context.Context, openOnly bool, limit, offset int) ([]ReportedUser, int64, error) {
+	db := s.db.WithContext(ctx)
+
+	// Reports and pending flags are combined into one row per incident.
+	incidents := db.Raw(`
+		SELECT reported_id AS user_id, 'report' AS kind, status, created_at FROM user_reports
+		UNION ALL
+		SELECT sender_id, 'flag', status, created_at FROM flagged_messages WHERE status = ?`,
+		domain.FlagPending)
+	q := db.Table("(?) AS incidents", incidents).
+		Joins("JOIN users ON users.id = incidents.user_id").
+		Group("users.id")
+	if openOnly {
+		q = q.Having("COUNT(*) FILTER (WHERE incidents.kind = 'flag' OR incidents.status = ?) > 0", domain.ReportOpen)
+	}
+
+	var total int64
+	if err := db.Table("(?) AS reported", q.Session(&gorm.Session{}).Select("users.id")).
+		Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count reported users: %w", err)
+	}
+
+	var users []ReportedUser
+	err := q.Select(`users.id AS user_id, users.username, users.full_name, users.banned_until,
+			COUNT(*) FILTER (WHERE incidents.kind = 'report') AS total_reports,
+			COUNT(*) FILTER (WHERE incidents.kind = 'report' AND incidents.status = ?) AS open_reports,
+			COUNT(*) FILTER (WHERE incidents.kind = 'flag') AS flagged_messages,
+			MAX(incidents.created_at) AS last_reported_at`, domain.ReportOpen).
+		Order("open_reports DESC, flagged_messages DESC, last_reported_at DESC").
+		Limit(limit).Offset(offset).
+		Scan(&users).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list reported users: %w", err)
+	}
+	return users, total, nil
+}
+
+// ---------------------------------------------------------------------------
+// Ban / Unban
+// ---------------------------------------------------------------------------
+
+// IsBanned reports whether userID is currently suspended or has deleted
+// their account, whose access tokens outlive it. It implements
+// middleware.BanChecker; lookup errors let the request through.
+func (s *ModerationService) IsBanned(userID string) bool {
+	var n int64
+	s.db.Unscoped().Model(&domain.User{}).
+		Where("id = ? AND (deleted_at IS NOT NULL OR banned_until > ?)", userID, time.Now()).
+		Count(&n)
+	return n > 0
+}
+
+// BanUser suspends userID until the given time, signs them out everywhere
+// and resolves the open reports against them.
+func (s *ModerationService) BanUser(ctx context.Context, adminID, userID string, until time.Time) (*domain.User, error) {
+	if adminID == userID {
+		return nil, ErrCannotBanSelf
+	}
+	if !until.After(time.Now()) {
+		return nil, ErrInvalidBanUntil
+	}
+
+	var user domain.User
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.First(&user, "id = ?", userID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to fetch user: %w", err)
+		}
+
+		if err := tx.Model(&user).Update("banned_until", until).Error; err != nil {
+			return fmt.Errorf("failed to ban user: %w", err)
+		}
+		if err := tx.Model(&domain.UserReport{}).
+			Where("reported_id = ? AND status = ?", userID, domain.ReportOpen).
+			Update("status", domain.ReportResolved).Error; err != nil {
+			return fmt.Errorf("failed to resolve reports: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Access tokens are rejected by the JWT middleware; refresh tokens go too
+	// so the ban can't be outlasted by refreshing.
+	if err := s.tokens.RevokeAll(context.Background(), userID); err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("failed to revoke tokens of banned user")
+	}
+	// Sockets outlive the token they were opened with.
+	if s.sockets != nil {
+		s.sockets.DisconnectUser(userID)
+	}
+	return &user, nil
+}
+
+// UnbanUser lifts any suspension on userID.
+func (s *ModerationService) UnbanUser(ctx context.Context, userID string) error {
+	res := s.db.WithContext(ctx).Model(&domain.User{}).Where("id = ?", userID).Update("banned_until", nil)
+	if res.Error != nil {
+		return fmt.Errorf("failed to unban user: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// Messages
+// ---------------------------------------------------------------------------
+
+// DeleteMessage permanently removes a message and returns what was deleted
+// for the audit trail.
+func (s *ModerationService) DeleteMessage(ctx context.Context, messageID uint) (*domain.Message, error) {
+	db := s.db.WithContext(ctx)
+
+	var msg domain.Message
+	err := db.First(&msg, messageID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrMessageNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch message: %w", err)
+	}
+
+	if err := db.Delete(&msg).Error; err != nil {
+		return nil, fmt.Errorf("failed to delete message: %w", err)
+	}
+	return &msg, nil
+}
+
+// ---------------------------------------------------------------------------
+// Flagged messages
+// ---------------------------------------------------------------------------
+
+// Flag review actions.
+const (
+	FlagActionDismiss = "dismiss"
+	FlagActionRemove  = "remove"
+)
+
+// ListFlaggedMessages returns the moderation queue, oldest first so flags
+// are reviewed in order. With pendingOnly, reviewed flags are left out.
+func (s *ModerationService) ListFlaggedMessages(ctx context.Context, pendingOnly bool, limit, offset int) ([]domain.FlaggedMessage, int64, error) {
+	q := s.db.WithContext(ctx).Model(&domain.FlaggedMessage{})
+	if pendingOnly {
+		q = q.Where("status = ?", domain.FlagPending)
+	}
+
+	var total int64
+	if err := q.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count flagged messages: %w", err)
+	}
+	var flags []domain.FlaggedMessage
+	if err := q.Order("created_at ASC, id ASC").Limit(limit).Offset(offset).Find(&flags).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list flagged messages: %w", err)
+	}
+	return flags, total, nil
+}
+
+// ReviewFlaggedMessage closes a pending flag. "dismiss" keeps the message;
+// "remove" deletes it, leaving the flag's copy of the content for the
+// record.
+func (s *ModerationService) ReviewFlaggedMessage(ctx context.Context, adminID string, flagID uint, action string) (*domain.FlaggedMessage, error) {
+	status := domain.FlagDismissed
+	switch action {
+	case FlagActionDismiss:
+	case FlagActionRemove:
+		status = domain.FlagRemoved
+	default:
+		return nil, ErrInvalidFlagAction
+	}
+
+	var flag domain.FlaggedMessage
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&flag, flagID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrFlagNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to fetch flagged message: %w", err)
+		}
+		if flag.Status != domain.FlagPending {
+			return ErrFlagAlreadyReviewed
+		}
+
+		if status == domain.FlagRemoved && flag.MessageID != nil {
+			if err := tx.Delete(&domain.Message{}, *flag.MessageID).Error; err != nil {
+				return fmt.Errorf("failed to delete message: %w", err)
+			}
+		}
+
+		now := time.Now()
+		if err := tx.Model(&flag).Updates(map[string]interface{}{
+			"status":      status,
+			"reviewed_by": adminID,
+			"reviewed_at": now,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to review flagged message: %w", err)
+		}
+		flag.Status, flag.ReviewedBy, flag.ReviewedAt = status, &adminID, &now
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &flag, nil
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+
+	"github.com/yourusername/skillsync/internal/domain"
+)
+
+var ErrNotificationNotFound = errors.New("notification not found")
+
+// NotificationPusher delivers realtime frames to connected users. The
+// websocket hub implements it.
+type NotificationPusher interface {
+	BroadcastToUser(userID string, data []byte)
+	OnlineUsersForMatch(matchID uint) []string
+}
+
+// NotificationPush is the websocket frame sent for each new notification.
+type NotificationPush struct {
+	Type         string               `json:"type"`
+	Notification *domain.Notification `json:"notification"`
+}
+
+// NotificationService stores the per-user notification feed and pushes new
+// entries to users who are online.
+type NotificationService struct {
+	db     *gorm.DB
+	pusher NotificationPusher
+}
+
+func NewNotificationService(db *gorm.DB, pusher NotificationPusher) *NotificationService {
+	return &NotificationService{db: db, pusher: pusher}
+}
+
+// ---------------------------------------------------------------------------
+// Notify
+// ---------------------------------------------------------------------------
+
+// Notify saves a notification for userID and pushes it over any open
+// websocket. It is best effort: failures are logged, never returned, so that
+// the action that triggered it still succeeds.
+func (s *NotificationService) Notify(userID string, typ domain.NotificationType, title, body string, data map[string]interface{}) {
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	raw, _ := json.Marshal(data)
+
+	n := domain.Notification{
+		UserID: userID,
+		Type:   typ,
+		Title:  title,
+		Body:   body,
+		Data:   domain.JSONB(raw),
+	}
+	if err := s.db.Create(&n).Error; err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Str("type", string(typ)).Msg("failed to save notification")
+		return
+	}
+
+	if s.pusher != nil {
+		frame, _ := json.Marshal(NotificationPush{Type: "notification", Notification: &n})
+		s.pusher.BroadcastToUser(userID, frame)
+	}
+}
+
+// NotifyMatchRequest tells receiverID that senderID sent them a match request.
+func (s *NotificationService) NotifyMatchRequest(senderID, receiverID string) {
+	s.Notify(receiverID, domain.NotifyMatchRequest, "New match request",
+		s.displayName(senderID)+" wants to pair with you",
+		map[string]interface{}{"sender_id": senderID})
+}
+
+// NotifyMatchRequestAnswered tells the sender of request requestID that
+// responderID accepted or rejected it. matchID is zero for rejections.
+func (s *NotificationService) NotifyMatchRequestAnswered(requestID uint, senderID, responderID string, accepted bool, matchID uint) {
+	data := map[string]interface{}{"request_id": requestID, "responder_id": responderID}
+	if accepted {
+		data["match_id"] = matchID
+		s.Notify(senderID, domain.NotifyMatchRequestAccepted, "Match request accepted",
+			s.displayName(responderID)+" accepted your match request", data)
+		return
+	}
+	s.Notify(senderID, domain.NotifyMatchRequestRejected, "Match request declined",
+		s.displayName(responderID)+" declined your match request", data)
+}
+
+// NotifySessionProposed tells the recipient of p that its proposer
+// suggested a session time.
+func (s *NotificationService) NotifySessionProposed(p *domain.SessionProposal) {
+	s.Notify(p.RecipientID, domain.NotifySessionProposed, "Session proposed",
+		s.displayName(p.ProposerID)+" proposed a pairing session on "+p.StartsAt.UTC().Format("Jan 2 at 15:04 UTC"),
+		map[string]interface{}{
+			"proposal_id":      p.ID,
+			"match_id":         p.MatchID,
+			"starts_at":        p.StartsAt,
+			"duration_minutes": p.DurationMinutes,
+		})
+}
+
+// NotifySessionProposalAnswered tells the proposer of p that its recipient
+// accepted or declined it.
+func (s *NotificationService) NotifySessionProposalAnswered(p *domain.SessionProposal) {
+	data := map[string]interface{}{
+		"proposal_id": p.ID,
+		"match_id":    p.MatchID,
+		"starts_at":   p.StartsAt,
+	}
+	if p.Status == domain.ProposalAccepted {
+		if p.SessionID != nil {
+			data["session_id"] = *p.SessionID
+		}
+		s.Notify(p.ProposerID, domain.NotifySessionAccepted, "Session confirmed",
+			s.displayName(p.RecipientID)+" accepted your proposed session", data)
+		return
+	}
+	s.Notify(p.ProposerID, domain.NotifySessionDeclined, "Session declined",
+		s.displayName(p.RecipientID)+" declined your proposed session", data)
+}
+
+// NotifyNewRating tells ratedID they received a rating for a session.
+func (s *NotificationService) NotifyNewRating(ratedID string, sessionID uint) {
+	s.Notify(ratedID, domain.NotifyNewRating, "New rating",
+		"Your pairing partner rated your session",
+		map[string]interface{}{"session_id": sessionID})
+}
+
+// NotifyNewMessage notifies the receiver of msg unless they are already
+// connected to the match's chat and saw it arrive.
+func (s *NotificationService) NotifyNewMessage(msg *domain.Message) {
+	if s.pusher != nil {
+		for _, id := range s.pusher.OnlineUsersForMatch(msg.MatchID) {
+			if id == msg.ReceiverID {
+				return
+			}
+		}
+	}
+
+	preview := msg.Content
+	if r := []rune(preview); len(r) > 140 {
+		preview = string(r[:140]) + "…"
+	}
+	s.Notify(msg.ReceiverID, domain.NotifyNewMessage, "New message", preview, map[string]interface{}{
+		"match_id":   msg.MatchID,
+		"message_id": msg.ID,
+		"sender_id":  msg.SenderID,
+	})
+}
+
+// displayName returns the user's full name, falling back to their username.
+func (s *NotificationService) displayName(userID string) string {
+	var u domain.User
+	if err := s.db.Select("username", "full_name").First(&u, "id = ?", userID).Error; err != nil {
+		return "Someone"
+	}
+	if u.FullName != "" {
+		return u.FullName
+	}
+	return u.Username
+}
+
+// ---------------------------------------------------------------------------
+// Feed
+// ---------------------------------------------------------------------------
+
+// List returns a page of the user's notifications, newest first, and the
+// total matching count.
+func (s *NotificationService) List(userID string, unreadOnly bool, limit, offset int) ([]domain.Notification, int64, error) {
+	q := s.db.Model(&domain.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		q = q.Where("read_at IS NULL")
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count notifications: %w", err)
+	}
+
+	var items []domain.Notification
+	if err := q.Order("created_at DESC, id DESC").Limit(limit).Offset(offset).Find(&items).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch notifications: %w", err)
+	}
+	return items, total, nil
+}
+
+// MarkRead marks one of the user's notifications read. Marking an already
+// read notification is a no-op.
+func (s *NotificationService) MarkRead(id uint, userID string) error {
+	res := s.db.Model(&domain.Notification{}).
+		Where("id = ? AND user_id = ?", id, userID).
+		Update("read_at", gorm.Expr("COALESCE(read_at, ?)", time.Now()))
+	if res.Error != nil {
+		return fmt.Errorf("failed to mark notification read: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return ErrNotificationNotFound
+	}
+	return nil
+}
+
+// UnreadCount returns how many of the user's notifications are unread.
+func (s *NotificationService) UnreadCount(userID string) (int64, error) {
+	var n int64
+	if err := s.db.Model(&domain.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&n).Error; err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+	return n, nil
+}
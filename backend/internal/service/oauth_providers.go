@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/skillsync/internal/domain"
+)
+
+// OAuthProvider describes one OAuth 2.0 login provider. Adding a provider
+// means adding an entry to DefaultOAuthConfig, a users column for its ids
+// (see oauthProviderColumn) and its login/callback routes.
+type OAuthProvider struct {
+	Name         string
+	ClientID     string
+	ClientSecret string
+	AuthURL      string
+	TokenURL     string
+	UserInfoURL  string
+	Scopes       []string
+	// AuthParams are extra query parameters for the authorization URL.
+	AuthParams map[string]string
+	// RedirectURL is where the provider sends the user back to. It defaults
+	// to OAUTH_REDIRECT_BASE + "/api/auth/<name>/callback".
+	RedirectURL string
+
+	// parseProfile maps the UserInfoURL response onto an oauthProfile.
+	parseProfile func(body []byte) (oauthProfile, error)
+	// primaryEmail, if set, is asked for the email when the profile has none.
+	primaryEmail func(accessToken string) (string, error)
+	// afterLogin, if set, runs after the user has been signed in.
+	afterLogin func(ctx context.Context, s *OAuthService, user *domain.User, accessToken string, profile oauthProfile)
+}
+
+// oauthProfile is the part of a provider's user profile SkillSync uses.
+type oauthProfile struct {
+	ID        string
+	Email     string
+	Name      string
+	Login     string
+	AvatarURL string
+}
+
+// Enabled reports whether the provider has client credentials.
+func (p *OAuthProvider) Enabled() bool {
+	return p.ClientID != "" && p.ClientSecret != ""
+}
+
+// OAuthConfig is the set of login providers. Credentials come from
+// <NAME>_CLIENT_ID and <NAME>_CLIENT_SECRET (GOOGLE_, GITHUB_, GITLAB_);
+// a provider without them is disabled. <NAME>_REDIRECT_URL overrides the
+// callback URL derived from OAUTH_REDIRECT_BASE, and GITLAB_BASE_URL points
+// GitLab login at a self-managed instance.
+type OAuthConfig struct {
+	Providers []*OAuthProvider
+}
+
+// DefaultOAuthConfig returns the supported providers without credentials.
+func DefaultOAuthConfig() OAuthConfig {
+	return OAuthConfig{Providers: []*OAuthProvider{
+		{
+			Name:        "google",
+			AuthURL:     "https://accounts.google.com/o/oauth2/v2/auth",
+			TokenURL:    "https://oauth2.googleapis.com/token",
+			UserInfoURL: "https://www.googleapis.com/oauth2/v2/userinfo",
+			Scopes:      []string{"openid", "email", "profile"},
+			AuthParams: map[string]string{
+				"access_type": "offline",
+				"prompt":      "consent",
+			},
+			parseProfile: func(body []byte) (oauthProfile, error) {
+				var p struct {
+					ID      string `json:"id"`
+					Email   string `json:"email"`
+					Name    string `json:"name"`
+					Picture string `json:"picture"`
+				}
+				err := json.Unmarshal(body, &p)
+				return oauthProfile{ID: p.ID, Email: p.Email, Name: p.Name, AvatarURL: p.Picture}, err
+			},
+		},
+		{
+			Name:        "github",
+			AuthURL:     "https://github.com/login/oauth/authorize",
+			TokenURL:    "https://github.com/login/oauth/access_token",
+			UserInfoURL: "https://api.github.com/user",
+			Scopes:      []string{"user:email", "read:user"},
+			parseProfile: func(body []byte) (oauthProfile, error) {
+				var p struct {
+					ID        int    `json:"id"`
+					Login     string `json:"login"`
+					Name      string `json:"name"`
+					Email     string `json:"email"`
+					AvatarURL string `json:"avatar_url"`
+				}
+				err := json.Unmarshal(body, &p)
+				return oauthProfile{ID: strconv.Itoa(p.ID), Email: p.Email, Name: p.Name, Login: p.Login, AvatarURL: p.AvatarURL}, err
+			},
+			primaryEmail: fetchGitHubPrimaryEmail,
+			afterLogin:   githubAfterLogin,
+		},
+		gitlabProvider("https://gitlab.com"),
+	}}
+}
+
+// gitlabProvider describes GitLab login against the instance at baseURL.
+func gitlabProvider(baseURL string) *OAuthProvider {
+	return &OAuthProvider{
+		Name:        "gitlab",
+		AuthURL:     baseURL + "/oauth/authorize",
+		TokenURL:    baseURL + "/oauth/token",
+		UserInfoURL: baseURL + "/api/v4/user",
+		Scopes:      []string{"read_user"},
+		parseProfile: func(body []byte) (oauthProfile, error) {
+			var p struct {
+				ID        int    `json:"id"`
+				Username  string `json:"username"`
+				Name      string `json:"name"`
+				Email     string `json:"email"`
+				AvatarURL string `json:"avatar_url"`
+			}
+			err := json.Unmarshal(body, &p)
+			return oauthProfile{ID: strconv.Itoa(p.ID), Email: p.Email, Name: p.Name, Login: p.Username, AvatarURL: p.AvatarURL}, err
+		},
+	}
+}
+
+// OAuthConfigFromEnv starts from DefaultOAuthConfig, fills in credentials
+// and redirect URLs from the environment and validates the result.
+func OAuthConfigFromEnv() (OAuthConfig, error) {
+	cfg := DefaultOAuthConfig()
+	if base := strings.TrimRight(os.Getenv("GITLAB_BASE_URL"), "/"); base != "" {
+		for i, p := range cfg.Providers {
+			if p.Name == "gitlab" {
+				cfg.Providers[i] = gitlabProvider(base)
+			}
+		}
+	}
+
+	redirectBase := strings.TrimRight(os.Getenv("OAUTH_REDIRECT_BASE"), "/")
+	for _, p := range cfg.Providers {
+		prefix := strings.ToUpper(p.Name) + "_"
+		p.ClientID = os.Getenv(prefix + "CLIENT_ID")
+		p.ClientSecret = os.Getenv(prefix + "CLIENT_SECRET")
+		p.RedirectURL = os.Getenv(prefix + "REDIRECT_URL")
+		if p.RedirectURL == "" && redirectBase != "" {
+			p.RedirectURL = redirectBase + "/api/auth/" + p.Name + "/callback"
+		}
+	}
+	return cfg, cfg.Validate()
+}
+
+// Validate checks that every provider has either both credentials or
+// neither, and that enabled providers have absolute http(s) URLs.
+func (c OAuthConfig) Validate() error {
+	for _, p := range c.Providers {
+		if (p.ClientID == "") != (p.ClientSecret == "") {
+			return fmt.Errorf("oauth %s: client id and client secret must be set together", p.Name)
+		}
+		if !p.Enabled() {
+			continue
+		}
+		if p.RedirectURL == "" {
+			return fmt.Errorf("oauth %s: set OAUTH_REDIRECT_BASE or %s_REDIRECT_URL", p.Name, strings.ToUpper(p.Name))
+		}
+		for _, u := range []string{p.AuthURL, p.TokenURL, p.UserInfoURL, p.RedirectURL} {
+			parsed, err := url.Parse(u)
+			if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+				return fmt.Errorf("oauth %s: %q is not an absolute http(s) URL", p.Name, u)
+			}
+		}
+	}
+	return nil
+}
+
+// EnabledProviders returns the names of the providers with credentials.
+func (c OAuthConfig) EnabledProviders() []string {
+	var names []string
+	for _, p := range c.Providers {
+		if p.Enabled() {
+			names = append(names, p.Name)
+		}
+	}
+	return names
+}
@@ -1,184 +1,198 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
-	"os"
 	"strings"
 
+	"github.com/rs/zerolog/log"
 	"gorm.io/gorm"
 
 	"github.com/yourusername/skillsync/internal/domain"
 )
 
+var ErrOAuthProviderUnavailable = errors.New("oauth provider is not configured")
+
 type OAuthService struct {
 	db          *gorm.DB
 	userService *UserService
+	providers   map[string]*OAuthProvider
 }
 
-func NewOAuthService(db *gorm.DB, userService *UserService) *OAuthService {
-	return &OAuthService{db: db, userService: userService}
+// NewOAuthService serves the providers in cfg that have credentials set.
+func NewOAuthService(db *gorm.DB, userService *UserService, cfg OAuthConfig) *OAuthService {
+	providers := make(map[string]*OAuthProvider)
+	for _, p := range cfg.Providers {
+		if p.Enabled() {
+			providers[p.Name] = p
+		}
+	}
+	return &OAuthService{db: db, userService: userService, providers: providers}
+}
+
+// provider returns the enabled provider called name.
+func (s *OAuthService) provider(name string) (*OAuthProvider, error) {
+	p, ok := s.providers[name]
+	if !ok {
+		return nil, ErrOAuthProviderUnavailable
+	}
+	return p, nil
 }
 
 // ---------------------------------------------------------------------------
-// Google
+// Login
 // ---------------------------------------------------------------------------
 
-func (s *OAuthService) GetGoogleLoginURL(state string) string {
+// LoginURL returns the provider's authorization URL the user is sent to.
+func (s *OAuthService) LoginURL(provider, state string) (string, error) {
+	p, err := s.provider(provider)
+	if err != nil {
+		return "", err
+	}
 	params := url.Values{
-		"client_id":     {os.Getenv("GOOGLE_CLIENT_ID")},
-		"redirect_uri":  {os.Getenv("OAUTH_REDIRECT_BASE") + "/api/auth/google/callback"},
+		"client_id":     {p.ClientID},
+		"redirect_uri":  {p.RedirectURL},
 		"response_type": {"code"},
-		"scope":         {"openid email profile"},
+		"scope":         {strings.Join(p.Scopes, " ")},
 		"state":         {state},
-		"access_type":   {"offline"},
-		"prompt":        {"consent"},
 	}
-	return "https://accounts.google.com/o/oauth2/v2/auth?" + params.Encode()
+	for k, v := range p.AuthParams {
+		params.Set(k, v)
+	}
+	return p.AuthURL + "?" + params.Encode(), nil
 }
 
-func (s *OAuthService) HandleGoogleCallback(code string) (*domain.User, error) {
-	// Exchange code for token.
-	tokenResp, err := http.PostForm("https://oauth2.googleapis.com/token", url.Values{
-		"code":          {code},
-		"client_id":     {os.Getenv("GOOGLE_CLIENT_ID")},
-		"client_secret": {os.Getenv("GOOGLE_CLIENT_SECRET")},
-		"redirect_uri":  {os.Getenv("OAUTH_REDIRECT_BASE") + "/api/auth/google/callback"},
-		"grant_type":    {"authorization_code"},
-	})
+// ---------------------------------------------------------------------------
+// Callback
+// ---------------------------------------------------------------------------
+
+// HandleCallback exchanges an authorization code for an access token, loads
+// the user's profile and signs them in, linking or creating an account.
+func (s *OAuthService) HandleCallback(ctx context.Context, provider, code string) (*domain.User, error) {
+	p, err := s.provider(provider)
 	if err != nil {
-		return nil, fmt.Errorf("google token exchange failed: %w", err)
+		return nil, err
 	}
-	defer tokenResp.Body.Close()
 
-	body, _ := io.ReadAll(tokenResp.Body)
-	if tokenResp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("google token exchange returned %d: %s", tokenResp.StatusCode, string(body))
+	accessToken, err := s.exchangeCode(p, code)
+	if err != nil {
+		return nil, err
 	}
 
-	var tokenData struct {
-		AccessToken string `json:"access_token"`
-	}
-	if err := json.Unmarshal(body, &tokenData); err != nil {
-		return nil, fmt.Errorf("failed to parse google token response: %w", err)
+	body, err := oauthGet(p.UserInfoURL, accessToken)
+	if err != nil {
+		return nil, fmt.Errorf("%s userinfo request failed: %w", p.Name, err)
 	}
-
-	// Fetch user profile.
-	req, _ := http.NewRequest("GET", "https://www.googleapis.com/oauth2/v2/userinfo", nil)
-	req.Header.Set("Authorization", "Bearer "+tokenData.AccessToken)
-	resp, err := http.DefaultClient.Do(req)
+	profile, err := p.parseProfile(body)
 	if err != nil {
-		return nil, fmt.Errorf("google userinfo request failed: %w", err)
+		return nil, fmt.Errorf("failed to parse %s profile: %w", p.Name, err)
 	}
-	defer resp.Body.Close()
 
-	body, _ = io.ReadAll(resp.Body)
-	var profile struct {
-		ID      string `json:"id"`
-		Email   string `json:"email"`
-		Name    string `json:"name"`
-		Picture string `json:"picture"`
+	if profile.Email == "" && p.primaryEmail != nil {
+		profile.Email, _ = p.primaryEmail(accessToken)
 	}
-	if err := json.Unmarshal(body, &profile); err != nil {
-		return nil, fmt.Errorf("failed to parse google profile: %w", err)
+	if profile.Name == "" {
+		profile.Name = profile.Login
 	}
 
-	return s.userService.FindOrCreateOAuthUser("google", profile.ID, profile.Email, profile.Name, profile.Picture)
-}
-
-// ---------------------------------------------------------------------------
-// GitHub
-// ---------------------------------------------------------------------------
-
-func (s *OAuthService) GetGitHubLoginURL(state string) string {
-	params := url.Values{
-		"client_id":    {os.Getenv("GITHUB_CLIENT_ID")},
-		"redirect_uri": {os.Getenv("OAUTH_REDIRECT_BASE") + "/api/auth/github/callback"},
-		"scope":        {"user:email read:user"},
-		"state":        {state},
+	user, err := s.userService.FindOrCreateOAuthUser(ctx, p.Name, profile.ID, profile.Email, profile.Name, profile.AvatarURL)
+	if err != nil {
+		return nil, err
 	}
-	return "https://github.com/login/oauth/authorize?" + params.Encode()
+	if p.afterLogin != nil {
+		p.afterLogin(ctx, s, user, accessToken, profile)
+	}
+	return user, nil
 }
 
-func (s *OAuthService) HandleGitHubCallback(code string) (*domain.User, error) {
-	// Exchange code for token.
+// exchangeCode trades code for an access token at the provider's token URL.
+func (s *OAuthService) exchangeCode(p *OAuthProvider, code string) (string, error) {
 	data := url.Values{
 		"code":          {code},
-		"client_id":     {os.Getenv("GITHUB_CLIENT_ID")},
-		"client_secret": {os.Getenv("GITHUB_CLIENT_SECRET")},
-		"redirect_uri":  {os.Getenv("OAUTH_REDIRECT_BASE") + "/api/auth/github/callback"},
+		"client_id":     {p.ClientID},
+		"client_secret": {p.ClientSecret},
+		"redirect_uri":  {p.RedirectURL},
+		"grant_type":    {"authorization_code"},
 	}
 
-	req, _ := http.NewRequest("POST", "https://github.com/login/oauth/access_token", strings.NewReader(data.Encode()))
+	req, _ := http.NewRequest("POST", p.TokenURL, strings.NewReader(data.Encode()))
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	// GitHub answers form-encoded unless asked for JSON.
 	req.Header.Set("Accept", "application/json")
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("github token exchange failed: %w", err)
+		return "", fmt.Errorf("%s token exchange failed: %w", p.Name, err)
 	}
 	defer resp.Body.Close()
 
 	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s token exchange returned %d: %s", p.Name, resp.StatusCode, string(body))
+	}
+
 	var tokenData struct {
 		AccessToken string `json:"access_token"`
 		Error       string `json:"error"`
 	}
 	if err := json.Unmarshal(body, &tokenData); err != nil {
-		return nil, fmt.Errorf("failed to parse github token response: %w", err)
+		return "", fmt.Errorf("failed to parse %s token response: %w", p.Name, err)
 	}
 	if tokenData.Error != "" {
-		return nil, fmt.Errorf("github token error: %s", tokenData.Error)
+		return "", fmt.Errorf("%s token error: %s", p.Name, tokenData.Error)
+	}
+	if tokenData.AccessToken == "" {
+		return "", fmt.Errorf("%s token response has no access token", p.Name)
 	}
+	return tokenData.AccessToken, nil
+}
 
-	// Fetch user profile.
-	profileReq, _ := http.NewRequest("GET", "https://api.github.com/user", nil)
-	profileReq.Header.Set("Authorization", "Bearer "+tokenData.AccessToken)
-	profileResp, err := http.DefaultClient.Do(profileReq)
+// oauthGet fetches rawURL with accessToken as a bearer token.
+func oauthGet(rawURL, accessToken string) ([]byte, error) {
+	req, _ := http.NewRequest("GET", rawURL, nil)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/json")
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("github user request failed: %w", err)
+		return nil, err
 	}
-	defer profileResp.Body.Close()
+	defer resp.Body.Close()
 
-	body, _ = io.ReadAll(profileResp.Body)
-	var profile struct {
-		ID        int    `json:"id"`
-		Login     string `json:"login"`
-		Name      string `json:"name"`
-		Email     string `json:"email"`
-		AvatarURL string `json:"avatar_url"`
-	}
-	if err := json.Unmarshal(body, &profile); err != nil {
-		return nil, fmt.Errorf("failed to parse github profile: %w", err)
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %d: %s", rawURL, resp.StatusCode, string(body))
 	}
+	return body, nil
+}
 
-	// GitHub may not return email in profile — fetch from emails endpoint.
-	email := profile.Email
-	if email == "" {
-		email, _ = s.fetchGitHubPrimaryEmail(tokenData.AccessToken)
-	}
+// ---------------------------------------------------------------------------
+// GitHub
+// ---------------------------------------------------------------------------
 
-	name := profile.Name
-	if name == "" {
-		name = profile.Login
+// githubAfterLogin keeps the token so repos can be imported later and fills
+// in the profile link. Neither is fatal to the login.
+func githubAfterLogin(ctx context.Context, s *OAuthService, user *domain.User, accessToken string, profile oauthProfile) {
+	if err := s.userService.SetGitHubToken(ctx, user.ID, accessToken); err != nil {
+		log.Warn().Err(err).Str("user_id", user.ID).Msg("failed to store github token")
+	}
+	if err := s.userService.SetGitHubProfileURL(ctx, user.ID, profile.Login); err != nil {
+		log.Warn().Err(err).Str("user_id", user.ID).Msg("failed to set github profile url")
 	}
-
-	return s.userService.FindOrCreateOAuthUser("github", fmt.Sprintf("%d", profile.ID), email, name, profile.AvatarURL)
 }
 
-func (s *OAuthService) fetchGitHubPrimaryEmail(accessToken string) (string, error) {
-	req, _ := http.NewRequest("GET", "https://api.github.com/user/emails", nil)
-	req.Header.Set("Authorization", "Bearer "+accessToken)
-	resp, err := http.DefaultClient.Do(req)
+// fetchGitHubPrimaryEmail looks up the user's email when their GitHub profile
+// keeps it private.
+func fetchGitHubPrimaryEmail(accessToken string) (string, error) {
+	body, err := oauthGet("https://api.github.com/user/emails", accessToken)
 	if err != nil {
 		return "", err
 	}
-	defer resp.Body.Close()
 
-	body, _ := io.ReadAll(resp.Body)
 	var emails []struct {
 		Email    string `json:"email"`
 		Primary  bool   `json:"primary"`
@@ -0,0 +1,455 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+
+	"github.com/yourusername/skillsync/internal/domain"
+	"github.com/yourusername/skillsync/pkg/auth"
+)
+
+var (
+	ErrInvalidGitHubUsername = errors.New("invalid github username")
+	ErrGitHubUserNotFound    = errors.New("github user not found")
+	ErrGitHubNotConnected    = errors.New("github account is not connected; sign in with github first")
+	ErrGitHubRateLimited     = errors.New("github rate limit reached; try again later")
+)
+
+// maxImportRepos caps how many repositories PreviewGitHubImport inspects, as
+// each costs one languages API call.
+const maxImportRepos = 30
+
+const defaultSuggestedSkillsLimit = 10
+
+// githubLanguageAliases maps GitHub linguist names onto catalog skill names
+// where the two differ.
+var githubLanguageAliases = map[string]string{
+	"vue":        "vue.js",
+	"dockerfile": "docker",
+	"shell":      "bash",
+}
+
+// SkillSuggestion is one ranked catalog skill for a new user to confirm.
+type SkillSuggestion struct {
+	Skill   domain.Skill `json:"skill"`
+	Score   float64      `json:"score"`
+	Sources []string     `json:"sources"`
+}
+
+// OnboardingService suggests catalog skills for new users from optional
+// hints. It never writes to user_skills; the user confirms explicitly.
+type OnboardingService struct {
+	db     *gorm.DB
+	claude ClaudeService
+	http   *http.Client
+}
+
+func NewOnboardingService(db *gorm.DB, claude ClaudeService) *OnboardingService {
+	return &OnboardingService{
+		db:     db,
+		claude: claude,
+		http:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// ---------------------------------------------------------------------------
+// SuggestSkills
+// ---------------------------------------------------------------------------
+
+// SuggestSkills ranks catalog skills for userID using a GitHub username and/or
+// a free-text description. Skills the user already has are left out. With no
+// hints at all it falls back to the most commonly held skills.
+func (s *OnboardingService) SuggestSkills(ctx context.Context, userID, githubUsername, description string, limit int) ([]SkillSuggestion, error) {
+	db := s.db.WithContext(ctx)
+
+	if limit <= 0 || limit > 50 {
+		limit = defaultSuggestedSkillsLimit
+	}
+
+	var catalog []domain.Skill
+	if err := db.Order("name ASC").Find(&catalog).Error; err != nil {
+		return nil, fmt.Errorf("failed to load skills: %w", err)
+	}
+
+	var owned []uint
+	if err := db.Model(&domain.UserSkill{}).
+		Where("user_id = ?", userID).
+		Pluck("skill_id", &owned).Error; err != nil {
+		return nil, fmt.Errorf("failed to load user skills: %w", err)
+	}
+	ownedSet := make(map[uint]bool, len(owned))
+	for _, id := range owned {
+		ownedSet[id] = true
+	}
+
+	byLower := make(map[string]domain.Skill, len(catalog))
+	names := make([]string, 0, len(catalog))
+	for _, sk := range catalog {
+		if ownedSet[sk.ID] {
+			continue
+		}
+		byLower[strings.ToLower(sk.Name)] = sk
+		names = append(names, sk.Name)
+	}
+
+	scores := make(map[uint]*SkillSuggestion)
+	add := func(sk domain.Skill, score float64, source string) {
+		sug, ok := scores[sk.ID]
+		if !ok {
+			sug = &SkillSuggestion{Skill: sk}
+			scores[sk.ID] = sug
+		}
+		sug.Score += score
+		sug.Sources = append(sug.Sources, source)
+	}
+
+	githubUsername = strings.TrimSpace(githubUsername)
+	description = strings.TrimSpace(description)
+
+	if githubUsername != "" {
+		langs, err := s.githubLanguages(ctx, githubUsername)
+		if err != nil {
+			return nil, err
+		}
+		for lang, share := range langs {
+			key := strings.ToLower(lang)
+			if alias, ok := githubLanguageAliases[key]; ok {
+				key = alias
+			}
+			if sk, ok := byLower[key]; ok {
+				add(sk, share*100, "github")
+			}
+		}
+	}
+
+	if description != "" && s.claude != nil && len(names) > 0 {
+		mapped, err := s.claude.MapDescriptionToSkills(ctx, description, names)
+		if err != nil {
+			// The GitHub signal is still useful on its own.
+			log.Warn().Err(err).Str("user_id", userID).Msg("skill suggestion from description failed")
+		}
+		for _, m := range mapped {
+			if sk, ok := byLower[strings.ToLower(m.Skill)]; ok {
+				add(sk, m.Relevance, "description")
+			}
+		}
+	}
+
+	if githubUsername == "" && description == "" {
+		return s.popularSkills(ctx, ownedSet, limit)
+	}
+
+	results := make([]SkillSuggestion, 0, len(scores))
+	for _, sug := range scores {
+		results = append(results, *sug)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Skill.Name < results[j].Skill.Name
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// ---------------------------------------------------------------------------
+// PreviewGitHubImport
+// ---------------------------------------------------------------------------
+
+// GitHubSkillImport is one proposed skill from a GitHub import. Action is
+// "add" for skills the user doesn't have and "keep" for ones they already
+// set; existing proficiencies are never changed by an import.
+type GitHubSkillImport struct {
+	Skill                domain.Skill            `json:"skill"`
+	Language             string                  `json:"language"`
+	Bytes                int64                   `json:"bytes"`
+	Share                float64                 `json:"share"`
+	SuggestedProficiency domain.ProficiencyLevel `json:"suggested_proficiency"`
+	CurrentProficiency   domain.ProficiencyLevel `json:"current_proficiency,omitempty"`
+	Action               string                  `json:"action"`
+}
+
+// PreviewGitHubImport aggregates language bytes across the user's own
+// repositories using their stored GitHub token, maps them onto catalog skills
+// and infers a proficiency from the volume of code. Nothing is persisted; the
+// client adds the skills the user confirms through the regular add-skill
+// endpoint.
+func (s *OnboardingService) PreviewGitHubImport(ctx context.Context, userID string) ([]GitHubSkillImport, error) {
+	db := s.db.WithContext(ctx)
+
+	var user domain.User
+	if err := db.Select("id", "github_token").First(&user, "id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch user: %w", err)
+	}
+	if user.GitHubToken == "" {
+		return nil, ErrGitHubNotConnected
+	}
+	token, err := auth.DecryptSecret(user.GitHubToken)
+	if err != nil {
+		return nil, ErrGitHubNotConnected
+	}
+
+	var repos []struct {
+		Fork         bool   `json:"fork"`
+		LanguagesURL string `json:"languages_url"`
+	}
+	if err := s.githubGet(ctx, token, "https://api.github.com/user/repos?per_page=100&affiliation=owner&sort=pushed", &repos); err != nil {
+		return nil, err
+	}
+
+	bytesByLang := make(map[string]int64)
+	var total int64
+	inspected := 0
+	for _, r := range repos {
+		if r.Fork || r.LanguagesURL == "" {
+			continue
+		}
+		if inspected == maxImportRepos {
+			break
+		}
+		inspected++
+
+		var langs map[string]int64
+		if err := s.githubGet(ctx, token, r.LanguagesURL, &langs); err != nil {
+			if err == ErrGitHubRateLimited || err == ErrGitHubNotConnected {
+				return nil, err
+			}
+			log.Warn().Err(err).Str("user_id", userID).Msg("github import: failed to fetch repo languages")
+			continue
+		}
+		for lang, n := range langs {
+			bytesByLang[lang] += n
+			total += n
+		}
+	}
+	if total == 0 {
+		return []GitHubSkillImport{}, nil
+	}
+
+	var catalog []domain.Skill
+	if err := db.Find(&catalog).Error; err != nil {
+		return nil, fmt.Errorf("failed to load skills: %w", err)
+	}
+	byLower := make(map[string]domain.Skill, len(catalog))
+	for _, sk := range catalog {
+		byLower[strings.ToLower(sk.Name)] = sk
+	}
+
+	var owned []domain.UserSkill
+	if err := db.Where("user_id = ?", userID).Find(&owned).Error; err != nil {
+		return nil, fmt.Errorf("failed to load user skills: %w", err)
+	}
+	current := make(map[uint]domain.ProficiencyLevel, len(owned))
+	for _, us := range owned {
+		current[us.SkillID] = us.ProficiencyLevel
+	}
+
+	results := make([]GitHubSkillImport, 0, len(bytesByLang))
+	for lang, n := range bytesByLang {
+		key := strings.ToLower(lang)
+		if alias, ok := githubLanguageAliases[key]; ok {
+			key = alias
+		}
+		sk, ok := byLower[key]
+		if !ok {
+			continue
+		}
+		share := float64(n) / float64(total)
+		imp := GitHubSkillImport{
+			Skill:                sk,
+			Language:             lang,
+			Bytes:                n,
+			Share:                math.Round(share*10000) / 100,
+			SuggestedProficiency: proficiencyFromBytes(n, share),
+			Action:               "add",
+		}
+		if level, ok := current[sk.ID]; ok {
+			imp.CurrentProficiency = level
+			imp.Action = "keep"
+		}
+		results = append(results, imp)
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Bytes > results[j].Bytes })
+	return results, nil
+}
+
+// proficiencyFromBytes is a rough volume heuristic: lots of code, or a
+// language dominating the user's repos, suggests deeper experience.
+func proficiencyFromBytes(n int64, share float64) domain.ProficiencyLevel {
+	switch {
+	case n >= 500_000 || share >= 0.30:
+		return domain.Advanced
+	case n >= 50_000 || share >= 0.10:
+		return domain.Intermediate
+	default:
+		return domain.Beginner
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Internal helpers
+// ---------------------------------------------------------------------------
+
+// githubLanguages returns the share of a user's public, non-fork repositories
+// written in each primary language.
+func (s *OnboardingService) githubLanguages(ctx context.Context, username string) (map[string]float64, error) {
+	if !domain.IsGitHubUsername(username) {
+		return nil, ErrInvalidGitHubUsername
+	}
+
+	url := fmt.Sprintf("https://api.github.com/users/%s/repos?per_page=100&sort=pushed", username)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build github request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch github repos: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrGitHubUserNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("github repos request returned status %d", resp.StatusCode)
+	}
+
+	var repos []struct {
+		Language string `json:"language"`
+		Fork     bool   `json:"fork"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&repos); err != nil {
+		return nil, fmt.Errorf("failed to decode github repos: %w", err)
+	}
+
+	counts := make(map[string]int)
+	total := 0
+	for _, r := range repos {
+		if r.Fork || r.Language == "" {
+			continue
+		}
+		counts[r.Language]++
+		total++
+	}
+
+	shares := make(map[string]float64, len(counts))
+	for lang, n := range counts {
+		shares[lang] = float64(n) / float64(total)
+	}
+	return shares, nil
+}
+
+// popularSkills is the cold-start fallback: the skills most users hold.
+func (s *OnboardingService) popularSkills(ctx context.Context, exclude map[uint]bool, limit int) ([]SkillSuggestion, error) {
+	db := s.db.WithContext(ctx)
+
+	type row struct {
+		SkillID uint
+		Holders int64
+	}
+	var rows []row
+	err := db.Model(&domain.UserSkill{}).
+		Select("skill_id, COUNT(*) AS holders").
+		Group("skill_id").
+		Order("holders DESC").
+		Limit(limit + len(exclude)).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load popular skills: %w", err)
+	}
+
+	ids := make([]uint, 0, len(rows))
+	for _, r := range rows {
+		if !exclude[r.SkillID] {
+			ids = append(ids, r.SkillID)
+		}
+	}
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	if len(ids) == 0 {
+		return []SkillSuggestion{}, nil
+	}
+
+	var skills []domain.Skill
+	if err := db.Where("id IN ?", ids).Find(&skills).Error; err != nil {
+		return nil, fmt.Errorf("failed to load skills: %w", err)
+	}
+	byID := make(map[uint]domain.Skill, len(skills))
+	for _, sk := range skills {
+		byID[sk.ID] = sk
+	}
+
+	var maxHolders int64 = 1
+	if len(rows) > 0 && rows[0].Holders > 0 {
+		maxHolders = rows[0].Holders
+	}
+	results := make([]SkillSuggestion, 0, len(ids))
+	for _, r := range rows {
+		sk, ok := byID[r.SkillID]
+		if !ok || exclude[r.SkillID] {
+			continue
+		}
+		results = append(results, SkillSuggestion{
+			Skill:   sk,
+			Score:   float64(r.Holders) / float64(maxHolders) * 100,
+			Sources: []string{"popular"},
+		})
+		if len(results) == limit {
+			break
+		}
+	}
+	return results, nil
+}
+
+// githubGet performs an authenticated GitHub API GET and decodes the JSON
+// response into out, translating auth and rate-limit failures.
+func (s *OnboardingService) githubGet(ctx context.Context, token, url string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build github request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("github request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		return ErrGitHubNotConnected
+	case resp.StatusCode == http.StatusTooManyRequests,
+		resp.StatusCode == http.StatusForbidden && resp.Header.Get("X-RateLimit-Remaining") == "0":
+		return ErrGitHubRateLimited
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("github request returned status %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode github response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/yourusername/skillsync/internal/domain"
+)
+
+// describingClaude maps descriptions to catalog skills from a fixed table.
+type describingClaude struct {
+	*MockClaudeService
+	skills map[string][]SkillRelevance
+}
+
+func (c describingClaude) MapDescriptionToSkills(ctx context.Context, description string, catalog []string) ([]SkillRelevance, error) {
+	inCatalog := make(map[string]bool, len(catalog))
+	for _, name := range catalog {
+		inCatalog[name] = true
+	}
+	var out []SkillRelevance
+	for _, r := range c.skills[description] {
+		if inCatalog[r.Skill] {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+func TestSuggestSkillsFromDescription(t *testing.T) {
+	db := testDB(t)
+	user := createTestUser(t, db)
+	goSkill := createTestSkill(t, db)
+	postgres := createTestSkill(t, db)
+	owned := createTestSkill(t, db)
+	addTestSkill(t, db, user, owned, domain.Intermediate, domain.DirectionBoth)
+
+	claude := describingClaude{
+		MockClaudeService: NewMockClaudeService(DefaultClaudeModels()),
+		skills: map[string][]SkillRelevance{
+			"backend Go dev": {
+				{Skill: postgres.Name, Relevance: 0.6},
+				{Skill: goSkill.Name, Relevance: 0.95},
+				{Skill: owned.Name, Relevance: 0.9},
+				{Skill: "not-in-catalog", Relevance: 0.8},
+			},
+		},
+	}
+	s := NewOnboardingService(db, claude)
+
+	got, err := s.SuggestSkills(context.Background(), user.ID, "", "  backend Go dev ", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d suggestions, want 2: %+v", len(got), got)
+	}
+	if got[0].Skill.ID != goSkill.ID || got[1].Skill.ID != postgres.ID {
+		t.Fatalf("ranking = %s, %s; want %s, %s", got[0].Skill.Name, got[1].Skill.Name, goSkill.Name, postgres.Name)
+	}
+	if len(got[0].Sources) != 1 || got[0].Sources[0] != "description" {
+		t.Fatalf("sources = %v, want [description]", got[0].Sources)
+	}
+
+	var count int64
+	db.Model(&domain.UserSkill{}).Where("user_id = ?", user.ID).Count(&count)
+	if count != 1 {
+		t.Fatalf("user has %d skills after suggesting, want 1", count)
+	}
+}
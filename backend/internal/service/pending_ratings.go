@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/skillsync/internal/domain"
+)
+
+// PendingRating is a completed session the caller still has to rate or give
+// feedback on, with the partner they paired with.
+type PendingRating struct {
+	SessionID       uint         `json:"session_id"`
+	MatchID         uint         `json:"match_id"`
+	StartedAt       time.Time    `json:"started_at"`
+	EndedAt         time.Time    `json:"ended_at"`
+	DurationMinutes int          `json:"duration_minutes"`
+	HasRated        bool         `json:"has_rated"`
+	HasFeedback     bool         `json:"has_feedback"`
+	Partner         *domain.User `json:"partner"`
+}
+
+// pendingRatingsFrom selects the ended sessions of userID's matches that lack
+// the caller's rating or feedback, with their partner still active. Its
+// placeholders are all userID.
+const pendingRatingsFrom = `
+	FROM coding_sessions
+	JOIN matches ON matches.id = coding_sessions.match_id
+	JOIN users partner ON partner.id = CASE WHEN matches.user1_id = ? THEN matches.user2_id ELSE matches.user1_id END
+		AND partner.deleted_at IS NULL
+	LEFT JOIN ratings ON ratings.session_id = coding_sessions.id AND ratings.rater_id = ?
+	LEFT JOIN session_feedbacks ON session_feedbacks.session_id = coding_sessions.id AND session_feedbacks.user_id = ?
+	WHERE (matches.user1_id = ? OR matches.user2_id = ?)
+		AND coding_sessions.ended_at IS NOT NULL
+		AND (ratings.id IS NULL OR session_feedbacks.id IS NULL)`
+
+// GetPendingRatings returns one page of userID's completed sessions they
+// haven't both rated and given feedback on, most recently ended first, and
+// how many there are in total.
+func (s *ReputationService) GetPendingRatings(ctx context.Context, userID string, limit, offset int) ([]*PendingRating, int64, error) {
+	db := s.db.WithContext(ctx)
+
+	args := []interface{}{userID, userID, userID, userID, userID}
+
+	var total int64
+	if err := db.Raw("SELECT COUNT(*)"+pendingRatingsFrom, args...).Scan(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count pending ratings: %w", err)
+	}
+
+	var rows []struct {
+		PendingRating
+		PartnerID string
+	}
+	err := db.Raw(`SELECT coding_sessions.id AS session_id, coding_sessions.match_id,
+			coding_sessions.started_at, coding_sessions.ended_at, coding_sessions.duration_minutes,
+			ratings.id IS NOT NULL AS has_rated, session_feedbacks.id IS NOT NULL AS has_feedback,
+			partner.id AS partner_id`+pendingRatingsFrom+`
+		ORDER BY coding_sessions.ended_at DESC, coding_sessions.id DESC
+		LIMIT ? OFFSET ?`, append(args, limit, offset)...).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch pending ratings: %w", err)
+	}
+
+	partnerIDs := make([]string, 0, len(rows))
+	for _, r := range rows {
+		partnerIDs = append(partnerIDs, r.PartnerID)
+	}
+	var partners []domain.User
+	if len(partnerIDs) > 0 {
+		if err := db.Where("id IN ?", partnerIDs).Find(&partners).Error; err != nil {
+			return nil, 0, fmt.Errorf("failed to fetch partners: %w", err)
+		}
+	}
+	byID := make(map[string]*domain.User, len(partners))
+	for i := range partners {
+		byID[partners[i].ID] = &partners[i]
+	}
+
+	results := make([]*PendingRating, 0, len(rows))
+	for i := range rows {
+		p := rows[i].PendingRating
+		p.Partner = byID[rows[i].PartnerID]
+		results = append(results, &p)
+	}
+	return results, total, nil
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultStatsCacheTTL = 5 * time.Minute
+	// popularSkillCount is how many skills PlatformStats ranks.
+	popularSkillCount = 10
+)
+
+// PopularSkill is a skill and how many users list it.
+type PopularSkill struct {
+	Name  string `json:"name"`
+	Users int64  `json:"users"`
+}
+
+// PublicPlatformStats are the platform-wide figures safe to show anyone.
+type PublicPlatformStats struct {
+	TotalUsers    int64          `json:"total_users"`
+	TotalMatches  int64          `json:"total_matches"`
+	TotalSessions int64          `json:"total_sessions"`
+	PopularSkills []PopularSkill `json:"popular_skills"`
+	GeneratedAt   time.Time      `json:"generated_at"`
+}
+
+// PlatformStats adds the figures reserved for admins: message volume says
+// how active private conversations are, and the average match score is a
+// tuning signal for the matching weights.
+type PlatformStats struct {
+	PublicPlatformStats
+	TotalMessages     int64   `json:"total_messages"`
+	AverageMatchScore float64 `json:"average_match_score"`
+}
+
+// StatsService computes PlatformStats and caches them for
+// PLATFORM_STATS_CACHE_TTL (default 5m).
+type StatsService struct {
+	db  *gorm.DB
+	ttl time.Duration
+
+	mu     sync.Mutex
+	cached *PlatformStats
+}
+
+func NewStatsService(db *gorm.DB) *StatsService {
+	return &StatsService{
+		db:  db,
+		ttl: getEnvDuration("PLATFORM_STATS_CACHE_TTL", defaultStatsCacheTTL),
+	}
+}
+
+// GetPlatformStats returns the cached stats, recomputing them once expired.
+func (s *StatsService) GetPlatformStats(ctx context.Context) (*PlatformStats, error) {
+	db := s.db.WithContext(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cached != nil && time.Since(s.cached.GeneratedAt) < s.ttl {
+		return s.cached, nil
+	}
+
+	var totals struct {
+		Users    int64
+		Matches  int64
+		Sessions int64
+		Messages int64
+		AvgScore float64
+	}
+	if err := db.Raw(`SELECT
+			(SELECT COUNT(*) FROM users WHERE deleted_at IS NULL) AS users,
+			(SELECT COUNT(*) FROM matches) AS matches,
+			(SELECT COUNT(*) FROM coding_sessions) AS sessions,
+			(SELECT COUNT(*) FROM messages) AS messages,
+			(SELECT COALESCE(AVG(match_score), 0) FROM matches) AS avg_score`).
+		Scan(&totals).Error; err != nil {
+		return nil, fmt.Errorf("failed to count platform totals: %w", err)
+	}
+
+	popular := []PopularSkill{}
+	if err := db.Raw(`SELECT skills.name AS name, COUNT(*) AS users
+		FROM user_skills
+		JOIN skills ON skills.id = user_skills.skill_id
+		JOIN users ON users.id = user_skills.user_id AND users.deleted_at IS NULL
+		GROUP BY skills.name
+		ORDER BY users DESC, skills.name ASC
+		LIMIT ?`, popularSkillCount).Scan(&popular).Error; err != nil {
+		return nil, fmt.Errorf("failed to rank skills: %w", err)
+	}
+
+	s.cached = &PlatformStats{
+		PublicPlatformStats: PublicPlatformStats{
+			TotalUsers:    totals.Users,
+			TotalMatches:  totals.Matches,
+			TotalSessions: totals.Sessions,
+			PopularSkills: popular,
+			GeneratedAt:   time.Now().UTC(),
+		},
+		TotalMessages:     totals.Messages,
+		AverageMatchScore: math.Round(totals.AvgScore*100) / 100,
+	}
+	return s.cached, nil
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+
+	"github.com/yourusername/skillsync/internal/domain"
+)
+
+const (
+	// presenceTouchThrottle bounds how often one user's last_active_at is
+	// written for API requests.
+	presenceTouchThrottle = time.Minute
+	presenceQueueSize     = 1024
+)
+
+// OnlineChecker reports whether a user has a live connection. The websocket
+// hub implements it.
+type OnlineChecker interface {
+	IsOnline(userID string) bool
+}
+
+// Presence is a user's online status and when they were last active.
+type Presence struct {
+	UserID       string     `json:"user_id"`
+	IsOnline     bool       `json:"is_online"`
+	LastActiveAt *time.Time `json:"last_active_at"`
+}
+
+// PresenceService keeps users.is_online and users.last_active_at current.
+// Websocket connects and disconnects are written by a single worker that
+// reads the hub's state when it gets to each user, so a burst of
+// reconnects always settles on the right value. API requests update
+// last_active_at, throttled to once a minute per user.
+//
+// is_online assumes a single API instance: it reflects this process's hub
+// only.
+type PresenceService struct {
+	db      *gorm.DB
+	online  OnlineChecker
+	changes chan string
+
+	mu        sync.Mutex
+	lastTouch map[string]time.Time
+}
+
+func NewPresenceService(db *gorm.DB, online OnlineChecker) *PresenceService {
+	return &PresenceService{
+		db:        db,
+		online:    online,
+		changes:   make(chan string, presenceQueueSize),
+		lastTouch: make(map[string]time.Time),
+	}
+}
+
+// PresenceChanged queues userID to have is_online refreshed. It never
+// blocks, so it is safe as the hub's presence hook.
+func (s *PresenceService) PresenceChanged(userID string) {
+	select {
+	case s.changes <- userID:
+	default:
+		log.Warn().Str("user_id", userID).Msg("presence queue full; update dropped")
+	}
+}
+
+// Run clears is_online left over from a previous process, then writes
+// queued presence changes until ctx is cancelled. Call as a goroutine.
+func (s *PresenceService) Run(ctx context.Context) {
+	if err := s.db.WithContext(ctx).Model(&domain.User{}).
+		Where("is_online").
+		Update("is_online", false).Error; err != nil {
+		log.Warn().Err(err).Msg("failed to reset online users")
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case userID := <-s.changes:
+			online := s.online.IsOnline(userID)
+			if err := s.db.WithContext(ctx).Model(&domain.User{}).Where("id = ?", userID).
+				Updates(map[string]interface{}{"is_online": online, "last_active_at": time.Now()}).Error; err != nil {
+				log.Warn().Err(err).Str("user_id", userID).Bool("online", online).Msg("failed to update presence")
+			}
+		}
+	}
+}
+
+// Touch records API activity by userID. It implements
+// middleware.ActivityRecorder.
+func (s *PresenceService) Touch(userID string) {
+	now := time.Now()
+
+	s.mu.Lock()
+	if last, ok := s.lastTouch[userID]; ok && now.Sub(last) < presenceTouchThrottle {
+		s.mu.Unlock()
+		return
+	}
+	s.lastTouch[userID] = now
+	// Entries older than the throttle no longer suppress anything.
+	if len(s.lastTouch) > presenceQueueSize {
+		for id, t := range s.lastTouch {
+			if now.Sub(t) >= presenceTouchThrottle {
+				delete(s.lastTouch, id)
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	if err := s.db.Model(&domain.User{}).Where("id = ?", userID).
+		Update("last_active_at", now).Error; err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Msg("failed to record user activity")
+	}
+}
+
+// GetPresence returns userID's presence. Online status comes from the live
+// connections rather than the stored column.
+func (s *PresenceService) GetPresence(ctx context.Context, userID string) (*Presence, error) {
+	var user domain.User
+	err := s.db.WithContext(ctx).Select("id", "last_active_at").First(&user, "id = ?", userID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user: %w", err)
+	}
+	return &Presence{
+		UserID:       user.ID,
+		IsOnline:     s.online.IsOnline(userID),
+		LastActiveAt: user.LastActiveAt,
+	}, nil
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/yourusername/skillsync/internal/domain"
+)
+
+const defaultProjectSuggestionCacheTTL = 15 * time.Minute
+
+var ErrNoSkills = errors.New("add skills to your profile to get project suggestions")
+
+// UserProjectSuggestions are project ideas generated from one user's
+// profile, along with the inputs Claude was given.
+type UserProjectSuggestions struct {
+	Skills      []string             `json:"skills"`
+	Level       string               `json:"level"`
+	Projects    []*ProjectSuggestion `json:"projects"`
+	GeneratedAt time.Time            `json:"generated_at"`
+}
+
+// ProjectSuggestionService suggests solo projects from a user's own skills.
+// Results are cached per user for PROJECT_SUGGESTION_CACHE_TTL (default
+// 15m); changing skills or proficiency bypasses the cache.
+type ProjectSuggestionService struct {
+	db     *gorm.DB
+	claude ClaudeService
+	ttl    time.Duration
+
+	mu    sync.Mutex
+	cache map[string]*UserProjectSuggestions
+}
+
+func NewProjectSuggestionService(db *gorm.DB, claude ClaudeService) *ProjectSuggestionService {
+	return &ProjectSuggestionService{
+		db:     db,
+		claude: claude,
+		ttl:    getEnvDuration("PROJECT_SUGGESTION_CACHE_TTL", defaultProjectSuggestionCacheTTL),
+		cache:  make(map[string]*UserProjectSuggestions),
+	}
+}
+
+// SuggestForUser passes userID's skills, sorted by name, and their highest
+// proficiency level to ClaudeService.SuggestProjects. It returns ErrNoSkills
+// when the user hasn't added any.
+func (s *ProjectSuggestionService) SuggestForUser(ctx context.Context, userID string) (*UserProjectSuggestions, error) {
+	var skills []domain.UserSkill
+	if err := s.db.WithContext(ctx).Preload("Skill").Where("user_id = ?", userID).Find(&skills).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch skills: %w", err)
+	}
+	if len(skills) == 0 {
+		return nil, ErrNoSkills
+	}
+
+	names := make([]string, 0, len(skills))
+	level := domain.Beginner
+	for _, sk := range skills {
+		names = append(names, sk.Skill.Name)
+		if proficiencyRank(sk.ProficiencyLevel) > proficiencyRank(level) {
+			level = sk.ProficiencyLevel
+		}
+	}
+	sort.Strings(names)
+
+	if cached := s.cached(userID, names, string(level)); cached != nil {
+		return cached, nil
+	}
+
+	projects, err := s.claude.SuggestProjects(ctx, names, string(level))
+	if err != nil {
+		return nil, err
+	}
+
+	result := &UserProjectSuggestions{
+		Skills:      names,
+		Level:       string(level),
+		Projects:    projects,
+		GeneratedAt: time.Now().UTC(),
+	}
+	s.mu.Lock()
+	s.cache[userID] = result
+	s.mu.Unlock()
+	return result, nil
+}
+
+// cached returns the user's cached suggestions if they are fresh and were
+// generated from the same skills and level.
+func (s *ProjectSuggestionService) cached(userID string, skills []string, level string) *UserProjectSuggestions {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.cache[userID]
+	if !ok {
+		return nil
+	}
+	if time.Since(e.GeneratedAt) > s.ttl {
+		delete(s.cache, userID)
+		return nil
+	}
+	if e.Level != level || strings.Join(e.Skills, "\x00") != strings.Join(skills, "\x00") {
+		return nil
+	}
+	return e
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrInvalidTrendWindow = errors.New("window must be day, week or month")
+
+// MaxTrendBuckets caps how many periods GetRatingTrends returns.
+const MaxTrendBuckets = 60
+
+// defaultTrendBuckets is how many periods each window covers when the caller
+// doesn't ask for a specific number.
+var defaultTrendBuckets = map[string]int{
+	"day":   30,
+	"week":  12,
+	"month": 12,
+}
+
+// RatingTrendBucket holds the averages of ratings received in one period.
+// Averages are nil for periods without ratings so charts can show gaps.
+type RatingTrendBucket struct {
+	PeriodStart      time.Time `json:"period_start"`
+	Count            int64     `json:"count"`
+	AvgOverall       *float64  `json:"avg_overall"`
+	AvgCodeQuality   *float64  `json:"avg_code_quality"`
+	AvgCommunication *float64  `json:"avg_communication"`
+	AvgHelpfulness   *float64  `json:"avg_helpfulness"`
+	AvgReliability   *float64  `json:"avg_reliability"`
+}
+
+// RatingTrends is a user's received ratings bucketed by period, oldest first.
+type RatingTrends struct {
+	Window  string              `json:"window"`
+	Buckets []RatingTrendBucket `json:"buckets"`
+}
+
+// ---------------------------------------------------------------------------
+// GetRatingTrends
+// ---------------------------------------------------------------------------
+
+// GetRatingTrends returns the last `buckets` periods of the given window
+// (day, week or month), ending with the current one. Every period is
+// present, including empty ones. buckets <= 0 uses the window's default and
+// larger values are capped at MaxTrendBuckets.
+func (s *ReputationService) GetRatingTrends(ctx context.Context, userID, window string, buckets int) (*RatingTrends, error) {
+	if window == "" {
+		window = "month"
+	}
+	def, ok := defaultTrendBuckets[window]
+	if !ok {
+		return nil, ErrInvalidTrendWindow
+	}
+	if buckets <= 0 {
+		buckets = def
+	}
+	if buckets > MaxTrendBuckets {
+		buckets = MaxTrendBuckets
+	}
+
+	// window is one of the whitelisted keys above, so it is safe to use as
+	// the date_trunc field and interval unit.
+	step := "1 " + window
+	rows := make([]RatingTrendBucket, 0, buckets)
+	err := s.db.WithContext(ctx).Raw(`
+		SELECT b.period_start,
+			COUNT(r.id) AS count,
+			ROUND(AVG(r.overall_rating)::numeric, 2)       AS avg_overall,
+			ROUND(AVG(r.code_quality_rating)::numeric, 2)  AS avg_code_quality,
+			ROUND(AVG(r.communication_rating)::numeric, 2) AS avg_communication,
+			ROUND(AVG(r.helpfulness_rating)::numeric, 2)   AS avg_helpfulness,
+			ROUND(AVG(r.reliability_rating)::numeric, 2)   AS avg_reliability
+		FROM generate_series(
+			date_trunc(?, NOW()) - (? - 1) * ?::interval,
+			date_trunc(?, NOW()),
+			?::interval
+		) AS b(period_start)
+		LEFT JOIN ratings r
+			ON r.rated_id = ? AND date_trunc(?, r.created_at) = b.period_start
+		GROUP BY b.period_start
+		ORDER BY b.period_start`,
+		window, buckets, step, window, step, userID, window).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute rating trends: %w", err)
+	}
+
+	return &RatingTrends{Window: window, Buckets: rows}, nil
+}
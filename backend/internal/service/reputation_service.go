@@ -1,13 +1,17 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
+	"sync/atomic"
+	"time"
 
 	"github.com/rs/zerolog/log"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/yourusername/skillsync/internal/domain"
 )
@@ -19,6 +23,10 @@ var (
 	ErrCannotRateSelf      = errors.New("you cannot rate yourself")
 	ErrAlreadyGaveFeedback = errors.New("you have already submitted feedback for this session")
 	ErrInvalidRating       = errors.New("ratings must be between 1 and 5")
+	ErrRatingNotFound      = errors.New("rating not found")
+	ErrNotRater            = errors.New("only the author of a rating can change it")
+	ErrRatingLocked        = errors.New("this rating can no longer be changed")
+	ErrRecalculationRunning = errors.New("a reputation recalculation is already running")
 )
 
 // SessionFeedbackInput is the input DTO for SubmitSessionFeedback.
@@ -32,12 +40,65 @@ type SessionFeedbackInput struct {
 	FeedbackText     string   `json:"feedback_text"`
 }
 
+// ReputationService computes and serves user reputation.
+//
+// Rating averages are time-weighted. A rating of age t carries weight
+//
+//	w(t) = 0.5 ^ (t / halfLife)
+//
+// and each category average is shrunk towards a neutral 3/5 by a single
+// pseudo-rating of weight 1:
+//
+//	avg = (Σ w·r + 3) / (Σ w + 1)
+//
+// Without the prior, a user whose ratings are all old would keep their score,
+// since the weights cancel out in a plain weighted mean. With it, scores fade
+// towards 50 as ratings age. halfLife comes from REPUTATION_HALF_LIFE (default
+// 180 days); "off" restores the unweighted average.
+//
+// Category scores are combined into the overall score with weights (see
+// ReputationWeights). Each session's success_rating is derived from its
+// feedback and ratings (see SessionSuccessFormula).
+//
+// Raters may edit or retract a rating for RATING_EDIT_WINDOW (default 48h)
+// after submitting it.
 type ReputationService struct {
-	db *gorm.DB
+	db             *gorm.DB
+	weights        ReputationWeights
+	trust          *trustCache
+	trustWeights   TrustWeights
+	successWeights SessionSuccessWeights
+	halfLife       time.Duration // zero disables decay
+	badgeRules     []BadgeRule
+	editWindow     time.Duration
+	// recalculating is set while a RecalculateAll run is in progress.
+	recalculating atomic.Bool
 }
 
-func NewReputationService(db *gorm.DB) *ReputationService {
-	return &ReputationService{db: db}
+const (
+	defaultReputationHalfLife = 180 * 24 * time.Hour
+	defaultRatingEditWindow   = 48 * time.Hour
+	// decayPriorRating and decayPriorWeight define the neutral pseudo-rating
+	// decayed averages are shrunk towards.
+	decayPriorRating = 3.0
+	decayPriorWeight = 1.0
+)
+
+func NewReputationService(db *gorm.DB, weights ReputationWeights) *ReputationService {
+	halfLife := getEnvDuration("REPUTATION_HALF_LIFE", defaultReputationHalfLife)
+	if getEnv("REPUTATION_HALF_LIFE", "") == "off" {
+		halfLife = 0
+	}
+	return &ReputationService{
+		db:             db,
+		weights:        weights,
+		trust:          newTrustCache(getEnvDuration("TRUST_SCORE_CACHE_TTL", 10*time.Minute)),
+		trustWeights:   trustWeightsFromEnv(),
+		successWeights: sessionSuccessWeightsFromEnv(),
+		halfLife:       halfLife,
+		badgeRules:     DefaultBadgeRules,
+		editWindow:     getEnvDuration("RATING_EDIT_WINDOW", defaultRatingEditWindow),
+	}
 }
 
 // ---------------------------------------------------------------------------
@@ -45,10 +106,13 @@ func NewReputationService(db *gorm.DB) *ReputationService {
 // ---------------------------------------------------------------------------
 
 func (s *ReputationService) SubmitRating(
+	ctx context.Context,
 	raterID, ratedID string, sessionID uint,
 	overallRating, codeQuality, communication, helpfulness, reliability int,
 	comment string,
 ) error {
+	db := s.db.WithContext(ctx)
+
 	if raterID == ratedID {
 		return ErrCannotRateSelf
 	}
@@ -62,7 +126,7 @@ func (s *ReputationService) SubmitRating(
 
 	// Verify the session exists.
 	var session domain.CodingSession
-	if err := s.db.Preload("Match").First(&session, "id = ?", sessionID).Error; err != nil {
+	if err := db.Preload("Match").First(&session, "id = ?", sessionID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return ErrSessionNotFound
 		}
@@ -78,7 +142,7 @@ func (s *ReputationService) SubmitRating(
 
 	// Check for duplicate rating.
 	var exists int64
-	s.db.Model(&domain.Rating{}).
+	db.Model(&domain.Rating{}).
 		Where("rater_id = ? AND rated_id = ? AND session_id = ?", raterID, ratedID, sessionID).
 		Count(&exists)
 	if exists > 0 {
@@ -96,32 +160,128 @@ func (s *ReputationService) SubmitRating(
 		ReliabilityRating:   reliability,
 		Comment:             comment,
 	}
-	if err := s.db.Create(&rating).Error; err != nil {
+	if err := db.Create(&rating).Error; err != nil {
+		// A concurrent submit can pass the check above; the unique index
+		// on (rater_id, rated_id, session_id) catches it.
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return ErrAlreadyRated
+		}
 		return fmt.Errorf("failed to save rating: %w", err)
 	}
+	s.refreshSessionSuccess(sessionID)
+	s.ratingChanged(raterID, ratedID)
 
-	// Recalculate the rated user's reputation in the background.
-	go func() {
-		if _, err := s.CalculateUserReputation(ratedID); err != nil {
-			log.Error().Err(err).Str("user_id", ratedID).Msg("failed to recalculate reputation after rating")
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// UpdateRating / RetractRating
+// ---------------------------------------------------------------------------
+
+// RatingScores are the editable parts of a rating.
+type RatingScores struct {
+	Overall       int
+	CodeQuality   int
+	Communication int
+	Helpfulness   int
+	Reliability   int
+	Comment       string
+}
+
+// UpdateRating replaces the scores and comment of a rating raterID wrote
+// within the edit window, then recalculates the rated user's reputation.
+func (s *ReputationService) UpdateRating(ctx context.Context, ratingID uint, raterID string, in RatingScores) (*domain.Rating, error) {
+	for _, v := range []int{in.Overall, in.CodeQuality, in.Communication, in.Helpfulness, in.Reliability} {
+		if v < 1 || v > 5 {
+			return nil, ErrInvalidRating
 		}
-	}()
+	}
+
+	rating, err := s.editableRating(ctx, ratingID, raterID)
+	if err != nil {
+		return nil, err
+	}
+
+	rating.OverallRating = in.Overall
+	rating.CodeQualityRating = in.CodeQuality
+	rating.CommunicationRating = in.Communication
+	rating.HelpfulnessRating = in.Helpfulness
+	rating.ReliabilityRating = in.Reliability
+	rating.Comment = in.Comment
+	if err := s.db.WithContext(ctx).Select("overall_rating", "code_quality_rating", "communication_rating",
+		"helpfulness_rating", "reliability_rating", "comment", "updated_at").
+		Save(rating).Error; err != nil {
+		return nil, fmt.Errorf("failed to update rating: %w", err)
+	}
+	s.refreshSessionSuccess(rating.SessionID)
+	s.ratingChanged(rating.RaterID, rating.RatedID)
 
+	return rating, nil
+}
+
+// RetractRating deletes a rating raterID wrote within the edit window, then
+// recalculates the rated user's reputation.
+func (s *ReputationService) RetractRating(ctx context.Context, ratingID uint, raterID string) error {
+	rating, err := s.editableRating(ctx, ratingID, raterID)
+	if err != nil {
+		return err
+	}
+	if err := s.db.WithContext(ctx).Delete(rating).Error; err != nil {
+		return fmt.Errorf("failed to delete rating: %w", err)
+	}
+	s.refreshSessionSuccess(rating.SessionID)
+	s.ratingChanged(rating.RaterID, rating.RatedID)
 	return nil
 }
 
+// editableRating loads a rating and checks that raterID wrote it and that
+// the edit window hasn't closed.
+func (s *ReputationService) editableRating(ctx context.Context, ratingID uint, raterID string) (*domain.Rating, error) {
+	var rating domain.Rating
+	if err := s.db.WithContext(ctx).First(&rating, ratingID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRatingNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch rating: %w", err)
+	}
+	if rating.RaterID != raterID {
+		return nil, ErrNotRater
+	}
+	if time.Since(rating.CreatedAt) > s.editWindow {
+		return nil, ErrRatingLocked
+	}
+	return &rating, nil
+}
+
+// ratingChanged drops the rater's and rated user's cached trust scores and
+// recalculates their reputations in the background. The rater is included
+// because the rating feeds the session's success rating, and with it both
+// participants' skill credibility.
+func (s *ReputationService) ratingChanged(raterID, ratedID string) {
+	s.trust.invalidate(raterID, ratedID)
+	go func() {
+		for _, id := range []string{ratedID, raterID} {
+			if _, err := s.CalculateUserReputation(context.Background(), id); err != nil {
+				log.Error().Err(err).Str("user_id", id).Msg("failed to recalculate reputation after rating")
+			}
+		}
+	}()
+}
+
 // ---------------------------------------------------------------------------
 // SubmitSessionFeedback
 // ---------------------------------------------------------------------------
 
-func (s *ReputationService) SubmitSessionFeedback(sessionID uint, userID string, input SessionFeedbackInput) error {
+func (s *ReputationService) SubmitSessionFeedback(ctx context.Context, sessionID uint, userID string, input SessionFeedbackInput) error {
+	db := s.db.WithContext(ctx)
+
 	if input.Rating < 1 || input.Rating > 5 {
 		return ErrInvalidRating
 	}
 
 	// Verify session exists and user is a participant.
 	var session domain.CodingSession
-	if err := s.db.Preload("Match").First(&session, "id = ?", sessionID).Error; err != nil {
+	if err := db.Preload("Match").First(&session, "id = ?", sessionID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return ErrSessionNotFound
 		}
@@ -133,15 +293,6 @@ func (s *ReputationService) SubmitSessionFeedback(sessionID uint, userID string,
 		return ErrNotSessionParticipant
 	}
 
-	// No duplicate feedback.
-	var exists int64
-	s.db.Model(&domain.SessionFeedback{}).
-		Where("session_id = ? AND user_id = ?", sessionID, userID).
-		Count(&exists)
-	if exists > 0 {
-		return ErrAlreadyGaveFeedback
-	}
-
 	strengthsJSON, _ := json.Marshal(input.Strengths)
 	improvementsJSON, _ := json.Marshal(input.Improvements)
 
@@ -156,9 +307,43 @@ func (s *ReputationService) SubmitSessionFeedback(sessionID uint, userID string,
 		Rating:           input.Rating,
 		FeedbackText:     input.FeedbackText,
 	}
-	if err := s.db.Create(&fb).Error; err != nil {
-		return fmt.Errorf("failed to save session feedback: %w", err)
+
+	// The session row is locked so the two participants' submissions are
+	// serialized and each recomputation sees the other's feedback.
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&domain.CodingSession{}, "id = ?", sessionID).Error; err != nil {
+			return fmt.Errorf("failed to lock session: %w", err)
+		}
+
+		// No duplicate feedback.
+		var exists int64
+		tx.Model(&domain.SessionFeedback{}).
+			Where("session_id = ? AND user_id = ?", sessionID, userID).
+			Count(&exists)
+		if exists > 0 {
+			return ErrAlreadyGaveFeedback
+		}
+
+		if err := tx.Create(&fb).Error; err != nil {
+			return fmt.Errorf("failed to save session feedback: %w", err)
+		}
+		return s.updateSessionSuccess(tx, sessionID)
+	})
+	if err != nil {
+		return err
 	}
+	s.trust.invalidate(match.User1ID, match.User2ID)
+
+	// Session success feeds skill credibility, so refresh both participants.
+	go func() {
+		for _, id := range []string{match.User1ID, match.User2ID} {
+			if _, err := s.CalculateUserReputation(context.Background(), id); err != nil {
+				log.Error().Err(err).Str("user_id", id).Msg("failed to recalculate reputation after session feedback")
+			}
+		}
+	}()
+
 	return nil
 }
 
@@ -166,8 +351,45 @@ func (s *ReputationService) SubmitSessionFeedback(sessionID uint, userID string,
 // CalculateUserReputation
 // ---------------------------------------------------------------------------
 
-func (s *ReputationService) CalculateUserReputation(userID string) (*domain.UserReputation, error) {
-	// Aggregate all ratings received by the user.
+// CalculateUserReputation recomputes userID's reputation from their ratings,
+// sessions and skills and stores it, along with the denormalized score on the
+// user row and any newly earned badges.
+//
+// Ratings are recalculated in the background as they arrive, so two
+// recalculations for the same user can overlap. Each one runs in a single
+// transaction that first locks the user row: concurrent runs for a user
+// (across instances too) are serialized, and a run that waited reads every
+// rating committed before it got the lock instead of overwriting a newer
+// result with a stale one.
+//
+// Background callers pass a context that outlives any request, so work
+// already committed to (a saved rating) is not abandoned with it.
+func (s *ReputationService) CalculateUserReputation(ctx context.Context, userID string) (*domain.UserReputation, error) {
+	var rep domain.UserReputation
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").First(&domain.User{}, "id = ?", userID).Error; err != nil {
+			return fmt.Errorf("failed to lock user: %w", err)
+		}
+
+		r, err := s.computeReputation(tx, userID)
+		if err != nil {
+			return err
+		}
+		rep = *r
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &rep, nil
+}
+
+// computeReputation does the work of CalculateUserReputation inside tx, which
+// must hold the lock on userID's row.
+func (s *ReputationService) computeReputation(tx *gorm.DB, userID string) (*domain.UserReputation, error) {
+	// Aggregate all ratings received by the user. AvgOverall stays the plain
+	// star average; the category averages are decayed by age.
 	var stats struct {
 		Count         int64
 		AvgOverall    float64
@@ -176,17 +398,12 @@ func (s *ReputationService) CalculateUserReputation(userID string) (*domain.User
 		AvgHelp       float64
 		AvgReliable   float64
 	}
-	s.db.Model(&domain.Rating{}).
+	if err := tx.Model(&domain.Rating{}).
 		Where("rated_id = ?", userID).
-		Select(`
-			COUNT(*)                         AS count,
-			COALESCE(AVG(overall_rating),0)       AS avg_overall,
-			COALESCE(AVG(code_quality_rating),0)  AS avg_code,
-			COALESCE(AVG(communication_rating),0) AS avg_comm,
-			COALESCE(AVG(helpfulness_rating),0)    AS avg_help,
-			COALESCE(AVG(reliability_rating),0)    AS avg_reliable
-		`).
-		Scan(&stats)
+		Select(s.ratingAggregateSQL()).
+		Scan(&stats).Error; err != nil {
+		return nil, fmt.Errorf("failed to aggregate ratings: %w", err)
+	}
 
 	// Normalize 1-5 averages to 0-100 scale.
 	codeScore := normalize(stats.AvgCode)
@@ -194,33 +411,36 @@ func (s *ReputationService) CalculateUserReputation(userID string) (*domain.User
 	helpScore := normalize(stats.AvgHelp)
 	reliScore := normalize(stats.AvgReliable)
 
-	// Weighted overall: code(30%) + communication(30%) + helpfulness(20%) + reliability(20%)
-	overall := codeScore*0.30 + commScore*0.30 + helpScore*0.20 + reliScore*0.20
+	overall := s.weights.overall(codeScore, commScore, helpScore, reliScore)
 
 	// Count completed sessions.
 	var completedSessions int64
-	s.db.Model(&domain.CodingSession{}).
+	if err := tx.Model(&domain.CodingSession{}).
 		Joins("JOIN matches ON matches.id = coding_sessions.match_id").
 		Where("(matches.user1_id = ? OR matches.user2_id = ?) AND coding_sessions.ended_at IS NOT NULL",
 			userID, userID).
-		Count(&completedSessions)
+		Count(&completedSessions).Error; err != nil {
+		return nil, fmt.Errorf("failed to count completed sessions: %w", err)
+	}
 
 	// Count successful matches (active matches with at least one completed session).
 	var successfulMatches int64
-	s.db.Model(&domain.Match{}).
+	if err := tx.Model(&domain.Match{}).
 		Where("(user1_id = ? OR user2_id = ?) AND status = ?", userID, userID, domain.MatchActive).
 		Where(`id IN (
 			SELECT match_id FROM coding_sessions WHERE ended_at IS NOT NULL
 		)`).
-		Count(&successfulMatches)
+		Count(&successfulMatches).Error; err != nil {
+		return nil, fmt.Errorf("failed to count successful matches: %w", err)
+	}
 
 	// Calculate per-skill credibility scores.
-	skillCredibility := s.calculateSkillCredibility(userID)
+	skillCredibility := s.calculateSkillCredibility(tx, userID)
 	credJSON, _ := json.Marshal(skillCredibility)
 
 	// Upsert reputation row.
 	var rep domain.UserReputation
-	err := s.db.Where("user_id = ?", userID).First(&rep).Error
+	err := tx.Where("user_id = ?", userID).First(&rep).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		rep = domain.UserReputation{UserID: userID}
 	} else if err != nil {
@@ -239,37 +459,157 @@ func (s *ReputationService) CalculateUserReputation(userID string) (*domain.User
 	rep.SkillCredibilityScores = domain.JSONB(credJSON)
 
 	if rep.ID == 0 {
-		err = s.db.Create(&rep).Error
+		err = tx.Create(&rep).Error
 	} else {
-		err = s.db.Save(&rep).Error
+		err = tx.Save(&rep).Error
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to save reputation: %w", err)
 	}
 
 	// Sync the denormalized score on the user row.
-	s.db.Model(&domain.User{}).Where("id = ?", userID).
+	if err := tx.Model(&domain.User{}).Where("id = ?", userID).
 		Updates(map[string]interface{}{
 			"reputation_score": rep.OverallScore,
 			"total_sessions":   rep.CompletedSessions,
-		})
+		}).Error; err != nil {
+		return nil, fmt.Errorf("failed to sync user reputation: %w", err)
+	}
 
 	// Award badges.
-	s.awardBadges(userID, &rep)
+	s.awardBadges(tx, userID, &rep)
 
 	return &rep, nil
 }
 
+// RunDecayRefresh recomputes the reputation of every rated user at startup
+// and then once a day, so that stored scores decay even for users who
+// receive no new ratings and restarts don't postpone the refresh. It returns
+// immediately when decay is disabled. Call as a goroutine.
+func (s *ReputationService) RunDecayRefresh(ctx context.Context) {
+	if s.halfLife <= 0 {
+		return
+	}
+
+	s.refreshDecay(ctx)
+
+	ticker := time.NewTicker(24 * time.Hour)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.refreshDecay(ctx)
+		}
+	}
+}
+
+// refreshDecay runs one decay pass over every rated user.
+func (s *ReputationService) refreshDecay(ctx context.Context) {
+	var userIDs []string
+	if err := s.db.WithContext(ctx).Model(&domain.UserReputation{}).
+		Where("total_ratings > 0").
+		Pluck("user_id", &userIDs).Error; err != nil {
+		log.Error().Err(err).Msg("reputation decay: failed to list users")
+		return
+	}
+	s.recalculateUsers(ctx, userIDs, "reputation decay")
+}
+
+// ---------------------------------------------------------------------------
+// On-demand recalculation
+// ---------------------------------------------------------------------------
+
+// RecalculateUserReputation recomputes userID's reputation now, dropping
+// their cached trust score, and returns the fresh scores. Use it after the
+// weights change or ratings are removed outside the rating endpoints.
+func (s *ReputationService) RecalculateUserReputation(ctx context.Context, userID string) (*domain.UserReputation, error) {
+	var exists int64
+	if err := s.db.WithContext(ctx).Model(&domain.User{}).Where("id = ?", userID).Count(&exists).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch user: %w", err)
+	}
+	if exists == 0 {
+		return nil, ErrUserNotFound
+	}
+
+	s.trust.invalidate(userID)
+	return s.CalculateUserReputation(ctx, userID)
+}
+
+// RecalculateAll starts recomputing the reputation of every user in the
+// background and returns how many are queued. Only one run may be in
+// progress; a second call meanwhile returns ErrRecalculationRunning.
+func (s *ReputationService) RecalculateAll(ctx context.Context) (int, error) {
+	if !s.recalculating.CompareAndSwap(false, true) {
+		return 0, ErrRecalculationRunning
+	}
+
+	var userIDs []string
+	if err := s.db.Model(&domain.User{}).Pluck("id", &userIDs).Error; err != nil {
+		s.recalculating.Store(false)
+		return 0, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	go func() {
+		defer s.recalculating.Store(false)
+		s.trust.invalidate(userIDs...)
+		s.recalculateUsers(ctx, userIDs, "reputation recalculation")
+	}()
+	return len(userIDs), nil
+}
+
+// recalculateUsers runs CalculateUserReputation for each of userIDs, logging
+// failures under label, until done or ctx is cancelled.
+func (s *ReputationService) recalculateUsers(ctx context.Context, userIDs []string, label string) {
+	for _, id := range userIDs {
+		if ctx.Err() != nil {
+			return
+		}
+		if _, err := s.CalculateUserReputation(ctx, id); err != nil {
+			log.Warn().Err(err).Str("user_id", id).Msg(label + ": recalculation failed")
+		}
+	}
+	log.Info().Int("users", len(userIDs)).Msg(label + " complete")
+}
+
 // ---------------------------------------------------------------------------
 // GetTopContributors
 // ---------------------------------------------------------------------------
 
-func (s *ReputationService) GetTopContributors(category string, limit int) ([]*UserWithReputation, error) {
-	if limit <= 0 || limit > 100 {
-		limit = 10
+// Canonical leaderboard page size. Callers pass limits through unvalidated;
+// GetTopContributors is the single place they are normalized.
+const (
+	DefaultLeaderboardLimit = 20
+	MaxLeaderboardLimit     = 100
+)
+
+// normalizeLeaderboardLimit maps a non-positive limit to
+// DefaultLeaderboardLimit and caps anything above MaxLeaderboardLimit.
+func normalizeLeaderboardLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultLeaderboardLimit
+	}
+	if limit > MaxLeaderboardLimit {
+		return MaxLeaderboardLimit
 	}
+	return limit
+}
+
+// leaderboardTieBreak orders users with equal scores: more ratings first,
+// then more completed sessions, then by user ID so the order is stable
+// between requests.
+const leaderboardTieBreak = "user_reputations.total_ratings DESC, user_reputations.completed_sessions DESC, user_reputations.user_id ASC"
+
+// GetTopContributors returns users ordered by the given score category, ties
+// broken by leaderboardTieBreak. limit defaults to DefaultLeaderboardLimit and is capped at
+// MaxLeaderboardLimit.
+func (s *ReputationService) GetTopContributors(ctx context.Context, category string, limit int) ([]*UserWithReputation, error) {
+	db := s.db.WithContext(ctx)
 
-	query := s.db.Model(&domain.UserReputation{})
+	limit = normalizeLeaderboardLimit(limit)
+
+	query := db.Model(&domain.UserReputation{})
 
 	// Allow filtering by score category.
 	var orderCol string
@@ -289,7 +629,7 @@ func (s *ReputationService) GetTopContributors(category string, limit int) ([]*U
 	var reps []domain.UserReputation
 	err := query.
 		Where("total_ratings > 0").
-		Order(orderCol + " DESC").
+		Order(orderCol + " DESC, " + leaderboardTieBreak).
 		Limit(limit).
 		Find(&reps).Error
 	if err != nil {
@@ -300,7 +640,7 @@ func (s *ReputationService) GetTopContributors(category string, limit int) ([]*U
 	results := make([]*UserWithReputation, 0, len(reps))
 	for i := range reps {
 		var user domain.User
-		if err := s.db.Preload("Skills.Skill").First(&user, reps[i].UserID).Error; err != nil {
+		if err := db.Preload("Skills.Skill").First(&user, "id = ?", reps[i].UserID).Error; err != nil {
 			continue
 		}
 		rep := reps[i] // copy for safe pointer
@@ -313,10 +653,141 @@ func (s *ReputationService) GetTopContributors(category string, limit int) ([]*U
 	return results, nil
 }
 
+// ---------------------------------------------------------------------------
+// GetTopBySkill
+// ---------------------------------------------------------------------------
+
+// SkillContributor is a leaderboard row for a single skill.
+type SkillContributor struct {
+	UserWithReputation
+	SkillScore float64 `json:"skill_score"`
+}
+
+// GetTopBySkill ranks users who hold skillName by the "total" of their
+// per-skill credibility score, ties broken by leaderboardTieBreak. skillName is matched case-insensitively and
+// ErrSkillNotFound is returned for unknown skills. limit is normalized the
+// same way as GetTopContributors.
+func (s *ReputationService) GetTopBySkill(ctx context.Context, skillName string, limit int) (*domain.Skill, []*SkillContributor, error) {
+	db := s.db.WithContext(ctx)
+
+	limit = normalizeLeaderboardLimit(limit)
+
+	var skill domain.Skill
+	if err := db.Where("LOWER(name) = LOWER(?)", skillName).First(&skill).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrSkillNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to fetch skill: %w", err)
+	}
+
+	type row struct {
+		domain.UserReputation
+		SkillScore float64
+	}
+	var rows []row
+	err := db.Model(&domain.UserReputation{}).
+		Select("user_reputations.*, COALESCE((user_reputations.skill_credibility_scores -> ? ->> 'total')::numeric, 0) AS skill_score", skill.Name).
+		Joins("JOIN user_skills ON user_skills.user_id = user_reputations.user_id AND user_skills.skill_id = ?", skill.ID).
+		Where("user_reputations.skill_credibility_scores -> ? IS NOT NULL", skill.Name).
+		Order("skill_score DESC, " + leaderboardTieBreak).
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch skill leaderboard: %w", err)
+	}
+
+	userIDs := make([]string, len(rows))
+	for i, r := range rows {
+		userIDs[i] = r.UserID
+	}
+	var users []domain.User
+	if len(userIDs) > 0 {
+		if err := db.Preload("Skills.Skill").Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+			return nil, nil, fmt.Errorf("failed to fetch users: %w", err)
+		}
+	}
+	byID := make(map[string]domain.User, len(users))
+	for _, u := range users {
+		byID[u.ID] = u
+	}
+
+	results := make([]*SkillContributor, 0, len(rows))
+	for i := range rows {
+		user, ok := byID[rows[i].UserID]
+		if !ok {
+			continue
+		}
+		rep := rows[i].UserReputation // copy for safe pointer
+		results = append(results, &SkillContributor{
+			UserWithReputation: UserWithReputation{User: user, Reputation: &rep},
+			SkillScore:         rows[i].SkillScore,
+		})
+	}
+
+	return &skill, results, nil
+}
+
+// CountRanked returns how many users appear on the overall leaderboard in
+// any category: those with at least one rating.
+func (s *ReputationService) CountRanked(ctx context.Context) (int64, error) {
+	var n int64
+	if err := s.db.WithContext(ctx).Model(&domain.UserReputation{}).Where("total_ratings > 0").Count(&n).Error; err != nil {
+		return 0, fmt.Errorf("failed to count ranked users: %w", err)
+	}
+	return n, nil
+}
+
+// CountRankedBySkill returns how many users GetTopBySkill could rank for
+// skill.
+func (s *ReputationService) CountRankedBySkill(ctx context.Context, skill *domain.Skill) (int64, error) {
+	var n int64
+	if err := s.db.WithContext(ctx).Model(&domain.UserReputation{}).
+		Joins("JOIN user_skills ON user_skills.user_id = user_reputations.user_id AND user_skills.skill_id = ?", skill.ID).
+		Where("user_reputations.skill_credibility_scores -> ? IS NOT NULL", skill.Name).
+		Count(&n).Error; err != nil {
+		return 0, fmt.Errorf("failed to count skill leaderboard: %w", err)
+	}
+	return n, nil
+}
+
 // ---------------------------------------------------------------------------
 // Internal helpers
 // ---------------------------------------------------------------------------
 
+// ratingAggregateSQL builds the SELECT list for CalculateUserReputation. With
+// decay enabled, category averages follow the formula documented on
+// ReputationService.
+func (s *ReputationService) ratingAggregateSQL() string {
+	if s.halfLife <= 0 {
+		return `
+			COUNT(*)                         AS count,
+			COALESCE(AVG(overall_rating),0)       AS avg_overall,
+			COALESCE(AVG(code_quality_rating),0)  AS avg_code,
+			COALESCE(AVG(communication_rating),0) AS avg_comm,
+			COALESCE(AVG(helpfulness_rating),0)    AS avg_help,
+			COALESCE(AVG(reliability_rating),0)    AS avg_reliable
+		`
+	}
+
+	weight := fmt.Sprintf("POWER(0.5, EXTRACT(EPOCH FROM (NOW() - created_at)) / %f)", s.halfLife.Seconds())
+	decayed := func(col string) string {
+		return fmt.Sprintf("(COALESCE(SUM(%s * %s),0) + %f) / (COALESCE(SUM(%s),0) + %f)",
+			weight, col, decayPriorRating*decayPriorWeight, weight, decayPriorWeight)
+	}
+	return fmt.Sprintf(`
+			COUNT(*)                         AS count,
+			COALESCE(AVG(overall_rating),0)       AS avg_overall,
+			CASE WHEN COUNT(*) = 0 THEN 0 ELSE %s END AS avg_code,
+			CASE WHEN COUNT(*) = 0 THEN 0 ELSE %s END AS avg_comm,
+			CASE WHEN COUNT(*) = 0 THEN 0 ELSE %s END AS avg_help,
+			CASE WHEN COUNT(*) = 0 THEN 0 ELSE %s END AS avg_reliable
+		`,
+		decayed("code_quality_rating"),
+		decayed("communication_rating"),
+		decayed("helpfulness_rating"),
+		decayed("reliability_rating"))
+}
+
 // normalize converts a 1-5 average to a 0-100 score.
 func normalize(avg float64) float64 {
 	if avg <= 0 {
@@ -338,9 +809,9 @@ type SkillCredibilityScore struct {
 // user's skills:
 //
 //	(AI_assessments * 0.4) + (peer_verifications * 0.4) + (session_success * 0.2)
-func (s *ReputationService) calculateSkillCredibility(userID string) map[string]SkillCredibilityScore {
+func (s *ReputationService) calculateSkillCredibility(db *gorm.DB, userID string) map[string]SkillCredibilityScore {
 	var userSkills []domain.UserSkill
-	s.db.Preload("Skill").Where("user_id = ?", userID).Find(&userSkills)
+	db.Preload("Skill").Where("user_id = ?", userID).Find(&userSkills)
 
 	result := make(map[string]SkillCredibilityScore, len(userSkills))
 
@@ -348,7 +819,7 @@ func (s *ReputationService) calculateSkillCredibility(userID string) map[string]
 		// AI assessment component: average AI score from assessments in the
 		// skill's language / challenge area (normalized to 0-100).
 		var avgAI float64
-		s.db.Model(&domain.Assessment{}).
+		db.Model(&domain.Assessment{}).
 			Where("user_id = ? AND language = ?", userID, us.Skill.Name).
 			Select("COALESCE(AVG(ai_score), 0)").
 			Scan(&avgAI)
@@ -359,7 +830,7 @@ func (s *ReputationService) calculateSkillCredibility(userID string) map[string]
 		// Session success component: average success_rating across all sessions
 		// the user participated in (normalized 0-100, already 0-1 in DB * 100).
 		var avgSession float64
-		s.db.Model(&domain.CodingSession{}).
+		db.Model(&domain.CodingSession{}).
 			Joins("JOIN matches ON matches.id = coding_sessions.match_id").
 			Where("(matches.user1_id = ? OR matches.user2_id = ?) AND coding_sessions.ended_at IS NOT NULL",
 				userID, userID).
@@ -381,52 +852,3 @@ func (s *ReputationService) calculateSkillCredibility(userID string) map[string]
 
 	return result
 }
-
-// awardBadges checks badge criteria and updates the user's badges JSONB.
-func (s *ReputationService) awardBadges(userID string, rep *domain.UserReputation) {
-	type badge struct {
-		Name        string `json:"name"`
-		Description string `json:"description"`
-	}
-
-	var badges []badge
-
-	if rep.OverallScore >= 90 {
-		badges = append(badges, badge{
-			Name:        "Top Contributor",
-			Description: "Maintained an overall reputation score above 90",
-		})
-	}
-	if rep.CodeQualityScore >= 95 {
-		badges = append(badges, badge{
-			Name:        "Code Master",
-			Description: "Achieved a code quality score above 95",
-		})
-	}
-	if rep.CompletedSessions >= 50 {
-		badges = append(badges, badge{
-			Name:        "Session Guru",
-			Description: "Completed over 50 pair-programming sessions",
-		})
-	}
-	if rep.SuccessfulMatches >= 25 {
-		badges = append(badges, badge{
-			Name:        "Networking Pro",
-			Description: "Successfully matched with over 25 developers",
-		})
-	}
-	if rep.AverageRating >= 4.8 && rep.TotalRatings >= 10 {
-		badges = append(badges, badge{
-			Name:        "Highly Rated",
-			Description: "Maintained a 4.8+ average rating with at least 10 reviews",
-		})
-	}
-
-	if len(badges) == 0 {
-		badges = []badge{}
-	}
-
-	data, _ := json.Marshal(badges)
-	s.db.Model(&domain.User{}).Where("id = ?", userID).
-		Update("badges", domain.JSONB(data))
-}
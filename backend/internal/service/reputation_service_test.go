@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yourusername/skillsync/internal/domain"
+)
+
+func TestSubmitRatingConcurrentReputation(t *testing.T) {
+	db := testDB(t)
+	s := NewReputationService(db, DefaultReputationWeights())
+	ctx := context.Background()
+
+	const n = 12
+	rated := createTestUser(t, db)
+	raters := make([]*domain.User, n)
+	sessions := make([]*domain.CodingSession, n)
+	for i := range raters {
+		raters[i] = createTestUser(t, db)
+		sessions[i] = createTestSession(t, db, createTestMatch(t, db, rated, raters[i]))
+	}
+
+	// Every rating triggers a background recalculation; extra direct
+	// recalculations race with them and with the inserts.
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*n)
+	sum := 0
+	for i := 0; i < n; i++ {
+		stars := i%5 + 1
+		sum += stars
+		wg.Add(2)
+		go func(i, stars int) {
+			defer wg.Done()
+			errs <- s.SubmitRating(ctx, raters[i].ID, rated.ID, sessions[i].ID, stars, stars, stars, stars, stars, "")
+		}(i, stars)
+		go func() {
+			defer wg.Done()
+			_, err := s.CalculateUserReputation(ctx, rated.ID)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The background recalculations finish on their own; the last one to
+	// take the lock must see every rating.
+	want := math.Round(float64(sum)/n*100) / 100
+	var rep domain.UserReputation
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		if err := db.Where("user_id = ?", rated.ID).First(&rep).Error; err == nil &&
+			rep.TotalRatings == n && rep.AverageRating == want {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("reputation = %d ratings, average %.2f; want %d, %.2f", rep.TotalRatings, rep.AverageRating, n, want)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	// Nothing still running may overwrite it with a stale result.
+	time.Sleep(500 * time.Millisecond)
+	if err := db.Where("user_id = ?", rated.ID).First(&rep).Error; err != nil {
+		t.Fatal(err)
+	}
+	if rep.TotalRatings != n || rep.AverageRating != want {
+		t.Fatalf("reputation changed to %d ratings, average %.2f; want %d, %.2f", rep.TotalRatings, rep.AverageRating, n, want)
+	}
+
+	var user domain.User
+	if err := db.Select("reputation_score").First(&user, "id = ?", rated.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if user.ReputationScore != rep.OverallScore {
+		t.Fatalf("users.reputation_score = %.2f, want %.2f", user.ReputationScore, rep.OverallScore)
+	}
+}
+
+func TestNormalizeLeaderboardLimit(t *testing.T) {
+	tests := []struct{ in, want int }{
+		{-5, DefaultLeaderboardLimit},
+		{0, DefaultLeaderboardLimit},
+		{1, 1},
+		{MaxLeaderboardLimit, MaxLeaderboardLimit},
+		{MaxLeaderboardLimit + 1, MaxLeaderboardLimit},
+		{10000, MaxLeaderboardLimit},
+	}
+	for _, tt := range tests {
+		if got := normalizeLeaderboardLimit(tt.in); got != tt.want {
+			t.Errorf("normalizeLeaderboardLimit(%d) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestGetTopContributorsLimits(t *testing.T) {
+	db := testDB(t)
+	s := NewReputationService(db, DefaultReputationWeights())
+	ctx := context.Background()
+
+	for i := 0; i < DefaultLeaderboardLimit+1; i++ {
+		u := createTestUser(t, db)
+		rep := domain.UserReputation{UserID: u.ID, OverallScore: float64(i), TotalRatings: 1}
+		if err := db.Create(&rep).Error; err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Delete(&domain.UserReputation{}, "user_id = ?", u.ID) })
+	}
+
+	for _, limit := range []int{-1, 0} {
+		got, err := s.GetTopContributors(ctx, "overall", limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != DefaultLeaderboardLimit {
+			t.Errorf("limit %d returned %d, want %d", limit, len(got), DefaultLeaderboardLimit)
+		}
+	}
+
+	got, err := s.GetTopContributors(ctx, "overall", MaxLeaderboardLimit*10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) < DefaultLeaderboardLimit+1 || len(got) > MaxLeaderboardLimit {
+		t.Errorf("limit %d returned %d, want %d-%d", MaxLeaderboardLimit*10, len(got), DefaultLeaderboardLimit+1, MaxLeaderboardLimit)
+	}
+}
+
+func TestRunDecayRefreshRunsAtStartup(t *testing.T) {
+	db := testDB(t)
+	s := NewReputationService(db, DefaultReputationWeights())
+
+	// A stale stored score the next recalculation is bound to replace.
+	u := createTestUser(t, db)
+	rep := domain.UserReputation{UserID: u.ID, OverallScore: 1234, TotalRatings: 1}
+	if err := db.Create(&rep).Error; err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Delete(&domain.UserReputation{}, "user_id = ?", u.ID) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.RunDecayRefresh(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		var got domain.UserReputation
+		if err := db.Where("user_id = ?", u.ID).First(&got).Error; err != nil {
+			t.Fatal(err)
+		}
+		if got.OverallScore != 1234 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("reputation not refreshed without waiting for the first tick")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+)
+
+// ReputationWeights holds the weights of the rating categories in a user's
+// overall reputation. They must be non-negative and sum to 1. Each can be
+// overridden with REPUTATION_WEIGHT_CODE_QUALITY,
+// REPUTATION_WEIGHT_COMMUNICATION, REPUTATION_WEIGHT_HELPFULNESS and
+// REPUTATION_WEIGHT_RELIABILITY.
+type ReputationWeights struct {
+	CodeQuality   float64 `json:"code_quality"`
+	Communication float64 `json:"communication"`
+	Helpfulness   float64 `json:"helpfulness"`
+	Reliability   float64 `json:"reliability"`
+}
+
+// DefaultReputationWeights returns the standard category weights.
+func DefaultReputationWeights() ReputationWeights {
+	return ReputationWeights{
+		CodeQuality:   0.30,
+		Communication: 0.30,
+		Helpfulness:   0.20,
+		Reliability:   0.20,
+	}
+}
+
+// ReputationWeightsFromEnv starts from DefaultReputationWeights, applies any
+// REPUTATION_WEIGHT_* overrides and validates the result.
+func ReputationWeightsFromEnv() (ReputationWeights, error) {
+	w := DefaultReputationWeights()
+	overrides := []struct {
+		key string
+		dst *float64
+	}{
+		{"REPUTATION_WEIGHT_CODE_QUALITY", &w.CodeQuality},
+		{"REPUTATION_WEIGHT_COMMUNICATION", &w.Communication},
+		{"REPUTATION_WEIGHT_HELPFULNESS", &w.Helpfulness},
+		{"REPUTATION_WEIGHT_RELIABILITY", &w.Reliability},
+	}
+	for _, o := range overrides {
+		v := os.Getenv(o.key)
+		if v == "" {
+			continue
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return w, fmt.Errorf("%s: %q is not a number", o.key, v)
+		}
+		*o.dst = f
+	}
+	return w, w.Validate()
+}
+
+// Validate checks that every weight is non-negative and that they sum to 1.
+func (w ReputationWeights) Validate() error {
+	var sum float64
+	for _, v := range []float64{w.CodeQuality, w.Communication, w.Helpfulness, w.Reliability} {
+		if v < 0 {
+			return fmt.Errorf("reputation weights must not be negative: %+v", w)
+		}
+		sum += v
+	}
+	if math.Abs(sum-1) > 1e-6 {
+		return fmt.Errorf("reputation weights must sum to 1, got %.4f", sum)
+	}
+	return nil
+}
+
+// overall combines normalized 0-100 category scores into the overall score.
+func (w ReputationWeights) overall(code, comm, help, reli float64) float64 {
+	return code*w.CodeQuality + comm*w.Communication + help*w.Helpfulness + reli*w.Reliability
+}
+
+// ReputationFormula describes how the overall reputation score is computed
+// from ratings, for clients that want to explain a score.
+type ReputationFormula struct {
+	Weights       ReputationWeights `json:"weights"`
+	Normalization string            `json:"normalization"`
+	Overall       string            `json:"overall"`
+	// Decay is nil when time weighting is disabled.
+	Decay *ReputationDecay `json:"decay"`
+	// SessionSuccess feeds the session component of skill credibility.
+	SessionSuccess SessionSuccessFormula `json:"session_success"`
+}
+
+// ReputationDecay describes how ratings lose weight with age.
+type ReputationDecay struct {
+	HalfLifeDays float64 `json:"half_life_days"`
+	PriorRating  float64 `json:"prior_rating"`
+	PriorWeight  float64 `json:"prior_weight"`
+	Average      string  `json:"average"`
+}
+
+// Formula returns the weights and formulas the service is running with.
+func (s *ReputationService) Formula() ReputationFormula {
+	f := ReputationFormula{
+		Weights:        s.weights,
+		Normalization:  "score = (avg - 1) / 4 * 100, mapping a 1-5 average to 0-100; no ratings scores 0",
+		Overall:        "overall = code_quality*w.code_quality + communication*w.communication + helpfulness*w.helpfulness + reliability*w.reliability",
+		SessionSuccess: s.SessionSuccessFormula(),
+	}
+	if s.halfLife > 0 {
+		f.Decay = &ReputationDecay{
+			HalfLifeDays: s.halfLife.Hours() / 24,
+			PriorRating:  decayPriorRating,
+			PriorWeight:  decayPriorWeight,
+			Average:      "w(t) = 0.5^(t / half_life); avg = (Σ w·r + prior_rating·prior_weight) / (Σ w + prior_weight)",
+		}
+	}
+	return f
+}
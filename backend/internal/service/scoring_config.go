@@ -0,0 +1,80 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+)
+
+// ScoringConfig holds the weights of the compatibility score components.
+// They must be non-negative and sum to 1. Each can be overridden with
+// MATCH_WEIGHT_SKILLS, MATCH_WEIGHT_GOALS, MATCH_WEIGHT_COMPLEMENTARY,
+// MATCH_WEIGHT_REPUTATION and MATCH_WEIGHT_CADENCE.
+type ScoringConfig struct {
+	SkillSimilarity float64 `json:"skill_similarity"`
+	GoalsAlignment  float64 `json:"goals_alignment"`
+	Complementary   float64 `json:"complementary"`
+	Reputation      float64 `json:"reputation"`
+	Cadence         float64 `json:"cadence"`
+}
+
+// DefaultScoringConfig returns the standard compatibility weights.
+func DefaultScoringConfig() ScoringConfig {
+	return ScoringConfig{
+		SkillSimilarity: 0.35,
+		GoalsAlignment:  0.25,
+		Complementary:   0.20,
+		Reputation:      0.10,
+		Cadence:         0.10,
+	}
+}
+
+// ScoringConfigFromEnv starts from DefaultScoringConfig, applies any
+// MATCH_WEIGHT_* overrides and validates the result.
+func ScoringConfigFromEnv() (ScoringConfig, error) {
+	c := DefaultScoringConfig()
+	overrides := []struct {
+		key string
+		dst *float64
+	}{
+		{"MATCH_WEIGHT_SKILLS", &c.SkillSimilarity},
+		{"MATCH_WEIGHT_GOALS", &c.GoalsAlignment},
+		{"MATCH_WEIGHT_COMPLEMENTARY", &c.Complementary},
+		{"MATCH_WEIGHT_REPUTATION", &c.Reputation},
+		{"MATCH_WEIGHT_CADENCE", &c.Cadence},
+	}
+	for _, o := range overrides {
+		v := os.Getenv(o.key)
+		if v == "" {
+			continue
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return c, fmt.Errorf("%s: %q is not a number", o.key, v)
+		}
+		*o.dst = f
+	}
+	return c, c.Validate()
+}
+
+// Validate checks that every weight is a finite, non-negative number and
+// that they sum to 1. NaN is rejected explicitly since it fails every
+// comparison, and ParseFloat accepts "NaN" and "Inf".
+func (c ScoringConfig) Validate() error {
+	weights := []float64{c.SkillSimilarity, c.GoalsAlignment, c.Complementary, c.Reputation, c.Cadence}
+	var sum float64
+	for _, w := range weights {
+		if math.IsNaN(w) || math.IsInf(w, 0) {
+			return fmt.Errorf("compatibility weights must be finite numbers: %+v", c)
+		}
+		if w < 0 {
+			return fmt.Errorf("compatibility weights must not be negative: %+v", c)
+		}
+		sum += w
+	}
+	if math.Abs(sum-1) > 1e-6 {
+		return fmt.Errorf("compatibility weights must sum to 1, got %.4f", sum)
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package service
+
+import "testing"
+
+func TestScoringConfigFromEnvRejectsNonFinite(t *testing.T) {
+	for _, v := range []string{"NaN", "Inf", "-Inf"} {
+		t.Run(v, func(t *testing.T) {
+			t.Setenv("MATCH_WEIGHT_CADENCE", v)
+			if _, err := ScoringConfigFromEnv(); err == nil {
+				t.Fatalf("MATCH_WEIGHT_CADENCE=%s accepted", v)
+			}
+		})
+	}
+
+	t.Setenv("MATCH_WEIGHT_CADENCE", "")
+	if _, err := ScoringConfigFromEnv(); err != nil {
+		t.Fatalf("defaults rejected: %v", err)
+	}
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/yourusername/skillsync/internal/domain"
+)
+
+var (
+	ErrProposalNotFound     = errors.New("session proposal not found")
+	ErrProposalNotPending   = errors.New("session proposal is no longer pending")
+	ErrNotProposalRecipient = errors.New("only the other participant can answer this proposal")
+	ErrProposalInPast       = errors.New("proposed start time must be in the future")
+	ErrProposalTooFar       = errors.New("proposed start time is too far ahead")
+	ErrProposalOverlap      = errors.New("the proposed time overlaps another pending or accepted session proposal")
+)
+
+const (
+	DefaultProposalMinutes = 60
+	// maxProposalLead is how far ahead a session can be proposed.
+	maxProposalLead = 90 * 24 * time.Hour
+)
+
+// ---------------------------------------------------------------------------
+// ProposeSession
+// ---------------------------------------------------------------------------
+
+// ProposeSession records userID's proposal to pair in matchID at startsAt
+// for the given number of minutes (DefaultProposalMinutes when zero). The
+// time must be in the future and must not overlap a pending or accepted
+// proposal of either participant, in this match or any other.
+func (s *MatchService) ProposeSession(ctx context.Context, matchID uint, userID string, startsAt time.Time, minutes int, note string) (*domain.SessionProposal, error) {
+	if minutes == 0 {
+		minutes = DefaultProposalMinutes
+	}
+	now := time.Now()
+	if !startsAt.After(now) {
+		return nil, ErrProposalInPast
+	}
+	if startsAt.After(now.Add(maxProposalLead)) {
+		return nil, ErrProposalTooFar
+	}
+
+	var proposal *domain.SessionProposal
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Locking the match serializes proposals within it.
+		match, err := s.participantMatch(tx.Clauses(clause.Locking{Strength: "UPDATE"}), matchID, userID)
+		if err != nil {
+			return err
+		}
+		if match.Status != domain.MatchActive {
+			return ErrMatchNotActive
+		}
+		recipientID := match.User1ID
+		if recipientID == userID {
+			recipientID = match.User2ID
+		}
+
+		p := &domain.SessionProposal{
+			MatchID:         matchID,
+			ProposerID:      userID,
+			RecipientID:     recipientID,
+			StartsAt:        startsAt,
+			DurationMinutes: minutes,
+			Note:            note,
+			Status:          domain.ProposalPending,
+		}
+		var overlapping int64
+		if err := tx.Model(&domain.SessionProposal{}).
+			Where("status IN ?", []domain.ProposalStatus{domain.ProposalPending, domain.ProposalAccepted}).
+			Where("(proposer_id IN ? OR recipient_id IN ?)", []string{userID, recipientID}, []string{userID, recipientID}).
+			Where("starts_at < ? AND starts_at + duration_minutes * INTERVAL '1 minute' > ?", p.EndsAt(), startsAt).
+			Count(&overlapping).Error; err != nil {
+			return fmt.Errorf("failed to check overlapping proposals: %w", err)
+		}
+		if overlapping > 0 {
+			return ErrProposalOverlap
+		}
+
+		if err := tx.Create(p).Error; err != nil {
+			return fmt.Errorf("failed to create session proposal: %w", err)
+		}
+		proposal = p
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return proposal, nil
+}
+
+// ---------------------------------------------------------------------------
+// Answer proposals
+// ---------------------------------------------------------------------------
+
+// AcceptSessionProposal accepts a pending proposal on behalf of its
+// recipient. With createSession, the CodingSession is scheduled to start at
+// the proposed time and its ID is stored on the proposal.
+func (s *MatchService) AcceptSessionProposal(ctx context.Context, matchID, proposalID uint, userID string, createSession bool) (*domain.SessionProposal, error) {
+	return s.answerProposal(ctx, matchID, proposalID, userID, func(tx *gorm.DB, p *domain.SessionProposal) error {
+		if !p.StartsAt.After(time.Now()) {
+			return ErrProposalInPast
+		}
+		// A hidden (soft-deleted) match is archived too.
+		var active int64
+		if err := tx.Model(&domain.Match{}).
+			Where("id = ? AND status = ?", p.MatchID, domain.MatchActive).
+			Count(&active).Error; err != nil {
+			return fmt.Errorf("failed to fetch match: %w", err)
+		}
+		if active == 0 {
+			return ErrMatchNotActive
+		}
+
+		p.Status = domain.ProposalAccepted
+		if createSession {
+			session := domain.CodingSession{MatchID: p.MatchID, StartedAt: p.StartsAt}
+			if err := tx.Create(&session).Error; err != nil {
+				return fmt.Errorf("failed to schedule session: %w", err)
+			}
+			p.SessionID = &session.ID
+		}
+		return nil
+	})
+}
+
+// DeclineSessionProposal declines a pending proposal on behalf of its
+// recipient.
+func (s *MatchService) DeclineSessionProposal(ctx context.Context, matchID, proposalID uint, userID string) (*domain.SessionProposal, error) {
+	return s.answerProposal(ctx, matchID, proposalID, userID, func(tx *gorm.DB, p *domain.SessionProposal) error {
+		p.Status = domain.ProposalDeclined
+		return nil
+	})
+}
+
+// answerProposal locks a pending proposal of matchID addressed to userID,
+// lets answer set its new state and saves it.
+func (s *MatchService) answerProposal(ctx context.Context, matchID, proposalID uint, userID string, answer func(tx *gorm.DB, p *domain.SessionProposal) error) (*domain.SessionProposal, error) {
+	var proposal domain.SessionProposal
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND match_id = ?", proposalID, matchID).
+			First(&proposal).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrProposalNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to fetch session proposal: %w", err)
+		}
+		if proposal.RecipientID != userID {
+			if proposal.ProposerID == userID {
+				return ErrNotProposalRecipient
+			}
+			return ErrProposalNotFound
+		}
+		if proposal.Status != domain.ProposalPending {
+			return ErrProposalNotPending
+		}
+
+		if err := answer(tx, &proposal); err != nil {
+			return err
+		}
+		now := time.Now()
+		proposal.RespondedAt = &now
+		if err := tx.Model(&proposal).Updates(map[string]interface{}{
+			"status":       proposal.Status,
+			"session_id":   proposal.SessionID,
+			"responded_at": now,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update session proposal: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &proposal, nil
+}
+
+// ---------------------------------------------------------------------------
+// GetSessionProposals
+// ---------------------------------------------------------------------------
+
+// GetSessionProposals lists the proposals of a match for one of its
+// participants, soonest first. Unless includePast is set, proposals whose
+// time has passed are left out.
+func (s *MatchService) GetSessionProposals(ctx context.Context, matchID uint, userID string, includePast bool) ([]domain.SessionProposal, error) {
+	db := s.db.WithContext(ctx)
+	if _, err := s.participantMatch(db, matchID, userID); err != nil {
+		return nil, err
+	}
+
+	q := db.Where("match_id = ?", matchID)
+	if !includePast {
+		q = q.Where("starts_at > ?", time.Now())
+	}
+	proposals := []domain.SessionProposal{}
+	if err := q.Order("starts_at ASC, id ASC").Find(&proposals).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch session proposals: %w", err)
+	}
+	return proposals, nil
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/yourusername/skillsync/internal/domain"
+)
+
+// Weights of each feedback signal in a session's feedback score (0-1). The
+// star rating is scaled from 1-5 to 0-1 first.
+const (
+	successWeightRating    = 0.50
+	successWeightEnjoyed   = 0.25
+	successWeightPairAgain = 0.25
+)
+
+// SessionSuccessWeights blend the two sources of a session's success_rating:
+// the participants' post-session feedback and the overall stars they gave
+// each other. Overridable with SESSION_SUCCESS_WEIGHT_FEEDBACK and
+// SESSION_SUCCESS_WEIGHT_RATINGS; they are normalized to sum to 1.
+type SessionSuccessWeights struct {
+	Feedback float64 `json:"feedback"`
+	Ratings  float64 `json:"ratings"`
+}
+
+func sessionSuccessWeightsFromEnv() SessionSuccessWeights {
+	w := SessionSuccessWeights{
+		Feedback: getEnvFloat("SESSION_SUCCESS_WEIGHT_FEEDBACK", 0.5),
+		Ratings:  getEnvFloat("SESSION_SUCCESS_WEIGHT_RATINGS", 0.5),
+	}
+	sum := w.Feedback + w.Ratings
+	if w.Feedback < 0 || w.Ratings < 0 || sum <= 0 {
+		return SessionSuccessWeights{Feedback: 0.5, Ratings: 0.5}
+	}
+	w.Feedback /= sum
+	w.Ratings /= sum
+	return w
+}
+
+// SessionSuccessFormula describes how success_rating is derived.
+type SessionSuccessFormula struct {
+	Weights  SessionSuccessWeights `json:"weights"`
+	Feedback string                `json:"feedback"`
+	Ratings  string                `json:"ratings"`
+	Blend    string                `json:"blend"`
+}
+
+// SessionSuccessFormula returns the weights and formulas behind every
+// session's success_rating.
+func (s *ReputationService) SessionSuccessFormula() SessionSuccessFormula {
+	return SessionSuccessFormula{
+		Weights: s.successWeights,
+		Feedback: fmt.Sprintf("feedback = avg over submitted feedback of %.2f*(rating-1)/4 + %.2f*enjoyed + %.2f*would_pair_again",
+			successWeightRating, successWeightEnjoyed, successWeightPairAgain),
+		Ratings: "ratings = avg of (overall_rating-1)/4 over the session's ratings, once both participants have rated",
+		Blend:   "success_rating = feedback*w.feedback + ratings*w.ratings when both exist, otherwise whichever exists; 0-1, unchanged while neither does",
+	}
+}
+
+// updateSessionSuccess recomputes the session's success_rating from all of
+// its feedback and ratings (see SessionSuccessFormula), so every submission
+// or edit blends with the others rather than replacing them. tx should hold
+// the lock on the session row.
+func (s *ReputationService) updateSessionSuccess(tx *gorm.DB, sessionID uint) error {
+	var fb struct {
+		Count int64
+		Score float64
+	}
+	if err := tx.Model(&domain.SessionFeedback{}).
+		Where("session_id = ?", sessionID).
+		Select(`COUNT(*) AS count, COALESCE(AVG(
+			? * (rating - 1) / 4.0 +
+			CASE WHEN enjoyed THEN ? ELSE 0 END +
+			CASE WHEN would_pair_again THEN ? ELSE 0 END
+		), 0) AS score`, successWeightRating, successWeightEnjoyed, successWeightPairAgain).
+		Scan(&fb).Error; err != nil {
+		return fmt.Errorf("failed to aggregate session feedback: %w", err)
+	}
+
+	var rt struct {
+		Raters int64
+		Score  float64
+	}
+	if err := tx.Model(&domain.Rating{}).
+		Where("session_id = ?", sessionID).
+		Select("COUNT(DISTINCT rater_id) AS raters, COALESCE(AVG((overall_rating - 1) / 4.0), 0) AS score").
+		Scan(&rt).Error; err != nil {
+		return fmt.Errorf("failed to aggregate session ratings: %w", err)
+	}
+
+	hasFeedback, hasRatings := fb.Count > 0, rt.Raters >= 2
+	var success float64
+	switch {
+	case hasFeedback && hasRatings:
+		success = fb.Score*s.successWeights.Feedback + rt.Score*s.successWeights.Ratings
+	case hasFeedback:
+		success = fb.Score
+	case hasRatings:
+		success = rt.Score
+	default:
+		return nil
+	}
+
+	if err := tx.Model(&domain.CodingSession{}).Where("id = ?", sessionID).
+		Update("success_rating", math.Round(success*100)/100).Error; err != nil {
+		return fmt.Errorf("failed to update session success rating: %w", err)
+	}
+	return nil
+}
+
+// refreshSessionSuccess recomputes a session's success_rating after one of
+// its ratings changed. Failures are logged; the rating itself is saved.
+func (s *ReputationService) refreshSessionSuccess(sessionID uint) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&domain.CodingSession{}, "id = ?", sessionID).Error; err != nil {
+			return fmt.Errorf("failed to lock session: %w", err)
+		}
+		return s.updateSessionSuccess(tx, sessionID)
+	})
+	if err != nil {
+		log.Error().Err(err).Uint("session_id", sessionID).Msg("failed to update session success rating")
+	}
+}
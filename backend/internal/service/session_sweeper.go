@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/yourusername/skillsync/internal/domain"
+)
+
+const (
+	defaultSessionIdleTimeout   = 30 * time.Minute
+	defaultSessionSweepInterval = time.Minute
+	// sessionActivityThrottle bounds how often one session's activity is
+	// written, since code changes arrive on nearly every keystroke.
+	sessionActivityThrottle = 30 * time.Second
+	sessionIdleNote         = "auto-ended (idle)"
+)
+
+// SessionSweeper ends coding sessions that have seen no chat message or code
+// change for the idle timeout, so a forgotten session doesn't inflate
+// durations and success metrics. The session is ended at its last activity,
+// not at sweep time.
+//
+// SESSION_IDLE_TIMEOUT sets the idle period (default 30m; "off" disables the
+// sweeper) and SESSION_SWEEP_INTERVAL how often it checks (default 1m).
+type SessionSweeper struct {
+	db          *gorm.DB
+	audit       *AuditService
+	idleTimeout time.Duration
+	interval    time.Duration
+	enabled     bool
+
+	// Activity writes are throttled per open session. open remembers which
+	// sessions of a match were open at its last write, or when a match was
+	// last found to have none, so the throttle can be checked without a
+	// query. Entries go when their session ends or once they are too old to
+	// suppress a write.
+	mu        sync.Mutex
+	lastWrite map[uint]time.Time    // open session ID -> last activity write
+	open      map[uint]openSessions // match ID -> sessions found by the last write
+	pruned    time.Time
+}
+
+type openSessions struct {
+	ids     []uint
+	checked time.Time
+}
+
+func NewSessionSweeper(db *gorm.DB, audit *AuditService) *SessionSweeper {
+	idle := getEnvDuration("SESSION_IDLE_TIMEOUT", defaultSessionIdleTimeout)
+	interval := getEnvDuration("SESSION_SWEEP_INTERVAL", defaultSessionSweepInterval)
+	return &SessionSweeper{
+		db:          db,
+		audit:       audit,
+		idleTimeout: idle,
+		interval:    interval,
+		enabled:     getEnv("SESSION_IDLE_TIMEOUT", "") != "off" && idle > 0 && interval > 0,
+		lastWrite:   make(map[uint]time.Time),
+		open:        make(map[uint]openSessions),
+	}
+}
+
+// RecordActivity marks the open sessions of a match as active now. Writes
+// are throttled per session to one every 30 seconds; a match with no open
+// session is rechecked at the same rate.
+func (s *SessionSweeper) RecordActivity(matchID uint) {
+	now := time.Now()
+
+	s.mu.Lock()
+	throttled := s.throttledLocked(matchID, now)
+	s.mu.Unlock()
+	if throttled {
+		return
+	}
+
+	var sessions []domain.CodingSession
+	if err := s.db.Model(&sessions).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "id"}}}).
+		Where("match_id = ? AND ended_at IS NULL", matchID).
+		Update("last_activity_at", now).Error; err != nil {
+		log.Warn().Err(err).Uint("match_id", matchID).Msg("failed to record session activity")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Sessions open at the previous write but not now have ended.
+	for _, id := range s.open[matchID].ids {
+		delete(s.lastWrite, id)
+	}
+	ids := make([]uint, len(sessions))
+	for i, session := range sessions {
+		ids[i] = session.ID
+		s.lastWrite[session.ID] = now
+	}
+	s.open[matchID] = openSessions{ids: ids, checked: now}
+
+	if now.Sub(s.pruned) >= sessionActivityThrottle {
+		s.pruneLocked(now)
+	}
+}
+
+// forgetSession drops the throttle entry of a session that has ended, so
+// the match's next activity is written straight away.
+func (s *SessionSweeper) forgetSession(matchID, sessionID uint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.lastWrite, sessionID)
+	o, ok := s.open[matchID]
+	if !ok {
+		return
+	}
+	ids := o.ids[:0:0]
+	for _, id := range o.ids {
+		if id != sessionID {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		delete(s.open, matchID)
+		return
+	}
+	s.open[matchID] = openSessions{ids: ids, checked: o.checked}
+}
+
+// throttledLocked reports whether matchID's open sessions, or its lack of
+// one, were all recorded within the throttle period. The caller holds s.mu.
+func (s *SessionSweeper) throttledLocked(matchID uint, now time.Time) bool {
+	o, ok := s.open[matchID]
+	if !ok {
+		return false
+	}
+	if len(o.ids) == 0 {
+		return now.Sub(o.checked) < sessionActivityThrottle
+	}
+	for _, id := range o.ids {
+		last, ok := s.lastWrite[id]
+		if !ok || now.Sub(last) >= sessionActivityThrottle {
+			return false
+		}
+	}
+	return true
+}
+
+// pruneLocked forgets entries too old to suppress a write. The caller holds
+// s.mu.
+func (s *SessionSweeper) pruneLocked(now time.Time) {
+	for matchID, o := range s.open {
+		if now.Sub(o.checked) >= sessionActivityThrottle {
+			for _, id := range o.ids {
+				delete(s.lastWrite, id)
+			}
+			delete(s.open, matchID)
+		}
+	}
+	s.pruned = now
+}
+
+// Run sweeps on every tick until ctx is cancelled. Call as a goroutine.
+func (s *SessionSweeper) Run(ctx context.Context) {
+	if !s.enabled {
+		log.Info().Msg("session idle sweeper disabled")
+		return
+	}
+
+	log.Info().Dur("idle_timeout", s.idleTimeout).Dur("interval", s.interval).Msg("session idle sweeper started")
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.SweepOnce(ctx)
+		}
+	}
+}
+
+// SweepOnce ends every open session idle for longer than the timeout and
+// returns how many were ended.
+func (s *SessionSweeper) SweepOnce(ctx context.Context) int {
+	cutoff := time.Now().Add(-s.idleTimeout)
+
+	var sessions []domain.CodingSession
+	if err := s.db.WithContext(ctx).
+		Where("ended_at IS NULL AND COALESCE(last_activity_at, started_at) < ?", cutoff).
+		Find(&sessions).Error; err != nil {
+		log.Error().Err(err).Msg("session sweeper: failed to list idle sessions")
+		return 0
+	}
+
+	ended := 0
+	for _, session := range sessions {
+		if ctx.Err() != nil {
+			break
+		}
+
+		endedAt := session.StartedAt
+		if session.LastActivityAt != nil && session.LastActivityAt.After(endedAt) {
+			endedAt = *session.LastActivityAt
+		}
+		duration := int(endedAt.Sub(session.StartedAt).Minutes())
+
+		notes := sessionIdleNote
+		if session.SessionNotes != "" {
+			notes = session.SessionNotes + "\n" + sessionIdleNote
+		}
+
+		// Recheck against the cutoff: the session may have been ended, or
+		// seen activity, since the query.
+		res := s.db.WithContext(ctx).Model(&domain.CodingSession{}).
+			Where("id = ? AND ended_at IS NULL AND COALESCE(last_activity_at, started_at) < ?", session.ID, cutoff).
+			Updates(map[string]interface{}{
+				"ended_at":         endedAt,
+				"duration_minutes": duration,
+				"session_notes":    notes,
+			})
+		if res.Error != nil {
+			log.Error().Err(res.Error).Uint("session_id", session.ID).Msg("session sweeper: failed to end session")
+			continue
+		}
+		if res.RowsAffected == 0 {
+			continue
+		}
+		ended++
+		s.forgetSession(session.MatchID, session.ID)
+
+		log.Info().
+			Uint("session_id", session.ID).
+			Uint("match_id", session.MatchID).
+			Time("ended_at", endedAt).
+			Int("duration_minutes", duration).
+			Msg("session auto-ended (idle)")
+		s.audit.Audit(AuditEntry{
+			Action:     domain.AuditSessionIdleEnd,
+			TargetType: "coding_session",
+			TargetID:   strconv.FormatUint(uint64(session.ID), 10),
+			Metadata: map[string]interface{}{
+				"match_id":         session.MatchID,
+				"ended_at":         endedAt,
+				"duration_minutes": duration,
+				"idle_timeout":     s.idleTimeout.String(),
+			},
+		})
+	}
+
+	s.mu.Lock()
+	s.pruneLocked(time.Now())
+	s.mu.Unlock()
+
+	return ended
+}
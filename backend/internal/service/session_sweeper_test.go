@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yourusername/skillsync/internal/domain"
+)
+
+func newTestSweeper() *SessionSweeper {
+	return &SessionSweeper{
+		lastWrite: make(map[uint]time.Time),
+		open:      make(map[uint]openSessions),
+	}
+}
+
+func TestSessionActivityThrottle(t *testing.T) {
+	s := newTestSweeper()
+	now := time.Now()
+
+	if s.throttledLocked(1, now) {
+		t.Fatal("unknown match throttled")
+	}
+
+	s.lastWrite[10] = now.Add(-time.Second)
+	s.lastWrite[11] = now.Add(-time.Second)
+	s.open[1] = openSessions{ids: []uint{10, 11}, checked: now.Add(-time.Second)}
+	if !s.throttledLocked(1, now) {
+		t.Fatal("sessions written a second ago not throttled")
+	}
+
+	// One session's write is due: the match is written again.
+	s.lastWrite[11] = now.Add(-sessionActivityThrottle)
+	if s.throttledLocked(1, now) {
+		t.Fatal("session due for a write throttled")
+	}
+
+	// A match without an open session is rechecked after the throttle.
+	s.open[2] = openSessions{checked: now.Add(-time.Second)}
+	if !s.throttledLocked(2, now) {
+		t.Fatal("match without sessions rechecked within the throttle")
+	}
+	if s.throttledLocked(2, now.Add(sessionActivityThrottle)) {
+		t.Fatal("match without sessions not rechecked after the throttle")
+	}
+}
+
+func TestSessionActivityForgetAndPrune(t *testing.T) {
+	s := newTestSweeper()
+	now := time.Now()
+	s.lastWrite[10] = now
+	s.lastWrite[11] = now
+	s.open[1] = openSessions{ids: []uint{10, 11}, checked: now}
+
+	// Forgetting an ended session keeps the match's other open sessions.
+	s.forgetSession(1, 10)
+	if _, ok := s.lastWrite[10]; ok {
+		t.Fatal("ended session kept")
+	}
+	if got := s.open[1].ids; len(got) != 1 || got[0] != 11 {
+		t.Fatalf("open sessions = %v, want [11]", got)
+	}
+
+	// The last one going drops the match, so its next activity is written.
+	s.forgetSession(1, 11)
+	if _, ok := s.open[1]; ok || len(s.lastWrite) != 0 {
+		t.Fatalf("entries left after every session ended: %v, %v", s.open, s.lastWrite)
+	}
+
+	old := now.Add(-sessionActivityThrottle)
+	s.lastWrite[20] = old
+	s.open[2] = openSessions{ids: []uint{20}, checked: old}
+	s.open[3] = openSessions{checked: old}
+	s.lastWrite[40] = now
+	s.open[4] = openSessions{ids: []uint{40}, checked: now}
+	s.pruneLocked(now)
+	if len(s.open) != 1 || len(s.lastWrite) != 1 {
+		t.Fatalf("after prune: open %v, lastWrite %v; want only match 4", s.open, s.lastWrite)
+	}
+}
+
+// A session started right after another ended on the same match has its
+// activity recorded at once, not after the ended session's throttle.
+func TestRecordActivityAfterSessionEnds(t *testing.T) {
+	db := testDB(t)
+	s := NewSessionSweeper(db, nil)
+	s.idleTimeout = time.Minute
+
+	match := createTestMatch(t, db, createTestUser(t, db), createTestUser(t, db))
+	idle := domain.CodingSession{MatchID: match.ID, StartedAt: time.Now().Add(-time.Hour)}
+	if err := db.Create(&idle).Error; err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Delete(&domain.CodingSession{}, idle.ID) })
+
+	s.RecordActivity(match.ID)
+	db.Model(&idle).Update("last_activity_at", time.Now().Add(-time.Hour))
+	if n := s.SweepOnce(context.Background()); n < 1 {
+		t.Fatalf("swept %d sessions, want the idle one", n)
+	}
+
+	next := domain.CodingSession{MatchID: match.ID, StartedAt: time.Now().Add(-time.Minute)}
+	if err := db.Create(&next).Error; err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Delete(&domain.CodingSession{}, next.ID) })
+
+	s.RecordActivity(match.ID)
+	var got domain.CodingSession
+	if err := db.First(&got, next.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if got.LastActivityAt == nil {
+		t.Fatal("activity on the new session was throttled")
+	}
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/yourusername/skillsync/internal/domain"
+)
+
+var ErrSkillMergeSelf = errors.New("cannot merge a skill into itself")
+
+const (
+	defaultSkillSearchLimit = 10
+	maxSkillSearchLimit     = 50
+)
+
+// SkillService serves the skill catalog: autocomplete, categories and admin
+// merges of duplicate skills.
+type SkillService struct {
+	db *gorm.DB
+}
+
+func NewSkillService(db *gorm.DB) *SkillService {
+	return &SkillService{db: db}
+}
+
+// SkillSearchResult is a catalog skill with how many users list it.
+type SkillSearchResult struct {
+	domain.Skill
+	UserCount int64 `json:"user_count"`
+}
+
+// ---------------------------------------------------------------------------
+// SearchSkills
+// ---------------------------------------------------------------------------
+
+// SearchSkills returns catalog skills whose name contains q, ignoring case
+// and punctuation so "nodejs" finds "Node.js". Prefix matches rank first,
+// then popularity.
+func (s *SkillService) SearchSkills(q string, limit int) ([]SkillSearchResult, error) {
+	if limit <= 0 || limit > maxSkillSearchLimit {
+		limit = defaultSkillSearchLimit
+	}
+
+	query := s.db.Model(&domain.Skill{}).
+		Select("skills.*, COUNT(user_skills.id) AS user_count").
+		Joins("LEFT JOIN user_skills ON user_skills.skill_id = skills.id").
+		Group("skills.id")
+
+	key := skillKey(q)
+	if key != "" {
+		query = query.
+			Where("regexp_replace(lower(skills.name), '[^a-z0-9+#]', '', 'g') LIKE ?", "%"+key+"%").
+			Order(clause.OrderBy{Expression: clause.Expr{
+				SQL:                "regexp_replace(lower(skills.name), '[^a-z0-9+#]', '', 'g') LIKE ? DESC",
+				Vars:               []interface{}{key + "%"},
+				WithoutParentheses: true,
+			}})
+	}
+
+	var results []SkillSearchResult
+	if err := query.Order("user_count DESC").Order("skills.name ASC").
+		Limit(limit).Find(&results).Error; err != nil {
+		return nil, fmt.Errorf("failed to search skills: %w", err)
+	}
+	return results, nil
+}
+
+// ---------------------------------------------------------------------------
+// MergeSkills
+// ---------------------------------------------------------------------------
+
+// MergeSkills folds sourceID into targetID: user skills move to the target
+// (users who already list the target keep their existing entry) and the
+// source skill is deleted.
+func (s *SkillService) MergeSkills(sourceID, targetID uint) (*domain.Skill, error) {
+	if sourceID == targetID {
+		return nil, ErrSkillMergeSelf
+	}
+
+	var target domain.Skill
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var source domain.Skill
+		if err := tx.First(&source, sourceID).Error; err != nil {
+			return err
+		}
+		if err := tx.First(&target, targetID).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("skill_id = ? AND user_id IN (?)", sourceID,
+			tx.Model(&domain.UserSkill{}).Select("user_id").Where("skill_id = ?", targetID)).
+			Delete(&domain.UserSkill{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&domain.UserSkill{}).Where("skill_id = ?", sourceID).
+			Update("skill_id", targetID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&source).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSkillNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge skills: %w", err)
+	}
+	return &target, nil
+}
+
+// ---------------------------------------------------------------------------
+// Internal helpers
+// ---------------------------------------------------------------------------
+
+// skillKey normalizes a skill name for comparison: lower case with only
+// letters, digits, '+' and '#' kept ("Node.js" and "NodeJS" both give
+// "nodejs", "C++" stays distinct from "C").
+func skillKey(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '+' || r == '#' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// resolveSkill finds the catalog skill a user means by name: one with the
+// same name ignoring case, or the same normalized key (skillKey). Otherwise
+// it creates the skill under CategoryOther and returns the names of catalog
+// skills a single edit away, for names of five or more characters, as
+// suggestions. Near names are never merged: "Preact" is not "React".
+func resolveSkill(db *gorm.DB, name string) (*domain.Skill, []string, error) {
+	name = strings.TrimSpace(name)
+
+	var skill domain.Skill
+	err := db.Where("lower(name) = lower(?)", name).First(&skill).Error
+	if err == nil {
+		return &skill, nil, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, fmt.Errorf("failed to look up skill: %w", err)
+	}
+
+	var catalog []domain.Skill
+	if err := db.Find(&catalog).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to load skills: %w", err)
+	}
+	key := skillKey(name)
+	var suggestions []string
+	for i := range catalog {
+		other := skillKey(catalog[i].Name)
+		if other == key && key != "" {
+			return &catalog[i], nil, nil
+		}
+		if len(key) >= 5 && editDistanceAtMostOne(key, other) {
+			suggestions = append(suggestions, catalog[i].Name)
+		}
+	}
+
+	skill = domain.Skill{Name: name, Category: domain.CategoryOther}
+	if err := db.Create(&skill).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to create skill: %w", err)
+	}
+	return &skill, suggestions, nil
+}
+
+// editDistanceAtMostOne reports whether a and b differ by at most one
+// insertion, deletion or substitution.
+func editDistanceAtMostOne(a, b string) bool {
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+	if len(a)-len(b) > 1 {
+		return false
+	}
+	i, j, edits := 0, 0, 0
+	for i < len(a) && j < len(b) {
+		if a[i] == b[j] {
+			i++
+			j++
+			continue
+		}
+		edits++
+		if edits > 1 {
+			return false
+		}
+		if len(a) == len(b) {
+			j++
+		}
+		i++
+	}
+	return edits+(len(a)-i) <= 1
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/yourusername/skillsync/internal/domain"
+)
+
+const (
+	defaultPredictionCacheTTL = 30 * time.Minute
+	// maxPredictionCacheEntries bounds the cache; one entry per match would
+	// otherwise accumulate for the life of the process.
+	maxPredictionCacheEntries = 10000
+)
+
+// predictionCache keeps Claude's session success predictions per match.
+// Reputation moves slowly, so a short-lived cache avoids a model call on
+// every page view. Expired entries are dropped when read or when the cache
+// is full; a full cache of live entries evicts the oldest.
+type predictionCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	limit   int
+	entries map[uint]cachedPrediction
+}
+
+type cachedPrediction struct {
+	prediction *SuccessPrediction
+	at         time.Time
+}
+
+func newPredictionCache(ttl time.Duration, limit int) *predictionCache {
+	return &predictionCache{ttl: ttl, limit: limit, entries: make(map[uint]cachedPrediction)}
+}
+
+func (c *predictionCache) get(matchID uint) *SuccessPrediction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[matchID]
+	if !ok {
+		return nil
+	}
+	if time.Since(e.at) > c.ttl {
+		delete(c.entries, matchID)
+		return nil
+	}
+	return e.prediction
+}
+
+func (c *predictionCache) put(matchID uint, p *SuccessPrediction) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[matchID]; !ok && len(c.entries) >= c.limit {
+		c.evict()
+	}
+	c.entries[matchID] = cachedPrediction{prediction: p, at: time.Now()}
+}
+
+// evict drops every expired entry, or the oldest one if none has expired.
+// The caller holds c.mu.
+func (c *predictionCache) evict() {
+	var oldest uint
+	var oldestAt time.Time
+	removed := false
+	for id, e := range c.entries {
+		if time.Since(e.at) > c.ttl {
+			delete(c.entries, id)
+			removed = true
+			continue
+		}
+		if oldestAt.IsZero() || e.at.Before(oldestAt) {
+			oldest, oldestAt = id, e.at
+		}
+	}
+	if !removed {
+		delete(c.entries, oldest)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// PredictSessionSuccess
+// ---------------------------------------------------------------------------
+
+// PredictSessionSuccess asks Claude how likely a session between the two
+// participants of matchID is to go well, based on their reputation. A user
+// without a reputation row yet is passed as all zeros. Results are cached
+// per match for PREDICTION_CACHE_TTL (default 30m).
+func (s *MatchService) PredictSessionSuccess(ctx context.Context, matchID uint, userID string) (*SuccessPrediction, error) {
+	db := s.db.WithContext(ctx)
+
+	var match domain.Match
+	if err := db.First(&match, matchID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMatchNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch match: %w", err)
+	}
+	if match.User1ID != userID && match.User2ID != userID {
+		return nil, ErrNotMatchParticipant
+	}
+
+	if p := s.predictions.get(match.ID); p != nil {
+		return p, nil
+	}
+
+	var reps []domain.UserReputation
+	if err := db.Where("user_id IN ?", []string{match.User1ID, match.User2ID}).Find(&reps).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch reputations: %w", err)
+	}
+	rep1 := domain.UserReputation{UserID: match.User1ID}
+	rep2 := domain.UserReputation{UserID: match.User2ID}
+	for _, r := range reps {
+		switch r.UserID {
+		case match.User1ID:
+			rep1 = r
+		case match.User2ID:
+			rep2 = r
+		}
+	}
+
+	prediction, err := s.claude.PredictSessionSuccess(ctx, rep1, rep2)
+	if err != nil {
+		return nil, err
+	}
+	s.predictions.put(match.ID, prediction)
+	return prediction, nil
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPredictionCacheBounded(t *testing.T) {
+	c := newPredictionCache(time.Hour, 3)
+	for id := uint(1); id <= 3; id++ {
+		c.put(id, &SuccessPrediction{})
+	}
+	c.entries[1] = cachedPrediction{prediction: c.entries[1].prediction, at: time.Now().Add(-2 * time.Hour)}
+
+	// Full: expired entries go first.
+	c.put(4, &SuccessPrediction{})
+	if _, ok := c.entries[1]; ok || len(c.entries) != 3 {
+		t.Fatalf("after expiry sweep: %d entries, expired entry kept = %v", len(c.entries), ok)
+	}
+
+	// Full of live entries: the oldest goes.
+	c.entries[2] = cachedPrediction{prediction: c.entries[2].prediction, at: time.Now().Add(-time.Minute)}
+	c.put(5, &SuccessPrediction{})
+	if len(c.entries) != 3 {
+		t.Fatalf("%d entries, want 3", len(c.entries))
+	}
+	if c.get(2) != nil {
+		t.Error("oldest entry was not evicted")
+	}
+	for _, id := range []uint{3, 4, 5} {
+		if c.get(id) == nil {
+			t.Errorf("entry %d evicted", id)
+		}
+	}
+
+	// Refreshing an existing entry never evicts.
+	c.put(3, &SuccessPrediction{})
+	if len(c.entries) != 3 || c.get(4) == nil || c.get(5) == nil {
+		t.Error("refreshing an entry evicted another")
+	}
+}
+
+func TestPredictionCacheGetDropsExpired(t *testing.T) {
+	c := newPredictionCache(time.Minute, 10)
+	c.entries[7] = cachedPrediction{prediction: &SuccessPrediction{}, at: time.Now().Add(-time.Hour)}
+	if c.get(7) != nil {
+		t.Fatal("expired prediction returned")
+	}
+	if _, ok := c.entries[7]; ok {
+		t.Fatal("expired prediction kept after read")
+	}
+}
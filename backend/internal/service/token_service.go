@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/yourusername/skillsync/internal/domain"
+	"github.com/yourusername/skillsync/pkg/auth"
+)
+
+var ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+
+// TokenPair is a short-lived access JWT plus the refresh token that renews it.
+type TokenPair struct {
+	AccessToken  string    `json:"token"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// TokenService issues access tokens and manages server-side refresh tokens,
+// which makes logout and revocation possible.
+type TokenService struct {
+	db *gorm.DB
+}
+
+func NewTokenService(db *gorm.DB) *TokenService {
+	return &TokenService{db: db}
+}
+
+// ---------------------------------------------------------------------------
+// Issue / Refresh
+// ---------------------------------------------------------------------------
+
+// Issue creates a new access token and refresh token for userID. Banned
+// users get ErrUserBanned.
+func (s *TokenService) Issue(ctx context.Context, userID string) (*TokenPair, error) {
+	return s.issue(s.db.WithContext(ctx), userID)
+}
+
+// Refresh exchanges a valid refresh token for a new pair. The presented
+// token is revoked (rotation), so each refresh token works only once.
+func (s *TokenService) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	var pair *TokenPair
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var rt domain.RefreshToken
+		err := tx.Where("token_hash = ? AND revoked_at IS NULL AND expires_at > ?",
+			auth.HashRefreshToken(refreshToken), time.Now()).
+			First(&rt).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidRefreshToken
+		}
+		if err != nil {
+			return fmt.Errorf("failed to fetch refresh token: %w", err)
+		}
+
+		// Conditional update so two concurrent refreshes can't both win.
+		res := tx.Model(&domain.RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", rt.ID).
+			Update("revoked_at", time.Now())
+		if res.Error != nil {
+			return fmt.Errorf("failed to revoke refresh token: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return ErrInvalidRefreshToken
+		}
+
+		pair, err = s.issue(tx, rt.UserID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pair, nil
+}
+
+// ---------------------------------------------------------------------------
+// Revoke
+// ---------------------------------------------------------------------------
+
+// Revoke invalidates one refresh token. Unknown or already revoked tokens
+// are ignored so logout is idempotent.
+func (s *TokenService) Revoke(ctx context.Context, refreshToken string) error {
+	err := s.db.WithContext(ctx).Model(&domain.RefreshToken{}).
+		Where("token_hash = ? AND revoked_at IS NULL", auth.HashRefreshToken(refreshToken)).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh token: %w", err)
+	}
+	return nil
+}
+
+// RevokeAll invalidates every outstanding refresh token of userID, e.g.
+// after a password change.
+func (s *TokenService) RevokeAll(ctx context.Context, userID string) error {
+	err := s.db.WithContext(ctx).Model(&domain.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// Internal helpers
+// ---------------------------------------------------------------------------
+
+func (s *TokenService) issue(db *gorm.DB, userID string) (*TokenPair, error) {
+	var user domain.User
+	err := db.Select("id", "role", "banned_until").First(&user, "id = ?", userID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user: %w", err)
+	}
+	if user.IsBanned(time.Now()) {
+		return nil, ErrUserBanned
+	}
+
+	access, err := auth.GenerateToken(userID, string(user.Role))
+	if err != nil {
+		return nil, err
+	}
+	raw, hash, err := auth.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	rt := domain.RefreshToken{
+		UserID:    userID,
+		TokenHash: hash,
+		ExpiresAt: now.Add(auth.RefreshTokenTTL()),
+	}
+	if err := db.Create(&rt).Error; err != nil {
+		return nil, fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	return &TokenPair{
+		AccessToken:  access,
+		RefreshToken: raw,
+		ExpiresAt:    now.Add(auth.AccessTokenTTL()),
+	}, nil
+}
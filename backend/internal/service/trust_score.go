@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/yourusername/skillsync/internal/domain"
+)
+
+// TrustWeights are the relative weights of the trust score components. They
+// are normalized to sum to 1 and can be overridden with TRUST_WEIGHT_RATING,
+// TRUST_WEIGHT_SESSIONS, TRUST_WEIGHT_PAIR_AGAIN, TRUST_WEIGHT_RESPONSE and
+// TRUST_WEIGHT_DISPUTES.
+type TrustWeights struct {
+	Rating    float64 `json:"rating"`
+	Sessions  float64 `json:"sessions"`
+	PairAgain float64 `json:"pair_again"`
+	Response  float64 `json:"response"`
+	Disputes  float64 `json:"disputes"`
+}
+
+// TrustScore is a reliability indicator built from how a user behaves as a
+// partner, independent of technical skill. Components are 0-100.
+type TrustScore struct {
+	UserID     string          `json:"user_id"`
+	Score      float64         `json:"score"`
+	Components TrustComponents `json:"components"`
+	Weights    TrustWeights    `json:"weights"`
+	ComputedAt time.Time       `json:"computed_at"`
+}
+
+// TrustComponents is the per-signal breakdown of a TrustScore. Signals with
+// no data yet score a neutral 50.
+type TrustComponents struct {
+	AverageRating     float64 `json:"average_rating"`
+	CompletedSessions float64 `json:"completed_sessions"`
+	WouldPairAgain    float64 `json:"would_pair_again"`
+	ResponseTime      float64 `json:"response_time"`
+	// DisputeRate is 100 minus the share of the user's partners, or of
+	// everyone who reported them if more, who filed an abuse report.
+	DisputeRate float64 `json:"dispute_rate"`
+}
+
+const (
+	// trustSessionTarget completed sessions score 100.
+	trustSessionTarget = 20
+	// trustSlowResponse is the average response time that scores 0.
+	trustSlowResponse = 7 * 24 * time.Hour
+)
+
+// trustCache holds computed trust scores until they expire or are
+// invalidated by new ratings or feedback.
+type trustCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]*TrustScore
+}
+
+func newTrustCache(ttl time.Duration) *trustCache {
+	return &trustCache{ttl: ttl, entries: make(map[string]*TrustScore)}
+}
+
+func (c *trustCache) get(userID string) *TrustScore {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ts, ok := c.entries[userID]
+	if !ok || time.Since(ts.ComputedAt) > c.ttl {
+		return nil
+	}
+	return ts
+}
+
+func (c *trustCache) put(ts *TrustScore) {
+	c.mu.Lock()
+	c.entries[ts.UserID] = ts
+	c.mu.Unlock()
+}
+
+func (c *trustCache) invalidate(userIDs ...string) {
+	c.mu.Lock()
+	for _, id := range userIDs {
+		delete(c.entries, id)
+	}
+	c.mu.Unlock()
+}
+
+func trustWeightsFromEnv() TrustWeights {
+	w := TrustWeights{
+		Rating:    getEnvFloat("TRUST_WEIGHT_RATING", 0.30),
+		Sessions:  getEnvFloat("TRUST_WEIGHT_SESSIONS", 0.20),
+		PairAgain: getEnvFloat("TRUST_WEIGHT_PAIR_AGAIN", 0.25),
+		Response:  getEnvFloat("TRUST_WEIGHT_RESPONSE", 0.15),
+		Disputes:  getEnvFloat("TRUST_WEIGHT_DISPUTES", 0.10),
+	}
+	sum := w.Rating + w.Sessions + w.PairAgain + w.Response + w.Disputes
+	if sum <= 0 {
+		return TrustWeights{Rating: 0.30, Sessions: 0.20, PairAgain: 0.25, Response: 0.15, Disputes: 0.10}
+	}
+	w.Rating /= sum
+	w.Sessions /= sum
+	w.PairAgain /= sum
+	w.Response /= sum
+	w.Disputes /= sum
+	return w
+}
+
+// ---------------------------------------------------------------------------
+// GetTrustScore
+// ---------------------------------------------------------------------------
+
+// GetTrustScore returns the user's trust score, served from cache when fresh.
+func (s *ReputationService) GetTrustScore(ctx context.Context, userID string) (*TrustScore, error) {
+	if ts := s.trust.get(userID); ts != nil {
+		return ts, nil
+	}
+
+	var exists int64
+	if err := s.db.WithContext(ctx).Model(&domain.User{}).Where("id = ?", userID).Count(&exists).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch user: %w", err)
+	}
+	if exists == 0 {
+		return nil, ErrUserNotFound
+	}
+
+	c, err := s.trustComponents(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	w := s.trustWeights
+	score := c.AverageRating*w.Rating + c.CompletedSessions*w.Sessions +
+		c.WouldPairAgain*w.PairAgain + c.ResponseTime*w.Response + c.DisputeRate*w.Disputes
+
+	ts := &TrustScore{
+		UserID:     userID,
+		Score:      math.Round(score*100) / 100,
+		Components: c,
+		Weights:    w,
+		ComputedAt: time.Now(),
+	}
+	s.trust.put(ts)
+	return ts, nil
+}
+
+func (s *ReputationService) trustComponents(ctx context.Context, userID string) (TrustComponents, error) {
+	db := s.db.WithContext(ctx)
+
+	c := TrustComponents{AverageRating: 50, WouldPairAgain: 50, ResponseTime: 50, DisputeRate: 100}
+
+	// Average overall rating received, 1-5 mapped to 0-100.
+	var ratings struct {
+		Count int64
+		Avg   float64
+	}
+	if err := db.Model(&domain.Rating{}).
+		Select("COUNT(*) AS count, COALESCE(AVG(overall_rating), 0) AS avg").
+		Where("rated_id = ?", userID).
+		Scan(&ratings).Error; err != nil {
+		return c, fmt.Errorf("failed to aggregate ratings: %w", err)
+	}
+	if ratings.Count > 0 {
+		c.AverageRating = (ratings.Avg - 1) / 4 * 100
+	}
+
+	// Completed sessions, saturating at trustSessionTarget.
+	var sessions int64
+	if err := db.Model(&domain.CodingSession{}).
+		Joins("JOIN matches ON matches.id = coding_sessions.match_id").
+		Where("(matches.user1_id = ? OR matches.user2_id = ?) AND coding_sessions.ended_at IS NOT NULL", userID, userID).
+		Count(&sessions).Error; err != nil {
+		return c, fmt.Errorf("failed to count sessions: %w", err)
+	}
+	c.CompletedSessions = math.Min(float64(sessions)/trustSessionTarget, 1) * 100
+
+	// Share of partners' feedback on the user's sessions saying they would
+	// pair again.
+	var pairAgain struct {
+		Total int64
+		Yes   int64
+	}
+	if err := db.Model(&domain.SessionFeedback{}).
+		Select("COUNT(*) AS total, COUNT(*) FILTER (WHERE session_feedbacks.would_pair_again) AS yes").
+		Joins("JOIN coding_sessions ON coding_sessions.id = session_feedbacks.session_id").
+		Joins("JOIN matches ON matches.id = coding_sessions.match_id").
+		Where("(matches.user1_id = ? OR matches.user2_id = ?) AND session_feedbacks.user_id <> ?", userID, userID, userID).
+		Scan(&pairAgain).Error; err != nil {
+		return c, fmt.Errorf("failed to aggregate session feedback: %w", err)
+	}
+	if pairAgain.Total > 0 {
+		c.WouldPairAgain = float64(pairAgain.Yes) / float64(pairAgain.Total) * 100
+	}
+
+	// Average time to answer received match requests.
+	var response struct {
+		Count      int64
+		AvgSeconds float64
+	}
+	if err := db.Model(&domain.MatchRequest{}).
+		Select("COUNT(*) AS count, COALESCE(AVG(EXTRACT(EPOCH FROM (responded_at - created_at))), 0) AS avg_seconds").
+		Where("receiver_id = ? AND responded_at IS NOT NULL", userID).
+		Scan(&response).Error; err != nil {
+		return c, fmt.Errorf("failed to aggregate response times: %w", err)
+	}
+	if response.Count > 0 {
+		avg := time.Duration(response.AvgSeconds * float64(time.Second))
+		c.ResponseTime = math.Max(0, 1-float64(avg)/float64(trustSlowResponse)) * 100
+	}
+
+	// Distinct users who reported the user, relative to their partners.
+	var reporters int64
+	if err := db.Model(&domain.UserReport{}).
+		Distinct("reporter_id").
+		Where("reported_id = ?", userID).
+		Count(&reporters).Error; err != nil {
+		return c, fmt.Errorf("failed to count reports: %w", err)
+	}
+	if reporters > 0 {
+		var partners int64
+		if err := db.Model(&domain.Match{}).
+			Where("user1_id = ? OR user2_id = ?", userID, userID).
+			Count(&partners).Error; err != nil {
+			return c, fmt.Errorf("failed to count matches: %w", err)
+		}
+		c.DisputeRate = (1 - float64(reporters)/float64(max(partners, reporters))) * 100
+	}
+
+	round := func(v float64) float64 { return math.Round(v*100) / 100 }
+	c.AverageRating = round(c.AverageRating)
+	c.CompletedSessions = round(c.CompletedSessions)
+	c.WouldPairAgain = round(c.WouldPairAgain)
+	c.ResponseTime = round(c.ResponseTime)
+	c.DisputeRate = round(c.DisputeRate)
+	return c, nil
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/yourusername/skillsync/internal/domain"
+)
+
+func TestTrustWeightsFromEnv(t *testing.T) {
+	t.Setenv("TRUST_WEIGHT_RATING", "2")
+	t.Setenv("TRUST_WEIGHT_SESSIONS", "1")
+	t.Setenv("TRUST_WEIGHT_PAIR_AGAIN", "1")
+	t.Setenv("TRUST_WEIGHT_RESPONSE", "0")
+	t.Setenv("TRUST_WEIGHT_DISPUTES", "0")
+
+	w := trustWeightsFromEnv()
+	if w.Rating != 0.5 || w.Sessions != 0.25 || w.PairAgain != 0.25 || w.Response != 0 || w.Disputes != 0 {
+		t.Fatalf("weights = %+v, want normalized 0.5/0.25/0.25/0/0", w)
+	}
+}
+
+// weightedTrust recomputes a trust score from its published breakdown.
+func weightedTrust(ts *TrustScore) float64 {
+	c, w := ts.Components, ts.Weights
+	return c.AverageRating*w.Rating + c.CompletedSessions*w.Sessions +
+		c.WouldPairAgain*w.PairAgain + c.ResponseTime*w.Response + c.DisputeRate*w.Disputes
+}
+
+func TestTrustScoreReflectsComponents(t *testing.T) {
+	db := testDB(t)
+	s := NewReputationService(db, DefaultReputationWeights())
+	ctx := context.Background()
+
+	user, partner := createTestUser(t, db), createTestUser(t, db)
+	session := createTestSession(t, db, createTestMatch(t, db, user, partner))
+	t.Cleanup(func() { db.Where("session_id = ?", session.ID).Delete(&domain.SessionFeedback{}) })
+
+	before, err := s.GetTrustScore(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if before.Components.AverageRating != 50 || before.Components.WouldPairAgain != 50 {
+		t.Fatalf("no feedback yet, components = %+v; want neutral 50s", before.Components)
+	}
+	if before.Components.CompletedSessions != 100.0/trustSessionTarget {
+		t.Fatalf("completed sessions = %v, want %v", before.Components.CompletedSessions, 100.0/trustSessionTarget)
+	}
+	if math.Abs(before.Score-weightedTrust(before)) > 0.01 {
+		t.Fatalf("score %v, weighted components %v", before.Score, weightedTrust(before))
+	}
+	if cached, _ := s.GetTrustScore(ctx, user.ID); cached != before {
+		t.Fatal("second call was not served from the cache")
+	}
+
+	// The partner wouldn't pair again: the cached score is dropped and the
+	// new one is lower.
+	if err := s.SubmitSessionFeedback(ctx, session.ID, partner.ID, SessionFeedbackInput{Rating: 2}); err != nil {
+		t.Fatal(err)
+	}
+	after, err := s.GetTrustScore(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.Components.WouldPairAgain != 0 || after.Score >= before.Score {
+		t.Fatalf("after negative feedback: pair again %v, score %v (was %v)",
+			after.Components.WouldPairAgain, after.Score, before.Score)
+	}
+	if math.Abs(after.Score-weightedTrust(after)) > 0.01 {
+		t.Fatalf("score %v, weighted components %v", after.Score, weightedTrust(after))
+	}
+
+	// A five-star rating raises the rating component to the top.
+	if err := s.SubmitRating(ctx, partner.ID, user.ID, session.ID, 5, 5, 5, 5, 5, ""); err != nil {
+		t.Fatal(err)
+	}
+	rated, err := s.GetTrustScore(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rated.Components.AverageRating != 100 || rated.Score <= after.Score {
+		t.Fatalf("after 5-star rating: rating %v, score %v (was %v)",
+			rated.Components.AverageRating, rated.Score, after.Score)
+	}
+	// A report from the only partner drops the dispute component to 0.
+	report := domain.UserReport{ReporterID: partner.ID, ReportedID: user.ID, Reason: "no-show"}
+	if err := db.Create(&report).Error; err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Delete(&domain.UserReport{}, report.ID) })
+	s.trust.invalidate(user.ID)
+	reported, err := s.GetTrustScore(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rated.Components.DisputeRate != 100 || reported.Components.DisputeRate != 0 || reported.Score >= rated.Score {
+		t.Fatalf("dispute rate %v -> %v, score %v -> %v; want 100 -> 0 and lower",
+			rated.Components.DisputeRate, reported.Components.DisputeRate, rated.Score, reported.Score)
+	}
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/yourusername/skillsync/internal/domain"
+)
+
+// exportBatchSize is how many rows of each collection are held in memory at
+// once while streaming an export.
+const exportBatchSize = 500
+
+// ExportUserData writes a JSON document with everything stored about userID:
+// profile, skills, reputation, matches, messages in those matches, ratings
+// given and received, and assessments. Collections are streamed in batches,
+// so memory use doesn't grow with account size.
+//
+// ErrUserNotFound is returned before anything is written to w. Any later
+// error leaves w with a truncated document.
+func (s *UserService) ExportUserData(ctx context.Context, userID string, w io.Writer) error {
+	db := s.db.WithContext(ctx)
+
+	var user domain.User
+	if err := db.Preload("Skills.Skill").First(&user, "id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to fetch user: %w", err)
+	}
+
+	var reputation *domain.UserReputation
+	var rep domain.UserReputation
+	if err := db.Where("user_id = ?", userID).First(&rep).Error; err == nil {
+		reputation = &rep
+	}
+
+	bw := bufio.NewWriter(w)
+	ew := &exportWriter{w: bw, enc: json.NewEncoder(bw)}
+
+	ew.raw("{")
+	ew.field("exported_at", time.Now().UTC())
+	ew.raw(",")
+	ew.field("profile", user)
+	ew.raw(",")
+	ew.field("reputation", reputation)
+
+	matchIDs := db.Model(&domain.Match{}).Select("id").
+		Where("user1_id = ? OR user2_id = ?", userID, userID)
+
+	ew.raw(",")
+	streamRows[domain.Match](ew, "matches",
+		db.Where("user1_id = ? OR user2_id = ?", userID, userID))
+	ew.raw(",")
+	streamRows[domain.Message](ew, "messages",
+		db.Where("match_id IN (?)", matchIDs))
+	ew.raw(",")
+	streamRows[domain.Rating](ew, "ratings_given",
+		db.Where("rater_id = ?", userID))
+	ew.raw(",")
+	streamRows[domain.Rating](ew, "ratings_received",
+		db.Where("rated_id = ?", userID))
+	ew.raw(",")
+	streamRows[domain.Assessment](ew, "assessments",
+		db.Where("user_id = ?", userID))
+	ew.raw("}\n")
+
+	if ew.err != nil {
+		return ew.err
+	}
+	return bw.Flush()
+}
+
+// exportWriter writes a JSON document piece by piece, keeping the first
+// error so callers can write unconditionally and check once at the end.
+type exportWriter struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+	err error
+}
+
+func (e *exportWriter) raw(s string) {
+	if e.err == nil {
+		_, e.err = e.w.WriteString(s)
+	}
+}
+
+func (e *exportWriter) value(v interface{}) {
+	if e.err == nil {
+		e.err = e.enc.Encode(v)
+	}
+}
+
+func (e *exportWriter) field(name string, v interface{}) {
+	e.value(name)
+	e.raw(":")
+	e.value(v)
+}
+
+// streamRows writes name as a JSON array of every row query finds, loading
+// exportBatchSize rows at a time and flushing after each batch.
+func streamRows[T any](e *exportWriter, name string, query *gorm.DB) {
+	e.value(name)
+	e.raw(":[")
+	first := true
+	var batch []T
+	res := query.FindInBatches(&batch, exportBatchSize, func(tx *gorm.DB, _ int) error {
+		for i := range batch {
+			if !first {
+				e.raw(",")
+			}
+			first = false
+			e.value(batch[i])
+		}
+		if e.err == nil {
+			e.err = e.w.Flush()
+		}
+		return e.err
+	})
+	if e.err == nil && res.Error != nil {
+		e.err = fmt.Errorf("failed to export %s: %w", name, res.Error)
+	}
+	e.raw("]")
+}
@@ -1,14 +1,19 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/rs/zerolog/log"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/yourusername/skillsync/internal/domain"
+	"github.com/yourusername/skillsync/pkg/auth"
 )
 
 var (
@@ -18,6 +23,17 @@ var (
 	ErrSkillNotFound = errors.New("skill not found")
 	ErrSkillExists   = errors.New("user already has this skill")
 	ErrInvalidLevel  = errors.New("invalid proficiency level; use beginner, intermediate, or advanced")
+	ErrInvalidDirection = errors.New("invalid direction; use teach, learn, or both")
+	ErrWrongPassword = errors.New("current password is incorrect")
+	ErrUserBanned    = errors.New("this account is suspended")
+	ErrInvalidTimezone = errors.New("invalid timezone; use an IANA name such as Europe/Berlin")
+
+	// ErrOAuthAccountConflict means the email-matched account is already
+	// linked to a different identity from the same provider.
+	ErrOAuthAccountConflict = errors.New("this account is already linked to a different login from this provider")
+	// ErrOAuthIdentityLinked means the provider identity belongs to another
+	// user.
+	ErrOAuthIdentityLinked = errors.New("this provider account is already linked to another user")
 )
 
 // UserWithReputation bundles a user with their reputation data for API
@@ -27,30 +43,42 @@ type UserWithReputation struct {
 	Reputation *domain.UserReputation `json:"reputation"`
 }
 
+// AccountSockets closes a deleted account's realtime connections and ends
+// its match rooms. The websocket hub implements it.
+type AccountSockets interface {
+	DisconnectUser(userID string)
+	EndMatch(matchID uint)
+}
+
 // UserService handles all user-related business logic.
 type UserService struct {
-	db *gorm.DB
+	db    *gorm.DB
+	audit *AuditService
+	// sockets, if set, is told about deleted accounts.
+	sockets AccountSockets
 }
 
 // NewUserService creates a UserService backed by the given database handle.
-func NewUserService(db *gorm.DB) *UserService {
-	return &UserService{db: db}
+func NewUserService(db *gorm.DB, audit *AuditService, sockets AccountSockets) *UserService {
+	return &UserService{db: db, audit: audit, sockets: sockets}
 }
 
 // ---------------------------------------------------------------------------
 // CreateUser
 // ---------------------------------------------------------------------------
 
-func (s *UserService) CreateUser(email, username, password, fullName string) (*domain.User, error) {
+func (s *UserService) CreateUser(ctx context.Context, email, username, password, fullName string) (*domain.User, error) {
+	db := s.db.WithContext(ctx)
+
 	// Check email uniqueness.
 	var count int64
-	s.db.Model(&domain.User{}).Where("email = ?", email).Count(&count)
+	db.Model(&domain.User{}).Where("email = ?", email).Count(&count)
 	if count > 0 {
 		return nil, ErrEmailTaken
 	}
 
 	// Check username uniqueness.
-	s.db.Model(&domain.User{}).Where("username = ?", username).Count(&count)
+	db.Model(&domain.User{}).Where("username = ?", username).Count(&count)
 	if count > 0 {
 		return nil, ErrUsernameTaken
 	}
@@ -68,11 +96,12 @@ func (s *UserService) CreateUser(email, username, password, fullName string) (*d
 		Badges:       domain.JSONB("[]"),
 	}
 
-	if err := s.db.Create(&user).Error; err != nil {
+	if err := db.Create(&user).Error; err != nil {
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
-	// Bootstrap a reputation row for the new user.
+	// Bootstrap a reputation row for the new user. The user exists now, so
+	// this runs even if the request has been cancelled.
 	rep := domain.UserReputation{
 		UserID:                 user.ID,
 		SkillCredibilityScores: domain.JSONB("{}"),
@@ -86,9 +115,9 @@ func (s *UserService) CreateUser(email, username, password, fullName string) (*d
 // GetUser
 // ---------------------------------------------------------------------------
 
-func (s *UserService) GetUser(id string) (*domain.User, error) {
+func (s *UserService) GetUser(ctx context.Context, id string) (*domain.User, error) {
 	var user domain.User
-	err := s.db.First(&user, "id = ?", id).Error
+	err := s.db.WithContext(ctx).First(&user, "id = ?", id).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, ErrUserNotFound
 	}
@@ -98,11 +127,41 @@ func (s *UserService) GetUser(id string) (*domain.User, error) {
 	return &user, nil
 }
 
+// MaxBatchUsers caps how many users one GetUsersByIDs call may look up.
+const MaxBatchUsers = 100
+
+// GetUsersByIDs returns the users with the given ids, skills preloaded, in
+// the order the ids were given. Unknown and deleted users are left out, and
+// repeated ids appear once.
+func (s *UserService) GetUsersByIDs(ctx context.Context, ids []string) ([]*domain.User, error) {
+	if len(ids) == 0 {
+		return []*domain.User{}, nil
+	}
+
+	var users []domain.User
+	if err := s.db.WithContext(ctx).Preload("Skills.Skill").Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch users: %w", err)
+	}
+	byID := make(map[string]*domain.User, len(users))
+	for i := range users {
+		byID[users[i].ID] = &users[i]
+	}
+
+	result := make([]*domain.User, 0, len(users))
+	for _, id := range ids {
+		if u, ok := byID[id]; ok {
+			result = append(result, u)
+			delete(byID, id)
+		}
+	}
+	return result, nil
+}
+
 // ---------------------------------------------------------------------------
 // UpdateProfile
 // ---------------------------------------------------------------------------
 
-func (s *UserService) UpdateProfile(id string, updates map[string]interface{}) error {
+func (s *UserService) UpdateProfile(ctx context.Context, id string, updates map[string]interface{}) error {
 	// Whitelist the columns that callers are allowed to touch.
 	allowed := map[string]bool{
 		"full_name":   true,
@@ -110,6 +169,10 @@ func (s *UserService) UpdateProfile(id string, updates map[string]interface{}) e
 		"avatar_url":  true,
 		"github_url":  true,
 		"linkedin_url": true,
+		"preferred_cadence":         true,
+		"preferred_session_minutes": true,
+		"timezone":                  true,
+		"preferred_languages":       true,
 	}
 
 	clean := make(map[string]interface{})
@@ -122,7 +185,7 @@ func (s *UserService) UpdateProfile(id string, updates map[string]interface{}) e
 		return nil
 	}
 
-	res := s.db.Model(&domain.User{}).Where("id = ?", id).Updates(clean)
+	res := s.db.WithContext(ctx).Model(&domain.User{}).Where("id = ?", id).Updates(clean)
 	if res.Error != nil {
 		return fmt.Errorf("failed to update profile: %w", res.Error)
 	}
@@ -136,36 +199,126 @@ func (s *UserService) UpdateProfile(id string, updates map[string]interface{}) e
 // SearchUsers
 // ---------------------------------------------------------------------------
 
-func (s *UserService) SearchUsers(skills []string, skillLevel string, search string, limit, offset int) ([]*domain.User, int64, error) {
-	query := s.db.Model(&domain.User{})
+// UserSearchFilter narrows SearchUsers. Zero values are ignored.
+type UserSearchFilter struct {
+	Skills []string
+	// Level matches users with a skill at this proficiency; combined with
+	// Skills, one of those skills must be at this level.
+	Level  string
+	Search string
+
+	MinReputation        float64 // user_reputations.overall_score, 0-100
+	MinRating            float64 // user_reputations.average_rating, 1-5
+	HasCompletedSessions bool
+
+	// OnlineUserIDs, when non-nil, restricts results to these users.
+	OnlineUserIDs []string
+
+	// Languages matches users preferring any of these (normalized with
+	// NormalizeLanguages).
+	Languages []string
+	// Timezone matches users whose timezone's current UTC offset is within
+	// TimezoneRange hours of this IANA zone's.
+	Timezone      string
+	TimezoneRange int
+}
+
+// NormalizeLanguages lowercases and trims language names and drops empty
+// and duplicate entries.
+func NormalizeLanguages(langs []string) []string {
+	out := make([]string, 0, len(langs))
+	seen := make(map[string]bool, len(langs))
+	for _, l := range langs {
+		l = strings.ToLower(strings.TrimSpace(l))
+		if l == "" || seen[l] {
+			continue
+		}
+		seen[l] = true
+		out = append(out, l)
+	}
+	return out
+}
 
-	if search != "" {
-		like := "%" + strings.ToLower(search) + "%"
+// utcOffsetHours returns the current UTC offset of an IANA timezone.
+func utcOffsetHours(tz string) (float64, error) {
+	if tz == "" || strings.EqualFold(tz, "local") {
+		return 0, ErrInvalidTimezone
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return 0, ErrInvalidTimezone
+	}
+	_, offset := time.Now().In(loc).Zone()
+	return float64(offset) / 3600, nil
+}
+
+func (s *UserService) SearchUsers(ctx context.Context, filter UserSearchFilter, limit, offset int) ([]*domain.User, int64, error) {
+	db := s.db.WithContext(ctx)
+
+	query := db.Model(&domain.User{})
+
+	if filter.Search != "" {
+		like := "%" + strings.ToLower(filter.Search) + "%"
 		query = query.Where(
-			"LOWER(full_name) LIKE ? OR LOWER(username) LIKE ? OR LOWER(bio) LIKE ?",
+			"LOWER(users.full_name) LIKE ? OR LOWER(users.username) LIKE ? OR LOWER(users.bio) LIKE ?",
 			like, like, like,
 		)
 	}
 
-	if len(skills) > 0 {
-		query = query.Where(
-			"id IN (?)",
-			s.db.Model(&domain.UserSkill{}).
-				Select("user_id").
-				Joins("JOIN skills ON skills.id = user_skills.skill_id").
-				Where("skills.name IN ?", skills),
-		)
+	if len(filter.Skills) > 0 || filter.Level != "" {
+		sub := db.Model(&domain.UserSkill{}).Select("user_id")
+		if len(filter.Skills) > 0 {
+			sub = sub.Joins("JOIN skills ON skills.id = user_skills.skill_id").
+				Where("skills.name IN ?", filter.Skills)
+		}
+		if filter.Level != "" {
+			sub = sub.Where("proficiency_level = ?", filter.Level)
+		}
+		query = query.Where("users.id IN (?)", sub)
 	}
 
-	if skillLevel != "" {
+	// user_reputations has at most one row per user, so the join doesn't
+	// duplicate users or skew the count.
+	if filter.MinReputation > 0 || filter.MinRating > 0 || filter.HasCompletedSessions {
+		query = query.Joins("JOIN user_reputations ON user_reputations.user_id = users.id")
+		if filter.MinReputation > 0 {
+			query = query.Where("user_reputations.overall_score >= ?", filter.MinReputation)
+		}
+		if filter.MinRating > 0 {
+			query = query.Where("user_reputations.average_rating >= ?", filter.MinRating)
+		}
+		if filter.HasCompletedSessions {
+			query = query.Where("user_reputations.completed_sessions > 0")
+		}
+	}
+
+	if len(filter.Languages) > 0 {
 		query = query.Where(
-			"id IN (?)",
-			s.db.Model(&domain.UserSkill{}).
-				Select("user_id").
-				Where("proficiency_level = ?", skillLevel),
+			"EXISTS (SELECT 1 FROM jsonb_array_elements_text(users.preferred_languages) AS lang WHERE lang IN ?)",
+			filter.Languages,
 		)
 	}
 
+	// Offsets are compared around the clock, so UTC+12 and UTC-11 are an
+	// hour apart. pg_timezone_names also skips stored names Postgres doesn't
+	// know instead of failing the query.
+	if filter.Timezone != "" {
+		offset, err := utcOffsetHours(filter.Timezone)
+		if err != nil {
+			return nil, 0, err
+		}
+		query = query.Where(`users.timezone IN (SELECT name FROM pg_timezone_names
+			WHERE LEAST(ABS(EXTRACT(EPOCH FROM utc_offset) / 3600 - ?), 24 - ABS(EXTRACT(EPOCH FROM utc_offset) / 3600 - ?)) <= ?)`,
+			offset, offset, filter.TimezoneRange)
+	}
+
+	if filter.OnlineUserIDs != nil {
+		if len(filter.OnlineUserIDs) == 0 {
+			return []*domain.User{}, 0, nil
+		}
+		query = query.Where("users.id IN ?", filter.OnlineUserIDs)
+	}
+
 	var total int64
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, fmt.Errorf("failed to count users: %w", err)
@@ -174,7 +327,7 @@ func (s *UserService) SearchUsers(skills []string, skillLevel string, search str
 	var users []*domain.User
 	err := query.
 		Preload("Skills.Skill").
-		Order("reputation_score DESC").
+		Order("users.reputation_score DESC").
 		Limit(limit).
 		Offset(offset).
 		Find(&users).Error
@@ -189,36 +342,45 @@ func (s *UserService) SearchUsers(skills []string, skillLevel string, search str
 // AddSkill
 // ---------------------------------------------------------------------------
 
-func (s *UserService) AddSkill(userID string, skillName, proficiency string, years float64) error {
+// AddSkill adds a skill to userID's profile. When the name created a new
+// catalog skill, it also returns the names of existing skills it is a typo
+// away from, so the client can offer "did you mean".
+func (s *UserService) AddSkill(ctx context.Context, userID string, skillName, proficiency, direction string, years float64) ([]string, error) {
+	return addUserSkill(s.db.WithContext(ctx), userID, skillName, proficiency, direction, years)
+}
+
+// addUserSkill validates and inserts a single user skill using db, which may
+// be a transaction. It returns resolveSkill's suggestions.
+func addUserSkill(db *gorm.DB, userID string, skillName, proficiency, direction string, years float64) ([]string, error) {
 	level := domain.ProficiencyLevel(proficiency)
 	switch level {
 	case domain.Beginner, domain.Intermediate, domain.Advanced:
 	default:
-		return ErrInvalidLevel
+		return nil, ErrInvalidLevel
 	}
 
-	// Find or create the skill.
-	var skill domain.Skill
-	err := s.db.Where("name = ?", skillName).First(&skill).Error
-	if errors.Is(err, gorm.ErrRecordNotFound) {
-		skill = domain.Skill{
-			Name:     skillName,
-			Category: domain.CategoryOther,
-		}
-		if err := s.db.Create(&skill).Error; err != nil {
-			return fmt.Errorf("failed to create skill: %w", err)
-		}
-	} else if err != nil {
-		return fmt.Errorf("failed to look up skill: %w", err)
+	dir := domain.SkillDirection(direction)
+	switch dir {
+	case "":
+		dir = domain.DirectionBoth
+	case domain.DirectionTeach, domain.DirectionLearn, domain.DirectionBoth:
+	default:
+		return nil, ErrInvalidDirection
+	}
+
+	// Reuse the matching catalog skill before creating a new one.
+	skill, suggestions, err := resolveSkill(db, skillName)
+	if err != nil {
+		return nil, err
 	}
 
 	// Guard against duplicates.
 	var exists int64
-	s.db.Model(&domain.UserSkill{}).
+	db.Model(&domain.UserSkill{}).
 		Where("user_id = ? AND skill_id = ?", userID, skill.ID).
 		Count(&exists)
 	if exists > 0 {
-		return ErrSkillExists
+		return nil, ErrSkillExists
 	}
 
 	us := domain.UserSkill{
@@ -226,20 +388,100 @@ func (s *UserService) AddSkill(userID string, skillName, proficiency string, yea
 		SkillID:          skill.ID,
 		ProficiencyLevel: level,
 		YearsExperience:  years,
+		Direction:        dir,
 	}
-	if err := s.db.Create(&us).Error; err != nil {
-		return fmt.Errorf("failed to add skill: %w", err)
+	if err := db.Create(&us).Error; err != nil {
+		return nil, fmt.Errorf("failed to add skill: %w", err)
 	}
-	return nil
+	return suggestions, nil
+}
+
+// ---------------------------------------------------------------------------
+// BulkAddSkills
+// ---------------------------------------------------------------------------
+
+// MaxBulkSkills caps how many skills one bulk request may add.
+const MaxBulkSkills = 50
+
+// BulkSkillInput is one entry of a bulk skill add.
+type BulkSkillInput struct {
+	SkillName   string
+	Proficiency string
+	Direction   string
+	Years       float64
+}
+
+// Bulk skill add outcomes.
+const (
+	BulkSkillAdded   = "added"
+	BulkSkillSkipped = "skipped"
+	BulkSkillInvalid = "invalid"
+	BulkSkillFailed  = "failed"
+)
+
+// BulkSkillResult reports what happened to one BulkSkillInput.
+type BulkSkillResult struct {
+	Index     int    `json:"index"`
+	SkillName string `json:"skill_name"`
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+	// Suggestions are existing skills a newly created one may have meant.
+	Suggestions []string `json:"suggestions,omitempty"`
+}
+
+// BulkAddSkills adds every valid entry in one transaction. Each insert runs
+// under its own savepoint, so duplicates, invalid entries and failed inserts
+// are reported per item without rolling back the rest of the batch.
+func (s *UserService) BulkAddSkills(ctx context.Context, userID string, items []BulkSkillInput) ([]BulkSkillResult, error) {
+	results := make([]BulkSkillResult, len(items))
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, item := range items {
+			r := BulkSkillResult{Index: i, SkillName: strings.TrimSpace(item.SkillName)}
+			switch {
+			case r.SkillName == "":
+				r.Status, r.Error = BulkSkillInvalid, "skill_name is required"
+			case item.Years < 0:
+				r.Status, r.Error = BulkSkillInvalid, "years_experience must not be negative"
+			default:
+				var suggestions []string
+				err := tx.Transaction(func(sp *gorm.DB) error {
+					var err error
+					suggestions, err = addUserSkill(sp, userID, r.SkillName, item.Proficiency, item.Direction, item.Years)
+					return err
+				})
+				switch err {
+				case nil:
+					r.Status, r.Suggestions = BulkSkillAdded, suggestions
+				case ErrSkillExists:
+					r.Status, r.Error = BulkSkillSkipped, err.Error()
+				case ErrInvalidLevel, ErrInvalidDirection:
+					r.Status, r.Error = BulkSkillInvalid, err.Error()
+				default:
+					log.Error().Err(err).Str("user_id", userID).Str("skill", r.SkillName).Msg("bulk skill insert failed")
+					r.Status, r.Error = BulkSkillFailed, "failed to add skill"
+				}
+			}
+			results[i] = r
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add skills: %w", err)
+	}
+	return results, nil
 }
 
 // ---------------------------------------------------------------------------
 // GetUserWithReputation
 // ---------------------------------------------------------------------------
 
-func (s *UserService) GetUserWithReputation(id string) (*UserWithReputation, error) {
+// GetUserWithReputation returns the user with skills preloaded and their
+// reputation row, if one exists yet.
+func (s *UserService) GetUserWithReputation(ctx context.Context, id string) (*UserWithReputation, error) {
+	db := s.db.WithContext(ctx)
+
 	var user domain.User
-	err := s.db.First(&user, "id = ?", id).Error
+	err := db.Preload("Skills.Skill").First(&user, "id = ?", id).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, ErrUserNotFound
 	}
@@ -253,7 +495,7 @@ func (s *UserService) GetUserWithReputation(id string) (*UserWithReputation, err
 
 	// Reputation table may not exist yet; ignore errors.
 	var rep domain.UserReputation
-	if err := s.db.Where("user_id = ?", id).First(&rep).Error; err == nil {
+	if err := db.Where("user_id = ?", id).First(&rep).Error; err == nil {
 		result.Reputation = &rep
 	}
 
@@ -264,31 +506,76 @@ func (s *UserService) GetUserWithReputation(id string) (*UserWithReputation, err
 // FindOrCreateOAuthUser
 // ---------------------------------------------------------------------------
 
-func (s *UserService) FindOrCreateOAuthUser(provider, providerID, email, fullName, avatarURL string) (*domain.User, error) {
+func (s *UserService) FindOrCreateOAuthUser(ctx context.Context, provider, providerID, email, fullName, avatarURL string) (*domain.User, error) {
+	db := s.db.WithContext(ctx)
+
 	var user domain.User
 
+	providerCol, ok := oauthProviderColumn(provider)
+	if !ok {
+		return nil, fmt.Errorf("unsupported oauth provider %q", provider)
+	}
+
 	// 1. Look up by provider ID.
-	providerCol := provider + "_id" // "google_id" or "github_id"
-	err := s.db.Where(providerCol+" = ?", providerID).First(&user).Error
+	err := db.Where(providerCol+" = ?", providerID).First(&user).Error
 	if err == nil {
 		return &user, nil
 	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to look up oauth user: %w", err)
+	}
 
 	// 2. Look up by email to link existing account.
 	if email != "" {
-		err = s.db.Where("email = ?", email).First(&user).Error
+		err = db.Where("email = ?", email).First(&user).Error
 		if err == nil {
-			// Link the provider ID to the existing account.
-			s.db.Model(&user).Update(providerCol, providerID)
+			if linked := user.OAuthID(provider); linked != "" && linked != providerID {
+				// Never overwrite an existing link; the user has to sign in
+				// with the original identity and sort it out.
+				log.Warn().
+					Str("user_id", user.ID).
+					Str("provider", provider).
+					Msg("oauth email match already linked to a different provider identity")
+				return nil, ErrOAuthAccountConflict
+			}
+
+			// Link only if the column is still empty, so a concurrent link
+			// cannot be clobbered.
+			res := db.Model(&domain.User{}).
+				Where("id = ? AND ("+providerCol+" IS NULL OR "+providerCol+" = '')", user.ID).
+				Update(providerCol, providerID)
+			if res.Error != nil {
+				return nil, fmt.Errorf("failed to link oauth account: %w", res.Error)
+			}
+			if res.RowsAffected == 0 {
+				return nil, ErrOAuthAccountConflict
+			}
+
+			// Make sure the identity didn't get attached elsewhere meanwhile.
+			var owners int64
+			db.Model(&domain.User{}).Where(providerCol+" = ?", providerID).Count(&owners)
+			if owners > 1 {
+				// Undo without the request context so it can't be cancelled.
+				s.db.Model(&domain.User{}).Where("id = ?", user.ID).Update(providerCol, "")
+				return nil, ErrOAuthIdentityLinked
+			}
+
 			if avatarURL != "" && user.AvatarURL == "" {
-				s.db.Model(&user).Update("avatar_url", avatarURL)
+				db.Model(&user).Update("avatar_url", avatarURL)
 			}
+			s.audit.Audit(AuditEntry{
+				Action:     domain.AuditOAuthLink,
+				ActorID:    user.ID,
+				TargetType: "user",
+				TargetID:   user.ID,
+				Metadata:   map[string]interface{}{"provider": provider},
+			})
 			return &user, nil
 		}
 	}
 
 	// 3. Create new user.
-	username := s.generateUniqueUsername(fullName, provider)
+	username := s.generateUniqueUsername(ctx, fullName, provider)
 
 	user = domain.User{
 		Email:     email,
@@ -304,13 +591,15 @@ func (s *UserService) FindOrCreateOAuthUser(provider, providerID, email, fullNam
 		user.GoogleID = providerID
 	case "github":
 		user.GitHubID = providerID
+	case "gitlab":
+		user.GitLabID = providerID
 	}
 
-	if err := s.db.Create(&user).Error; err != nil {
+	if err := db.Create(&user).Error; err != nil {
 		return nil, fmt.Errorf("failed to create oauth user: %w", err)
 	}
 
-	// Bootstrap reputation row.
+	// Bootstrap reputation row, even if the request has been cancelled.
 	rep := domain.UserReputation{
 		UserID:                 user.ID,
 		SkillCredibilityScores: domain.JSONB("{}"),
@@ -320,9 +609,45 @@ func (s *UserService) FindOrCreateOAuthUser(provider, providerID, email, fullNam
 	return &user, nil
 }
 
+// SetGitHubToken stores the user's GitHub access token encrypted at rest.
+func (s *UserService) SetGitHubToken(ctx context.Context, userID, token string) error {
+	encrypted, err := auth.EncryptSecret(token)
+	if err != nil {
+		return err
+	}
+	return s.db.WithContext(ctx).Model(&domain.User{}).Where("id = ?", userID).Update("github_token", encrypted).Error
+}
+
+// SetGitHubProfileURL fills in the user's github_url from their GitHub login
+// when they haven't set one, so signing in with GitHub gives a working
+// profile link. An existing link is left alone.
+func (s *UserService) SetGitHubProfileURL(ctx context.Context, userID, login string) error {
+	u, err := domain.NormalizeGitHubURL("https://github.com/" + login)
+	if err != nil {
+		return err
+	}
+	return s.db.WithContext(ctx).Model(&domain.User{}).
+		Where("id = ? AND (github_url IS NULL OR github_url = '')", userID).
+		Update("github_url", u).Error
+}
+
+// oauthProviderColumn maps a provider name to its users column. GitHubID and
+// GitLabID are stored as git_hub_id and git_lab_id by GORM's naming strategy.
+func oauthProviderColumn(provider string) (string, bool) {
+	switch provider {
+	case "google":
+		return "google_id", true
+	case "github":
+		return "git_hub_id", true
+	case "gitlab":
+		return "git_lab_id", true
+	}
+	return "", false
+}
+
 // generateUniqueUsername creates a username from the user's name, appending a
 // number if the base name is already taken.
-func (s *UserService) generateUniqueUsername(fullName, provider string) string {
+func (s *UserService) generateUniqueUsername(ctx context.Context, fullName, provider string) string {
 	base := strings.ToLower(strings.ReplaceAll(fullName, " ", ""))
 	if base == "" {
 		base = provider + "user"
@@ -341,7 +666,7 @@ func (s *UserService) generateUniqueUsername(fullName, provider string) string {
 	username := clean
 	var count int64
 	for i := 1; ; i++ {
-		s.db.Model(&domain.User{}).Where("username = ?", username).Count(&count)
+		s.db.WithContext(ctx).Model(&domain.User{}).Where("username = ?", username).Count(&count)
 		if count == 0 {
 			return username
 		}
@@ -353,9 +678,9 @@ func (s *UserService) generateUniqueUsername(fullName, provider string) string {
 // Authenticate (used by login handler)
 // ---------------------------------------------------------------------------
 
-func (s *UserService) Authenticate(email, password string) (*domain.User, error) {
+func (s *UserService) Authenticate(ctx context.Context, email, password string) (*domain.User, error) {
 	var user domain.User
-	err := s.db.Where("email = ?", email).First(&user).Error
+	err := s.db.WithContext(ctx).Where("email = ?", email).First(&user).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, ErrUserNotFound
 	}
@@ -368,3 +693,162 @@ func (s *UserService) Authenticate(email, password string) (*domain.User, error)
 	}
 	return &user, nil
 }
+
+// ---------------------------------------------------------------------------
+// ChangePassword
+// ---------------------------------------------------------------------------
+
+// ChangePassword replaces the user's password. The current password must match
+// unless the account has none yet (OAuth-only sign-ups).
+func (s *UserService) ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) error {
+	db := s.db.WithContext(ctx)
+
+	var user domain.User
+	err := db.First(&user, "id = ?", userID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrUserNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to fetch user: %w", err)
+	}
+
+	if user.PasswordHash != "" {
+		if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(currentPassword)); err != nil {
+			return ErrWrongPassword
+		}
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	if err := db.Model(&user).Update("password_hash", string(hash)).Error; err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// DeleteAccount
+// ---------------------------------------------------------------------------
+
+// DeleteAccount erases a user's account in a single transaction. Accounts
+// with a password must confirm it; OAuth-only accounts need none.
+//
+// Purged (rows deleted): the user's skills, match requests sent or received,
+// reputation, ratings received, session feedback given, assessments, blocks,
+// notifications, match digests, session proposals sent or received,
+// flagged copies of the user's messages, refresh tokens, API keys and
+// idempotency keys. The users row is only soft-deleted, so foreign key
+// cascades never run; every table holding the user's data is listed here.
+//
+// Anonymized (kept so other users' history stays intact): messages the user
+// sent have their content replaced with "[deleted]" and metadata cleared;
+// ratings the user gave keep their scores but lose the comment. The user's
+// matches are set inactive and their rooms ended, and the user's open
+// sockets are closed. Abuse reports are kept for moderation.
+//
+// The users row is scrubbed of all personal data (email, username, names,
+// links, OAuth IDs and tokens, password, timezone and languages) and
+// soft-deleted, which hides it from search, matching and leaderboards and
+// makes the JWT middleware reject the user's remaining access tokens.
+func (s *UserService) DeleteAccount(ctx context.Context, userID, password string) error {
+	db := s.db.WithContext(ctx)
+
+	var user domain.User
+	err := db.First(&user, "id = ?", userID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrUserNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to fetch user: %w", err)
+	}
+
+	if user.PasswordHash != "" {
+		if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+			return ErrWrongPassword
+		}
+	}
+
+	var ended []domain.Match
+	err = db.Transaction(func(tx *gorm.DB) error {
+		purge := []struct {
+			model interface{}
+			where string
+			args  []interface{}
+		}{
+			{&domain.UserSkill{}, "user_id = ?", []interface{}{userID}},
+			{&domain.MatchRequest{}, "sender_id = ? OR receiver_id = ?", []interface{}{userID, userID}},
+			{&domain.UserReputation{}, "user_id = ?", []interface{}{userID}},
+			{&domain.Rating{}, "rated_id = ?", []interface{}{userID}},
+			{&domain.SessionFeedback{}, "user_id = ?", []interface{}{userID}},
+			{&domain.Assessment{}, "user_id = ?", []interface{}{userID}},
+			{&domain.UserBlock{}, "blocker_id = ? OR blocked_id = ?", []interface{}{userID, userID}},
+			{&domain.Notification{}, "user_id = ?", []interface{}{userID}},
+			{&domain.MatchDigest{}, "user_id = ?", []interface{}{userID}},
+			{&domain.SessionProposal{}, "proposer_id = ? OR recipient_id = ?", []interface{}{userID, userID}},
+			{&domain.FlaggedMessage{}, "sender_id = ?", []interface{}{userID}},
+			{&domain.RefreshToken{}, "user_id = ?", []interface{}{userID}},
+			{&domain.APIKey{}, "user_id = ?", []interface{}{userID}},
+			{&domain.IdempotencyKey{}, "user_id = ?", []interface{}{userID}},
+		}
+		for _, p := range purge {
+			if err := tx.Where(p.where, p.args...).Delete(p.model).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&domain.Message{}).Where("sender_id = ?", userID).
+			Updates(map[string]interface{}{"content": "[deleted]", "metadata": nil}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&domain.Rating{}).Where("rater_id = ?", userID).
+			Update("comment", "").Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&ended).
+			Clauses(clause.Returning{Columns: []clause.Column{{Name: "id"}}}).
+			Where("(user1_id = ? OR user2_id = ?) AND status = ?", userID, userID, domain.MatchActive).
+			Update("status", domain.MatchInactive).Error; err != nil {
+			return err
+		}
+
+		// Free the unique email/username for reuse and drop all PII.
+		if err := tx.Model(&user).Updates(map[string]interface{}{
+			"email":               "deleted-" + userID + "@deleted.invalid",
+			"username":            "deleted-" + userID,
+			"password_hash":       "",
+			"full_name":           "",
+			"bio":                 "",
+			"avatar_url":          "",
+			"github_url":          "",
+			"linkedin_url":        "",
+			"google_id":           "",
+			"git_hub_id":          "",
+			"git_lab_id":          "",
+			"github_token":        "",
+			"badges":              domain.JSONB("[]"),
+			"timezone":            "",
+			"preferred_languages": domain.StringList{},
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&user).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete account: %w", err)
+	}
+
+	// Otherwise messages sent on a socket opened before the deletion would
+	// still be stored intact.
+	if s.sockets != nil {
+		s.sockets.DisconnectUser(userID)
+		for _, m := range ended {
+			s.sockets.EndMatch(m.ID)
+		}
+	}
+
+	log.Info().Str("user_id", userID).Msg("account deleted")
+	return nil
+}
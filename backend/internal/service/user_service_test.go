@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/skillsync/internal/domain"
+)
+
+func TestFindOrCreateOAuthUserConflictingLink(t *testing.T) {
+	db := testDB(t)
+	s := NewUserService(db, nil, nil)
+
+	user := createTestUser(t, db)
+	original := "gh-" + testSuffix(t)
+	if err := db.Model(user).Update("git_hub_id", original).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// Same email, different GitHub identity: refused, link untouched.
+	other := "gh-" + testSuffix(t)
+	if _, err := s.FindOrCreateOAuthUser(context.Background(), "github", other, user.Email, user.FullName, ""); err != ErrOAuthAccountConflict {
+		t.Fatalf("conflicting link = %v, want %v", err, ErrOAuthAccountConflict)
+	}
+	var got domain.User
+	db.First(&got, "id = ?", user.ID)
+	if got.GitHubID != original {
+		t.Fatalf("git_hub_id = %q, want %q kept", got.GitHubID, original)
+	}
+	var count int64
+	db.Model(&domain.User{}).Where("git_hub_id = ?", other).Count(&count)
+	if count != 0 {
+		t.Fatalf("%d users hold the refused identity, want 0", count)
+	}
+
+	// The original identity still signs in.
+	signedIn, err := s.FindOrCreateOAuthUser(context.Background(), "github", original, user.Email, user.FullName, "")
+	if err != nil || signedIn.ID != user.ID {
+		t.Fatalf("original identity = %v, %v; want user %s", signedIn, err, user.ID)
+	}
+}
+
+func TestFindOrCreateOAuthUserLinksByEmail(t *testing.T) {
+	db := testDB(t)
+	s := NewUserService(db, nil, nil)
+
+	user := createTestUser(t, db)
+	id := "gh-" + testSuffix(t)
+	linked, err := s.FindOrCreateOAuthUser(context.Background(), "github", id, user.Email, user.FullName, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if linked.ID != user.ID {
+		t.Fatalf("linked user %s, want %s", linked.ID, user.ID)
+	}
+	var got domain.User
+	db.First(&got, "id = ?", user.ID)
+	if got.GitHubID != id {
+		t.Fatalf("git_hub_id = %q, want %q", got.GitHubID, id)
+	}
+}
+
+type recordingSockets struct {
+	disconnected []string
+	ended        []uint
+}
+
+func (r *recordingSockets) DisconnectUser(userID string) {
+	r.disconnected = append(r.disconnected, userID)
+}
+func (r *recordingSockets) EndMatch(matchID uint) { r.ended = append(r.ended, matchID) }
+
+func TestDeleteAccountClosesSocketsAndRejectsTokens(t *testing.T) {
+	db := testDB(t)
+	sockets := &recordingSockets{}
+	s := NewUserService(db, nil, sockets)
+	mod := NewModerationService(db, s, NewTokenService(db), nil)
+
+	user := createTestUser(t, db)
+	match := createTestMatch(t, db, user, createTestUser(t, db))
+	db.Model(user).Updates(map[string]interface{}{
+		"timezone":            "Europe/Berlin",
+		"preferred_languages": domain.StringList{"go"},
+	})
+	if mod.IsBanned(user.ID) {
+		t.Fatal("live account rejected")
+	}
+
+	if err := s.DeleteAccount(context.Background(), user.ID, ""); err != nil {
+		t.Fatal(err)
+	}
+	if len(sockets.disconnected) != 1 || sockets.disconnected[0] != user.ID {
+		t.Fatalf("disconnected %v, want [%s]", sockets.disconnected, user.ID)
+	}
+	if len(sockets.ended) != 1 || sockets.ended[0] != match.ID {
+		t.Fatalf("ended matches %v, want [%d]", sockets.ended, match.ID)
+	}
+	if !mod.IsBanned(user.ID) {
+		t.Fatal("deleted account's tokens still accepted")
+	}
+
+	var got domain.User
+	db.Unscoped().First(&got, "id = ?", user.ID)
+	if got.Timezone != "" || len(got.PreferredLanguages) != 0 {
+		t.Fatalf("timezone %q, languages %v kept", got.Timezone, got.PreferredLanguages)
+	}
+}
+
+// A name one edit from a catalog skill creates its own skill and suggests
+// the existing one; only the same normalized key reuses it.
+func TestAddSkillSuggestsNearNames(t *testing.T) {
+	db := testDB(t)
+	s := NewUserService(db, nil, nil)
+	ctx := context.Background()
+
+	user := createTestUser(t, db)
+	existing := createTestSkill(t, db)
+	near := existing.Name[:len(existing.Name)-1] + "z"
+	if near == existing.Name {
+		near = existing.Name[:len(existing.Name)-1] + "y"
+	}
+	t.Cleanup(func() {
+		db.Where("skill_id IN (?)", db.Model(&domain.Skill{}).Select("id").Where("name = ?", near)).Delete(&domain.UserSkill{})
+		db.Where("name = ?", near).Delete(&domain.Skill{})
+	})
+
+	suggestions, err := s.AddSkill(ctx, user.ID, near, "beginner", "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(suggestions) != 1 || suggestions[0] != existing.Name {
+		t.Fatalf("suggestions = %v, want [%s]", suggestions, existing.Name)
+	}
+	var linked int64
+	db.Model(&domain.UserSkill{}).Where("user_id = ? AND skill_id = ?", user.ID, existing.ID).Count(&linked)
+	if linked != 0 {
+		t.Fatal("near name merged into the existing skill")
+	}
+
+	upper := strings.ToUpper(strings.ReplaceAll(existing.Name, "-", " "))
+	if suggestions, err := s.AddSkill(ctx, user.ID, upper, "beginner", "", 0); err != nil || suggestions != nil {
+		t.Fatalf("same key = %v, %v; want reused without suggestions", suggestions, err)
+	}
+	db.Model(&domain.UserSkill{}).Where("user_id = ? AND skill_id = ?", user.ID, existing.ID).Count(&linked)
+	if linked != 1 {
+		t.Fatal("same normalized key not resolved to the existing skill")
+	}
+	db.Where("user_id = ?", user.ID).Delete(&domain.UserSkill{})
+}
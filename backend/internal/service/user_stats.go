@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/yourusername/skillsync/internal/domain"
+)
+
+// UserStats summarizes a user's pairing activity for the dashboard. It is
+// separate from reputation: nothing here depends on how others rated them.
+type UserStats struct {
+	TotalMatches      int64   `json:"total_matches"`
+	ActiveMatches     int64   `json:"active_matches"`
+	DistinctPartners  int64   `json:"distinct_partners"`
+	TotalSessions     int64   `json:"total_sessions"`
+	CompletedSessions int64   `json:"completed_sessions"`
+	TotalHoursPaired  float64 `json:"total_hours_paired"`
+	// MostPairedSkill is the user's skill that the most partners also list,
+	// or "" when no partner shares one.
+	MostPairedSkill      string `json:"most_paired_skill"`
+	MostPairedSkillCount int64  `json:"most_paired_skill_count"`
+	RequestsSent         int64  `json:"requests_sent"`
+	RequestsAccepted     int64  `json:"requests_accepted"`
+	RequestsRejected     int64  `json:"requests_rejected"`
+	// AcceptanceRate is accepted / (accepted + rejected) for sent requests,
+	// from 0 to 1. Pending requests are not counted.
+	AcceptanceRate float64 `json:"acceptance_rate"`
+}
+
+// partnersSQL selects the other user of every match userID is in. It takes
+// userID three times.
+const partnersSQL = `SELECT CASE WHEN user1_id = ? THEN user2_id ELSE user1_id END AS partner_id
+	FROM matches WHERE user1_id = ? OR user2_id = ?`
+
+// GetStats computes UserStats for userID.
+func (s *UserService) GetStats(ctx context.Context, userID string) (*UserStats, error) {
+	db := s.db.WithContext(ctx)
+
+	var stats UserStats
+
+	var matches struct {
+		Total    int64
+		Active   int64
+		Partners int64
+	}
+	if err := db.Raw(`SELECT COUNT(*) AS total,
+			COUNT(*) FILTER (WHERE status = ?) AS active,
+			COUNT(DISTINCT CASE WHEN user1_id = ? THEN user2_id ELSE user1_id END) AS partners
+		FROM matches WHERE user1_id = ? OR user2_id = ?`,
+		domain.MatchActive, userID, userID, userID).Scan(&matches).Error; err != nil {
+		return nil, fmt.Errorf("failed to count matches: %w", err)
+	}
+	stats.TotalMatches = matches.Total
+	stats.ActiveMatches = matches.Active
+	stats.DistinctPartners = matches.Partners
+
+	var sessions struct {
+		Total     int64
+		Completed int64
+		Minutes   int64
+	}
+	if err := db.Model(&domain.CodingSession{}).
+		Joins("JOIN matches ON matches.id = coding_sessions.match_id").
+		Where("matches.user1_id = ? OR matches.user2_id = ?", userID, userID).
+		Select(`COUNT(*) AS total,
+			COUNT(coding_sessions.ended_at) AS completed,
+			COALESCE(SUM(coding_sessions.duration_minutes), 0) AS minutes`).
+		Scan(&sessions).Error; err != nil {
+		return nil, fmt.Errorf("failed to aggregate sessions: %w", err)
+	}
+	stats.TotalSessions = sessions.Total
+	stats.CompletedSessions = sessions.Completed
+	stats.TotalHoursPaired = math.Round(float64(sessions.Minutes)/60*10) / 10
+
+	var skill struct {
+		Name     string
+		Partners int64
+	}
+	if err := db.Raw(`SELECT skills.name AS name, COUNT(DISTINCT theirs.user_id) AS partners
+		FROM user_skills mine
+		JOIN user_skills theirs ON theirs.skill_id = mine.skill_id
+		JOIN skills ON skills.id = mine.skill_id
+		WHERE mine.user_id = ? AND theirs.user_id IN (`+partnersSQL+`)
+		GROUP BY skills.name
+		ORDER BY partners DESC, skills.name ASC
+		LIMIT 1`,
+		userID, userID, userID, userID).Scan(&skill).Error; err != nil {
+		return nil, fmt.Errorf("failed to find most paired skill: %w", err)
+	}
+	stats.MostPairedSkill = skill.Name
+	stats.MostPairedSkillCount = skill.Partners
+
+	var requests struct {
+		Sent     int64
+		Accepted int64
+		Rejected int64
+	}
+	if err := db.Model(&domain.MatchRequest{}).
+		Where("sender_id = ?", userID).
+		Select(`COUNT(*) AS sent,
+			COUNT(*) FILTER (WHERE status = ?) AS accepted,
+			COUNT(*) FILTER (WHERE status = ?) AS rejected`,
+			domain.RequestAccepted, domain.RequestRejected).
+		Scan(&requests).Error; err != nil {
+		return nil, fmt.Errorf("failed to count match requests: %w", err)
+	}
+	stats.RequestsSent = requests.Sent
+	stats.RequestsAccepted = requests.Accepted
+	stats.RequestsRejected = requests.Rejected
+	if responded := requests.Accepted + requests.Rejected; responded > 0 {
+		stats.AcceptanceRate = math.Round(float64(requests.Accepted)/float64(responded)*100) / 100
+	}
+
+	return &stats, nil
+}
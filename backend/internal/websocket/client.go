@@ -2,6 +2,11 @@ package websocket
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -9,41 +14,104 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/yourusername/skillsync/internal/domain"
+	"github.com/yourusername/skillsync/pkg/auth"
 )
 
 const (
-	// Time allowed to write a message to the peer.
-	writeWait = 10 * time.Second
-
-	// Time allowed to read the next pong message from the peer.
-	pongWait = 60 * time.Second
+	// typingDebounce is the minimum gap between "is typing" broadcasts for
+	// one client; typingExpiry clears the indicator when the client goes
+	// quiet without sending is_typing=false.
+	typingDebounce = 2 * time.Second
+	typingExpiry   = 5 * time.Second
+)
 
-	// Send pings to peer with this period. Must be less than pongWait.
-	pingPeriod = (pongWait * 9) / 10
+// Application close codes sent when a refresh_token message is rejected.
+// Clients should fetch a new token and reconnect on CloseTokenInvalid;
+// CloseTokenUserMismatch means a different account's token was sent and
+// should not be retried on this connection.
+const (
+	CloseTokenInvalid      = 4002
+	CloseTokenUserMismatch = 4003
+)
 
-	// Maximum message size allowed from peer (64 KB).
-	maxMessageSize = 64 * 1024
+var (
+	ErrMatchNotFound   = errors.New("match not found")
+	ErrNotParticipant  = errors.New("you are not a participant in this match")
+	ErrMatchNotActive  = errors.New("match is not active")
+	ErrNotSubscribed   = errors.New("not subscribed to this match")
+	ErrMatchIDRequired = errors.New("match_id is required when subscribed to several matches")
+	ErrInvalidPayload  = errors.New("invalid payload")
+	ErrCodeTooLarge    = errors.New("code is too large")
+	ErrUnknownLanguage = errors.New("unknown language")
+	ErrInvalidCursor   = errors.New("cursor is outside the code")
 )
 
+// CheckMatchAccess verifies that matchID exists, is active and has userID as
+// a participant.
+func CheckMatchAccess(db *gorm.DB, userID string, matchID uint) error {
+	var match domain.Match
+	if err := db.First(&match, "id = ?", matchID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrMatchNotFound
+		}
+		return err
+	}
+	if match.User1ID != userID && match.User2ID != userID {
+		return ErrNotParticipant
+	}
+	if match.Status != domain.MatchActive {
+		return ErrMatchNotActive
+	}
+	return nil
+}
+
 // Client is a middleman between a single WebSocket connection and the Hub.
 type Client struct {
-	Hub     *Hub
-	Conn    *websocket.Conn
-	UserID  string
-	MatchID uint
-	DB      *gorm.DB
-	send    chan []byte
+	Hub    *Hub
+	Conn   *websocket.Conn
+	UserID string
+	DB     *gorm.DB
+	send   chan []byte
+
+	// matches is the set of subscribed match IDs, guarded by Hub.mu.
+	// closeWhenIdle is set for connections opened for a specific match;
+	// they close once their last match ends.
+	matches       map[uint]bool
+	closeWhenIdle bool
+
+	// Typing indicator state per match. typingGen numbers expiry timers.
+	typingMu  sync.Mutex
+	typing    map[uint]*typingState
+	typingGen uint64
+}
+
+// typingState tracks one match's indicator; timer fires expireTyping after
+// typingExpiry without a new typing event. gen identifies the current
+// timer, so one that fired just before a renewal does nothing.
+type typingState struct {
+	typing bool
+	sentAt time.Time
+	timer  *time.Timer
+	gen    uint64
 }
 
-func NewClient(hub *Hub, conn *websocket.Conn, userID string, matchID uint, db *gorm.DB) *Client {
-	return &Client{
-		Hub:     hub,
-		Conn:    conn,
-		UserID:  userID,
-		MatchID: matchID,
-		DB:      db,
-		send:    make(chan []byte, 256),
+// NewClient creates a client subscribed to matchIDs, which the caller has
+// already authorized. More matches can be joined with "subscribe" messages.
+func NewClient(hub *Hub, conn *websocket.Conn, userID string, db *gorm.DB, matchIDs ...uint) *Client {
+	c := &Client{
+		Hub:           hub,
+		Conn:          conn,
+		UserID:        userID,
+		DB:            db,
+		send:          make(chan []byte, 256),
+		matches:       make(map[uint]bool, len(matchIDs)),
+		closeWhenIdle: len(matchIDs) > 0,
+		typing:        make(map[uint]*typingState),
 	}
+	for _, id := range matchIDs {
+		c.matches[id] = true
+	}
+	return c
 }
 
 // ---------------------------------------------------------------------------
@@ -56,24 +124,59 @@ type InboundMessage struct {
 	Data json.RawMessage `json:"data"`
 }
 
+// Room-scoped payloads carry the match they are for. MatchID may be omitted
+// while the client is subscribed to exactly one match.
+
+// SubscribePayload is the data field for "subscribe" and "unsubscribe".
+type SubscribePayload struct {
+	MatchID uint `json:"match_id"`
+}
+
 // ChatPayload is the data field for a "chat_message".
+//
+// Type is text (default), code or file; Metadata carries the language hint
+// for code and the filename/url for files.
 type ChatPayload struct {
-	Content    string `json:"content"`
-	ReceiverID string `json:"receiver_id"`
+	MatchID    uint                    `json:"match_id"`
+	Content    string                  `json:"content"`
+	ReceiverID string                  `json:"receiver_id"`
+	Type       domain.MessageType      `json:"type"`
+	Metadata   *domain.MessageMetadata `json:"metadata"`
 }
 
 // TypingPayload is the data field for a "typing_indicator".
 type TypingPayload struct {
+	MatchID  uint `json:"match_id"`
 	IsTyping bool `json:"is_typing"`
 }
 
-// CodeChangePayload is the data field for a "code_change".
+// CodeChangePayload is the data field for a "code_change". Code is capped at
+// Config.MaxCodeSize bytes; Language must be empty or a known language (see
+// languageCache); Cursor is an offset into Code.
 type CodeChangePayload struct {
+	MatchID  uint   `json:"match_id"`
 	Code     string `json:"code"`
 	Language string `json:"language"`
 	Cursor   int    `json:"cursor"`
 }
 
+// RefreshTokenPayload is the data field for a "refresh_token".
+type RefreshTokenPayload struct {
+	Token string `json:"token"`
+}
+
+// OutboundTokenRefreshed acknowledges a successful "refresh_token".
+type OutboundTokenRefreshed struct {
+	Type      string    `json:"type"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// OutboundSubscription acknowledges "subscribe" and "unsubscribe".
+type OutboundSubscription struct {
+	Type    string `json:"type"`
+	MatchID uint   `json:"match_id"`
+}
+
 // OutboundChatMessage is what gets broadcast for chat messages.
 type OutboundChatMessage struct {
 	Type      string         `json:"type"`
@@ -81,9 +184,18 @@ type OutboundChatMessage struct {
 	Timestamp time.Time      `json:"timestamp"`
 }
 
+// OutboundError is sent back to the sender when one of their messages is
+// rejected.
+type OutboundError struct {
+	Type    string `json:"type"`
+	Request string `json:"request"`
+	Error   string `json:"error"`
+}
+
 // OutboundTypingMessage is what gets broadcast for typing indicators.
 type OutboundTypingMessage struct {
 	Type     string `json:"type"`
+	MatchID  uint   `json:"match_id"`
 	UserID   string `json:"user_id"`
 	IsTyping bool   `json:"is_typing"`
 }
@@ -91,6 +203,7 @@ type OutboundTypingMessage struct {
 // OutboundCodeChange is what gets broadcast for code changes.
 type OutboundCodeChange struct {
 	Type     string `json:"type"`
+	MatchID  uint   `json:"match_id"`
 	UserID   string `json:"user_id"`
 	Code     string `json:"code"`
 	Language string `json:"language"`
@@ -105,14 +218,16 @@ type OutboundCodeChange struct {
 // It runs in its own goroutine per client.
 func (c *Client) ReadPump() {
 	defer func() {
+		c.clearTyping()
 		c.Hub.Unregister(c)
 		c.Conn.Close()
 	}()
 
-	c.Conn.SetReadLimit(maxMessageSize)
-	c.Conn.SetReadDeadline(time.Now().Add(pongWait))
+	cfg := c.Hub.cfg
+	c.Conn.SetReadLimit(cfg.MaxMessageSize)
+	c.Conn.SetReadDeadline(time.Now().Add(cfg.PongWait))
 	c.Conn.SetPongHandler(func(string) error {
-		c.Conn.SetReadDeadline(time.Now().Add(pongWait))
+		c.Conn.SetReadDeadline(time.Now().Add(cfg.PongWait))
 		return nil
 	})
 
@@ -145,7 +260,8 @@ func (c *Client) ReadPump() {
 // WritePump pumps messages from the hub to the WebSocket connection.
 // A goroutine running WritePump is started for each client.
 func (c *Client) WritePump() {
-	ticker := time.NewTicker(pingPeriod)
+	cfg := c.Hub.cfg
+	ticker := time.NewTicker(cfg.PingPeriod)
 	defer func() {
 		ticker.Stop()
 		c.Conn.Close()
@@ -154,7 +270,7 @@ func (c *Client) WritePump() {
 	for {
 		select {
 		case message, ok := <-c.send:
-			c.Conn.SetWriteDeadline(time.Now().Add(writeWait))
+			c.Conn.SetWriteDeadline(time.Now().Add(cfg.WriteWait))
 			if !ok {
 				// Hub closed the channel.
 				c.Conn.WriteMessage(websocket.CloseMessage, []byte{})
@@ -167,6 +283,15 @@ func (c *Client) WritePump() {
 			}
 			w.Write(message)
 
+			// With a batch window, hold the frame open briefly so bursts
+			// (typing, code changes) go out as one write. Frames come off a
+			// single channel, so ordering is preserved.
+			if !c.collectBatch(w) {
+				w.Close()
+				c.Conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+
 			// Drain any queued messages into the same write.
 			n := len(c.send)
 			for i := 0; i < n; i++ {
@@ -179,7 +304,7 @@ func (c *Client) WritePump() {
 			}
 
 		case <-ticker.C:
-			c.Conn.SetWriteDeadline(time.Now().Add(writeWait))
+			c.Conn.SetWriteDeadline(time.Now().Add(cfg.WriteWait))
 			if err := c.Conn.WriteMessage(websocket.PingMessage, nil); err != nil {
 				return
 			}
@@ -187,26 +312,135 @@ func (c *Client) WritePump() {
 	}
 }
 
+// collectBatch appends messages arriving within the hub's batch window to w.
+// It returns false if the hub closed the send channel meanwhile.
+func (c *Client) collectBatch(w io.Writer) bool {
+	if c.Hub.batchWindow <= 0 {
+		return true
+	}
+
+	timer := time.NewTimer(c.Hub.batchWindow)
+	defer timer.Stop()
+	for {
+		select {
+		case message, ok := <-c.send:
+			if !ok {
+				return false
+			}
+			w.Write([]byte("\n"))
+			w.Write(message)
+		case <-timer.C:
+			return true
+		}
+	}
+}
+
 // ---------------------------------------------------------------------------
 // HandleMessage
 // ---------------------------------------------------------------------------
 
 func (c *Client) HandleMessage(msgType string, data json.RawMessage) {
 	switch msgType {
+	case "subscribe":
+		c.handleSubscribe(data)
+	case "unsubscribe":
+		c.handleUnsubscribe(data)
 	case "chat_message":
 		c.handleChat(data)
 	case "typing_indicator":
 		c.handleTyping(data)
 	case "code_change":
 		c.handleCodeChange(data)
+	case "refresh_token":
+		c.handleRefreshToken(data)
 	default:
 		log.Warn().Str("type", msgType).Msg("ws unknown message type")
 	}
 }
 
+// handleSubscribe joins another match's room after checking the user may.
+func (c *Client) handleSubscribe(data json.RawMessage) {
+	var payload SubscribePayload
+	if err := json.Unmarshal(data, &payload); err != nil || payload.MatchID == 0 {
+		c.sendError("subscribe", ErrMatchNotFound)
+		return
+	}
+	if err := CheckMatchAccess(c.DB, c.UserID, payload.MatchID); err != nil {
+		if !errors.Is(err, ErrMatchNotFound) && !errors.Is(err, ErrNotParticipant) && !errors.Is(err, ErrMatchNotActive) {
+			log.Error().Err(err).Uint("match_id", payload.MatchID).Msg("ws subscribe lookup failed")
+			err = errors.New("failed to subscribe")
+		}
+		c.sendError("subscribe", err)
+		return
+	}
+	if !c.Hub.Subscribe(c, payload.MatchID) {
+		c.sendError("subscribe", ErrMatchNotActive)
+		return
+	}
+	c.sendSubscription("subscribed", payload.MatchID)
+}
+
+func (c *Client) handleUnsubscribe(data json.RawMessage) {
+	var payload SubscribePayload
+	if err := json.Unmarshal(data, &payload); err != nil || payload.MatchID == 0 {
+		c.sendError("unsubscribe", ErrNotSubscribed)
+		return
+	}
+	c.setTyping(payload.MatchID, false)
+	c.Hub.Unsubscribe(c, payload.MatchID)
+	c.sendSubscription("unsubscribed", payload.MatchID)
+}
+
+func (c *Client) sendSubscription(kind string, matchID uint) {
+	frame, _ := json.Marshal(OutboundSubscription{Type: kind, MatchID: matchID})
+	c.Hub.SendToClient(c, frame)
+}
+
+// resolveMatch returns the match a room-scoped message is for: matchID if
+// subscribed, or the only subscription when matchID is 0.
+func (c *Client) resolveMatch(matchID uint) (uint, error) {
+	if matchID != 0 {
+		if !c.Hub.IsSubscribed(c, matchID) {
+			return 0, ErrNotSubscribed
+		}
+		return matchID, nil
+	}
+	subs := c.Hub.Subscriptions(c)
+	switch len(subs) {
+	case 0:
+		return 0, ErrNotSubscribed
+	case 1:
+		return subs[0], nil
+	default:
+		return 0, ErrMatchIDRequired
+	}
+}
+
 func (c *Client) handleChat(data json.RawMessage) {
 	var payload ChatPayload
-	if err := json.Unmarshal(data, &payload); err != nil || payload.Content == "" {
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return
+	}
+	matchID, err := c.resolveMatch(payload.MatchID)
+	if err != nil {
+		c.sendError("chat_message", err)
+		return
+	}
+
+	msgType, metadata, err := domain.NormalizeMessage(payload.Type, payload.Metadata)
+	if err != nil {
+		c.sendError("chat_message", err)
+		return
+	}
+	payload.Content, err = domain.SanitizeMessageContent(msgType, payload.Content)
+	if err != nil {
+		c.sendError("chat_message", err)
+		return
+	}
+	if payload.Content == "" && msgType == domain.MessageFile {
+		payload.Content = payload.Metadata.Filename
+	}
+	if payload.Content == "" {
 		return
 	}
 
@@ -214,7 +448,7 @@ func (c *Client) handleChat(data json.RawMessage) {
 	receiverID := payload.ReceiverID
 	if receiverID == "" {
 		var match domain.Match
-		if err := c.DB.First(&match, c.MatchID).Error; err != nil {
+		if err := c.DB.First(&match, matchID).Error; err != nil {
 			log.Error().Err(err).Msg("ws cannot find match")
 			return
 		}
@@ -229,8 +463,10 @@ func (c *Client) handleChat(data json.RawMessage) {
 	msg := domain.Message{
 		SenderID:   c.UserID,
 		ReceiverID: receiverID,
-		MatchID:    c.MatchID,
+		MatchID:    matchID,
 		Content:    payload.Content,
+		Type:       msgType,
+		Metadata:   metadata,
 	}
 	if err := c.DB.Create(&msg).Error; err != nil {
 		log.Error().Err(err).Msg("ws failed to persist message")
@@ -246,7 +482,19 @@ func (c *Client) handleChat(data json.RawMessage) {
 		Timestamp: msg.CreatedAt,
 	}
 	outBytes, _ := json.Marshal(out)
-	c.Hub.BroadcastToMatch(c.MatchID, outBytes)
+	c.Hub.BroadcastToMatch(matchID, outBytes)
+	c.setTyping(matchID, false)
+	c.Hub.RecordActivity(matchID)
+
+	if c.Hub.onChatMessage != nil {
+		c.Hub.onChatMessage(&msg)
+	}
+}
+
+// sendError reports a rejected inbound message to this client only.
+func (c *Client) sendError(request string, err error) {
+	frame, _ := json.Marshal(OutboundError{Type: "error", Request: request, Error: err.Error()})
+	c.Hub.SendToClient(c, frame)
 }
 
 func (c *Client) handleTyping(data json.RawMessage) {
@@ -254,29 +502,178 @@ func (c *Client) handleTyping(data json.RawMessage) {
 	if err := json.Unmarshal(data, &payload); err != nil {
 		return
 	}
+	matchID, err := c.resolveMatch(payload.MatchID)
+	if err != nil {
+		c.sendError("typing_indicator", err)
+		return
+	}
+	c.setTyping(matchID, payload.IsTyping)
+}
+
+// setTyping updates the client's typing state in a match and broadcasts
+// changes. Repeated "typing" events are rebroadcast at most once per
+// typingDebounce, and the indicator is cleared automatically after
+// typingExpiry of silence.
+func (c *Client) setTyping(matchID uint, isTyping bool) {
+	c.typingMu.Lock()
+	defer c.typingMu.Unlock()
+
+	st := c.typing[matchID]
+	if st == nil {
+		if !isTyping {
+			return
+		}
+		st = &typingState{}
+		c.typing[matchID] = st
+	}
+	if st.timer != nil {
+		st.timer.Stop()
+		st.timer = nil
+	}
+
+	if !isTyping {
+		if st.typing {
+			c.broadcastTyping(matchID, false)
+		}
+		delete(c.typing, matchID)
+		return
+	}
+
+	c.typingGen++
+	st.gen = c.typingGen
+	gen := st.gen
+	st.timer = time.AfterFunc(typingExpiry, func() { c.expireTyping(matchID, gen) })
+	if st.typing && time.Since(st.sentAt) < typingDebounce {
+		return
+	}
+	st.typing = true
+	st.sentAt = time.Now()
+	c.broadcastTyping(matchID, true)
+}
+
+// expireTyping clears the indicator in a match when timer gen fires. Stop
+// can't cancel a timer whose func is already waiting on typingMu, so a
+// renewal in the meantime is detected by the generation instead.
+func (c *Client) expireTyping(matchID uint, gen uint64) {
+	c.typingMu.Lock()
+	defer c.typingMu.Unlock()
 
+	st := c.typing[matchID]
+	if st == nil || st.gen != gen {
+		return
+	}
+	if st.typing {
+		c.broadcastTyping(matchID, false)
+	}
+	delete(c.typing, matchID)
+}
+
+// clearTyping clears the indicator in every match, e.g. on disconnect.
+func (c *Client) clearTyping() {
+	c.typingMu.Lock()
+	ids := make([]uint, 0, len(c.typing))
+	for id := range c.typing {
+		ids = append(ids, id)
+	}
+	c.typingMu.Unlock()
+
+	for _, id := range ids {
+		c.setTyping(id, false)
+	}
+}
+
+func (c *Client) broadcastTyping(matchID uint, isTyping bool) {
 	out := OutboundTypingMessage{
 		Type:     "typing_indicator",
+		MatchID:  matchID,
 		UserID:   c.UserID,
-		IsTyping: payload.IsTyping,
+		IsTyping: isTyping,
 	}
 	outBytes, _ := json.Marshal(out)
-	c.Hub.BroadcastToMatch(c.MatchID, outBytes)
+	c.Hub.BroadcastToMatch(matchID, outBytes)
 }
 
 func (c *Client) handleCodeChange(data json.RawMessage) {
 	var payload CodeChangePayload
 	if err := json.Unmarshal(data, &payload); err != nil {
+		c.sendError("code_change", ErrInvalidPayload)
+		return
+	}
+	if err := c.validateCodeChange(&payload); err != nil {
+		c.sendError("code_change", err)
+		return
+	}
+	matchID, err := c.resolveMatch(payload.MatchID)
+	if err != nil {
+		c.sendError("code_change", err)
 		return
 	}
 
 	out := OutboundCodeChange{
 		Type:     "code_change",
+		MatchID:  matchID,
 		UserID:   c.UserID,
 		Code:     payload.Code,
 		Language: payload.Language,
 		Cursor:   payload.Cursor,
 	}
 	outBytes, _ := json.Marshal(out)
-	c.Hub.BroadcastToMatch(c.MatchID, outBytes)
+	c.Hub.BroadcastToMatch(matchID, outBytes)
+	c.Hub.RecordActivity(matchID)
+}
+
+// validateCodeChange rejects code_change payloads that shouldn't be relayed
+// to the match, and normalizes the language name.
+func (c *Client) validateCodeChange(p *CodeChangePayload) error {
+	if max := c.Hub.cfg.MaxCodeSize; len(p.Code) > max {
+		return fmt.Errorf("%w: at most %d bytes", ErrCodeTooLarge, max)
+	}
+	// Editors count the cursor in characters or UTF-16 units, never more
+	// than the UTF-8 length.
+	if p.Cursor < 0 || p.Cursor > len(p.Code) {
+		return ErrInvalidCursor
+	}
+	p.Language = strings.ToLower(strings.TrimSpace(p.Language))
+	if p.Language != "" && !c.Hub.languages.known(c.DB, p.Language) {
+		return fmt.Errorf("%w: %q", ErrUnknownLanguage, p.Language)
+	}
+	return nil
+}
+
+// handleRefreshToken swaps in a newer JWT for the same user so long sessions
+// survive token rotation. An invalid token, or one for another user, closes
+// the connection.
+func (c *Client) handleRefreshToken(data json.RawMessage) {
+	var payload RefreshTokenPayload
+	if err := json.Unmarshal(data, &payload); err != nil || payload.Token == "" {
+		c.closeWithCode(CloseTokenInvalid, "invalid token")
+		return
+	}
+
+	claims, err := auth.ValidateToken(payload.Token)
+	if err != nil || claims.ExpiresAt == nil {
+		c.closeWithCode(CloseTokenInvalid, "invalid token")
+		return
+	}
+	if claims.UserID != c.UserID {
+		log.Warn().Str("user_id", c.UserID).Str("token_user_id", claims.UserID).Msg("ws refresh token for different user")
+		c.closeWithCode(CloseTokenUserMismatch, "token belongs to a different user")
+		return
+	}
+
+	out := OutboundTokenRefreshed{
+		Type:      "token_refreshed",
+		ExpiresAt: claims.ExpiresAt.Time,
+	}
+	outBytes, _ := json.Marshal(out)
+	c.Hub.SendToClient(c, outBytes)
+}
+
+// closeWithCode sends a close frame with an application close code and shuts
+// the connection, which ends both pumps. WriteControl is safe to call
+// concurrently with the write pump.
+func (c *Client) closeWithCode(code int, reason string) {
+	msg := websocket.FormatCloseMessage(code, reason)
+	c.Conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(c.Hub.cfg.WriteWait))
+	c.Conn.Close()
 }
@@ -0,0 +1,89 @@
+package websocket
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testClient returns a client whose hub accepts code up to maxCode bytes and
+// the given languages, with the language list fresh so no database is read.
+func testClient(maxCode int, languages ...string) *Client {
+	cfg := DefaultConfig()
+	cfg.MaxCodeSize = maxCode
+	hub := NewHub(cfg)
+	hub.languages.names = make(map[string]bool, len(languages))
+	for _, l := range languages {
+		hub.languages.names[l] = true
+	}
+	hub.languages.loadedAt = time.Now()
+	return &Client{Hub: hub}
+}
+
+func TestValidateCodeChange(t *testing.T) {
+	c := testClient(16, "go", "python")
+
+	tests := []struct {
+		name    string
+		payload CodeChangePayload
+		want    error
+	}{
+		{"accepted", CodeChangePayload{Code: "package main", Language: " Go ", Cursor: 3}, nil},
+		{"no language", CodeChangePayload{Code: "notes"}, nil},
+		{"at size limit", CodeChangePayload{Code: strings.Repeat("x", 16), Language: "python"}, nil},
+		{"oversized", CodeChangePayload{Code: strings.Repeat("x", 17), Language: "go"}, ErrCodeTooLarge},
+		{"unknown language", CodeChangePayload{Code: "x", Language: "brainfuck"}, ErrUnknownLanguage},
+		{"cursor past end", CodeChangePayload{Code: "x", Cursor: 2}, ErrInvalidCursor},
+		{"negative cursor", CodeChangePayload{Code: "x", Cursor: -1}, ErrInvalidCursor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := tt.payload
+			err := c.validateCodeChange(&p)
+			if tt.want == nil && err != nil {
+				t.Fatalf("validateCodeChange = %v, want nil", err)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Fatalf("validateCodeChange = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateCodeChangeNormalizesLanguage(t *testing.T) {
+	c := testClient(64, "go")
+	p := CodeChangePayload{Code: "x", Language: "  GO\n"}
+	if err := c.validateCodeChange(&p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Language != "go" {
+		t.Fatalf("Language = %q, want %q", p.Language, "go")
+	}
+}
+
+// An expiry timer that fired before a renewal but ran after it leaves the
+// renewed indicator alone.
+func TestStaleTypingExpiryIgnored(t *testing.T) {
+	hub := NewHub(DefaultConfig())
+	go hub.Run()
+	c := NewClient(hub, nil, "alice", nil)
+
+	c.setTyping(7, true)
+	stale := c.typing[7].gen
+	c.setTyping(7, true)
+
+	c.expireTyping(7, stale)
+	c.typingMu.Lock()
+	st := c.typing[7]
+	c.typingMu.Unlock()
+	if st == nil || !st.typing {
+		t.Fatal("stale expiry cleared a renewed indicator")
+	}
+
+	c.expireTyping(7, st.gen)
+	if _, ok := c.typing[7]; ok {
+		t.Fatal("current expiry left the indicator set")
+	}
+	c.clearTyping()
+}
@@ -0,0 +1,116 @@
+package websocket
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Config holds the per-connection deadlines and size limits. The root API
+// server reads the same variables with the same defaults.
+type Config struct {
+	// WriteWait is the time allowed to write a message to the peer.
+	WriteWait time.Duration
+	// PongWait is the time allowed to read the next pong from the peer.
+	PongWait time.Duration
+	// PingPeriod is how often pings are sent. Must be less than PongWait.
+	PingPeriod time.Duration
+	// MaxMessageSize is the largest message, in bytes, accepted from a peer.
+	MaxMessageSize int64
+	// ReadBufferSize and WriteBufferSize size the upgrader's I/O buffers.
+	ReadBufferSize  int
+	WriteBufferSize int
+	// MaxConnectionsPerUser caps how many sockets one user may hold open.
+	MaxConnectionsPerUser int
+	// MaxCodeSize is the largest code_change code, in bytes, relayed to a
+	// match. The root API server has no code_change and ignores it.
+	MaxCodeSize int
+}
+
+// DefaultConfig returns the settings used when nothing is overridden.
+func DefaultConfig() Config {
+	return Config{
+		WriteWait:             10 * time.Second,
+		PongWait:              60 * time.Second,
+		PingPeriod:            54 * time.Second,
+		MaxMessageSize:        64 * 1024,
+		ReadBufferSize:        1024,
+		WriteBufferSize:       1024,
+		MaxConnectionsPerUser: 10,
+		MaxCodeSize:           32 * 1024,
+	}
+}
+
+// ConfigFromEnv overrides DefaultConfig with WS_WRITE_WAIT, WS_PONG_WAIT,
+// WS_PING_PERIOD (durations such as "10s"), WS_MAX_MESSAGE_SIZE,
+// WS_READ_BUFFER_SIZE, WS_WRITE_BUFFER_SIZE, WS_MAX_CODE_SIZE (bytes) and
+// WS_MAX_CONNECTIONS_PER_USER. When only WS_PONG_WAIT is set, the ping
+// period follows it at 9/10.
+func ConfigFromEnv() (Config, error) {
+	cfg := DefaultConfig()
+
+	var err error
+	if cfg.WriteWait, err = envDuration("WS_WRITE_WAIT", cfg.WriteWait); err != nil {
+		return Config{}, err
+	}
+	if cfg.PongWait, err = envDuration("WS_PONG_WAIT", cfg.PongWait); err != nil {
+		return Config{}, err
+	}
+	if cfg.PingPeriod, err = envDuration("WS_PING_PERIOD", cfg.PongWait*9/10); err != nil {
+		return Config{}, err
+	}
+	maxSize, err := envInt("WS_MAX_MESSAGE_SIZE", int(cfg.MaxMessageSize))
+	if err != nil {
+		return Config{}, err
+	}
+	cfg.MaxMessageSize = int64(maxSize)
+	if cfg.ReadBufferSize, err = envInt("WS_READ_BUFFER_SIZE", cfg.ReadBufferSize); err != nil {
+		return Config{}, err
+	}
+	if cfg.WriteBufferSize, err = envInt("WS_WRITE_BUFFER_SIZE", cfg.WriteBufferSize); err != nil {
+		return Config{}, err
+	}
+	if cfg.MaxConnectionsPerUser, err = envInt("WS_MAX_CONNECTIONS_PER_USER", cfg.MaxConnectionsPerUser); err != nil {
+		return Config{}, err
+	}
+	if cfg.MaxCodeSize, err = envInt("WS_MAX_CODE_SIZE", cfg.MaxCodeSize); err != nil {
+		return Config{}, err
+	}
+
+	return cfg, cfg.Validate()
+}
+
+// Validate rejects settings under which healthy connections would time out:
+// a ping period that isn't shorter than the pong wait lets the read deadline
+// expire before the peer has been asked to answer.
+func (c Config) Validate() error {
+	if c.PingPeriod >= c.PongWait {
+		return fmt.Errorf("websocket ping period (%s) must be less than pong wait (%s)", c.PingPeriod, c.PongWait)
+	}
+	return nil
+}
+
+func envDuration(key string, fallback time.Duration) (time.Duration, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("%s must be a positive duration, got %q", key, v)
+	}
+	return d, nil
+}
+
+func envInt(key string, fallback int) (int, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("%s must be a positive integer, got %q", key, v)
+	}
+	return n, nil
+}
@@ -1,37 +1,136 @@
 package websocket
 
 import (
+	"encoding/json"
+	"os"
 	"sync"
+	"time"
 
 	"github.com/rs/zerolog/log"
+
+	"github.com/yourusername/skillsync/internal/domain"
 )
 
 // Hub maintains the set of active clients and broadcasts messages to clients
-// that belong to the same match.
+// subscribed to the same match, or to all connections of one user. A client
+// may be subscribed to any number of matches (rooms) over a single
+// connection.
 type Hub struct {
-	mu         sync.RWMutex
-	clients    map[*Client]bool
+	mu      sync.RWMutex
+	clients map[*Client]bool
+	// users indexes registered clients by user ID for BroadcastToUser.
+	users map[string]map[*Client]bool
+	// userConns counts each user's registered and about-to-register
+	// connections, for the per-user cap.
+	userConns  map[string]int
+	rooms      map[uint]map[*Client]bool
 	register   chan *Client
 	unregister chan *Client
 	broadcast  chan *OutboundMessage
+	endMatch   chan uint
+
+	// cfg holds the deadlines and size limits applied to every client.
+	cfg Config
+
+	// batchWindow is how long a client's write pump waits to coalesce
+	// further frames into one write. Zero writes each frame immediately.
+	batchWindow time.Duration
+
+	// ended holds matches that were deactivated while the hub was running.
+	// Nothing is routed to them and new clients for them are dropped until
+	// ReopenMatch.
+	ended map[uint]bool
+
+	// onChatMessage, if set, is called after a chat message sent over a
+	// socket has been persisted.
+	onChatMessage func(msg *domain.Message)
+
+	// onActivity, if set, is called for each chat message or code change in
+	// a match.
+	onActivity func(matchID uint)
+
+	// onPresence, if set, is called with the hub lock held whenever a user
+	// opens their first connection or closes their last one.
+	onPresence func(userID string)
+
+	// languages are the language names code_change accepts.
+	languages languageCache
+}
+
+// MatchEndedMessage is sent to every client of a match right before the hub
+// closes their connections.
+type MatchEndedMessage struct {
+	Type    string `json:"type"`
+	MatchID uint   `json:"match_id"`
+}
+
+// MatchRequestMessage is pushed to the receiver of a new match request.
+type MatchRequestMessage struct {
+	Type              string             `json:"type"`
+	RequestID         uint               `json:"request_id"`
+	Sender            MatchRequestSender `json:"sender"`
+	Message           string             `json:"message"`
+	AIPreviewInsights domain.JSONB       `json:"ai_preview_insights"`
+	CreatedAt         time.Time          `json:"created_at"`
+}
+
+// MatchRequestSender is the part of the sender's profile shown with a
+// match request.
+type MatchRequestSender struct {
+	ID        string `json:"id"`
+	Username  string `json:"username"`
+	FullName  string `json:"full_name"`
+	AvatarURL string `json:"avatar_url"`
+}
+
+// MatchRequestAnsweredMessage is pushed to the sender of a match request
+// when the receiver accepts ("match_accepted") or rejects ("match_rejected")
+// it. MatchID is the new match and is only set on acceptance.
+type MatchRequestAnsweredMessage struct {
+	Type        string `json:"type"`
+	RequestID   uint   `json:"request_id"`
+	ResponderID string `json:"responder_id"`
+	MatchID     uint   `json:"match_id,omitempty"`
 }
 
-// OutboundMessage wraps a payload with the target match so the hub can route
-// it to the right clients.
+// OutboundMessage wraps a payload with its target so the hub can route it to
+// the right clients: every connection of UserID when set, otherwise every
+// client subscribed to MatchID.
 type OutboundMessage struct {
 	MatchID uint
+	UserID  string
 	Data    []byte
 }
 
-func NewHub() *Hub {
+// NewHub creates a hub whose clients use cfg. WS_BATCH_WINDOW (e.g. "5ms")
+// enables coalescing of outbound frames per client; it defaults to 0
+// (disabled). Coalesced frames are joined with newlines in one message.
+func NewHub(cfg Config) *Hub {
+	var batchWindow time.Duration
+	if d, err := time.ParseDuration(os.Getenv("WS_BATCH_WINDOW")); err == nil && d > 0 {
+		batchWindow = d
+	}
+
 	return &Hub{
-		clients:    make(map[*Client]bool),
-		register:   make(chan *Client),
-		unregister: make(chan *Client),
-		broadcast:  make(chan *OutboundMessage, 256),
+		cfg:         cfg,
+		batchWindow: batchWindow,
+		clients:     make(map[*Client]bool),
+		users:       make(map[string]map[*Client]bool),
+		userConns:   make(map[string]int),
+		rooms:       make(map[uint]map[*Client]bool),
+		register:    make(chan *Client),
+		unregister:  make(chan *Client),
+		broadcast:   make(chan *OutboundMessage, 256),
+		endMatch:    make(chan uint),
+		ended:       make(map[uint]bool),
 	}
 }
 
+// Config returns the settings the hub's clients use.
+func (h *Hub) Config() Config {
+	return h.cfg
+}
+
 // Run starts the hub's event loop. Call as a goroutine: go hub.Run()
 func (h *Hub) Run() {
 	for {
@@ -39,71 +138,381 @@ func (h *Hub) Run() {
 		case client := <-h.register:
 			h.mu.Lock()
 			h.clients[client] = true
+			if h.users[client.UserID] == nil {
+				h.users[client.UserID] = make(map[*Client]bool)
+			}
+			h.users[client.UserID][client] = true
+			if len(h.users[client.UserID]) == 1 {
+				h.presenceChangedLocked(client.UserID)
+			}
+			for matchID := range client.matches {
+				if h.ended[matchID] {
+					// Raced with EndMatch after the handler's active check.
+					delete(client.matches, matchID)
+					h.queueLocked(client, matchEndedFrame(matchID))
+					continue
+				}
+				h.joinLocked(matchID, client)
+			}
+			if client.closeWhenIdle && len(client.matches) == 0 {
+				h.removeLocked(client)
+			}
+			subscriptions := len(client.matches)
 			h.mu.Unlock()
 			log.Info().
 				Str("user_id", client.UserID).
-				Uint("match_id", client.MatchID).
+				Int("subscriptions", subscriptions).
 				Msg("ws client registered")
 
 		case client := <-h.unregister:
 			h.mu.Lock()
-			if _, ok := h.clients[client]; ok {
-				delete(h.clients, client)
-				close(client.send)
-			}
+			h.removeLocked(client)
 			h.mu.Unlock()
 			log.Info().
 				Str("user_id", client.UserID).
-				Uint("match_id", client.MatchID).
 				Msg("ws client unregistered")
 
+		case matchID := <-h.endMatch:
+			frame := matchEndedFrame(matchID)
+			h.mu.Lock()
+			h.ended[matchID] = true
+			for client := range h.rooms[matchID] {
+				h.queueLocked(client, frame)
+				delete(client.matches, matchID)
+				// Connections opened for just this match close with it;
+				// multi-room connections keep their other subscriptions.
+				if client.closeWhenIdle && len(client.matches) == 0 {
+					h.removeLocked(client)
+				}
+			}
+			delete(h.rooms, matchID)
+			h.mu.Unlock()
+			log.Info().Uint("match_id", matchID).Msg("ws match ended")
+
 		case msg := <-h.broadcast:
-			h.mu.RLock()
-			for client := range h.clients {
-				if client.MatchID != msg.MatchID {
-					continue
+			h.mu.Lock()
+			if msg.UserID != "" {
+				for client := range h.users[msg.UserID] {
+					select {
+					case client.send <- msg.Data:
+					default:
+						h.removeLocked(client)
+					}
 				}
-				select {
-				case client.send <- msg.Data:
-				default:
-					// Client's send buffer is full; drop it.
-					h.mu.RUnlock()
-					h.mu.Lock()
-					delete(h.clients, client)
-					close(client.send)
-					h.mu.Unlock()
-					h.mu.RLock()
+			} else if !h.ended[msg.MatchID] {
+				for client := range h.rooms[msg.MatchID] {
+					select {
+					case client.send <- msg.Data:
+					default:
+						// Client's send buffer is full; drop it.
+						h.removeLocked(client)
+					}
 				}
 			}
-			h.mu.RUnlock()
+			h.mu.Unlock()
+		}
+	}
+}
+
+// Subscribe adds client to matchID's room. It returns false if the client
+// is no longer registered or the match has ended. Callers check that the
+// user may join the match.
+func (h *Hub) Subscribe(client *Client, matchID uint) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !h.clients[client] || h.ended[matchID] {
+		return false
+	}
+	client.matches[matchID] = true
+	h.joinLocked(matchID, client)
+	return true
+}
+
+// Unsubscribe removes client from matchID's room.
+func (h *Hub) Unsubscribe(client *Client, matchID uint) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(client.matches, matchID)
+	h.leaveLocked(matchID, client)
+}
+
+// IsSubscribed reports whether client is in matchID's room.
+func (h *Hub) IsSubscribed(client *Client, matchID uint) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return client.matches[matchID]
+}
+
+// Subscriptions returns the matches client is subscribed to.
+func (h *Hub) Subscriptions(client *Client) []uint {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	ids := make([]uint, 0, len(client.matches))
+	for id := range client.matches {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func (h *Hub) joinLocked(matchID uint, client *Client) {
+	room := h.rooms[matchID]
+	if room == nil {
+		room = make(map[*Client]bool)
+		h.rooms[matchID] = room
+	}
+	room[client] = true
+}
+
+func (h *Hub) leaveLocked(matchID uint, client *Client) {
+	if room, ok := h.rooms[matchID]; ok {
+		delete(room, client)
+		if len(room) == 0 {
+			delete(h.rooms, matchID)
+		}
+	}
+}
+
+// removeLocked drops client from the hub and every room and closes its send
+// channel, which makes WritePump flush and close the connection.
+func (h *Hub) removeLocked(client *Client) {
+	if !h.clients[client] {
+		return
+	}
+	delete(h.clients, client)
+	if conns := h.users[client.UserID]; conns != nil {
+		delete(conns, client)
+		if len(conns) == 0 {
+			delete(h.users, client.UserID)
+			h.presenceChangedLocked(client.UserID)
 		}
 	}
+	for matchID := range client.matches {
+		h.leaveLocked(matchID, client)
+	}
+	close(client.send)
+	h.releaseLocked(client.UserID)
+}
+
+func (h *Hub) releaseLocked(userID string) {
+	if h.userConns[userID] <= 1 {
+		delete(h.userConns, userID)
+		return
+	}
+	h.userConns[userID]--
+}
+
+// queueLocked sends data to client if its buffer has room.
+func (h *Hub) queueLocked(client *Client, data []byte) {
+	select {
+	case client.send <- data:
+	default:
+	}
+}
+
+func matchEndedFrame(matchID uint) []byte {
+	frame, _ := json.Marshal(MatchEndedMessage{Type: "match_ended", MatchID: matchID})
+	return frame
+}
+
+// MatchRequestFrame builds the "match_request" frame for req, whose Sender
+// must be loaded.
+func MatchRequestFrame(req *domain.MatchRequest) []byte {
+	frame, _ := json.Marshal(MatchRequestMessage{
+		Type:      "match_request",
+		RequestID: req.ID,
+		Sender: MatchRequestSender{
+			ID:        req.Sender.ID,
+			Username:  req.Sender.Username,
+			FullName:  req.Sender.FullName,
+			AvatarURL: req.Sender.AvatarURL,
+		},
+		Message:           req.Message,
+		AIPreviewInsights: req.AIPreviewInsights,
+		CreatedAt:         req.CreatedAt,
+	})
+	return frame
+}
+
+// MatchAcceptedFrame builds the "match_accepted" frame for request
+// requestID, which responderID accepted, creating match matchID.
+func MatchAcceptedFrame(requestID uint, responderID string, matchID uint) []byte {
+	frame, _ := json.Marshal(MatchRequestAnsweredMessage{
+		Type:        "match_accepted",
+		RequestID:   requestID,
+		ResponderID: responderID,
+		MatchID:     matchID,
+	})
+	return frame
+}
+
+// MatchRejectedFrame builds the "match_rejected" frame for request
+// requestID, which responderID rejected.
+func MatchRejectedFrame(requestID uint, responderID string) []byte {
+	frame, _ := json.Marshal(MatchRequestAnsweredMessage{
+		Type:        "match_rejected",
+		RequestID:   requestID,
+		ResponderID: responderID,
+	})
+	return frame
 }
 
-// BroadcastToMatch sends a message to every client connected to a match.
+// BroadcastToMatch sends a message to every client subscribed to a match.
 func (h *Hub) BroadcastToMatch(matchID uint, data []byte) {
 	h.broadcast <- &OutboundMessage{MatchID: matchID, Data: data}
 }
 
+// EndMatch tells every client subscribed to a deactivated match that it
+// ended, unsubscribes them and stops routing messages to the match.
+// Connections opened for that match alone are closed.
+func (h *Hub) EndMatch(matchID uint) {
+	h.endMatch <- matchID
+}
+
+// ReopenMatch lets clients join and message a match again after EndMatch,
+// for a match that was reactivated.
+func (h *Hub) ReopenMatch(matchID uint) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.ended, matchID)
+}
+
+// DisconnectUser closes every connection userID has open, for a user who
+// was suspended or deleted.
+func (h *Hub) DisconnectUser(userID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for client := range h.users[userID] {
+		h.removeLocked(client)
+	}
+}
+
+// SendToClient queues data for a single client. It is dropped if the client
+// has already been unregistered or its buffer is full.
+func (h *Hub) SendToClient(client *Client, data []byte) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if !h.clients[client] {
+		return
+	}
+	select {
+	case client.send <- data:
+	default:
+	}
+}
+
+// BroadcastToUser sends data to every connection the user currently has
+// open, whichever matches it is subscribed to. Users with no connection are
+// skipped; like BroadcastToMatch, a connection whose buffer is full is
+// dropped.
+func (h *Hub) BroadcastToUser(userID string, data []byte) {
+	h.broadcast <- &OutboundMessage{UserID: userID, Data: data}
+}
+
+// OnChatMessage registers fn to run after each chat message received over a
+// socket is saved. Call before Run.
+func (h *Hub) OnChatMessage(fn func(msg *domain.Message)) {
+	h.onChatMessage = fn
+}
+
+// OnActivity registers fn to run for each chat message or code change in a
+// match. Call before Run.
+func (h *Hub) OnActivity(fn func(matchID uint)) {
+	h.onActivity = fn
+}
+
+// OnPresenceChange registers fn to run when a user comes online (first
+// connection) or goes offline (last connection closed). fn runs with the
+// hub lock held, so it must not block or call back into the hub; check
+// IsOnline later for the current state. Call before Run.
+func (h *Hub) OnPresenceChange(fn func(userID string)) {
+	h.onPresence = fn
+}
+
+func (h *Hub) presenceChangedLocked(userID string) {
+	if h.onPresence != nil {
+		h.onPresence(userID)
+	}
+}
+
+// RecordActivity reports chat or coding activity in a match to the OnActivity
+// hook. Messages sent over REST call it too.
+func (h *Hub) RecordActivity(matchID uint) {
+	if h.onActivity != nil {
+		h.onActivity(matchID)
+	}
+}
+
 // Register queues a client for registration.
 func (h *Hub) Register(client *Client) {
 	h.register <- client
 }
 
+// AcquireConnection reserves one of userID's Config.MaxConnectionsPerUser
+// connection slots, or returns false when all are taken. On success the
+// caller must either Register a client for userID, whose removal frees the
+// slot, or call ReleaseConnection.
+func (h *Hub) AcquireConnection(userID string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.userConns[userID] >= h.cfg.MaxConnectionsPerUser {
+		return false
+	}
+	h.userConns[userID]++
+	return true
+}
+
+// ReleaseConnection frees a slot taken by AcquireConnection that was never
+// used to register a client.
+func (h *Hub) ReleaseConnection(userID string) {
+	h.mu.Lock()
+	h.releaseLocked(userID)
+	h.mu.Unlock()
+}
+
 // Unregister queues a client for removal.
 func (h *Hub) Unregister(client *Client) {
 	h.unregister <- client
 }
 
-// OnlineUsersForMatch returns the user IDs currently connected to a match.
+// ClientCount returns the number of open connections.
+func (h *Hub) ClientCount() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.clients)
+}
+
+// IsOnline reports whether userID has at least one open connection.
+func (h *Hub) IsOnline(userID string) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.users[userID]) > 0
+}
+
+// OnlineUserIDs returns every user with at least one open connection.
+func (h *Hub) OnlineUserIDs() []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	ids := make([]string, 0, len(h.users))
+	for userID := range h.users {
+		ids = append(ids, userID)
+	}
+	return ids
+}
+
+// OnlineUsersForMatch returns the user IDs currently subscribed to a match.
 func (h *Hub) OnlineUsersForMatch(matchID uint) []string {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
 	var ids []string
 	seen := make(map[string]bool)
-	for client := range h.clients {
-		if client.MatchID == matchID && !seen[client.UserID] {
+	for client := range h.rooms[matchID] {
+		if !seen[client.UserID] {
 			ids = append(ids, client.UserID)
 			seen[client.UserID] = true
 		}
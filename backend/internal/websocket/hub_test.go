@@ -0,0 +1,193 @@
+package websocket
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// dialTestClient connects a real socket to a client registered on hub for
+// userID. With matchIDs it behaves like a connection opened with
+// ?match_id=, closing when its last match ends; multiRoom keeps it open.
+func dialTestClient(t *testing.T, hub *Hub, userID string, multiRoom bool, matchIDs ...uint) *websocket.Conn {
+	t.Helper()
+	registered := make(chan *Client, 1)
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		c := NewClient(hub, conn, userID, nil, matchIDs...)
+		if multiRoom {
+			c.closeWhenIdle = false
+		}
+		hub.Register(c)
+		go c.WritePump()
+		registered <- c
+	}))
+	t.Cleanup(srv.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	c := <-registered
+	waitFor(t, func() bool { return hub.IsSubscribed(c, matchIDs[0]) })
+	return conn
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the hub")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// readMatchEnded reads the next frame from conn and checks it is a
+// match_ended for matchID.
+func readMatchEnded(t *testing.T, conn *websocket.Conn, matchID uint) {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("reading match_ended: %v", err)
+	}
+	var msg MatchEndedMessage
+	if err := json.Unmarshal(data, &msg); err != nil || msg.Type != "match_ended" || msg.MatchID != matchID {
+		t.Fatalf("got frame %s, want match_ended for match %d", data, matchID)
+	}
+}
+
+func TestEndMatchClosesMatchConnections(t *testing.T) {
+	hub := NewHub(DefaultConfig())
+	go hub.Run()
+
+	const ended, other = 7, 8
+	alice := dialTestClient(t, hub, "alice", false, ended)
+	bob := dialTestClient(t, hub, "bob", false, ended)
+	carol := dialTestClient(t, hub, "carol", true, ended, other)
+
+	hub.EndMatch(ended)
+
+	for _, conn := range []*websocket.Conn{alice, bob} {
+		readMatchEnded(t, conn, ended)
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		if _, _, err := conn.ReadMessage(); !websocket.IsCloseError(err, websocket.CloseNoStatusReceived, websocket.CloseNormalClosure) {
+			t.Fatalf("after match_ended got %v, want the connection closed", err)
+		}
+	}
+
+	// The multi-room connection stays open on its other match.
+	readMatchEnded(t, carol, ended)
+	waitFor(t, func() bool { return hub.ClientCount() == 1 })
+
+	// Nothing more is routed to the ended match; the other still works.
+	hub.BroadcastToMatch(ended, []byte(`{"type":"chat_message","match_id":7}`))
+	hub.BroadcastToMatch(other, []byte(`{"type":"chat_message","match_id":8}`))
+	carol.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, data, err := carol.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"type":"chat_message","match_id":8}` {
+		t.Fatalf("got %s, want only the match 8 message", data)
+	}
+}
+
+func TestDisconnectUserClosesEveryConnection(t *testing.T) {
+	hub := NewHub(DefaultConfig())
+	go hub.Run()
+
+	first := dialTestClient(t, hub, "alice", true, 7)
+	second := dialTestClient(t, hub, "alice", true, 8)
+	bob := dialTestClient(t, hub, "bob", true, 7)
+
+	hub.DisconnectUser("alice")
+
+	for _, conn := range []*websocket.Conn{first, second} {
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		if _, _, err := conn.ReadMessage(); !websocket.IsCloseError(err, websocket.CloseNoStatusReceived, websocket.CloseNormalClosure) {
+			t.Fatalf("got %v, want the connection closed", err)
+		}
+	}
+	if hub.IsOnline("alice") || hub.ClientCount() != 1 {
+		t.Fatalf("alice online %v with %d clients left, want only bob", hub.IsOnline("alice"), hub.ClientCount())
+	}
+
+	hub.BroadcastToMatch(7, []byte(`{"type":"chat_message","match_id":7}`))
+	bob.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, _, err := bob.ReadMessage(); err != nil {
+		t.Fatalf("other user's connection: %v", err)
+	}
+}
+
+// benchmarkWritePump pushes b.N frames through one client's write pump to a
+// real socket and reports how many socket messages carried them.
+func benchmarkWritePump(b *testing.B, window time.Duration) {
+	hub := NewHub(DefaultConfig())
+	hub.batchWindow = window
+
+	clients := make(chan *Client, 1)
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		c := NewClient(hub, conn, "bench", nil)
+		go c.WritePump()
+		clients <- c
+	}))
+	defer srv.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer conn.Close()
+	c := <-clients
+
+	frame := []byte(`{"type":"typing_indicator","match_id":1,"user_id":"bench","is_typing":true}`)
+	done := make(chan int)
+	go func() {
+		received, messages := 0, 0
+		for received < b.N {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				break
+			}
+			messages++
+			received += strings.Count(string(data), "\n") + 1
+		}
+		done <- messages
+	}()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.send <- frame
+	}
+	messages := <-done
+	b.StopTimer()
+	b.ReportMetric(float64(messages)/float64(b.N), "writes/frame")
+	close(c.send)
+}
+
+func BenchmarkWritePump(b *testing.B) {
+	for _, window := range []time.Duration{0, 2 * time.Millisecond} {
+		b.Run("window="+window.String(), func(b *testing.B) {
+			benchmarkWritePump(b, window)
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package websocket
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+
+	"github.com/yourusername/skillsync/internal/domain"
+)
+
+// languageRefresh is how long the language list is used before it is read
+// from the skills table again.
+const languageRefresh = 5 * time.Minute
+
+// editorLanguages are accepted besides the language skills: modes an editor
+// offers that aren't programming language skills.
+var editorLanguages = []string{
+	"plaintext", "text", "markdown", "json", "yaml", "toml", "xml",
+	"html", "css", "sql", "shell", "bash", "dockerfile",
+}
+
+// languageCache holds the languages a code_change may name: every skill in
+// the language category plus editorLanguages, lowercased.
+type languageCache struct {
+	mu       sync.Mutex
+	names    map[string]bool
+	loadedAt time.Time
+	loading  bool
+}
+
+// known reports whether name, compared case-insensitively, is an accepted
+// language. The list is reloaded from db when it is older than
+// languageRefresh; a failed reload keeps the previous list. The query runs
+// without the lock, and while one reload is in flight other callers keep
+// using the stale list.
+func (lc *languageCache) known(db *gorm.DB, name string) bool {
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	lc.mu.Lock()
+	names := lc.names
+	load := names == nil || (time.Since(lc.loadedAt) >= languageRefresh && !lc.loading)
+	if load {
+		lc.loading = true
+	}
+	lc.mu.Unlock()
+
+	if load {
+		names = lc.reload(db, names)
+	}
+	return names[name]
+}
+
+// reload reads the language list from db and swaps it in, returning the
+// list now in use. prev is kept when the query fails.
+func (lc *languageCache) reload(db *gorm.DB, prev map[string]bool) map[string]bool {
+	var skills []string
+	err := db.Model(&domain.Skill{}).
+		Where("category = ?", domain.CategoryLanguage).
+		Pluck("name", &skills).Error
+	if err != nil {
+		log.Warn().Err(err).Msg("ws failed to load language skills")
+	}
+
+	names := prev
+	if err == nil || prev == nil {
+		names = make(map[string]bool, len(skills)+len(editorLanguages))
+		for _, l := range editorLanguages {
+			names[l] = true
+		}
+		for _, s := range skills {
+			names[strings.ToLower(strings.TrimSpace(s))] = true
+		}
+	}
+
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	lc.names = names
+	lc.loadedAt = time.Now()
+	lc.loading = false
+	return names
+}
@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+const (
+	apiKeyTag = "ssk_"
+	// APIKeyPrefixLen is how much of a key is kept in clear to identify it.
+	APIKeyPrefixLen = len(apiKeyTag) + 8
+)
+
+// GenerateAPIKey returns a random API key, its displayable prefix and the
+// hash to store for it. The full key is only ever shown to its owner once.
+func GenerateAPIKey() (key, prefix, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+	key = apiKeyTag + base64.RawURLEncoding.EncodeToString(buf)
+	return key, key[:APIKeyPrefixLen], HashAPIKey(key), nil
+}
+
+// HashAPIKey returns the hex SHA-256 of an API key. Keys are high-entropy,
+// so an unsalted hash is sufficient.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -1,6 +1,10 @@
 package auth
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"os"
@@ -17,6 +21,7 @@ var (
 // Claims holds the JWT payload for SkillSync tokens.
 type Claims struct {
 	UserID string `json:"user_id"`
+	Role   string `json:"role,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -29,14 +34,40 @@ func getSecret() []byte {
 	return []byte(secret)
 }
 
-// GenerateToken creates a signed JWT for the given user with a 7-day expiry.
-func GenerateToken(userID string) (string, error) {
+// Token lifetimes, overridable with JWT_ACCESS_TTL and JWT_REFRESH_TTL.
+const (
+	defaultAccessTTL  = time.Hour
+	defaultRefreshTTL = 30 * 24 * time.Hour
+)
+
+// AccessTokenTTL is how long access tokens from GenerateToken are valid.
+func AccessTokenTTL() time.Duration {
+	return envDuration("JWT_ACCESS_TTL", defaultAccessTTL)
+}
+
+// RefreshTokenTTL is how long a refresh token is valid after it is issued.
+func RefreshTokenTTL() time.Duration {
+	return envDuration("JWT_REFRESH_TTL", defaultRefreshTTL)
+}
+
+func envDuration(key string, fallback time.Duration) time.Duration {
+	d, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || d <= 0 {
+		return fallback
+	}
+	return d
+}
+
+// GenerateToken creates a signed, short-lived access JWT for the given user
+// and role. Role changes take effect when the next access token is issued.
+func GenerateToken(userID, role string) (string, error) {
 	now := time.Now()
 	claims := Claims{
 		UserID: userID,
+		Role:   role,
 		RegisteredClaims: jwt.RegisteredClaims{
 			IssuedAt:  jwt.NewNumericDate(now),
-			ExpiresAt: jwt.NewNumericDate(now.Add(7 * 24 * time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(now.Add(AccessTokenTTL())),
 			Issuer:    "skillsync",
 		},
 	}
@@ -68,6 +99,24 @@ func ValidateToken(tokenStr string) (*Claims, error) {
 	return claims, nil
 }
 
+// GenerateRefreshToken returns a random opaque refresh token and the hash
+// to store for it. Only the hash is kept server-side.
+func GenerateRefreshToken() (token, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	token = base64.RawURLEncoding.EncodeToString(buf)
+	return token, HashRefreshToken(token), nil
+}
+
+// HashRefreshToken returns the hex SHA-256 of a refresh token. Refresh tokens
+// are high-entropy, so an unsalted hash is sufficient.
+func HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
 // ExtractUserID is a convenience wrapper that pulls just the user ID from a
 // raw token string.
 func ExtractUserID(tokenStr string) (string, error) {
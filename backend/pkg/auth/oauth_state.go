@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrInvalidOAuthState = errors.New("invalid, expired or reused oauth state")
+
+// stateKey derives the HMAC key for OAuth state values from the JWT secret.
+func stateKey() []byte {
+	key := sha256.Sum256([]byte("skillsync-oauth-state:" + string(getSecret())))
+	return key[:]
+}
+
+// GenerateOAuthState returns a state value for an OAuth login with provider
+// that expires after ttl. It has the form nonce.expiry.signature, where the
+// signature is an HMAC over provider, nonce and expiry.
+func GenerateOAuthState(provider string, ttl time.Duration) (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate oauth state: %w", err)
+	}
+	nonce := base64.RawURLEncoding.EncodeToString(buf)
+	expiry := strconv.FormatInt(time.Now().Add(ttl).Unix(), 10)
+	return nonce + "." + expiry + "." + signOAuthState(provider, nonce, expiry), nil
+}
+
+// ConsumeOAuthState checks that state was issued by GenerateOAuthState for
+// provider, hasn't expired and hasn't been consumed before. A state is
+// accepted at most once per process.
+func ConsumeOAuthState(state, provider string) error {
+	parts := strings.Split(state, ".")
+	if len(parts) != 3 {
+		return ErrInvalidOAuthState
+	}
+	nonce, expiry, sig := parts[0], parts[1], parts[2]
+	if !hmac.Equal([]byte(sig), []byte(signOAuthState(provider, nonce, expiry))) {
+		return ErrInvalidOAuthState
+	}
+	exp, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil {
+		return ErrInvalidOAuthState
+	}
+	expiresAt := time.Unix(exp, 0)
+	if !time.Now().Before(expiresAt) {
+		return ErrInvalidOAuthState
+	}
+	if !usedStates.add(nonce, expiresAt) {
+		return ErrInvalidOAuthState
+	}
+	return nil
+}
+
+func signOAuthState(provider, nonce, expiry string) string {
+	mac := hmac.New(sha256.New, stateKey())
+	mac.Write([]byte(provider + "|" + nonce + "|" + expiry))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// usedStates remembers consumed nonces until they expire, after which the
+// signature check rejects them anyway.
+var usedStates = &nonceSet{seen: make(map[string]time.Time)}
+
+type nonceSet struct {
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+// add records nonce and reports whether it was new.
+func (s *nonceSet) add(nonce string, expiresAt time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for n, exp := range s.seen {
+		if !now.Before(exp) {
+			delete(s.seen, n)
+		}
+	}
+	if _, ok := s.seen[nonce]; ok {
+		return false
+	}
+	s.seen[nonce] = expiresAt
+	return true
+}
@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+var ErrInvalidCiphertext = errors.New("invalid encrypted secret")
+
+// secretKey derives the AES-256 key used for third-party tokens at rest from
+// TOKEN_ENCRYPTION_KEY, falling back to the JWT secret.
+func secretKey() []byte {
+	seed := os.Getenv("TOKEN_ENCRYPTION_KEY")
+	if seed == "" {
+		seed = string(getSecret())
+	}
+	key := sha256.Sum256([]byte("skillsync-token-encryption:" + seed))
+	return key[:]
+}
+
+// EncryptSecret seals plaintext with AES-GCM and returns it base64 encoded.
+func EncryptSecret(plaintext string) (string, error) {
+	block, err := aes.NewCipher(secretKey())
+	if err != nil {
+		return "", fmt.Errorf("failed to create cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", fmt.Errorf("failed to create gcm: %w", err)
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptSecret reverses EncryptSecret.
+func DecryptSecret(encoded string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", ErrInvalidCiphertext
+	}
+
+	block, err := aes.NewCipher(secretKey())
+	if err != nil {
+		return "", fmt.Errorf("failed to create cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", fmt.Errorf("failed to create gcm: %w", err)
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return "", ErrInvalidCiphertext
+	}
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", ErrInvalidCiphertext
+	}
+	return string(plaintext), nil
+}
@@ -50,6 +50,7 @@ func Connect() (*gorm.DB, error) {
 			Logger:                 logger.Default.LogMode(logLevel),
 			SkipDefaultTransaction: true,
 			PrepareStmt:            true,
+			TranslateError:         true,
 		})
 		if err != nil {
 			connectErr = fmt.Errorf("failed to connect to database: %w", err)
@@ -88,26 +89,293 @@ func Migrate() error {
 	if db == nil {
 		return fmt.Errorf("database not connected; call Connect() first")
 	}
+	return MigrateDB(db)
+}
 
+// MigrateDB runs the migrations on conn, a database that already has the
+// base schema from migrations/*.up.sql. Migrate uses the connection opened
+// by Connect; tests pass their own.
+func MigrateDB(conn *gorm.DB) error {
 	log.Info().Msg("running database migrations")
 
 	// Run incremental SQL migrations rather than AutoMigrate, because the
-	// existing DB schema (UUID PKs, array columns) diverges from GORM's
-	// model-based expectations.
+	// existing DB schema (UUID PKs, legacy array columns) diverges from
+	// GORM's model-based expectations.
 	migrations := []string{
 		"ALTER TABLE users ADD COLUMN IF NOT EXISTS google_id VARCHAR(255)",
 		"ALTER TABLE users ADD COLUMN IF NOT EXISTS git_hub_id VARCHAR(255)",
 		"ALTER TABLE users ADD COLUMN IF NOT EXISTS github_url VARCHAR(512)",
+		"ALTER TABLE users ADD COLUMN IF NOT EXISTS github_token TEXT",
 		"ALTER TABLE users ADD COLUMN IF NOT EXISTS linkedin_url VARCHAR(512)",
 		"ALTER TABLE users ADD COLUMN IF NOT EXISTS total_sessions BIGINT DEFAULT 0",
 		"ALTER TABLE users ADD COLUMN IF NOT EXISTS badges JSONB DEFAULT '[]'",
+		"ALTER TABLE users ADD COLUMN IF NOT EXISTS preferred_cadence VARCHAR(20) DEFAULT ''",
+		"ALTER TABLE users ADD COLUMN IF NOT EXISTS preferred_session_minutes INT DEFAULT 0",
+		"ALTER TABLE users ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) DEFAULT ''",
+		"ALTER TABLE users ADD COLUMN IF NOT EXISTS preferred_languages JSONB DEFAULT '[]'",
+		"ALTER TABLE matches ADD COLUMN IF NOT EXISTS insights_generated_at TIMESTAMPTZ",
+		"ALTER TABLE user_skills ADD COLUMN IF NOT EXISTS direction VARCHAR(10) NOT NULL DEFAULT 'both'",
 		`DO $$ BEGIN
 			ALTER TABLE users ALTER COLUMN password_hash DROP NOT NULL;
 		EXCEPTION WHEN others THEN NULL;
 		END $$`,
+		`CREATE TABLE IF NOT EXISTS audit_log (
+			id          BIGSERIAL    PRIMARY KEY,
+			action      VARCHAR(50)  NOT NULL,
+			actor_id    VARCHAR(64)  DEFAULT '',
+			target_type VARCHAR(50)  DEFAULT '',
+			target_id   VARCHAR(64)  DEFAULT '',
+			ip          VARCHAR(64)  DEFAULT '',
+			metadata    JSONB        DEFAULT '{}'::jsonb,
+			created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+		)`,
+		"CREATE INDEX IF NOT EXISTS idx_audit_log_action ON audit_log (action)",
+		"CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log (actor_id)",
+		"CREATE INDEX IF NOT EXISTS idx_audit_log_target ON audit_log (target_id)",
+		"CREATE INDEX IF NOT EXISTS idx_audit_log_created ON audit_log (created_at)",
+		`CREATE TABLE IF NOT EXISTS match_digests (
+			id          BIGSERIAL    PRIMARY KEY,
+			user_id     UUID         NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			digest_date DATE         NOT NULL,
+			suggestions JSONB        DEFAULT '[]'::jsonb,
+			created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+			UNIQUE (user_id, digest_date)
+		)`,
+		`CREATE TABLE IF NOT EXISTS user_blocks (
+			id          BIGSERIAL    PRIMARY KEY,
+			blocker_id  UUID         NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			blocked_id  UUID         NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+			UNIQUE (blocker_id, blocked_id)
+		)`,
+		"CREATE INDEX IF NOT EXISTS idx_user_blocks_blocked ON user_blocks (blocked_id)",
+		`CREATE TABLE IF NOT EXISTS user_reports (
+			id           BIGSERIAL    PRIMARY KEY,
+			reporter_id  UUID         NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			reported_id  UUID         NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			reason       TEXT         NOT NULL,
+			status       VARCHAR(20)  NOT NULL DEFAULT 'open',
+			created_at   TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+		)`,
+		"CREATE INDEX IF NOT EXISTS idx_user_reports_reported ON user_reports (reported_id)",
+		"CREATE INDEX IF NOT EXISTS idx_user_reports_status ON user_reports (status)",
+		`CREATE TABLE IF NOT EXISTS notifications (
+			id          BIGSERIAL    PRIMARY KEY,
+			user_id     UUID         NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			type        VARCHAR(50)  NOT NULL,
+			title       VARCHAR(255) NOT NULL,
+			body        TEXT,
+			data        JSONB        NOT NULL DEFAULT '{}',
+			read_at     TIMESTAMPTZ,
+			created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+		)`,
+		"CREATE INDEX IF NOT EXISTS idx_notifications_user_created ON notifications (user_id, created_at DESC)",
+		"CREATE INDEX IF NOT EXISTS idx_notifications_unread ON notifications (user_id) WHERE read_at IS NULL",
+		"ALTER TABLE messages ADD COLUMN IF NOT EXISTS type VARCHAR(10) NOT NULL DEFAULT 'text'",
+		"ALTER TABLE messages ADD COLUMN IF NOT EXISTS metadata JSONB",
+		`CREATE TABLE IF NOT EXISTS refresh_tokens (
+			id          BIGSERIAL    PRIMARY KEY,
+			user_id     UUID         NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			token_hash  CHAR(64)     NOT NULL UNIQUE,
+			expires_at  TIMESTAMPTZ  NOT NULL,
+			revoked_at  TIMESTAMPTZ,
+			created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+		)`,
+		"CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user ON refresh_tokens (user_id)",
+		"ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'user'",
+		"ALTER TABLE users ADD COLUMN IF NOT EXISTS banned_until TIMESTAMPTZ",
+		"CREATE INDEX IF NOT EXISTS idx_users_banned_until ON users (banned_until) WHERE banned_until IS NOT NULL",
+		// Drop duplicates left by past double-submits before enforcing
+		// uniqueness; the oldest row wins.
+		`DELETE FROM ratings a USING ratings b
+			WHERE a.rater_id = b.rater_id AND a.rated_id = b.rated_id
+			AND a.session_id = b.session_id AND a.id > b.id`,
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_ratings_rater_rated_session ON ratings (rater_id, rated_id, session_id)",
+		"ALTER TABLE ratings ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ",
+		"UPDATE ratings SET updated_at = created_at WHERE updated_at IS NULL",
+		`UPDATE match_requests a SET status = 'rejected' FROM match_requests b
+			WHERE a.status = 'pending' AND b.status = 'pending'
+			AND LEAST(a.sender_id, a.receiver_id) = LEAST(b.sender_id, b.receiver_id)
+			AND GREATEST(a.sender_id, a.receiver_id) = GREATEST(b.sender_id, b.receiver_id)
+			AND a.id > b.id`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_match_requests_pending_pair
+			ON match_requests (LEAST(sender_id, receiver_id), GREATEST(sender_id, receiver_id))
+			WHERE status = 'pending'`,
+		`CREATE TABLE IF NOT EXISTS idempotency_keys (
+			id             BIGSERIAL     PRIMARY KEY,
+			user_id        UUID          NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			key            VARCHAR(255)  NOT NULL,
+			request_hash   CHAR(64)      NOT NULL,
+			status_code    INT           NOT NULL DEFAULT 0,
+			response_body  BYTEA,
+			created_at     TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+			UNIQUE (user_id, key)
+		)`,
+		"CREATE INDEX IF NOT EXISTS idx_idempotency_keys_created ON idempotency_keys (created_at)",
+		`CREATE TABLE IF NOT EXISTS challenges (
+			id                 VARCHAR(100)  PRIMARY KEY,
+			title              VARCHAR(200)  NOT NULL,
+			prompt             TEXT          NOT NULL,
+			expected_behavior  TEXT          NOT NULL,
+			test_cases         JSONB         DEFAULT '[]',
+			difficulty         VARCHAR(20)   NOT NULL,
+			created_at         TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+			updated_at         TIMESTAMPTZ   NOT NULL DEFAULT NOW()
+		)`,
+		"CREATE INDEX IF NOT EXISTS idx_challenges_difficulty ON challenges (difficulty)",
+		`INSERT INTO challenges (id, title, prompt, expected_behavior, test_cases, difficulty) VALUES
+			('two-sum', 'Two Sum',
+			 'Given an array of integers and a target, return the indices of the two numbers that add up to the target.',
+			 'Return a pair of distinct indices whose values sum to the target. Assume exactly one solution exists. Should run in O(n).',
+			 '[{"input": "nums=[2,7,11,15], target=9", "expected_output": "[0,1]"}, {"input": "nums=[3,2,4], target=6", "expected_output": "[1,2]"}]',
+			 'beginner'),
+			('valid-parentheses', 'Valid Parentheses',
+			 'Given a string containing only the characters ()[]{}, determine whether the brackets are balanced.',
+			 'Return true when every opening bracket is closed by the same type in the correct order, false otherwise. The empty string is valid.',
+			 '[{"input": "\"()[]{}\"", "expected_output": "true"}, {"input": "\"(]\"", "expected_output": "false"}, {"input": "\"([)]\"", "expected_output": "false"}]',
+			 'beginner'),
+			('lru-cache', 'LRU Cache',
+			 'Implement a least-recently-used cache with a fixed capacity supporting get(key) and put(key, value).',
+			 'get returns the value or -1 when missing and marks the key as recently used. put inserts or updates and evicts the least recently used key when over capacity. Both operations should be O(1).',
+			 '[{"input": "capacity=2; put(1,1); put(2,2); get(1); put(3,3); get(2)", "expected_output": "1, -1"}]',
+			 'intermediate'),
+			('merge-intervals', 'Merge Intervals',
+			 'Given a list of [start, end] intervals, merge all overlapping intervals.',
+			 'Return non-overlapping intervals covering the same ranges, sorted by start. Intervals that touch (end == next start) are merged.',
+			 '[{"input": "[[1,3],[2,6],[8,10],[15,18]]", "expected_output": "[[1,6],[8,10],[15,18]]"}, {"input": "[[1,4],[4,5]]", "expected_output": "[[1,5]]"}]',
+			 'intermediate'),
+			('rate-limiter', 'Sliding Window Rate Limiter',
+			 'Implement a rate limiter that allows at most N requests per key within any rolling window of W seconds and is safe for concurrent use.',
+			 'allow(key, now) returns true and records the request when fewer than N requests for key fall in (now - W, now], otherwise false. Memory for idle keys should be reclaimable.',
+			 '[{"input": "N=2, W=10; allow(a,0); allow(a,1); allow(a,2); allow(a,11)", "expected_output": "true, true, false, true"}]',
+			 'advanced')
+		ON CONFLICT (id) DO NOTHING`,
+		"ALTER TABLE coding_sessions ADD COLUMN IF NOT EXISTS last_activity_at TIMESTAMPTZ",
+		`CREATE INDEX IF NOT EXISTS idx_coding_sessions_open
+			ON coding_sessions (match_id) WHERE ended_at IS NULL`,
+		"ALTER TABLE matches ADD COLUMN IF NOT EXISTS user1_hidden_at TIMESTAMPTZ",
+		"ALTER TABLE matches ADD COLUMN IF NOT EXISTS user2_hidden_at TIMESTAMPTZ",
+		// Matches were first hidden for both participants with a shared
+		// deleted_at; carry those over as hidden for each of them.
+		`DO $$ BEGIN
+			IF EXISTS (SELECT 1 FROM information_schema.columns
+			           WHERE table_name = 'matches' AND column_name = 'deleted_at') THEN
+				UPDATE matches SET user1_hidden_at = deleted_at, user2_hidden_at = deleted_at
+				WHERE deleted_at IS NOT NULL;
+				ALTER TABLE matches DROP COLUMN deleted_at;
+			END IF;
+		END $$`,
+		"ALTER TABLE users ADD COLUMN IF NOT EXISTS git_lab_id VARCHAR(255)",
+		"CREATE INDEX IF NOT EXISTS idx_users_git_lab_id ON users (git_lab_id)",
+		`CREATE TABLE IF NOT EXISTS flagged_messages (
+			id           BIGSERIAL    PRIMARY KEY,
+			message_id   BIGINT       UNIQUE REFERENCES messages(id) ON DELETE SET NULL,
+			sender_id    UUID         NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			match_id     BIGINT       NOT NULL,
+			content      TEXT         NOT NULL,
+			category     VARCHAR(30),
+			reason       TEXT,
+			source       VARCHAR(20)  NOT NULL,
+			status       VARCHAR(20)  NOT NULL DEFAULT 'pending',
+			reviewed_by  UUID         REFERENCES users(id) ON DELETE SET NULL,
+			reviewed_at  TIMESTAMPTZ,
+			created_at   TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+		)`,
+		"CREATE INDEX IF NOT EXISTS idx_flagged_messages_sender ON flagged_messages (sender_id)",
+		"CREATE INDEX IF NOT EXISTS idx_flagged_messages_status ON flagged_messages (status)",
+		`CREATE TABLE IF NOT EXISTS session_proposals (
+			id                BIGSERIAL    PRIMARY KEY,
+			match_id          BIGINT       NOT NULL REFERENCES matches(id) ON DELETE CASCADE,
+			proposer_id       UUID         NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			recipient_id      UUID         NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			starts_at         TIMESTAMPTZ  NOT NULL,
+			duration_minutes  INTEGER      NOT NULL,
+			note              TEXT,
+			status            VARCHAR(20)  NOT NULL DEFAULT 'pending',
+			session_id        BIGINT       REFERENCES coding_sessions(id) ON DELETE SET NULL,
+			responded_at      TIMESTAMPTZ,
+			created_at        TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+		)`,
+		"CREATE INDEX IF NOT EXISTS idx_session_proposals_match ON session_proposals (match_id, starts_at)",
+		"CREATE INDEX IF NOT EXISTS idx_session_proposals_proposer ON session_proposals (proposer_id, starts_at)",
+		"CREATE INDEX IF NOT EXISTS idx_session_proposals_recipient ON session_proposals (recipient_id, starts_at)",
+		"ALTER TABLE users ADD COLUMN IF NOT EXISTS is_online BOOLEAN NOT NULL DEFAULT false",
+		"ALTER TABLE users ADD COLUMN IF NOT EXISTS last_active_at TIMESTAMPTZ",
+		`CREATE TABLE IF NOT EXISTS api_keys (
+			id            BIGSERIAL     PRIMARY KEY,
+			user_id       UUID          NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			name          VARCHAR(100)  NOT NULL,
+			prefix        VARCHAR(16)   NOT NULL,
+			key_hash      CHAR(64)      NOT NULL UNIQUE,
+			scope         VARCHAR(10)   NOT NULL DEFAULT 'read',
+			last_used_at  TIMESTAMPTZ,
+			revoked_at    TIMESTAMPTZ,
+			created_at    TIMESTAMPTZ   NOT NULL DEFAULT NOW()
+		)`,
+		"CREATE INDEX IF NOT EXISTS idx_api_keys_user ON api_keys (user_id)",
+		// A database created by the root migrations keeps skills in the
+		// users.skills_teach / skills_learn arrays. Move them into
+		// user_skills, the canonical model, and drop the arrays; the same
+		// step is migrations/003_user_skills.up.sql on the other side.
+		`CREATE TABLE IF NOT EXISTS skills (
+			id           BIGSERIAL     PRIMARY KEY,
+			name         VARCHAR(100)  NOT NULL,
+			category     VARCHAR(50)   NOT NULL DEFAULT 'other',
+			description  TEXT          DEFAULT '',
+			created_at   TIMESTAMPTZ   NOT NULL DEFAULT NOW()
+		)`,
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_skills_name ON skills (name)",
+		`CREATE TABLE IF NOT EXISTS user_skills (
+			id                 BIGSERIAL      PRIMARY KEY,
+			user_id            UUID           NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			skill_id           BIGINT         NOT NULL REFERENCES skills(id) ON DELETE CASCADE,
+			proficiency_level  VARCHAR(20)    NOT NULL DEFAULT 'beginner',
+			years_experience   DECIMAL(4,1)   DEFAULT 0,
+			credibility_score  DECIMAL(10,2)  DEFAULT 0,
+			verified_by_peers  INTEGER        DEFAULT 0,
+			direction          VARCHAR(10)    NOT NULL DEFAULT 'both',
+			created_at         TIMESTAMPTZ    NOT NULL DEFAULT NOW()
+		)`,
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_user_skill ON user_skills (user_id, skill_id)",
+		"CREATE INDEX IF NOT EXISTS idx_user_skills_skill ON user_skills (skill_id)",
+		`DO $$ BEGIN
+			IF EXISTS (SELECT 1 FROM information_schema.columns
+					WHERE table_name = 'users' AND column_name = 'skills_teach') THEN
+				INSERT INTO skills (name, category)
+				SELECT DISTINCT ON (lower(btrim(n))) btrim(n), 'other'
+				FROM users u, unnest(u.skills_teach || u.skills_learn) AS n
+				WHERE btrim(n) <> ''
+					AND NOT EXISTS (SELECT 1 FROM skills s WHERE lower(s.name) = lower(btrim(n)))
+				ORDER BY lower(btrim(n)), btrim(n);
+
+				INSERT INTO user_skills (user_id, skill_id, proficiency_level, direction)
+				SELECT u.id, s.id,
+					CASE WHEN u.skill_level IN ('beginner', 'intermediate', 'advanced')
+						THEN u.skill_level ELSE 'beginner' END,
+					CASE WHEN bool_or(t.teach) AND bool_or(NOT t.teach) THEN 'both'
+						WHEN bool_or(t.teach) THEN 'teach'
+						ELSE 'learn' END
+				FROM users u
+				CROSS JOIN LATERAL (
+					SELECT btrim(n) AS name, true AS teach FROM unnest(u.skills_teach) AS n
+					UNION ALL
+					SELECT btrim(n), false FROM unnest(u.skills_learn) AS n
+				) t
+				JOIN skills s ON lower(s.name) = lower(t.name)
+				GROUP BY u.id, s.id, u.skill_level
+				ON CONFLICT (user_id, skill_id) DO NOTHING;
+
+				ALTER TABLE users DROP COLUMN skills_teach;
+				ALTER TABLE users DROP COLUMN skills_learn;
+			END IF;
+		END $$`,
+		// Latest message per match, for the match list's preview and
+		// activity sort.
+		"CREATE INDEX IF NOT EXISTS idx_messages_match_created ON messages (match_id, created_at DESC)",
+		"ALTER TABLE matches ADD COLUMN IF NOT EXISTS archived_by UUID REFERENCES users(id) ON DELETE SET NULL",
 	}
 	for _, stmt := range migrations {
-		if err := db.Exec(stmt).Error; err != nil {
+		if err := conn.Exec(stmt).Error; err != nil {
 			log.Warn().Err(err).Str("stmt", stmt).Msg("migration statement failed (may be safe to ignore)")
 		}
 	}
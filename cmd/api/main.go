@@ -57,7 +57,18 @@ func main() {
 	pairingInsightsService := service.NewPairingInsightsService(claudeService, sessionRepo, matchRepo)
 
 	// 🔹 WebSocket hub
-	hub := ws.NewHub()
+	wsConfig, err := ws.ConfigFromEnv()
+	if err != nil {
+		appLogger.Fatal("Invalid WebSocket configuration", "error", err)
+	}
+	appLogger.Info("WebSocket config loaded",
+		"write_wait", wsConfig.WriteWait,
+		"pong_wait", wsConfig.PongWait,
+		"ping_period", wsConfig.PingPeriod,
+		"max_message_size", wsConfig.MaxMessageSize,
+		"read_buffer_size", wsConfig.ReadBufferSize,
+		"write_buffer_size", wsConfig.WriteBufferSize)
+	hub := ws.NewHub(wsConfig)
 	go hub.Run()
 
 	// 🔹 Echo setup
@@ -67,11 +78,15 @@ func main() {
 	// 🔹 Logger
 	e.Use(middleware.Logger(appLogger))
 
-	// 🔥 CORS (uses ALLOWED_ORIGINS env var, falls back to localhost for dev)
-	corsOrigins := []string{cfg.AllowedOrigins[0]}
+	// 🔥 CORS (ALLOWED_ORIGINS is a comma-separated list; required in production)
+	if err := cfg.ValidateOrigins(); err != nil {
+		appLogger.Fatal("Invalid CORS configuration", "error", err)
+	}
+	corsOrigins := cfg.AllowedOrigins
 	if cfg.Environment == "development" {
-		corsOrigins = append(corsOrigins, "http://localhost:5173", "http://localhost:3000")
+		corsOrigins = appendMissing(corsOrigins, "http://localhost:5173", "http://localhost:3000")
 	}
+	appLogger.Info("CORS origins loaded", "origins", corsOrigins)
 	e.Use(echoMiddleware.CORSWithConfig(echoMiddleware.CORSConfig{
 		AllowOrigins: corsOrigins,
 		AllowMethods: []string{
@@ -100,7 +115,7 @@ func main() {
 	assessmentHandler := handler.NewAssessmentHandler(claudeService, userService)
 	reputationHandler := handler.NewReputationHandler(reputationService)
 	insightsHandler := handler.NewInsightsHandler(pairingInsightsService)
-	wsHandler := handler.NewWebSocketHandler(hub, messageRepo, jwtManager)
+	wsHandler := handler.NewWebSocketHandler(hub, messageRepo, jwtManager, corsOrigins)
 
 	// =========================
 	// 🌐 ROUTES
@@ -163,3 +178,19 @@ func main() {
 	}
 }
 
+// appendMissing appends each of extra to list unless it is already present.
+func appendMissing(list []string, extra ...string) []string {
+	for _, e := range extra {
+		found := false
+		for _, v := range list {
+			if v == e {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, e)
+		}
+	}
+	return list
+}
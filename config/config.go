@@ -1,7 +1,11 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"net/url"
 	"os"
+	"strings"
 	"time"
 )
 
@@ -23,12 +27,62 @@ func Load() *Config {
 		JWTSecret:      getEnv("JWT_SECRET", "change-me-in-production"),
 		JWTExpiry:      parseDuration(getEnv("JWT_EXPIRY", "24h")),
 		ClaudeAPIKey:   getEnv("CLAUDE_API_KEY", ""),
-		AllowedOrigins: []string{getEnv("ALLOWED_ORIGINS", "http://localhost:3000")},
+		AllowedOrigins: splitList(os.Getenv("ALLOWED_ORIGINS")),
 		LogLevel:       getEnv("LOG_LEVEL", "info"),
 		Environment:    getEnv("ENVIRONMENT", "development"),
 	}
 }
 
+// ValidateOrigins normalizes AllowedOrigins and checks that each one is a bare
+// http(s) origin. Outside production an empty list falls back to the local
+// dev servers and "*" allows any origin; in production both are errors.
+func (c *Config) ValidateOrigins() error {
+	production := c.Environment == "production"
+	if len(c.AllowedOrigins) == 0 {
+		if production {
+			return errors.New("ALLOWED_ORIGINS must list at least one origin in production")
+		}
+		c.AllowedOrigins = []string{"http://localhost:3000", "http://localhost:5173"}
+	}
+
+	for i, origin := range c.AllowedOrigins {
+		if origin == "*" {
+			if production {
+				return errors.New(`ALLOWED_ORIGINS may not contain "*" in production`)
+			}
+			continue
+		}
+		normalized, err := normalizeOrigin(origin)
+		if err != nil {
+			return err
+		}
+		c.AllowedOrigins[i] = normalized
+	}
+	return nil
+}
+
+// normalizeOrigin accepts scheme://host[:port] with an optional trailing
+// slash, which browsers never send and would never match.
+func normalizeOrigin(origin string) (string, error) {
+	u, err := url.Parse(strings.TrimSuffix(origin, "/"))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+		u.Path != "" || u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+		return "", fmt.Errorf("invalid origin %q in ALLOWED_ORIGINS: want scheme://host[:port]", origin)
+	}
+	return u.Scheme + "://" + u.Host, nil
+}
+
+// splitList splits a comma-separated value, dropping blank entries.
+func splitList(raw string) []string {
+	var out []string
+	for _, part := range strings.Split(raw, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
 func getEnv(key, fallback string) string {
 	if val := os.Getenv(key); val != "" {
 		return val
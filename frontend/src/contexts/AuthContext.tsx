@@ -8,6 +8,11 @@ import React, {
 } from "react";
 import { User } from "../types";
 import authService from "../services/auth";
+import {
+  storeTokens,
+  TOKEN_KEY,
+  TOKEN_CHANGED_EVENT,
+} from "../services/api";
 import toast from "react-hot-toast";
 
 interface AuthContextType {
@@ -32,14 +37,15 @@ interface AuthProviderProps {
 export const AuthProvider: React.FC<AuthProviderProps> = ({ children }) => {
   // 🔐 Load token from storage OR from OAuth redirect (synchronous to avoid race)
   const [token, setToken] = useState<string | null>(() => {
-    const params = new URLSearchParams(window.location.search);
+    // Tokens arrive in the fragment so they never reach a server log.
+    const params = new URLSearchParams(window.location.hash.slice(1));
     const oauthToken = params.get("token");
     if (oauthToken) {
-      localStorage.setItem("jwt_token", oauthToken);
-      window.history.replaceState({}, "", window.location.pathname);
+      storeTokens(oauthToken, params.get("refresh_token") || undefined);
+      window.history.replaceState({}, "", window.location.pathname + window.location.search);
       return oauthToken;
     }
-    return localStorage.getItem("jwt_token");
+    return localStorage.getItem(TOKEN_KEY);
   });
 
   const [user, setUser] = useState<User | null>(null);
@@ -48,6 +54,17 @@ export const AuthProvider: React.FC<AuthProviderProps> = ({ children }) => {
   // ✅ Authenticated if token exists AND user loaded
   const isAuthenticated = !!token && !!user;
 
+  // 🔁 Follow token refreshes and sign-outs done by the API client
+  useEffect(() => {
+    const onTokenChanged = () => {
+      const stored = localStorage.getItem(TOKEN_KEY);
+      setToken(stored);
+      if (!stored) setUser(null);
+    };
+    window.addEventListener(TOKEN_CHANGED_EVENT, onTokenChanged);
+    return () => window.removeEventListener(TOKEN_CHANGED_EVENT, onTokenChanged);
+  }, []);
+
   // 🌐 Handle OAuth error redirects
   useEffect(() => {
     const params = new URLSearchParams(window.location.search);
@@ -94,8 +111,7 @@ export const AuthProvider: React.FC<AuthProviderProps> = ({ children }) => {
       const { token: newToken, user: newUser } =
         await authService.login(credentials);
 
-      // ✅ Save token first
-      localStorage.setItem("jwt_token", newToken);
+      // ✅ Token is stored by authService
       setToken(newToken);
 
       // ✅ Set user immediately
@@ -113,7 +129,6 @@ export const AuthProvider: React.FC<AuthProviderProps> = ({ children }) => {
       const { token: newToken, user: newUser } =
         await authService.register(userData);
 
-      localStorage.setItem("jwt_token", newToken);
       setToken(newToken);
       setUser(newUser);
     } finally {
@@ -123,7 +138,7 @@ export const AuthProvider: React.FC<AuthProviderProps> = ({ children }) => {
 
   // 🚪 LOGOUT
   const logout = useCallback(() => {
-    localStorage.removeItem("jwt_token");
+    authService.logout();
     setToken(null);
     setUser(null);
   }, []);
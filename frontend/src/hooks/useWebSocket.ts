@@ -22,7 +22,12 @@ const useWebSocket = ({ token, matchId }: UseWebSocketProps) => {
   const reconnectTimeout = useRef<number | null>(null);
   const reconnectAttempts = useRef(0);
 
+  // The socket outlives access tokens: a new token is sent in-band as a
+  // refresh_token message instead of reconnecting.
+  const tokenRef = useRef(token);
+
   const connect = useCallback(() => {
+    const token = tokenRef.current;
     if (!token || !matchId) {
       console.warn("WebSocket: Missing token or matchId");
       setIsConnected(false);
@@ -46,43 +51,51 @@ const useWebSocket = ({ token, matchId }: UseWebSocketProps) => {
     };
 
     ws.current.onmessage = (event) => {
-      try {
-        const parsed = JSON.parse(event.data);
-
-        switch (parsed.type) {
-          case "chat_message":
-            if (parsed.message) {
-              const msg: Message = {
-                id: parsed.message.id?.toString() || "",
-                match_id: parsed.message.match_id?.toString() || matchId || "",
-                sender_id: parsed.message.sender_id || "",
-                content: parsed.message.content || "",
-                timestamp: parsed.message.created_at || parsed.timestamp || new Date().toISOString(),
-              };
-              setMessages((prev) => [...prev, msg]);
-            }
-            break;
-
-          case "typing_indicator":
-            setTypingUsers((prev) => {
-              const userId = parsed.user_id;
-              return parsed.is_typing
-                ? prev.includes(userId)
-                  ? prev
-                  : [...prev, userId]
-                : prev.filter((u) => u !== userId);
-            });
-            break;
-
-          case "code_change":
-            console.log("Code change:", parsed.code);
-            break;
-
-          default:
-            console.warn("Unknown WS message:", parsed.type);
+      // The server may coalesce several messages into one frame, one JSON
+      // object per line.
+      for (const line of String(event.data).split("\n")) {
+        if (!line.trim()) continue;
+        try {
+          const parsed = JSON.parse(line);
+
+          switch (parsed.type) {
+            case "chat_message":
+              if (parsed.message) {
+                const msg: Message = {
+                  id: parsed.message.id?.toString() || "",
+                  match_id: parsed.message.match_id?.toString() || matchId || "",
+                  sender_id: parsed.message.sender_id || "",
+                  content: parsed.message.content || "",
+                  timestamp: parsed.message.created_at || parsed.timestamp || new Date().toISOString(),
+                };
+                setMessages((prev) => [...prev, msg]);
+              }
+              break;
+
+            case "typing_indicator":
+              setTypingUsers((prev) => {
+                const userId = parsed.user_id;
+                return parsed.is_typing
+                  ? prev.includes(userId)
+                    ? prev
+                    : [...prev, userId]
+                  : prev.filter((u) => u !== userId);
+              });
+              break;
+
+            case "code_change":
+              console.log("Code change:", parsed.code);
+              break;
+
+            case "token_refreshed":
+              break;
+
+            default:
+              console.warn("Unknown WS message:", parsed.type);
+          }
+        } catch (err) {
+          console.error("Failed to parse WS message:", err);
         }
-      } catch (err) {
-        console.error("Failed to parse WS message:", err);
       }
     };
 
@@ -100,7 +113,15 @@ const useWebSocket = ({ token, matchId }: UseWebSocketProps) => {
       console.error("WebSocket Error:", error);
       ws.current?.close();
     };
-  }, [token, matchId]);
+  }, [matchId, !!token]);
+
+  useEffect(() => {
+    const previous = tokenRef.current;
+    tokenRef.current = token;
+    if (token && previous && token !== previous && ws.current?.readyState === WebSocket.OPEN) {
+      ws.current.send(JSON.stringify({ type: "refresh_token", data: { token } }));
+    }
+  }, [token]);
 
   useEffect(() => {
     connect();
@@ -3,7 +3,7 @@ import { useParams, Link, useNavigate } from 'react-router-dom';
 import api from '../services/api';
 import { useAuth } from '../contexts/AuthContext';
 import useWebSocket from '../hooks/useWebSocket';
-import { APIResponse, Match, Message } from '../types';
+import { APIResponse, Match, Message, PaginatedResponse } from '../types';
 import { FiSend, FiLoader, FiMessageSquare } from 'react-icons/fi';
 import toast from 'react-hot-toast';
 
@@ -40,9 +40,9 @@ const Chat: React.FC = () => {
   const fetchMatches = useCallback(async () => {
     setLoadingMatches(true);
     try {
-      const response: APIResponse<{ matches: Match[]; total: number }> = await api.get('/matches');
+      const response: APIResponse<PaginatedResponse<Match>> = await api.get('/matches');
       if (response.success && response.data) {
-        const matchList = response.data.matches || [];
+        const matchList = response.data.items || [];
         setMatches(matchList);
         if (!currentMatchId && matchList.length > 0) {
           navigate(`/chat/${matchList[0].id}`, { replace: true });
@@ -69,9 +69,9 @@ const Chat: React.FC = () => {
     const loadHistory = async () => {
       setLoadingHistory(true);
       try {
-        const response: APIResponse<Message[]> = await api.get(`/matches/${currentMatchId}/messages`);
+        const response: APIResponse<PaginatedResponse<Message>> = await api.get(`/matches/${currentMatchId}/messages`);
         if (response.success && response.data) {
-          setHistoryMessages(response.data);
+          setHistoryMessages(response.data.items || []);
         }
       } catch {
         // History loading is best-effort
@@ -2,7 +2,7 @@ import React, { useState, useEffect, useCallback } from 'react';
 import { Link } from 'react-router-dom';
 import { useAuth } from '../contexts/AuthContext';
 import api from '../services/api';
-import { Match, APIResponse } from '../types';
+import { Match, APIResponse, PaginatedResponse } from '../types';
 import { FiUsers, FiClipboard, FiStar, FiArrowRight, FiSearch, FiAward, FiTrendingUp, FiLoader, FiInbox } from 'react-icons/fi';
 
 interface DashboardStats {
@@ -29,12 +29,12 @@ const Dashboard: React.FC = () => {
       setError(null);
 
       const [matchesResponse, pendingResponse] = await Promise.all([
-        api.get<{ matches: Match[]; total: number }>('/matches'),
+        api.get<PaginatedResponse<Match>>('/matches'),
         api.get<{ received: any[]; sent: any[] }>('/matches/requests/pending'),
       ]);
 
       const totalMatches = matchesResponse?.success && matchesResponse.data
-        ? (matchesResponse.data.matches || []).length
+        ? matchesResponse.data.total
         : 0;
       const pendingRequests = pendingResponse?.success && pendingResponse.data
         ? (pendingResponse.data.received || []).length
@@ -33,7 +33,7 @@ const Leaderboard: React.FC = () => {
     setLoading(true);
     setError(null);
     try {
-      const response: APIResponse<{ entries: any[]; category: string }> = await api.get('/leaderboard', {
+      const response: APIResponse<PaginatedResponse<any> & { category: string }> = await api.get('/leaderboard', {
         params: {
           category: activeCategory,
           limit: 10,
@@ -41,7 +41,7 @@ const Leaderboard: React.FC = () => {
       });
 
       if (response.success && response.data) {
-        const items = response.data.entries || [];
+        const items = response.data.items || [];
         const ranked = items.map((entry: any, i: number) => ({
           ...entry.user,
           id: entry.user?.id || entry.id,
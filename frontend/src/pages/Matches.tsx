@@ -2,7 +2,7 @@ import React, { useState, useEffect, useCallback } from 'react';
 import { Link } from 'react-router-dom';
 import { useAuth } from '../contexts/AuthContext';
 import api from '../services/api';
-import { Match, MatchRequest, APIResponse } from '../types';
+import { Match, MatchRequest, APIResponse, PaginatedResponse } from '../types';
 import toast from 'react-hot-toast';
 import { Loader2, Handshake, MessageSquare, Sparkles, UserCheck, CheckCircle, XCircle, ArrowDownLeft, ArrowUpRight } from 'lucide-react';
 
@@ -29,12 +29,12 @@ const Matches: React.FC = () => {
     setError(null);
     try {
       const [matchesRes, pendingRes] = await Promise.all([
-        api.get<{ matches: Match[]; total: number }>('/matches'),
+        api.get<PaginatedResponse<Match>>('/matches'),
         api.get<{ received: MatchRequest[]; sent: MatchRequest[] }>('/matches/requests/pending'),
       ]);
 
       if (matchesRes.success && matchesRes.data) {
-        setActiveMatches(matchesRes.data.matches || []);
+        setActiveMatches(matchesRes.data.items || []);
       }
       if (pendingRes.success && pendingRes.data) {
         setIncomingRequests(pendingRes.data.received || []);
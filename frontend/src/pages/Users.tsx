@@ -32,14 +32,14 @@ const Users: React.FC = () => {
     setError(null);
     try {
       const skillParams = skills.length > 0 ? skills.join(',') : undefined;
-      const response: APIResponse<{ users: User[]; total: number; page: number; pages: number }> = await api.get('/users', {
+      const response: APIResponse<PaginatedResponse<User>> = await api.get('/users', {
         params: { page, limit: 12, search: search || undefined, skills: skillParams },
       });
 
       if (response.success && response.data) {
-        setUsers(response.data.users || []);
+        setUsers(response.data.items || []);
         setCurrentPage(response.data.page);
-        setTotalPages(response.data.pages);
+        setTotalPages(Math.max(1, Math.ceil(response.data.total / response.data.limit)));
       } else {
         throw new Error(response.error?.message || 'Failed to fetch users');
       }
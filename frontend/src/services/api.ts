@@ -1,11 +1,63 @@
 // src/services/api.ts
-import axios, { AxiosInstance, AxiosError, AxiosResponse } from "axios";
+import axios, {
+  AxiosInstance,
+  AxiosError,
+  AxiosResponse,
+  InternalAxiosRequestConfig,
+} from "axios";
 import { APIResponse } from "../types";
 
 const API_BASE_URL =
   import.meta.env.VITE_API_BASE_URL || "http://localhost:8080/api/v1";
 
-const apiClient: AxiosInstance = axios.create({
+export const TOKEN_KEY = "jwt_token";
+export const REFRESH_TOKEN_KEY = "refresh_token";
+
+// Fired on window whenever the stored tokens change, so AuthContext can
+// follow refreshes done here.
+export const TOKEN_CHANGED_EVENT = "auth:token-changed";
+
+export function storeTokens(token: string, refreshToken?: string) {
+  localStorage.setItem(TOKEN_KEY, token);
+  if (refreshToken) {
+    localStorage.setItem(REFRESH_TOKEN_KEY, refreshToken);
+  }
+  window.dispatchEvent(new Event(TOKEN_CHANGED_EVENT));
+}
+
+export function clearTokens() {
+  localStorage.removeItem(TOKEN_KEY);
+  localStorage.removeItem(REFRESH_TOKEN_KEY);
+  window.dispatchEvent(new Event(TOKEN_CHANGED_EVENT));
+}
+
+// One refresh at a time: concurrent 401s wait for the same request, since
+// each refresh token can only be used once.
+let refreshing: Promise<string | null> | null = null;
+
+export function refreshAccessToken(): Promise<string | null> {
+  if (!refreshing) {
+    refreshing = (async () => {
+      const refreshToken = localStorage.getItem(REFRESH_TOKEN_KEY);
+      if (!refreshToken) return null;
+      try {
+        const response = await axios.post(`${API_BASE_URL}/auth/refresh`, {
+          refresh_token: refreshToken,
+        });
+        const { token, refresh_token } = response.data;
+        storeTokens(token, refresh_token);
+        return token as string;
+      } catch {
+        return null;
+      }
+    })().finally(() => {
+      refreshing = null;
+    });
+  }
+  return refreshing;
+}
+
+export const apiClient: AxiosInstance = axios.create({
   baseURL: API_BASE_URL,
   headers: {
     "Content-Type": "application/json",
@@ -15,7 +67,7 @@ const apiClient: AxiosInstance = axios.create({
 // Request interceptor: attach JWT
 apiClient.interceptors.request.use(
   (config) => {
-    const token = localStorage.getItem("jwt_token");
+    const token = localStorage.getItem(TOKEN_KEY);
     if (token) {
       config.headers.Authorization = `Bearer ${token}`;
     }
@@ -24,13 +76,23 @@ apiClient.interceptors.request.use(
   (error) => Promise.reject(error)
 );
 
-// Response interceptor: handle auth errors
+// Response interceptor: on a 401, refresh the access token once and retry
 apiClient.interceptors.response.use(
   (response) => response,
   async (error: AxiosError) => {
-    if (error.response?.status === 401) {
+    const config = error.config as
+      | (InternalAxiosRequestConfig & { _retried?: boolean })
+      | undefined;
+
+    if (error.response?.status === 401 && config && !config._retried) {
+      config._retried = true;
+      const token = await refreshAccessToken();
+      if (token) {
+        config.headers.Authorization = `Bearer ${token}`;
+        return apiClient(config);
+      }
       console.warn("Unauthorized — removing token");
-      localStorage.removeItem("jwt_token");
+      clearTokens();
     }
     return Promise.reject(error);
   }
@@ -1,12 +1,20 @@
 // src/services/auth.ts
 import axios, { AxiosError } from "axios";
 import { User } from "../types";
+import {
+  apiClient,
+  storeTokens,
+  clearTokens,
+  REFRESH_TOKEN_KEY,
+  TOKEN_KEY,
+} from "./api";
 
 const API_BASE_URL =
   import.meta.env.VITE_API_BASE_URL || "http://localhost:8080/api/v1";
 
 interface AuthResponse {
   token: string;
+  refresh_token?: string;
   user: User;
 }
 
@@ -19,12 +27,12 @@ const authService = {
         data
       );
 
-      const { token, user } = response.data;
+      const { token, refresh_token, user } = response.data;
 
-      // ✅ Save token
-      localStorage.setItem("jwt_token", token);
+      // ✅ Save both tokens
+      storeTokens(token, refresh_token);
 
-      return { token, user };
+      return { token, refresh_token, user };
     } catch (error) {
       const err = error as AxiosError<any>;
       throw new Error(
@@ -43,12 +51,12 @@ const authService = {
         data
       );
 
-      const { token, user } = response.data;
+      const { token, refresh_token, user } = response.data;
 
-      // ✅ Save token
-      localStorage.setItem("jwt_token", token);
+      // ✅ Save both tokens
+      storeTokens(token, refresh_token);
 
-      return { token, user };
+      return { token, refresh_token, user };
     } catch (error) {
       const err = error as AxiosError<any>;
       throw new Error(
@@ -61,34 +69,41 @@ const authService = {
 
   // 🚪 LOGOUT
   async logout(): Promise<void> {
-    localStorage.removeItem("jwt_token");
+    const refreshToken = localStorage.getItem(REFRESH_TOKEN_KEY);
+    clearTokens();
+
+    // Revoke the refresh token server-side; signing out locally doesn't
+    // depend on it succeeding.
+    if (refreshToken) {
+      try {
+        await axios.post(`${API_BASE_URL}/auth/logout`, {
+          refresh_token: refreshToken,
+        });
+      } catch (error) {
+        console.warn("Logout request failed:", error);
+      }
+    }
   },
 
   // 👤 GET CURRENT USER  ✅ FIXED ENDPOINT
   async getCurrentUser(): Promise<User> {
-    const token = localStorage.getItem("jwt_token");
+    const token = localStorage.getItem(TOKEN_KEY);
 
     if (!token) {
       throw new Error("No token found");
     }
 
     try {
-      const response = await axios.get<User>(
-        `${API_BASE_URL}/users/me`,
-        {
-          headers: {
-            Authorization: `Bearer ${token}`,
-          },
-        }
-      );
+      // apiClient refreshes an expired access token and retries once
+      const response = await apiClient.get<User>("/users/me");
 
       return response.data;
     } catch (error) {
       const err = error as AxiosError<any>;
 
-      // ❗ Token invalid → clear it
-      if (err.response?.status === 401 || err.response?.status === 404) {
-        localStorage.removeItem("jwt_token");
+      // ❗ Account gone → clear tokens (401s are cleared by apiClient)
+      if (err.response?.status === 404) {
+        clearTokens();
       }
 
       throw new Error(
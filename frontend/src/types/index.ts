@@ -187,10 +187,11 @@ export interface APIResponse<T> {
   message?: string;
 }
 
+// Envelope returned by every list endpoint.
 export interface PaginatedResponse<T> {
-  data: T[];
+  items: T[];
   total: number;
   page: number;
   limit: number;
-  pages: number;
+  has_more: boolean;
 }
\ No newline at end of file
@@ -2,7 +2,9 @@ package domain
 
 import "time"
 
-// User represents a registered user with their skills.
+// User represents a registered user with their skills. SkillsTeach and
+// SkillsLearn are views of the user's user_skills rows, the backend API's
+// canonical skill model; the repository reads and writes them there.
 type User struct {
 	ID             string    `json:"id" db:"id"`
 	Email          string    `json:"email" db:"email"`
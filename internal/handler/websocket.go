@@ -3,8 +3,10 @@ package handler
 import (
 	"net/http"
 
+	"github.com/gorilla/websocket"
 	"github.com/labstack/echo/v4"
 
+	"github.com/yourusername/skillsync/internal/middleware"
 	"github.com/yourusername/skillsync/internal/repository"
 	ws "github.com/yourusername/skillsync/internal/websocket"
 	"github.com/yourusername/skillsync/pkg/auth"
@@ -14,13 +16,25 @@ type WebSocketHandler struct {
 	hub         *ws.Hub
 	messageRepo *repository.MessageRepository
 	jwt         *auth.JWTManager
+	checkOrigin func(r *http.Request) bool
+	upgrader    websocket.Upgrader
 }
 
-func NewWebSocketHandler(hub *ws.Hub, mr *repository.MessageRepository, jwt *auth.JWTManager) *WebSocketHandler {
-	return &WebSocketHandler{hub: hub, messageRepo: mr, jwt: jwt}
+func NewWebSocketHandler(hub *ws.Hub, mr *repository.MessageRepository, jwt *auth.JWTManager, allowedOrigins []string) *WebSocketHandler {
+	return &WebSocketHandler{
+		hub:         hub,
+		messageRepo: mr,
+		jwt:         jwt,
+		checkOrigin: ws.OriginChecker(allowedOrigins),
+		upgrader:    ws.NewUpgrader(hub.Config(), allowedOrigins),
+	}
 }
 
 func (h *WebSocketHandler) HandleConnection(c echo.Context) error {
+	if !h.checkOrigin(c.Request()) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Origin not allowed"})
+	}
+
 	token := c.QueryParam("token")
 	if token == "" {
 		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Missing token"})
@@ -31,7 +45,7 @@ func (h *WebSocketHandler) HandleConnection(c echo.Context) error {
 		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
 	}
 
-	conn, err := ws.Upgrader.Upgrade(c.Response(), c.Request(), nil)
+	conn, err := h.upgrader.Upgrade(c.Response(), c.Request(), middleware.SecurityHeaders())
 	if err != nil {
 		return err
 	}
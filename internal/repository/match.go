@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 
+	"github.com/lib/pq"
 	"github.com/yourusername/skillsync/internal/domain"
 )
 
@@ -83,11 +84,11 @@ func (r *MatchRepository) CountCompletedByUser(ctx context.Context, userID strin
 
 func (r *MatchRepository) GetUserByID(ctx context.Context, userID string) (*domain.User, error) {
 	var u domain.User
-	query := `SELECT id, email, username, full_name, skills_teach, skills_learn, skill_level, reputation_score
+	query := `SELECT id, email, username, full_name, ` + skillsTeachExpr + `, ` + skillsLearnExpr + `, skill_level, reputation_score
 	          FROM users WHERE id = $1`
 	err := r.db.QueryRowContext(ctx, query, userID).Scan(
 		&u.ID, &u.Email, &u.Username, &u.FullName,
-		&u.SkillsTeach, &u.SkillsLearn, &u.SkillLevel, &u.ReputationScore,
+		pq.Array(&u.SkillsTeach), pq.Array(&u.SkillsLearn), &u.SkillLevel, &u.ReputationScore,
 	)
 	if err != nil {
 		return nil, err
@@ -3,11 +3,21 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"strings"
 
 	"github.com/lib/pq"
 	"github.com/yourusername/skillsync/internal/domain"
 )
 
+// Skills are stored as user_skills rows, shared with the backend API. These
+// expressions present them as the teach and learn lists of domain.User.
+const (
+	skillsTeachExpr = `ARRAY(SELECT s.name FROM user_skills us JOIN skills s ON s.id = us.skill_id
+	          WHERE us.user_id = users.id AND us.direction IN ('teach', 'both') ORDER BY s.name)`
+	skillsLearnExpr = `ARRAY(SELECT s.name FROM user_skills us JOIN skills s ON s.id = us.skill_id
+	          WHERE us.user_id = users.id AND us.direction IN ('learn', 'both') ORDER BY s.name)`
+)
+
 type UserRepository struct {
 	db *sql.DB
 }
@@ -18,14 +28,25 @@ func NewUserRepository(db *sql.DB) *UserRepository {
 
 func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
 	query := `
-		INSERT INTO users (email, username, password_hash, full_name, skills_teach, skills_learn, skill_level, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+		INSERT INTO users (email, username, password_hash, full_name, skill_level, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
 		RETURNING id, created_at, updated_at`
 
-	return r.db.QueryRowContext(ctx, query,
-		user.Email, user.Username, user.PasswordHash, user.FullName,
-		pq.Array(user.SkillsTeach), pq.Array(user.SkillsLearn), user.SkillLevel,
-	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := tx.QueryRowContext(ctx, query,
+		user.Email, user.Username, user.PasswordHash, user.FullName, user.SkillLevel,
+	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt); err != nil {
+		return err
+	}
+	if err := setSkills(ctx, tx, user.ID, user.SkillsTeach, user.SkillsLearn, user.SkillLevel); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 func (r *UserRepository) FindByID(ctx context.Context, id string) (*domain.User, error) {
@@ -35,7 +56,7 @@ func (r *UserRepository) FindByID(ctx context.Context, id string) (*domain.User,
 	var isOnline sql.NullBool
 	var lastActiveAt, createdAt, updatedAt sql.NullTime
 
-	query := `SELECT id, email, username, full_name, bio, avatar_url, skills_teach, skills_learn,
+	query := `SELECT id, email, username, full_name, bio, avatar_url, ` + skillsTeachExpr + `, ` + skillsLearnExpr + `,
 	          skill_level, reputation_score, is_online, last_active_at, created_at, updated_at
 	          FROM users WHERE id = $1`
 
@@ -96,14 +117,15 @@ func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*domain
 }
 
 func (r *UserRepository) List(ctx context.Context, skill, level string) ([]domain.User, error) {
-	query := `SELECT id, email, username, full_name, bio, avatar_url, skills_teach, skills_learn,
+	query := `SELECT id, email, username, full_name, bio, avatar_url, ` + skillsTeachExpr + `, ` + skillsLearnExpr + `,
 	          skill_level, reputation_score, is_online, created_at
 	          FROM users WHERE 1=1`
 	args := []any{}
 	argIdx := 1
 
 	if skill != "" {
-		query += ` AND ($` + string(rune('0'+argIdx)) + ` = ANY(skills_teach) OR $` + string(rune('0'+argIdx)) + ` = ANY(skills_learn))`
+		query += ` AND EXISTS (SELECT 1 FROM user_skills us JOIN skills s ON s.id = us.skill_id
+		          WHERE us.user_id = users.id AND lower(s.name) = lower($` + string(rune('0'+argIdx)) + `))`
 		args = append(args, skill)
 		argIdx++
 	}
@@ -151,12 +173,36 @@ func (r *UserRepository) List(ctx context.Context, skill, level string) ([]domai
 }
 
 func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
-	query := `UPDATE users SET full_name=$1, bio=$2, avatar_url=$3, skills_teach=$4, skills_learn=$5, updated_at=NOW()
-	          WHERE id=$6`
-	_, err := r.db.ExecContext(ctx, query,
-		user.FullName, user.Bio, user.AvatarURL, pq.Array(user.SkillsTeach), pq.Array(user.SkillsLearn), user.ID,
-	)
-	return err
+	query := `UPDATE users SET full_name=$1, bio=$2, avatar_url=$3, updated_at=NOW()
+	          WHERE id=$4`
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, query, user.FullName, user.Bio, user.AvatarURL, user.ID); err != nil {
+		return err
+	}
+	if err := setSkills(ctx, tx, user.ID, user.SkillsTeach, user.SkillsLearn, user.SkillLevel); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SetSkills replaces a user's skills with the given teach and learn lists.
+func (r *UserRepository) SetSkills(ctx context.Context, userID string, teach, learn []string, level string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := setSkills(ctx, tx, userID, teach, learn, level); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 func (r *UserRepository) UpdateSkillLevel(ctx context.Context, userID, skill, level string) error {
@@ -175,7 +221,7 @@ func (r *UserRepository) FindByOAuth(ctx context.Context, provider, oauthID stri
 	var user domain.User
 	var lastActiveAt, createdAt, updatedAt sql.NullTime
 	query := `SELECT id, email, username, COALESCE(full_name,''), COALESCE(bio,''), COALESCE(avatar_url,''),
-	          skills_teach, skills_learn, COALESCE(skill_level,'beginner'), COALESCE(reputation_score,0),
+	          ` + skillsTeachExpr + `, ` + skillsLearnExpr + `, COALESCE(skill_level,'beginner'), COALESCE(reputation_score,0),
 	          COALESCE(is_online,false), last_active_at, created_at, updated_at
 	          FROM users WHERE oauth_provider = $1 AND oauth_id = $2`
 
@@ -204,12 +250,85 @@ func (r *UserRepository) FindByOAuth(ctx context.Context, provider, oauthID stri
 
 func (r *UserRepository) CreateOAuthUser(ctx context.Context, user *domain.User, provider, oauthID string) error {
 	query := `
-		INSERT INTO users (email, username, password_hash, full_name, avatar_url, skills_teach, skills_learn, skill_level, oauth_provider, oauth_id, created_at, updated_at)
-		VALUES ($1, $2, '', $3, $4, $5, $6, 'beginner', $7, $8, NOW(), NOW())
+		INSERT INTO users (email, username, password_hash, full_name, avatar_url, skill_level, oauth_provider, oauth_id, created_at, updated_at)
+		VALUES ($1, $2, '', $3, $4, 'beginner', $5, $6, NOW(), NOW())
 		RETURNING id, created_at, updated_at`
 
-	return r.db.QueryRowContext(ctx, query,
-		user.Email, user.Username, user.FullName, user.AvatarURL,
-		pq.Array(user.SkillsTeach), pq.Array(user.SkillsLearn), provider, oauthID,
-	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := tx.QueryRowContext(ctx, query,
+		user.Email, user.Username, user.FullName, user.AvatarURL, provider, oauthID,
+	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt); err != nil {
+		return err
+	}
+	if err := setSkills(ctx, tx, user.ID, user.SkillsTeach, user.SkillsLearn, "beginner"); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// setSkills makes userID's user_skills rows match teach and learn, matching
+// skill names case-insensitively and creating unknown skills. Skills the
+// user keeps retain their proficiency and peer verifications; new ones
+// start at level.
+func setSkills(ctx context.Context, tx *sql.Tx, userID string, teach, learn []string, level string) error {
+	switch level {
+	case "beginner", "intermediate", "advanced":
+	default:
+		level = "beginner"
+	}
+
+	directions := map[string]string{}
+	names := map[string]string{}
+	var keys []string
+	add := func(list []string, direction string) {
+		for _, name := range list {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			key := strings.ToLower(name)
+			if cur, ok := directions[key]; !ok {
+				directions[key] = direction
+				names[key] = name
+				keys = append(keys, key)
+			} else if cur != direction {
+				directions[key] = "both"
+			}
+		}
+	}
+	add(teach, "teach")
+	add(learn, "learn")
+
+	skillIDs := make([]int64, 0, len(keys))
+	for _, key := range keys {
+		var skillID int64
+		err := tx.QueryRowContext(ctx, `SELECT id FROM skills WHERE lower(name) = $1 ORDER BY id LIMIT 1`, key).Scan(&skillID)
+		if err == sql.ErrNoRows {
+			err = tx.QueryRowContext(ctx, `
+				INSERT INTO skills (name, category) VALUES ($1, 'other')
+				ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+				RETURNING id`, names[key]).Scan(&skillID)
+		}
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO user_skills (user_id, skill_id, proficiency_level, direction)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (user_id, skill_id) DO UPDATE SET direction = EXCLUDED.direction`,
+			userID, skillID, level, directions[key]); err != nil {
+			return err
+		}
+		skillIDs = append(skillIDs, skillID)
+	}
+
+	_, err := tx.ExecContext(ctx, `DELETE FROM user_skills WHERE user_id = $1 AND NOT (skill_id = ANY($2))`,
+		userID, pq.Array(skillIDs))
+	return err
 }
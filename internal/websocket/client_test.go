@@ -0,0 +1,32 @@
+package websocket
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOriginChecker(t *testing.T) {
+	check := OriginChecker([]string{"https://app.example.com"})
+
+	tests := []struct {
+		name   string
+		origin string
+		want   bool
+	}{
+		{"allowed", "https://app.example.com", true},
+		{"disallowed", "https://evil.example.com", false},
+		{"missing", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/ws", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if got := check(req); got != tt.want {
+				t.Fatalf("OriginChecker(%q) = %v, want %v", tt.origin, got, tt.want)
+			}
+		})
+	}
+}
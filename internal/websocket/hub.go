@@ -9,6 +9,7 @@ type Hub struct {
 	Unregister chan *Client
 	Broadcast  chan *RoomMessage
 	mu         sync.RWMutex
+	cfg        Config
 }
 
 type RoomMessage struct {
@@ -17,8 +18,10 @@ type RoomMessage struct {
 	Sender  string
 }
 
-func NewHub() *Hub {
+// NewHub creates a hub whose clients use cfg.
+func NewHub(cfg Config) *Hub {
 	return &Hub{
+		cfg:        cfg,
 		clients:    make(map[string]*Client),
 		rooms:      make(map[string]map[*Client]bool),
 		Register:   make(chan *Client),
@@ -27,6 +30,11 @@ func NewHub() *Hub {
 	}
 }
 
+// Config returns the settings the hub's clients use.
+func (h *Hub) Config() Config {
+	return h.cfg
+}
+
 func (h *Hub) Run() {
 	for {
 		select {
@@ -0,0 +1,12 @@
+-- skills and user_skills stay: the backend API owns them.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS skills_teach TEXT[] DEFAULT '{}';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS skills_learn TEXT[] DEFAULT '{}';
+
+UPDATE users u SET
+    skills_teach = ARRAY(SELECT s.name FROM user_skills us JOIN skills s ON s.id = us.skill_id
+                         WHERE us.user_id = u.id AND us.direction IN ('teach', 'both') ORDER BY s.name),
+    skills_learn = ARRAY(SELECT s.name FROM user_skills us JOIN skills s ON s.id = us.skill_id
+                         WHERE us.user_id = u.id AND us.direction IN ('learn', 'both') ORDER BY s.name);
+
+CREATE INDEX IF NOT EXISTS idx_users_skills_teach ON users USING GIN(skills_teach);
+CREATE INDEX IF NOT EXISTS idx_users_skills_learn ON users USING GIN(skills_learn);
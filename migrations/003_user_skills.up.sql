@@ -0,0 +1,62 @@
+-- Skills move from the skills_teach / skills_learn arrays on users into
+-- user_skills rows, the model the backend API reads and writes. Both apps
+-- share this database; whichever migrates first does the backfill.
+CREATE TABLE IF NOT EXISTS skills (
+    id          BIGSERIAL PRIMARY KEY,
+    name        VARCHAR(100) NOT NULL,
+    category    VARCHAR(50) NOT NULL DEFAULT 'other',
+    description TEXT DEFAULT '',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_skills_name ON skills (name);
+
+CREATE TABLE IF NOT EXISTS user_skills (
+    id                BIGSERIAL PRIMARY KEY,
+    user_id           UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    skill_id          BIGINT NOT NULL REFERENCES skills(id) ON DELETE CASCADE,
+    proficiency_level VARCHAR(20) NOT NULL DEFAULT 'beginner',
+    years_experience  DECIMAL(4,1) DEFAULT 0,
+    credibility_score DECIMAL(10,2) DEFAULT 0,
+    verified_by_peers INTEGER DEFAULT 0,
+    direction         VARCHAR(10) NOT NULL DEFAULT 'both',
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_skill ON user_skills (user_id, skill_id);
+CREATE INDEX IF NOT EXISTS idx_user_skills_skill ON user_skills (skill_id);
+
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.columns
+               WHERE table_name = 'users' AND column_name = 'skills_teach') THEN
+        -- Reuse skills that differ only in case.
+        INSERT INTO skills (name, category)
+        SELECT DISTINCT ON (lower(btrim(n))) btrim(n), 'other'
+        FROM users u, unnest(u.skills_teach || u.skills_learn) AS n
+        WHERE btrim(n) <> ''
+          AND NOT EXISTS (SELECT 1 FROM skills s WHERE lower(s.name) = lower(btrim(n)))
+        ORDER BY lower(btrim(n)), btrim(n);
+
+        -- A skill in both arrays becomes one row with direction 'both'.
+        INSERT INTO user_skills (user_id, skill_id, proficiency_level, direction)
+        SELECT u.id, s.id,
+               CASE WHEN u.skill_level IN ('beginner', 'intermediate', 'advanced')
+                    THEN u.skill_level ELSE 'beginner' END,
+               CASE WHEN bool_or(t.teach) AND bool_or(NOT t.teach) THEN 'both'
+                    WHEN bool_or(t.teach) THEN 'teach'
+                    ELSE 'learn' END
+        FROM users u
+        CROSS JOIN LATERAL (
+            SELECT btrim(n) AS name, true AS teach FROM unnest(u.skills_teach) AS n
+            UNION ALL
+            SELECT btrim(n), false FROM unnest(u.skills_learn) AS n
+        ) t
+        JOIN skills s ON lower(s.name) = lower(t.name)
+        GROUP BY u.id, s.id, u.skill_level
+        ON CONFLICT (user_id, skill_id) DO NOTHING;
+
+        ALTER TABLE users DROP COLUMN skills_teach;
+        ALTER TABLE users DROP COLUMN skills_learn;
+    END IF;
+END $$;
@@ -1,12 +1,14 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
 	"os"
 
 	_ "github.com/lib/pq"
+	"github.com/yourusername/skillsync/internal/repository"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -38,14 +40,24 @@ func main() {
 			[]string{"Rust", "C++", "Linux"}, []string{"React", "TypeScript"}},
 	}
 
+	users := repository.NewUserRepository(db)
 	for _, u := range seedUsers {
 		hash, _ := bcrypt.GenerateFromPassword([]byte(u.password), bcrypt.DefaultCost)
-		_, err := db.Exec(`
-			INSERT INTO users (email, username, password_hash, full_name, bio, skills_teach, skills_learn)
-			VALUES ($1, $2, $3, $4, $5, $6, $7)
-			ON CONFLICT (email) DO NOTHING`,
-			u.email, u.username, string(hash), u.fullName, u.bio, u.teach, u.learn,
-		)
+		var id string
+		err := db.QueryRow(`
+			INSERT INTO users (email, username, password_hash, full_name, bio)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (email) DO NOTHING
+			RETURNING id`,
+			u.email, u.username, string(hash), u.fullName, u.bio,
+		).Scan(&id)
+		if err == sql.ErrNoRows {
+			fmt.Printf("User %s already exists, skipping\n", u.username)
+			continue
+		}
+		if err == nil {
+			err = users.SetSkills(context.Background(), id, u.teach, u.learn, "beginner")
+		}
 		if err != nil {
 			log.Printf("Failed to seed user %s: %v", u.username, err)
 		} else {